@@ -1,22 +1,48 @@
 """MCP (Model Context Protocol) API routes - OpenAI Compatible."""
 
 from typing import Optional, Dict, Any
-from fastapi import APIRouter, HTTPException, Request
+from fastapi import APIRouter, Depends, HTTPException, Request
 from fastapi.responses import JSONResponse
+import jwt
 import uuid
 import json
 import traceback
 
+from app.config.settings import get_settings
+from app.database.users import UserOperations
 from app.models.mcp import (
     McpListToolsResponse,
     McpCallResponse,
     McpResourceResponse,
 )
+from app.services.auth import verify_admin_user
 from app.services.mcp_service import McpWorkshopService
 
 router = APIRouter()
 
 
+def is_admin_request(request: Request) -> bool:
+    """Check whether the request carries a valid admin bearer token.
+
+    The JSON-RPC endpoint is public for workshop resources, so admin-scoped
+    resources (analytics) verify the Authorization header by hand instead of
+    through a FastAPI dependency.
+    """
+    auth = request.headers.get("Authorization", "")
+    if not auth.startswith("Bearer "):
+        return False
+    settings = get_settings()
+    try:
+        payload = jwt.decode(auth[len("Bearer "):], settings.secret_key, algorithms=[settings.algorithm])
+        user_id = payload.get("sub")
+    except jwt.PyJWTError:
+        return False
+    if not user_id:
+        return False
+    user = UserOperations.get_user_by_id(user_id)
+    return bool(user and user.get("is_admin", False))
+
+
 def create_jsonrpc_response(id: Any, result: Any = None, error: Any = None):
     """Create a JSON-RPC 2.0 response"""
     response = {
@@ -159,6 +185,11 @@ async def mcp_jsonrpc_endpoint(request: Request):
                         "uri": "studios://all",
                         "name": "All Studios",
                         "description": "Complete list of dance studios"
+                    },
+                    {
+                        "uri": "analytics://kpis",
+                        "name": "Platform KPIs",
+                        "description": "Admin-scoped platform analytics: weekly actives, booking conversion, top artists by follows, revenue summaries. Accepts start_date/end_date (YYYY-MM-DD) params."
                     }
                 ]
             }
@@ -177,7 +208,19 @@ async def mcp_jsonrpc_endpoint(request: Request):
                 )
             
             resource_type = uri.split("://")[0]
-            resource_data = McpWorkshopService.get_resource(resource_type)
+            if resource_type == "analytics":
+                if not is_admin_request(request):
+                    return JSONResponse(
+                        create_jsonrpc_error(request_id, -32603, "Admin access required for analytics resource"),
+                        status_code=403,
+                        headers=headers
+                    )
+                resource_data = McpWorkshopService.get_analytics_resource(
+                    start_date=params.get("start_date"),
+                    end_date=params.get("end_date"),
+                )
+            else:
+                resource_data = McpWorkshopService.get_resource(resource_type)
             
             result = {
                 "contents": [
@@ -273,6 +316,25 @@ async def call_mcp_tool(
         )
 
 
+@router.get("/resources/analytics", response_model=McpResourceResponse)
+async def get_mcp_analytics_resource(
+    start_date: Optional[str] = None,
+    end_date: Optional[str] = None,
+    user_id: str = Depends(verify_admin_user),
+):
+    """Get the platform KPI resource (admin only, REST endpoint for testing).
+
+    Declared before the generic resource route so /resources/analytics
+    resolves here and picks up the admin dependency.
+    """
+    try:
+        return McpWorkshopService.get_analytics_resource(start_date=start_date, end_date=end_date)
+    except Exception as e:
+        print(f"Error getting MCP analytics resource: {e}")
+        print(f"Traceback: {traceback.format_exc()}")
+        raise HTTPException(status_code=500, detail=f"Failed to get resource: {str(e)}")
+
+
 @router.get("/resources/{resource_type}", response_model=McpResourceResponse)
 async def get_mcp_resource(resource_type: str, resource_id: Optional[str] = None):
     """Get a resource with MCP metadata (REST endpoint for testing)"""
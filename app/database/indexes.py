@@ -1,6 +1,7 @@
 """Database index management for MongoDB collections."""
 
 import logging
+import os
 from pymongo import ASCENDING, DESCENDING, IndexModel
 from pymongo.errors import OperationFailure
 from typing import List, Dict, Any
@@ -234,6 +235,111 @@ DISCOVERY_INDEX_DEFINITIONS: Dict[str, List[Dict[str, Any]]] = {
 }
 
 
+# Central TTL retention policy for transient collections, expressed as
+# (timestamp field, retention seconds). A retention of 0 means the document
+# carries its own expiry in the field (MongoDB's document-driven TTL).
+# Override per collection via TTL_RETENTION_OVERRIDES, e.g.
+# "discovery.domain_events=2592000,dance_app.otp_attempts=0".
+TTL_RETENTION_SECONDS: Dict[str, Dict[str, tuple]] = {
+    "dance_app": {
+        # OTP attempts and rate-limit counters carry their own expires_at.
+        "otp_attempts": ("expires_at", 0),
+        "rate_limits": ("expires_at", 0),
+    },
+    "discovery": {
+        # Replay/idempotency nonces only need to outlive the replay window;
+        # an hour is a comfortable backstop over the active pruning.
+        "replay_nonces": ("seen_at", 60 * 60),
+        # Raw analytics events are exported to the warehouse continuously;
+        # 90 days covers export gaps and ad-hoc debugging.
+        "domain_events": ("emitted_at", 90 * 24 * 60 * 60),
+    },
+}
+
+
+def _ttl_retention_with_overrides() -> Dict[str, Dict[str, tuple]]:
+    """Apply TTL_RETENTION_OVERRIDES env entries onto the central policy."""
+    policy = {
+        db_name: dict(collections)
+        for db_name, collections in TTL_RETENTION_SECONDS.items()
+    }
+    overrides = os.environ.get("TTL_RETENTION_OVERRIDES", "")
+    for entry in overrides.split(","):
+        entry = entry.strip()
+        if not entry or "=" not in entry:
+            continue
+        target, _, seconds = entry.partition("=")
+        if "." not in target:
+            continue
+        db_name, _, collection_name = target.partition(".")
+        try:
+            seconds = int(seconds)
+        except ValueError:
+            logger.warning(f"Ignoring invalid TTL override: {entry}")
+            continue
+        existing = policy.setdefault(db_name, {}).get(collection_name)
+        field = existing[0] if existing else "created_at"
+        policy[db_name][collection_name] = (field, seconds)
+    return policy
+
+
+def ensure_ttl_indexes() -> Dict[str, List[str]]:
+    """
+    Ensure TTL indexes exist with the centrally configured retention.
+
+    An existing TTL index whose expireAfterSeconds differs from the policy
+    is updated in place via collMod, so retention changes roll out without
+    dropping and rebuilding the index.
+
+    Returns:
+        Dictionary mapping db.collection names to ensured TTL index names
+    """
+    client = get_mongo_client()
+    results = {}
+
+    for db_name, collections in _ttl_retention_with_overrides().items():
+        db = client[db_name]
+        for collection_name, (field, seconds) in collections.items():
+            index_name = f"{field}_ttl"
+            try:
+                existing = None
+                for index in db[collection_name].list_indexes():
+                    if index["name"] == index_name:
+                        existing = index
+                        break
+
+                if existing is None:
+                    db[collection_name].create_index(
+                        [(field, ASCENDING)],
+                        expireAfterSeconds=seconds,
+                        name=index_name,
+                    )
+                    logger.info(
+                        f"Created TTL index '{index_name}' on "
+                        f"{db_name}.{collection_name} ({seconds}s)"
+                    )
+                elif existing.get("expireAfterSeconds") != seconds:
+                    db.command({
+                        "collMod": collection_name,
+                        "index": {
+                            "name": index_name,
+                            "expireAfterSeconds": seconds,
+                        },
+                    })
+                    logger.info(
+                        f"Updated TTL retention on {db_name}.{collection_name} "
+                        f"to {seconds}s"
+                    )
+                results[f"{db_name}.{collection_name}"] = [index_name]
+            except OperationFailure as e:
+                logger.error(
+                    f"Failed to ensure TTL index on {db_name}.{collection_name}: {e}"
+                )
+                results[f"{db_name}.{collection_name}"] = []
+
+    return results
+
+
 def create_indexes_for_collection(
     db,
     collection_name: str,
@@ -320,6 +426,10 @@ def ensure_indexes() -> Dict[str, List[str]]:
             logger.error(f"Error creating indexes for discovery.{collection_name}: {e}")
             results[f"discovery.{collection_name}"] = []
 
+    # TTL indexes for transient data, driven by the central retention policy
+    for key, created in ensure_ttl_indexes().items():
+        results.setdefault(key, []).extend(created)
+
     return results
 
 
@@ -2,6 +2,7 @@
 
 import time
 import uuid
+from datetime import datetime, timedelta
 from typing import List, Dict, Any, Optional
 from app.models.mcp import (
     McpToolDefinition,
@@ -10,6 +11,7 @@ from app.models.mcp import (
     McpResourceResponse,
 )
 from app.database.workshops import DatabaseOperations
+from utils.utils import get_mongo_client
 
 
 class McpWorkshopService:
@@ -153,6 +155,142 @@ class McpWorkshopService:
                 error=str(e)
             )
     
+    # Default window for the analytics resource when the caller does not
+    # parameterize the date range.
+    ANALYTICS_DEFAULT_RANGE_DAYS = 30
+
+    @classmethod
+    def get_analytics_resource(
+        cls,
+        start_date: Optional[str] = None,
+        end_date: Optional[str] = None,
+    ) -> McpResourceResponse:
+        """Build the admin-scoped platform KPI resource.
+
+        Includes weekly active users, booking conversion, top artists by
+        follows, and revenue summaries. start_date/end_date are YYYY-MM-DD
+        and bound the order-based KPIs; the window defaults to the last 30
+        days. Callers are responsible for admin scoping - this method does
+        not check authorization itself.
+        """
+        try:
+            end = (
+                datetime.strptime(end_date, "%Y-%m-%d") + timedelta(days=1)
+                if end_date else datetime.utcnow()
+            )
+            start = (
+                datetime.strptime(start_date, "%Y-%m-%d")
+                if start_date else end - timedelta(days=cls.ANALYTICS_DEFAULT_RANGE_DAYS)
+            )
+            if start >= end:
+                raise ValueError("start_date must be before end_date")
+
+            client = get_mongo_client()
+            orders = client["dance_app"]["orders"]
+            reactions = client["dance_app"]["reactions"]
+
+            # Weekly actives: distinct users who reacted or ordered in the
+            # last 7 days, independent of the requested range.
+            week_ago = datetime.utcnow() - timedelta(days=7)
+            active_users = set(
+                reactions.distinct("user_id", {"updated_at": {"$gte": week_ago}})
+            )
+            active_users.update(
+                orders.distinct("user_id", {"created_at": {"$gte": week_ago}})
+            )
+
+            range_filter = {"created_at": {"$gte": start, "$lt": end}}
+            orders_created = orders.count_documents(range_filter)
+            orders_paid = orders.count_documents({**range_filter, "status": "paid"})
+            conversion_rate = (
+                round(orders_paid / orders_created, 4) if orders_created else 0.0
+            )
+
+            # Follows are NOTIFY reactions, matching the admin app-insights
+            # endpoint's definition.
+            top_artists = list(reactions.aggregate([
+                {"$match": {
+                    "entity_type": "ARTIST",
+                    "reaction": "NOTIFY",
+                    "is_deleted": {"$ne": True},
+                }},
+                {"$group": {"_id": "$entity_id", "follows": {"$sum": 1}}},
+                {"$sort": {"follows": -1}},
+                {"$limit": 10},
+            ]))
+            artist_names = {
+                a["artist_id"]: a.get("artist_name")
+                for a in client["discovery"]["artists_v2"].find(
+                    {"artist_id": {"$in": [t["_id"] for t in top_artists]}},
+                    {"artist_id": 1, "artist_name": 1},
+                )
+            }
+            top_artists_by_follows = [
+                {
+                    "artist_id": t["_id"],
+                    "artist_name": artist_names.get(t["_id"]),
+                    "follows": t["follows"],
+                }
+                for t in top_artists
+            ]
+
+            revenue = [
+                {
+                    "currency": row["_id"] or "INR",
+                    "total_amount": row["total_amount"],
+                    "orders": row["orders"],
+                }
+                for row in orders.aggregate([
+                    {"$match": {**range_filter, "status": "paid"}},
+                    {"$group": {
+                        "_id": "$currency",
+                        "total_amount": {"$sum": "$amount"},
+                        "orders": {"$sum": 1},
+                    }},
+                    {"$sort": {"total_amount": -1}},
+                ])
+            ]
+
+            data = {
+                "range": {
+                    "start": start.strftime("%Y-%m-%d"),
+                    "end": (end - timedelta(days=1)).strftime("%Y-%m-%d") if end_date else end.strftime("%Y-%m-%d"),
+                },
+                "weekly_active_users": len(active_users),
+                "booking_conversion": {
+                    "orders_created": orders_created,
+                    "orders_paid": orders_paid,
+                    "rate": conversion_rate,
+                },
+                "top_artists_by_follows": top_artists_by_follows,
+                "revenue": revenue,
+            }
+            return McpResourceResponse(
+                resource_type="analytics",
+                resource_id="kpis",
+                data=data,
+                metadata={
+                    "server_label": cls.SERVER_LABEL,
+                    "server_version": cls.SERVER_VERSION,
+                    "admin_scoped": True,
+                    "cache_ttl": 300,
+                    "last_updated": time.time(),
+                },
+                timestamp=time.time()
+            )
+        except Exception as e:
+            return McpResourceResponse(
+                resource_type="analytics",
+                resource_id="kpis",
+                data=None,
+                metadata={
+                    "server_label": cls.SERVER_LABEL,
+                    "error": str(e),
+                    "timestamp": time.time(),
+                },
+                timestamp=time.time()
+            )
+
     @classmethod
     def get_resource(cls, resource_type: str, resource_id: Optional[str] = None) -> McpResourceResponse:
         """Get a resource with MCP metadata."""
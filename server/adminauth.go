@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/services"
+)
+
+// Admin authentication for the legacy /admin/api/* CRUD routes: every call
+// must carry a Bearer JWT for an is_admin account. Mutations are audit
+// logged with who changed what so studio and workshop edits are traceable.
+
+// adminAuth verifies admin JWTs; wired up in main before the server starts.
+var adminAuth *services.AuthServiceImpl
+
+// auditBodyLimit caps how much of a mutation body the audit log keeps.
+const auditBodyLimit = 4096
+
+// requireAdmin rejects requests without a valid admin Bearer token and audit
+// logs mutations that went through. 401 covers missing/invalid tokens, 403
+// covers valid tokens on non-admin or restricted accounts.
+func requireAdmin(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		token := bearerToken(ctx)
+		if token == "" {
+			writeJSONError(ctx, fasthttp.StatusUnauthorized, "Missing authentication token")
+			return
+		}
+		userID, err := adminAuth.VerifyToken(token)
+		if err != nil {
+			writeJSONError(ctx, fasthttp.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+		user, err := adminAuth.GetUser(userID)
+		if err != nil {
+			var stateErr *services.AccountStateError
+			if errors.As(err, &stateErr) {
+				writeJSONError(ctx, fasthttp.StatusForbidden, stateErr.Message)
+				return
+			}
+			writeJSONError(ctx, fasthttp.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+		if !user.IsAdmin {
+			writeJSONError(ctx, fasthttp.StatusForbidden, "Admin access required")
+			return
+		}
+
+		if !isMutation(string(ctx.Method())) {
+			next(ctx)
+			return
+		}
+
+		// Snapshot the targeted entity before and after the handler so the
+		// audit entry carries a real diff, and stamp the request ID on the
+		// response for correlation.
+		requestID := uuid.NewString()
+		ctx.Response.Header.Set("X-Request-ID", requestID)
+		ids := auditEntityIDs(ctx)
+		entityType, entityID, before := services.ResolveAuditEntity(cfg, ids)
+
+		next(ctx)
+
+		auditAdminAction(userID, requestID, entityType, entityID, before, ctx)
+	}
+}
+
+// auditEntityIDs collects the candidate entity identifiers from the request
+// body and query string that the audit resolver understands.
+func auditEntityIDs(ctx *fasthttp.RequestCtx) map[string]string {
+	ids := map[string]string{}
+	var body map[string]interface{}
+	if err := json.Unmarshal(ctx.PostBody(), &body); err == nil {
+		for _, key := range []string{"workshop_id", "workshop_uuid", "studio_id", "artist_id"} {
+			if value, ok := body[key].(string); ok && value != "" {
+				ids[key] = value
+			}
+		}
+	}
+	for _, key := range []string{"workshop_id", "workshop_uuid", "studio_id", "artist_id"} {
+		if ids[key] == "" {
+			if value := string(ctx.QueryArgs().Peek(key)); value != "" {
+				ids[key] = value
+			}
+		}
+	}
+	return ids
+}
+
+// bearerToken extracts the token from the Authorization header.
+func bearerToken(ctx *fasthttp.RequestCtx) string {
+	header := string(ctx.Request.Header.Peek("Authorization"))
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return ""
+}
+
+// isMutation reports whether the method changes state and should be audited.
+func isMutation(method string) bool {
+	switch method {
+	case fasthttp.MethodPost, fasthttp.MethodPut, fasthttp.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// auditAdminAction records who called which admin mutation with what body,
+// the before/after snapshots of the entity it targeted, and how it ended.
+// Re-resolving after the handler gives the "after" side of the diff.
+func auditAdminAction(userID, requestID, entityType, entityID string, before bson.M, reqCtx *fasthttp.RequestCtx) {
+	body := reqCtx.PostBody()
+	if len(body) > auditBodyLimit {
+		body = body[:auditBodyLimit]
+	}
+	var after bson.M
+	if entityType != "" {
+		_, _, after = services.ResolveAuditEntity(cfg, auditEntityIDs(reqCtx))
+	}
+	services.RecordAuditLog(cfg, services.AuditLogEntry{
+		RequestID:     requestID,
+		UserID:        userID,
+		Method:        string(reqCtx.Method()),
+		Path:          string(reqCtx.Path()),
+		Status:        reqCtx.Response.StatusCode(),
+		EntityType:    entityType,
+		EntityID:      entityID,
+		Before:        before,
+		After:         after,
+		ChangedFields: services.DiffAuditDocs(before, after),
+		Body:          string(body),
+		LoggedAt:      time.Now().UTC(),
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"dance_api_go/database"
+)
+
+// Agent-facing surface: the MCP server covers agents that speak the
+// protocol, but plenty only do plain HTTP. /llms.txt tells them what the
+// site is and which endpoints to use, and /api/structured/workshops serves
+// the listing in a deliberately boring shape — stable IDs, units and
+// timezone spelled out in field names, no categorization to unpick.
+
+// llmsTxt is the machine-readable site description served at /llms.txt.
+const llmsTxt = `# Nachna
+
+Nachna lists dance workshops at partner studios in India. Listings are
+scraped from studio booking sites and refreshed continuously.
+
+## For agents
+
+- Structured workshop feed: GET /api/structured/workshops
+  Flat JSON array of upcoming sessions. Stable IDs: workshop_uuid,
+  studio_id, artist IDs. All dates/times are Asia/Kolkata (IST);
+  start_epoch_utc is Unix seconds. Prices are integer INR unless the
+  entry's currency field says otherwise.
+- Workshop detail: GET /api/workshop/{uuid}
+- Artists: GET /api/artists    Studios: GET /api/studios
+- Human-readable booking pages: https://nachna.com/web/{studio_id}
+
+Responses are JSON with snake_case fields. Endpoints are rate limited;
+identify your agent in the User-Agent header.
+`
+
+// structuredWorkshop is one session in the agent feed. Field names carry
+// the units so no out-of-band schema doc is needed.
+type structuredWorkshop struct {
+	WorkshopUUID  string   `json:"workshop_uuid"`
+	StudioID      string   `json:"studio_id"`
+	StudioName    string   `json:"studio_name"`
+	ArtistIDs     []string `json:"artist_ids"`
+	ArtistNames   *string  `json:"artist_names"`
+	Song          *string  `json:"song"`
+	EventType     *string  `json:"event_type"`
+	StartEpochUTC int64    `json:"start_epoch_utc"`
+	DateIST       *string  `json:"date_ist"`
+	TimeIST       *string  `json:"time_ist"`
+	PriceMinINR   int64    `json:"price_min_inr"`
+	PriceMaxINR   int64    `json:"price_max_inr"`
+	Currency      string   `json:"currency"`
+	BookingURL    string   `json:"booking_url"`
+}
+
+// structuredWorkshopFeed is the envelope around the session list; the
+// schema version lets agents pin against silent shape changes.
+type structuredWorkshopFeed struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Timezone      string               `json:"timezone"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	Count         int                  `json:"count"`
+	Workshops     []structuredWorkshop `json:"workshops"`
+}
+
+// handleLlmsTxt serves the static agent-facing site description.
+func handleLlmsTxt(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("text/plain; charset=utf-8")
+	ctx.SetBodyString(llmsTxt)
+}
+
+// handleStructuredWorkshops serves the flat agent feed of upcoming
+// sessions, sharing the cache middleware with the other public listings.
+func handleStructuredWorkshops(ctx *fasthttp.RequestCtx) {
+	items, err := database.GetUpcomingWorkshopItems(cfg)
+	if err != nil {
+		log.Printf("structured workshops failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	workshops := make([]structuredWorkshop, 0, len(items))
+	for _, item := range items {
+		workshops = append(workshops, structuredWorkshop{
+			WorkshopUUID:  item.UUID,
+			StudioID:      item.StudioID,
+			StudioName:    item.StudioName,
+			ArtistIDs:     item.ArtistIDList,
+			ArtistNames:   item.By,
+			Song:          item.Song,
+			EventType:     item.EventType,
+			StartEpochUTC: item.TimestampEpoch,
+			DateIST:       item.Date,
+			TimeIST:       item.Time,
+			PriceMinINR:   item.MinPrice,
+			PriceMaxINR:   item.MaxPrice,
+			Currency:      item.Currency,
+			BookingURL:    item.PaymentLink,
+		})
+	}
+	writeJSON(ctx, fasthttp.StatusOK, structuredWorkshopFeed{
+		SchemaVersion: 1,
+		Timezone:      "Asia/Kolkata",
+		GeneratedAt:   time.Now().UTC(),
+		Count:         len(workshops),
+		Workshops:     workshops,
+	})
+}
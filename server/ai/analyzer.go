@@ -0,0 +1,187 @@
+// Package ai wraps the LLM-based extraction of workshop details from scraped
+// event page screenshots.
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"dance_api_go/config"
+)
+
+// EventSummary is the structured result extracted from an event page.
+type EventSummary struct {
+	ArtistNames []string `json:"artist_names"`
+	Song        *string  `json:"song"`
+	PricingInfo *string  `json:"pricing_info"`
+	EventType   string   `json:"event_type"`
+	Day         *int     `json:"day"`
+	Month       *int     `json:"month"`
+	Year        *int     `json:"year"`
+	StartTime   *string  `json:"start_time"`
+	EndTime     *string  `json:"end_time"`
+	Confidence  *float64 `json:"confidence"`
+	// Language is the ISO 639-1 code of the page's dominant language, as
+	// detected by the model; extracted fields are normalized to English
+	// regardless.
+	Language string `json:"language"`
+
+	// Decision records how the summary was produced, for the AI decision
+	// log. Not part of the model's JSON output.
+	Decision *AnalysisDecision `json:"-"`
+}
+
+// AnalysisDecision is the provenance of one extraction: the model's
+// verbatim reply plus which model and prompt revision produced it, and
+// the token usage the call was billed for.
+type AnalysisDecision struct {
+	RawOutput     string
+	Model         string
+	PromptVersion int
+	InputTokens   int
+	OutputTokens  int
+}
+
+// AIAnalyzer extracts an EventSummary from an event page screenshot.
+type AIAnalyzer interface {
+	AnalyzeScreenshot(ctx context.Context, screenshot []byte) (*EventSummary, error)
+}
+
+// extractionPromptVersion is bumped whenever extractionPrompt changes, so
+// decision log entries say which prompt revision produced them.
+const extractionPromptVersion = 3
+
+// PromptVersion reports the current extraction prompt revision, so cached
+// analysis results from older prompts can be told apart from fresh ones.
+func PromptVersion() int { return extractionPromptVersion }
+
+const extractionPrompt = `You are given a screenshot of a dance workshop booking page from an Indian studio.
+The page may be in any language (English, Hindi, Kannada, ...). Extract the workshop
+details and reply with only a JSON object with keys:
+artist_names (list of strings), song, pricing_info, event_type (workshop/intensive/regulars),
+day, month, year (integers), start_time, end_time (like "7:00 PM"),
+confidence (number from 0 to 1: how certain you are the extraction is correct overall),
+language (ISO 639-1 code of the page's dominant language, like "en" or "hi").
+Write artist_names, song, and pricing_info in Latin script, transliterating or
+translating where needed, and convert times to the 12-hour form above.
+Use null for anything not visible.`
+
+// openaiDefaultModel is the vision model used when AI_MODEL is unset. The
+// pinned go-openai release predates the GPT-4o constant, so the model is
+// named by its API string.
+const openaiDefaultModel = "gpt-4o"
+
+// OpenAIAnalyzer implements AIAnalyzer using the OpenAI vision API.
+type OpenAIAnalyzer struct {
+	client      *openai.Client
+	model       string
+	temperature float32
+}
+
+// NewOpenAIAnalyzer builds the analyzer from config credentials, using the
+// configured model and temperature (defaulting to GPT-4o at temperature 0).
+func NewOpenAIAnalyzer(cfg *config.Config) *OpenAIAnalyzer {
+	model := cfg.AIModel
+	if model == "" {
+		model = openaiDefaultModel
+	}
+	return &OpenAIAnalyzer{
+		client:      openai.NewClient(cfg.OpenAIAPIKey),
+		model:       model,
+		temperature: float32(cfg.AITemperature),
+	}
+}
+
+// AnalyzeScreenshot sends the screenshot to the model and parses the JSON
+// response into an EventSummary.
+func (a *OpenAIAnalyzer) AnalyzeScreenshot(ctx context.Context, screenshot []byte) (*EventSummary, error) {
+	imageURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(screenshot)
+	resp, err := a.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       a.model,
+		Temperature: a.temperature,
+		Messages: []openai.ChatCompletionMessage{{
+			Role: openai.ChatMessageRoleUser,
+			MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: extractionPrompt},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: imageURL}},
+			},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("openai analysis failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("openai returned no choices")
+	}
+
+	var summary EventSummary
+	if err := json.Unmarshal([]byte(stripCodeFence(resp.Choices[0].Message.Content)), &summary); err != nil {
+		return nil, fmt.Errorf("unparseable analysis response: %w", err)
+	}
+	summary.Language = NormalizeLanguage(summary.Language)
+	summary.Decision = &AnalysisDecision{
+		RawOutput:     resp.Choices[0].Message.Content,
+		Model:         a.model,
+		PromptVersion: extractionPromptVersion,
+		InputTokens:   resp.Usage.PromptTokens,
+		OutputTokens:  resp.Usage.CompletionTokens,
+	}
+	return &summary, nil
+}
+
+// languageNames maps full language names the model sometimes answers with
+// to the ISO 639-1 codes the prompt asks for.
+var languageNames = map[string]string{
+	"english": "en",
+	"hindi":   "hi",
+	"kannada": "kn",
+	"tamil":   "ta",
+	"telugu":  "te",
+	"marathi": "mr",
+}
+
+// NormalizeLanguage canonicalizes a detected language to a lowercase ISO
+// 639-1 code, defaulting to "en" when the model gave nothing usable.
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if code, ok := languageNames[language]; ok {
+		return code
+	}
+	if len(language) == 2 {
+		return language
+	}
+	return "en"
+}
+
+// IsRateLimited reports whether an analysis error is a provider refusing
+// on rate limits, so callers can back off instead of hammering the API.
+func IsRateLimited(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	var anthropicErr *anthropicAPIError
+	return errors.As(err, &anthropicErr) && anthropicErr.StatusCode == http.StatusTooManyRequests
+}
+
+// stripCodeFence removes a ```json ... ``` wrapper the model sometimes adds.
+func stripCodeFence(s string) string {
+	const fence = "```"
+	if len(s) > 6 && s[:3] == fence {
+		s = s[3:]
+		if len(s) > 4 && s[:4] == "json" {
+			s = s[4:]
+		}
+		if idx := len(s) - 3; idx > 0 && s[idx:] == fence {
+			s = s[:idx]
+		}
+	}
+	return s
+}
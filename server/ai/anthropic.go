@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"dance_api_go/config"
+)
+
+// anthropicDefaultModel is used when no AI_MODEL override is configured.
+const anthropicDefaultModel = "claude-sonnet-4-20250514"
+
+// anthropicAPIURL is the Messages API endpoint; the API is plain HTTP, so
+// no SDK dependency is needed.
+const anthropicAPIURL = "https://api.anthropic.com/v1/messages"
+
+// AnthropicAnalyzer implements AIAnalyzer using the Anthropic Messages API.
+type AnthropicAnalyzer struct {
+	client      *http.Client
+	apiKey      string
+	model       string
+	temperature float64
+}
+
+// NewAnthropicAnalyzer builds the analyzer from config credentials, using
+// the configured model and temperature.
+func NewAnthropicAnalyzer(cfg *config.Config) *AnthropicAnalyzer {
+	model := cfg.AIModel
+	if model == "" {
+		model = anthropicDefaultModel
+	}
+	return &AnthropicAnalyzer{
+		client:      &http.Client{Timeout: 120 * time.Second},
+		apiKey:      cfg.AnthropicAPIKey,
+		model:       model,
+		temperature: cfg.AITemperature,
+	}
+}
+
+// anthropicAPIError is a non-2xx reply from the Messages API.
+type anthropicAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *anthropicAPIError) Error() string {
+	return fmt.Sprintf("anthropic API returned %d: %s", e.StatusCode, e.Body)
+}
+
+// AnalyzeScreenshot sends the screenshot to the model and parses the JSON
+// response into an EventSummary.
+func (a *AnthropicAnalyzer) AnalyzeScreenshot(ctx context.Context, screenshot []byte) (*EventSummary, error) {
+	if a.apiKey == "" {
+		return nil, fmt.Errorf("anthropic credentials not configured")
+	}
+	payload := map[string]interface{}{
+		"model":       a.model,
+		"max_tokens":  1024,
+		"temperature": a.temperature,
+		"messages": []map[string]interface{}{{
+			"role": "user",
+			"content": []map[string]interface{}{
+				{"type": "image", "source": map[string]string{
+					"type":       "base64",
+					"media_type": "image/png",
+					"data":       base64.StdEncoding.EncodeToString(screenshot),
+				}},
+				{"type": "text", "text": extractionPrompt},
+			},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, anthropicAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", a.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic analysis failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &anthropicAPIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var reply struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &reply); err != nil {
+		return nil, fmt.Errorf("unparseable anthropic response: %w", err)
+	}
+	text := ""
+	for _, block := range reply.Content {
+		if block.Type == "text" {
+			text = block.Text
+			break
+		}
+	}
+	if text == "" {
+		return nil, fmt.Errorf("anthropic returned no text content")
+	}
+
+	var summary EventSummary
+	if err := json.Unmarshal([]byte(stripCodeFence(text)), &summary); err != nil {
+		return nil, fmt.Errorf("unparseable analysis response: %w", err)
+	}
+	summary.Language = NormalizeLanguage(summary.Language)
+	summary.Decision = &AnalysisDecision{
+		RawOutput:     text,
+		Model:         a.model,
+		PromptVersion: extractionPromptVersion,
+		InputTokens:   reply.Usage.InputTokens,
+		OutputTokens:  reply.Usage.OutputTokens,
+	}
+	return &summary, nil
+}
@@ -0,0 +1,120 @@
+package ai
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Provider selection: AI_PROVIDER picks the analyzer backend, AI_MODEL and
+// AI_TEMPERATURE tune it, and AI_FALLBACK_PROVIDER names a second backend
+// to try automatically when the primary errors. Every concrete backend is
+// wrapped in a metering layer recording per-provider cost and latency to
+// ai_metrics, so a fallback call is attributed to the provider that served
+// it, not the one that failed.
+
+// Analyzer backend names accepted by AI_PROVIDER.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+// NewAnalyzer builds the configured analyzer: the AI_PROVIDER backend,
+// with the AI_FALLBACK_PROVIDER backend behind it when one is set. An
+// unknown provider name logs and falls back to OpenAI so a config typo
+// degrades the scraper rather than disabling it.
+func NewAnalyzer(cfg *config.Config) AIAnalyzer {
+	primary := newProvider(cfg, cfg.AIProvider)
+	if cfg.AIFallbackProvider == "" || cfg.AIFallbackProvider == cfg.AIProvider {
+		return primary
+	}
+	return &fallbackAnalyzer{
+		primary:       primary,
+		primaryName:   cfg.AIProvider,
+		secondary:     newProvider(cfg, cfg.AIFallbackProvider),
+		secondaryName: cfg.AIFallbackProvider,
+	}
+}
+
+// newProvider builds one metered backend by name.
+func newProvider(cfg *config.Config, provider string) AIAnalyzer {
+	switch provider {
+	case ProviderAnthropic:
+		inner := NewAnthropicAnalyzer(cfg)
+		return &meteredAnalyzer{cfg: cfg, inner: inner, provider: provider, model: inner.model}
+	case ProviderOpenAI:
+	default:
+		log.Printf("unknown AI provider %q, using %s", provider, ProviderOpenAI)
+		provider = ProviderOpenAI
+	}
+	inner := NewOpenAIAnalyzer(cfg)
+	return &meteredAnalyzer{cfg: cfg, inner: inner, provider: provider, model: inner.model}
+}
+
+// fallbackAnalyzer tries the secondary backend when the primary errors.
+type fallbackAnalyzer struct {
+	primary       AIAnalyzer
+	primaryName   string
+	secondary     AIAnalyzer
+	secondaryName string
+}
+
+// AnalyzeScreenshot runs the primary backend, falling back on error.
+func (f *fallbackAnalyzer) AnalyzeScreenshot(ctx context.Context, screenshot []byte) (*EventSummary, error) {
+	summary, err := f.primary.AnalyzeScreenshot(ctx, screenshot)
+	if err == nil {
+		return summary, nil
+	}
+	log.Printf("%s analysis failed, falling back to %s: %v", f.primaryName, f.secondaryName, err)
+	return f.secondary.AnalyzeScreenshot(ctx, screenshot)
+}
+
+// meteredAnalyzer records each call's latency, token usage, and estimated
+// cost against its provider.
+type meteredAnalyzer struct {
+	cfg      *config.Config
+	inner    AIAnalyzer
+	provider string
+	model    string
+}
+
+// AnalyzeScreenshot delegates to the backend and records the call.
+func (m *meteredAnalyzer) AnalyzeScreenshot(ctx context.Context, screenshot []byte) (*EventSummary, error) {
+	start := time.Now()
+	summary, err := m.inner.AnalyzeScreenshot(ctx, screenshot)
+	metric := database.AIMetric{
+		Provider:  m.provider,
+		Model:     m.model,
+		Success:   err == nil,
+		LatencyMS: time.Since(start).Milliseconds(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if summary != nil && summary.Decision != nil {
+		metric.InputTokens = summary.Decision.InputTokens
+		metric.OutputTokens = summary.Decision.OutputTokens
+		metric.EstimatedCostUSD = estimateCost(m.model,
+			summary.Decision.InputTokens, summary.Decision.OutputTokens)
+	}
+	database.RecordAIMetric(m.cfg, metric)
+	return summary, err
+}
+
+// modelPricing is USD per million tokens, input and output, for the models
+// the analyzers default to. Unlisted models record zero cost; token counts
+// are still there to price them after the fact.
+var modelPricing = map[string][2]float64{
+	openaiDefaultModel:    {2.50, 10.00},
+	anthropicDefaultModel: {3.00, 15.00},
+}
+
+// estimateCost prices one call from its token usage.
+func estimateCost(model string, inputTokens, outputTokens int) float64 {
+	pricing, ok := modelPricing[model]
+	if !ok {
+		return 0
+	}
+	return (float64(inputTokens)*pricing[0] + float64(outputTokens)*pricing[1]) / 1e6
+}
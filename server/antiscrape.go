@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+)
+
+// Anti-scraping protection for the public APIs: competitor aggregators farm
+// our listings, so repeated anonymous access from one client fingerprint is
+// progressively slowed and eventually refused. Registered partners sending a
+// known X-API-Key bypass all of it, and a honeypot route auto-blocks
+// crawlers that follow links no real client requests.
+
+const (
+	scrapeWindow = time.Minute
+	// Above slowThreshold requests/minute responses get progressively
+	// delayed; above blockThreshold they get 429s.
+	scrapeSlowThreshold  = 60
+	scrapeBlockThreshold = 180
+	scrapeMaxDelay       = 3 * time.Second
+	// fingerprintIdleEviction drops fingerprints not seen for this long;
+	// without the sweep every IP|User-Agent pair ever observed would stay
+	// in the map for the life of the process.
+	fingerprintIdleEviction = 10 * time.Minute
+)
+
+var (
+	fingerprintHits    = make(map[string][]time.Time)
+	fingerprintHitsMu  sync.Mutex
+	fingerprintsPruned time.Time
+
+	blockedFingerprints   = make(map[string]bool)
+	blockedFingerprintsMu sync.RWMutex
+)
+
+// clientFingerprint identifies a client by IP and user agent. The IP comes
+// from clientIP, which only honors X-Forwarded-For from a trusted proxy,
+// so a scraper cannot mint a fresh fingerprint by rotating the header.
+func clientFingerprint(ctx *fasthttp.RequestCtx) string {
+	return fmt.Sprintf("%s|%s", clientIP(ctx), ctx.UserAgent())
+}
+
+// isPartnerRequest reports whether the request carries a registered partner
+// API key, exempting it from scraping protections.
+func isPartnerRequest(ctx *fasthttp.RequestCtx) bool {
+	key := string(ctx.Request.Header.Peek("X-API-Key"))
+	if key == "" {
+		return false
+	}
+	for _, partnerKey := range cfg.PartnerAPIKeys {
+		if key == partnerKey {
+			return true
+		}
+	}
+	return false
+}
+
+// antiScrapeMiddleware applies fingerprint throttling and the persistent
+// blocklist to a public route.
+func antiScrapeMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if isPartnerRequest(ctx) {
+			next(ctx)
+			return
+		}
+		fingerprint := clientFingerprint(ctx)
+
+		blockedFingerprintsMu.RLock()
+		blocked := blockedFingerprints[fingerprint]
+		blockedFingerprintsMu.RUnlock()
+		if blocked {
+			writeJSONError(ctx, fasthttp.StatusForbidden, "Access denied")
+			return
+		}
+
+		hits := recordFingerprintHit(fingerprint)
+		switch {
+		case hits > scrapeBlockThreshold:
+			ctx.Response.Header.Set("Retry-After", "60")
+			writeJSONError(ctx, fasthttp.StatusTooManyRequests, "Too many requests")
+			return
+		case hits > scrapeSlowThreshold:
+			// Progressive delay: grows linearly with the overshoot.
+			delay := time.Duration(hits-scrapeSlowThreshold) * 25 * time.Millisecond
+			if delay > scrapeMaxDelay {
+				delay = scrapeMaxDelay
+			}
+			time.Sleep(delay)
+		}
+		next(ctx)
+	}
+}
+
+// recordFingerprintHit appends a hit and returns the count in the window.
+func recordFingerprintHit(fingerprint string) int {
+	fingerprintHitsMu.Lock()
+	defer fingerprintHitsMu.Unlock()
+	now := time.Now()
+	pruneFingerprints(now)
+	cutoff := now.Add(-scrapeWindow)
+	kept := fingerprintHits[fingerprint][:0]
+	for _, t := range fingerprintHits[fingerprint] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	kept = append(kept, now)
+	fingerprintHits[fingerprint] = kept
+	return len(kept)
+}
+
+// pruneFingerprints evicts fingerprints with no hits since the eviction
+// horizon; called with the lock held.
+func pruneFingerprints(now time.Time) {
+	if now.Sub(fingerprintsPruned) < fingerprintIdleEviction {
+		return
+	}
+	fingerprintsPruned = now
+	cutoff := now.Add(-fingerprintIdleEviction)
+	for fingerprint, hits := range fingerprintHits {
+		if len(hits) == 0 || hits[len(hits)-1].Before(cutoff) {
+			delete(fingerprintHits, fingerprint)
+		}
+	}
+}
+
+// handleHoneypot is mounted on a route only crawlers discover; any hit
+// blocks the fingerprint immediately and persists the block.
+func handleHoneypot(ctx *fasthttp.RequestCtx) {
+	fingerprint := clientFingerprint(ctx)
+	blockFingerprint(fingerprint, "honeypot")
+	log.Printf("anti-scrape: honeypot hit, blocked %s", fingerprint)
+	writeJSONError(ctx, fasthttp.StatusForbidden, "Access denied")
+}
+
+// blockFingerprint adds a fingerprint to the in-memory and persistent
+// blocklists.
+func blockFingerprint(fingerprint, reason string) {
+	blockedFingerprintsMu.Lock()
+	blockedFingerprints[fingerprint] = true
+	blockedFingerprintsMu.Unlock()
+
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "blocked_clients").UpdateOne(dbCtx,
+		bson.M{"fingerprint": fingerprint},
+		bson.M{"$set": bson.M{"fingerprint": fingerprint, "reason": reason, "blocked_at": time.Now().UTC()}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("anti-scrape: persist block failed: %v", err)
+	}
+}
+
+// loadBlocklist hydrates the in-memory blocklist from MongoDB at startup.
+func loadBlocklist() {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "blocked_clients").Find(dbCtx, bson.M{})
+	if err != nil {
+		log.Printf("anti-scrape: load blocklist failed: %v", err)
+		return
+	}
+	var entries []struct {
+		Fingerprint string `bson:"fingerprint"`
+	}
+	if err := cursor.All(dbCtx, &entries); err != nil {
+		return
+	}
+	blockedFingerprintsMu.Lock()
+	for _, e := range entries {
+		blockedFingerprints[e.Fingerprint] = true
+	}
+	blockedFingerprintsMu.Unlock()
+}
+
+// handleBlocklist is the admin API over the blocklist: GET lists entries,
+// POST blocks a fingerprint, DELETE unblocks one.
+func handleBlocklist(ctx *fasthttp.RequestCtx) {
+	switch string(ctx.Method()) {
+	case fasthttp.MethodGet:
+		dbCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		cursor, err := database.Collection(cfg, "blocked_clients").Find(dbCtx, bson.M{})
+		if err != nil {
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to load blocklist")
+			return
+		}
+		entries := []bson.M{}
+		if err := cursor.All(dbCtx, &entries); err != nil {
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to load blocklist")
+			return
+		}
+		writeJSON(ctx, fasthttp.StatusOK, entries)
+	case fasthttp.MethodPost:
+		var body struct {
+			Fingerprint string `json:"fingerprint"`
+			Reason      string `json:"reason"`
+		}
+		if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.Fingerprint == "" {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, "fingerprint is required")
+			return
+		}
+		if body.Reason == "" {
+			body.Reason = "manual"
+		}
+		blockFingerprint(body.Fingerprint, body.Reason)
+		writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+	case fasthttp.MethodDelete:
+		fingerprint := string(ctx.QueryArgs().Peek("fingerprint"))
+		if fingerprint == "" {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, "fingerprint is required")
+			return
+		}
+		blockedFingerprintsMu.Lock()
+		delete(blockedFingerprints, fingerprint)
+		blockedFingerprintsMu.Unlock()
+		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = database.Collection(cfg, "blocked_clients").DeleteOne(dbCtx, bson.M{"fingerprint": fingerprint})
+		writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+	default:
+		writeJSONError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dance_api_go/utils"
+)
+
+// GetAnnouncements serves the active banners for the calling client.
+// ?platform=, ?app_version=, and ?city= describe the client so targeting
+// applies; banners the user already dismissed are excluded.
+func (s *Server) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	announcements, err := s.announceService.GetActiveAnnouncements(userID,
+		r.URL.Query().Get("platform"),
+		r.URL.Query().Get("app_version"),
+		r.URL.Query().Get("city"))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch announcements")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, announcements)
+}
+
+// DismissAnnouncement records that the user closed a banner so it stays
+// closed across their devices.
+func (s *Server) DismissAnnouncement(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	var body struct {
+		AnnouncementID string `json:"announcement_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.AnnouncementID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "announcement_id is required")
+		return
+	}
+	if err := s.announceService.DismissAnnouncement(userID, body.AnnouncementID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to dismiss announcement")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// HandleContactSync matches the caller's hashed contacts against
+// registered users. The client uploads SHA-256 digests of normalized
+// numbers, never the numbers themselves; the response lists the friends
+// found and, privacy settings permitting, the workshops they're attending.
+func (s *Server) HandleContactSync(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	var body struct {
+		ContactHashes []string `json:"contact_hashes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	result, err := services.SyncContacts(s.cfg, userID, body.ContactHashes)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// HandleWorkshopSocial dispatches /api/workshops/{uuid}/... social actions
+// for the authenticated user. GET {uuid}/friends-going lists which synced
+// friends hold a friend-visible order for the workshop; POST {uuid}/invite
+// sends a one-time nudge push to a friend, respecting their privacy
+// settings.
+func (s *Server) HandleWorkshopSocial(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/api/workshops/")
+	switch {
+	case strings.HasSuffix(rest, "/friends-going") && r.Method == http.MethodGet:
+		workshopUUID := strings.TrimSuffix(rest, "/friends-going")
+		if workshopUUID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "workshop_uuid is required")
+			return
+		}
+		result, err := services.GetFriendsGoing(s.cfg, userID, workshopUUID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch friends going")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, result)
+	case strings.HasSuffix(rest, "/invite") && r.Method == http.MethodPost:
+		workshopUUID := strings.TrimSuffix(rest, "/invite")
+		var body struct {
+			FriendUserID string `json:"friend_user_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.FriendUserID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "friend_user_id is required")
+			return
+		}
+		if err := services.InviteFriend(s.cfg, s.notifService, userID, body.FriendUserID, workshopUUID); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	default:
+		utils.WriteError(w, http.StatusNotFound, "Not found")
+	}
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// GraphQL gateway: POST /graphql lets clients ask for exactly the fields
+// they need — song and date for a feed row, say — instead of the full
+// documents the REST endpoints return. The schema covers workshops,
+// artists, studios, and the caller's reactions, resolved by the same
+// database layer the REST handlers use. It is defined in code with the
+// runtime graphql-go library rather than generated: this server has no
+// generate step, and a hand-held schema keeps the gateway one reviewable
+// file.
+
+// gqlContextKey keys the per-request values resolvers read.
+type gqlContextKey string
+
+const (
+	gqlLoaderKey gqlContextKey = "loader"
+	gqlUserKey   gqlContextKey = "user_id"
+)
+
+// gqlLoader batches the entity lookups behind nested resolvers: artists
+// and studios are each loaded at most once per request, no matter how many
+// workshops reference them, so a workshops query with nested artists costs
+// two queries instead of one per workshop.
+type gqlLoader struct {
+	cfg *config.Config
+
+	artistsOnce sync.Once
+	artists     map[string]response.Artist
+	artistsErr  error
+
+	studiosOnce sync.Once
+	studios     map[string]response.Studio
+	studiosErr  error
+}
+
+// artistByID returns one artist from the request-scoped batch, or nil when
+// the ID is unknown.
+func (l *gqlLoader) artistByID(id string) (*response.Artist, error) {
+	l.artistsOnce.Do(func() {
+		all, err := database.GetAllArtistsFromDB(l.cfg, false)
+		if err != nil {
+			l.artistsErr = err
+			return
+		}
+		l.artists = make(map[string]response.Artist, len(all))
+		for _, artist := range all {
+			l.artists[artist.ID] = artist
+		}
+	})
+	if l.artistsErr != nil {
+		return nil, l.artistsErr
+	}
+	if artist, ok := l.artists[id]; ok {
+		return &artist, nil
+	}
+	return nil, nil
+}
+
+// studioByID returns one studio from the request-scoped batch, or nil when
+// the ID is unknown.
+func (l *gqlLoader) studioByID(id string) (*response.Studio, error) {
+	l.studiosOnce.Do(func() {
+		l.studios, l.studiosErr = database.GetStudioMap(l.cfg)
+	})
+	if l.studiosErr != nil {
+		return nil, l.studiosErr
+	}
+	if studio, ok := l.studios[id]; ok {
+		return &studio, nil
+	}
+	return nil, nil
+}
+
+// loaderFrom pulls the request's loader out of the resolver context.
+func loaderFrom(p graphql.ResolveParams) *gqlLoader {
+	loader, _ := p.Context.Value(gqlLoaderKey).(*gqlLoader)
+	return loader
+}
+
+// buildGraphQLSchema wires the object types and query resolvers. Field
+// resolvers are explicit because the Go structs use snake_case JSON tags
+// while GraphQL convention is camelCase.
+func buildGraphQLSchema() (graphql.Schema, error) {
+	timeDetailType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TimeDetail",
+		Fields: graphql.Fields{
+			"day":       gqlField(graphql.Int, func(td response.TimeDetail) interface{} { return td.Day }),
+			"month":     gqlField(graphql.Int, func(td response.TimeDetail) interface{} { return td.Month }),
+			"year":      gqlField(graphql.Int, func(td response.TimeDetail) interface{} { return td.Year }),
+			"startTime": gqlField(graphql.String, func(td response.TimeDetail) interface{} { return td.StartTime }),
+			"endTime":   gqlField(graphql.String, func(td response.TimeDetail) interface{} { return td.EndTime }),
+		},
+	})
+
+	artistType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Artist",
+		Fields: graphql.Fields{
+			"id":            gqlField(graphql.String, func(a response.Artist) interface{} { return a.ID }),
+			"name":          gqlField(graphql.String, func(a response.Artist) interface{} { return a.Name }),
+			"imageUrl":      gqlField(graphql.String, func(a response.Artist) interface{} { return a.ImageURL }),
+			"instagramLink": gqlField(graphql.String, func(a response.Artist) interface{} { return a.InstagramLink }),
+			"danceStyles":   gqlField(graphql.NewList(graphql.String), func(a response.Artist) interface{} { return a.DanceStyles }),
+		},
+	})
+
+	studioType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Studio",
+		Fields: graphql.Fields{
+			"id":            gqlField(graphql.String, func(s response.Studio) interface{} { return s.ID }),
+			"name":          gqlField(graphql.String, func(s response.Studio) interface{} { return s.Name }),
+			"imageUrl":      gqlField(graphql.String, func(s response.Studio) interface{} { return s.ImageURL }),
+			"instagramLink": gqlField(graphql.String, func(s response.Studio) interface{} { return s.InstagramLink }),
+			"city":          gqlField(graphql.String, func(s response.Studio) interface{} { return s.City }),
+			"currency":      gqlField(graphql.String, func(s response.Studio) interface{} { return s.Currency }),
+		},
+	})
+
+	workshopType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Workshop",
+		Fields: graphql.Fields{
+			"uuid":        gqlField(graphql.String, func(w response.Workshop) interface{} { return w.UUID }),
+			"song":        gqlField(graphql.String, func(w response.Workshop) interface{} { return w.Song }),
+			"by":          gqlField(graphql.String, func(w response.Workshop) interface{} { return w.By }),
+			"studioId":    gqlField(graphql.String, func(w response.Workshop) interface{} { return w.StudioID }),
+			"eventType":   gqlField(graphql.String, func(w response.Workshop) interface{} { return w.EventType }),
+			"paymentLink": gqlField(graphql.String, func(w response.Workshop) interface{} { return w.PaymentLink }),
+			"pricingInfo": gqlField(graphql.String, func(w response.Workshop) interface{} { return w.PricingInfo }),
+			"minPrice":    gqlField(graphql.Int, func(w response.Workshop) interface{} { return w.MinPrice }),
+			"maxPrice":    gqlField(graphql.Int, func(w response.Workshop) interface{} { return w.MaxPrice }),
+			"timeDetails": gqlField(graphql.NewList(timeDetailType), func(w response.Workshop) interface{} { return w.TimeDetails }),
+			"artists": &graphql.Field{
+				Type: graphql.NewList(artistType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					workshop, ok := p.Source.(response.Workshop)
+					if !ok {
+						return nil, nil
+					}
+					loader := loaderFrom(p)
+					artists := []response.Artist{}
+					for _, artistID := range workshop.ArtistIDList {
+						artist, err := loader.artistByID(artistID)
+						if err != nil {
+							return nil, err
+						}
+						if artist != nil {
+							artists = append(artists, *artist)
+						}
+					}
+					return artists, nil
+				},
+			},
+			"studio": &graphql.Field{
+				Type: studioType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					workshop, ok := p.Source.(response.Workshop)
+					if !ok {
+						return nil, nil
+					}
+					studio, err := loaderFrom(p).studioByID(workshop.StudioID)
+					if err != nil {
+						return nil, err
+					}
+					if studio == nil {
+						return nil, nil
+					}
+					return *studio, nil
+				},
+			},
+		},
+	})
+
+	reactionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Reaction",
+		Fields: graphql.Fields{
+			"entityId":   gqlField(graphql.String, func(r services.Reaction) interface{} { return r.EntityID }),
+			"entityType": gqlField(graphql.String, func(r services.Reaction) interface{} { return r.EntityType }),
+			"reaction":   gqlField(graphql.String, func(r services.Reaction) interface{} { return r.Reaction }),
+			"updatedAt":  gqlField(graphql.DateTime, func(r services.Reaction) interface{} { return r.UpdatedAt }),
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"workshops": &graphql.Field{
+				Type:        graphql.NewList(workshopType),
+				Description: "Upcoming workshops, optionally narrowed to one studio or artist.",
+				Args: graphql.FieldConfigArgument{
+					"studioId": &graphql.ArgumentConfig{Type: graphql.String},
+					"artistId": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					studioID, _ := p.Args["studioId"].(string)
+					artistID, _ := p.Args["artistId"].(string)
+					return database.GetUpcomingWorkshopsRaw(loaderFrom(p).cfg, studioID, artistID)
+				},
+			},
+			"artists": &graphql.Field{
+				Type:        graphql.NewList(artistType),
+				Description: "All artists; hasWorkshops filters to those with upcoming sessions.",
+				Args: graphql.FieldConfigArgument{
+					"hasWorkshops": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hasWorkshops, _ := p.Args["hasWorkshops"].(bool)
+					return database.GetAllArtistsFromDB(loaderFrom(p).cfg, hasWorkshops)
+				},
+			},
+			"studios": &graphql.Field{
+				Type:        graphql.NewList(studioType),
+				Description: "All studios.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return database.GetStudios(loaderFrom(p).cfg)
+				},
+			},
+			"myReactions": &graphql.Field{
+				Type:        graphql.NewList(reactionType),
+				Description: "The authenticated caller's reactions.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID, _ := p.Context.Value(gqlUserKey).(string)
+					if userID == "" {
+						return nil, fmt.Errorf("myReactions requires authentication")
+					}
+					return services.NewReactionService(loaderFrom(p).cfg).GetUserReactions(userID)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// gqlField builds a leaf field backed by a typed extractor, keeping the
+// schema definition table-shaped.
+func gqlField[S any](fieldType graphql.Output, extract func(S) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			source, ok := p.Source.(S)
+			if !ok {
+				return nil, nil
+			}
+			return extract(source), nil
+		},
+	}
+}
+
+// HandleGraphQL serves POST /graphql. The request body is the standard
+// GraphQL-over-HTTP shape (query, operationName, variables); the response
+// is the standard {data, errors} envelope. Identity is optional — only the
+// myReactions field requires it.
+func (s *Server) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Query == "" {
+		utils.WriteError(w, http.StatusBadRequest, "A query is required")
+		return
+	}
+	ctx := context.WithValue(r.Context(), gqlLoaderKey, &gqlLoader{cfg: s.cfg})
+	// The route is mounted without a role gate, so the identity resolvers
+	// see must come from the verified bearer token — the X-User-ID header
+	// is client-supplied here and cannot be trusted.
+	ctx = context.WithValue(ctx, gqlUserKey, s.verifiedUserID(r))
+	result := graphql.Do(graphql.Params{
+		Schema:         s.graphqlSchema,
+		RequestString:  body.Query,
+		OperationName:  body.OperationName,
+		VariableValues: body.Variables,
+		Context:        ctx,
+	})
+	utils.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// GetPassProducts serves the pass products currently for sale: class
+// packs scoped to an artist and monthly unlimited passes scoped to a
+// studio.
+func (s *Server) GetPassProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := services.GetPassProducts(s.cfg, true)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch pass products")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, products)
+}
+
+// HandlePurchasePass creates a payment order for a pass product; the
+// client completes payment through the usual gateway flow and the paid
+// webhook activates the pass.
+func (s *Server) HandlePurchasePass(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	var body struct {
+		ProductID string `json:"product_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ProductID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "product_id is required")
+		return
+	}
+	order, err := s.orderService.PurchasePass(userID, body.ProductID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, order)
+}
+
+// HandleRedeemPass books a workshop against one of the caller's passes,
+// decrementing class-pack credits atomically.
+func (s *Server) HandleRedeemPass(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	var body struct {
+		PassID       string `json:"pass_id"`
+		WorkshopUUID string `json:"workshop_uuid"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.PassID == "" || body.WorkshopUUID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "pass_id and workshop_uuid are required")
+		return
+	}
+	result, err := services.RedeemPass(s.cfg, userID, body.PassID, body.WorkshopUUID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// GetMyPasses lists the caller's passes with live status for the account
+// screen.
+func (s *Server) GetMyPasses(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	passes, err := services.GetUserPasses(s.cfg, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch passes")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, passes)
+}
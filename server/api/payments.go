@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// webhookBodyLimit caps how much of a gateway delivery is read and kept for
+// audit.
+const webhookBodyLimit = 1 << 20
+
+// HandlePaymentWebhook receives Razorpay webhook deliveries. The HMAC
+// signature over the raw body is the only authentication — no bearer token,
+// no CSRF. Every delivery is recorded in the webhook_events audit trail with
+// its outcome, deliveries are idempotent on the gateway event ID, and
+// duplicates or event types we do not act on are acknowledged with 200 so
+// Razorpay stops retrying them.
+func (s *Server) HandlePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.RazorpayWebhookSecret == "" {
+		utils.WriteError(w, http.StatusServiceUnavailable, "Webhook secret not configured")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, webhookBodyLimit))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	eventID := r.Header.Get("X-Razorpay-Event-Id")
+
+	if !services.VerifyRazorpaySignature(s.cfg.RazorpayWebhookSecret, body, r.Header.Get("X-Razorpay-Signature")) {
+		services.RecordWebhookDelivery(s.cfg, eventID, "", "", false, body, "invalid_signature")
+		utils.WriteError(w, http.StatusUnauthorized, "Invalid webhook signature")
+		return
+	}
+
+	event, err := services.ParseRazorpayEvent(eventID, body)
+	if err != nil {
+		services.RecordWebhookDelivery(s.cfg, eventID, "", "", true, body, "malformed")
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !services.RazorpayEventHandled(event.Event) {
+		services.RecordWebhookDelivery(s.cfg, eventID, event.Event, event.OrderID, true, body, "ignored")
+		utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+		return
+	}
+
+	if err := s.orderService.ProcessPaymentWebhook(event); err != nil {
+		if errors.Is(err, services.ErrReplayedRequest) {
+			services.RecordWebhookDelivery(s.cfg, eventID, event.Event, event.OrderID, true, body, "duplicate")
+			utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "duplicate"})
+			return
+		}
+		services.RecordWebhookDelivery(s.cfg, eventID, event.Event, event.OrderID, true, body, "error")
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	services.RecordWebhookDelivery(s.cfg, eventID, event.Event, event.OrderID, true, body, "processed")
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": "processed"})
+}
+
+// HandleSimulateWebhook (dev only) builds a signed gateway webhook for an
+// order — event is "paid", "failed", or "refunded" — and delivers it to the
+// local webhook endpoint, so the order state machine and QR pipeline can be
+// exercised without a real Razorpay account. The route is only mounted in
+// the dev environment.
+func (s *Server) HandleSimulateWebhook(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Event   string `json:"event"`
+		OrderID string `json:"order_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Event == "" || body.OrderID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "event and order_id are required")
+		return
+	}
+	result, err := services.SimulateWebhook(s.cfg, body.Event, body.OrderID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, result)
+}
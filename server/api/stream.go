@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// streamHeartbeatInterval keeps proxies from timing out an idle stream
+// connection; clients treat the comment as a liveness signal.
+const streamHeartbeatInterval = 25 * time.Second
+
+// streamRetryMillis is the reconnect delay advertised to EventSource
+// clients at the top of the stream.
+const streamRetryMillis = 5000
+
+// HandleStream serves /api/stream: a Server-Sent Events feed of live
+// platform events. ?topics= names the comma-separated subscriptions —
+// "studio:{id}" and "artist:{id}" for workshop publications, "order"
+// (authenticated) for the caller's own order status changes. Every event
+// carries its domain event ID as the SSE event ID, so a reconnecting
+// EventSource resumes from Last-Event-ID without losing events.
+func (s *Server) HandleStream(w http.ResponseWriter, r *http.Request) {
+	// The route is mounted without a role gate so anonymous clients can
+	// follow public topics; the private order topic must therefore take
+	// its identity from the verified bearer token, never from the
+	// client-supplied X-User-ID header.
+	topics, err := services.ParseStreamTopics(r.URL.Query().Get("topics"), s.verifiedUserID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.WriteError(w, http.StatusInternalServerError, "Streaming unsupported")
+		return
+	}
+
+	// The server-wide write timeout would sever a long-lived stream;
+	// disable the write deadline for this connection only.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	flusher.Flush()
+
+	// Subscribe before replaying so nothing emitted in between is lost; a
+	// reconnect may then see an event twice, which clients keyed on the
+	// event ID absorb.
+	sub := services.SubscribeStream(topics)
+	defer sub.Close()
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		for _, event := range services.ReplayStreamEvents(s.cfg, lastID, topics) {
+			writeStreamEvent(w, event)
+		}
+		flusher.Flush()
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, open := <-sub.Events:
+			if !open {
+				// The broker dropped us for falling behind; closing the
+				// connection lets the client's retry replay what it missed.
+				return
+			}
+			writeStreamEvent(w, event)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeStreamEvent frames one event in SSE wire format.
+func writeStreamEvent(w io.Writer, event services.StreamEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
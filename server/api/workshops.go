@@ -0,0 +1,799 @@
+// Package api contains the net/http handlers for authenticated user routes
+// (reactions, device tokens) and the public workshop listings they share.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/middleware"
+	"dance_api_go/models/request"
+	"dance_api_go/queryopts"
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// Server bundles the dependencies the HTTP handlers need.
+type Server struct {
+	cfg             *config.Config
+	authService     services.AuthService
+	orderService    services.OrderService
+	reactionService *services.ReactionServiceImpl
+	notifService    *services.NotificationServiceImpl
+	choreoService   *services.ChoreoLinkServiceImpl
+	announceService *services.AnnouncementServiceImpl
+	graphqlSchema   graphql.Schema
+}
+
+// NewServer wires up the handler dependencies.
+func NewServer(cfg *config.Config) *Server {
+	s := &Server{
+		cfg:             cfg,
+		authService:     services.NewAuthService(cfg),
+		orderService:    services.NewOrderService(cfg),
+		reactionService: services.NewReactionService(cfg),
+		notifService:    services.NewNotificationService(cfg),
+		choreoService:   services.NewChoreoLinkService(cfg),
+		announceService: services.NewAnnouncementService(cfg),
+	}
+	schema, err := buildGraphQLSchema()
+	if err != nil {
+		// The schema is defined entirely in code, so a build failure is a
+		// programming error caught at startup, not a runtime condition.
+		log.Fatalf("graphql schema: %v", err)
+	}
+	s.graphqlSchema = schema
+	utils.SetTokenValidator(s.validateToken)
+	return s
+}
+
+// validateToken backs the MakeHandler role gates: the token must verify,
+// the account must be active, and the "admin" role additionally requires
+// the is_admin flag. Returns the user ID from the verified claims.
+func (s *Server) validateToken(token, role string) (string, bool) {
+	userID, err := s.authService.VerifyToken(token)
+	if err != nil {
+		return "", false
+	}
+	user, err := s.authService.GetUser(userID)
+	if err != nil {
+		return "", false
+	}
+	if role == "admin" && !user.IsAdmin {
+		return "", false
+	}
+	return userID, true
+}
+
+// verifiedUserID resolves the caller's identity on routes mounted without
+// a role gate, where MakeHandler never overwrites X-User-ID and the header
+// is whatever the client sent. Only the bearer token is consulted; an
+// absent or invalid token yields "" (anonymous), never a spoofed identity.
+func (s *Server) verifiedUserID(r *http.Request) string {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return ""
+	}
+	userID, ok := s.validateToken(token, "user")
+	if !ok {
+		return ""
+	}
+	return userID
+}
+
+// requireMongo gates a data handler behind MongoDB readiness, returning 503
+// with a retry hint while the database is unreachable.
+func requireMongo(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !database.IsReady() {
+			w.Header().Set("Retry-After", "10")
+			utils.WriteError(w, http.StatusServiceUnavailable, "Service temporarily unavailable, please retry shortly")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// methods is the method-gating middleware: HEAD works wherever GET does,
+// OPTIONS answers with Allow, and wrong methods get a 405 up front.
+func methods(allowed ...string) middleware.Middleware[http.HandlerFunc] {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return utils.AllowMethods(next, allowed...)
+	}
+}
+
+// auth is the role-binding middleware: MakeHandler verifies the bearer
+// token against the given roles (none means unauthenticated) and stamps
+// X-User-ID for the handler.
+func auth(roles ...string) middleware.Middleware[http.HandlerFunc] {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return utils.MakeHandler(next, roles...)
+	}
+}
+
+// RegisterRoutes mounts all handlers on the mux. Routes are organized into
+// middleware chains — one per route group — so cross-cutting behavior
+// (method gating, readiness, CSRF, role binding) is declared once per group
+// instead of hand-wrapped on every route.
+func (s *Server) RegisterRoutes(mux *http.ServeMux) {
+	publicGet := middleware.New(methods(http.MethodGet), requireMongo, auth())
+	userGet := middleware.New(methods(http.MethodGet), requireMongo, auth("user"))
+	user := func(allowed ...string) middleware.Chain[http.HandlerFunc] {
+		return middleware.New(methods(allowed...), requireMongo, utils.ValidateCSRF, auth("user"))
+	}
+	admin := func(allowed ...string) middleware.Chain[http.HandlerFunc] {
+		return middleware.New(methods(allowed...), requireMongo, utils.ValidateCSRF, auth("admin"))
+	}
+
+	mux.HandleFunc("/api/workshops", publicGet.Then(s.GetWorkshops))
+	mux.HandleFunc("/api/artists", publicGet.Then(s.GetArtists))
+	mux.HandleFunc("/api/artists/", publicGet.Then(s.HandleArtistRoutes))
+	mux.HandleFunc("/api/studios", publicGet.Then(s.GetStudios))
+	mux.HandleFunc("/api/workshops_by_artist/", publicGet.Then(s.GetWorkshopsByArtist))
+	mux.HandleFunc("/api/workshops_by_song/", publicGet.Then(s.GetWorkshopsBySong))
+	mux.HandleFunc("/api/workshops_by_studio/", publicGet.Then(s.GetWorkshopsByStudio))
+	// GraphQL reads with optional identity: POST carries the query, so no
+	// CSRF (nothing state-changing is exposed), and only the myReactions
+	// field requires a bearer token.
+	mux.HandleFunc("/graphql", middleware.New(methods(http.MethodPost), requireMongo, auth()).Then(s.HandleGraphQL))
+	// The SSE feed holds its connection open, so it never goes near the
+	// response cache; the public chain only gates method and readiness,
+	// and the handler verifies the bearer token itself when the private
+	// order topic is requested.
+	mux.HandleFunc("/api/stream", publicGet.Then(s.HandleStream))
+	// The CSRF token must be issuable before MongoDB is up, so no readiness
+	// gate.
+	mux.HandleFunc("/api/csrf-token", middleware.New(methods(http.MethodGet), auth()).Then(s.GetCSRFToken))
+	mux.HandleFunc("/api/reactions", user(http.MethodPost, http.MethodDelete).Then(s.HandleReactions))
+	mux.HandleFunc("/api/user/reactions", userGet.Then(s.GetUserReactions))
+	mux.HandleFunc("/api/notifications/register-token", user(http.MethodPost).Then(s.RegisterDeviceToken))
+	mux.HandleFunc("/api/notifications/preferences", user(http.MethodGet, http.MethodPut).Then(s.HandleNotificationPreferences))
+	mux.HandleFunc("/api/pass-products", publicGet.Then(s.GetPassProducts))
+	mux.HandleFunc("/api/passes/purchase", user(http.MethodPost).Then(s.HandlePurchasePass))
+	mux.HandleFunc("/api/passes/redeem", user(http.MethodPost).Then(s.HandleRedeemPass))
+	mux.HandleFunc("/api/me/passes", userGet.Then(s.GetMyPasses))
+	mux.HandleFunc("/api/friends/contact-sync", user(http.MethodPost).Then(s.HandleContactSync))
+	mux.HandleFunc("/api/workshops/", user(http.MethodGet, http.MethodPost).Then(s.HandleWorkshopSocial))
+	mux.HandleFunc("/api/announcements", userGet.Then(s.GetAnnouncements))
+	mux.HandleFunc("/api/announcements/dismiss", user(http.MethodPost).Then(s.DismissAnnouncement))
+	// Webhooks authenticate by signature, not bearer token or CSRF.
+	mux.HandleFunc("/api/payments/webhook", middleware.New(methods(http.MethodPost), requireMongo, auth()).Then(s.HandlePaymentWebhook))
+	mux.HandleFunc("/api/orders/", user(http.MethodGet, http.MethodPost).Then(s.HandleOrderActions))
+	mux.HandleFunc("/artist/api/choreo-links", user(http.MethodGet, http.MethodPost).Then(s.HandleChoreoLinks))
+	mux.HandleFunc("/admin/api/choreo-links", admin(http.MethodGet, http.MethodPost).Then(s.HandleChoreoModeration))
+	mux.HandleFunc("/admin/api/send-test-notification", admin(http.MethodPost).Then(s.SendTestNotification))
+	mux.HandleFunc("/admin/api/notifications/preview", admin(http.MethodGet, http.MethodPost).Then(s.HandleNotificationPreview))
+	mux.HandleFunc("/admin/api/notifications/campaign", admin(http.MethodGet, http.MethodPost).Then(s.HandleCampaign))
+	mux.HandleFunc("/admin/api/moderation/flags", admin(http.MethodGet, http.MethodPost).Then(s.HandleModerationFlags))
+	if s.cfg.Env == "dev" {
+		// Dev-only tooling; never mounted in prod.
+		mux.HandleFunc("/dev/api/simulate-webhook", middleware.New(methods(http.MethodPost), requireMongo, auth()).Then(s.HandleSimulateWebhook))
+	}
+}
+
+// GetCSRFToken issues (or echoes) the CSRF cookie and returns the token for
+// AJAX callers on the admin panel and studio portal pages.
+func (s *Server) GetCSRFToken(w http.ResponseWriter, r *http.Request) {
+	token, err := utils.IssueCSRFCookie(w, r)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to issue CSRF token")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"csrf_token": token})
+}
+
+// GetWorkshops serves the categorized workshop list; ?when= narrows it to
+// an IST quick filter (today/tomorrow/weekend/next_7_days), and
+// ?start_date=/&end_date= (YYYY-MM-DD), ?style=, ?min_price=, and
+// ?max_price= narrow it further server-side. With ?limit= the response
+// switches to a flat paginated page instead.
+func (s *Server) GetWorkshops(w http.ResponseWriter, r *http.Request) {
+	if opts := queryopts.FromRequest(r); opts.Requested {
+		page, err := database.GetWorkshopsPage(s.cfg, r.URL.Query().Get("when"), opts.Sort,
+			queryInt64(r, "min_price"), queryInt64(r, "max_price"), opts.Limit, opts.Offset)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch workshops")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, page)
+		return
+	}
+	data, err := database.GetWorkshopsFiltered(s.cfg, database.WorkshopFilters{
+		When:      r.URL.Query().Get("when"),
+		StartDate: r.URL.Query().Get("start_date"),
+		EndDate:   r.URL.Query().Get("end_date"),
+		Style:     r.URL.Query().Get("style"),
+		MinPrice:  queryInt64(r, "min_price"),
+		MaxPrice:  queryInt64(r, "max_price"),
+	})
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	if sortKey := r.URL.Query().Get("sort"); sortKey != "" {
+		// An explicit sort is the user's choice; it overrides the ranking
+		// experiment.
+		database.SortCategorizedWorkshops(s.cfg, &data, sortKey)
+	} else {
+		// The route has no role gate, so X-User-ID is client-supplied
+		// here; the experiment's identity must come from the verified
+		// bearer token or a spoofed header would expose which artists the
+		// named user reacts to through the personalized ordering.
+		services.ApplyRankingExperiment(s.cfg, s.verifiedUserID(r), &data)
+	}
+	utils.WriteJSON(w, http.StatusOK, data)
+}
+
+// queryInt64 parses an integer query parameter, returning 0 when absent or
+// malformed.
+func queryInt64(r *http.Request, name string) int64 {
+	n, err := strconv.ParseInt(r.URL.Query().Get(name), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// GetArtists serves all artists; ?has_workshops=true filters to artists with
+// upcoming sessions, and ?limit=/&offset= page the list.
+func (s *Server) GetArtists(w http.ResponseWriter, r *http.Request) {
+	hasWorkshops := r.URL.Query().Get("has_workshops") == "true"
+	if opts := queryopts.FromRequest(r); opts.Requested {
+		page, err := database.GetArtistsPage(s.cfg, hasWorkshops, opts.Limit, opts.Offset)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch artists")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, page)
+		return
+	}
+	artists, err := database.GetAllArtistsFromDB(s.cfg, hasWorkshops)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch artists")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, artists)
+}
+
+// GetStudios serves all studios; ?limit=/&offset= page the list.
+func (s *Server) GetStudios(w http.ResponseWriter, r *http.Request) {
+	if opts := queryopts.FromRequest(r); opts.Requested {
+		page, err := database.GetStudiosPage(s.cfg, opts.Limit, opts.Offset)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch studios")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, page)
+		return
+	}
+	studios, err := database.GetStudios(s.cfg)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch studios")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, studios)
+}
+
+// GetWorkshopsByArtist serves upcoming sessions for one artist.
+func (s *Server) GetWorkshopsByArtist(w http.ResponseWriter, r *http.Request) {
+	artistID := strings.TrimPrefix(r.URL.Path, "/api/workshops_by_artist/")
+	if artistID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "artist_id is required")
+		return
+	}
+	sessions, err := database.GetWorkshopsByArtistWhen(s.cfg, artistID, r.URL.Query().Get("when"))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, sessions)
+}
+
+// HandleArtistRoutes dispatches /api/artists/{id}...: a bare {id} serves
+// the enriched artist profile, {id}/past-workshops the paginated session
+// history.
+func (s *Server) HandleArtistRoutes(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/artists/")
+	if strings.HasSuffix(rest, "/past-workshops") {
+		s.GetArtistPastWorkshops(w, r)
+		return
+	}
+	if rest == "" || strings.Contains(rest, "/") {
+		utils.WriteError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	profile, err := database.GetArtistProfile(s.cfg, rest)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch artist")
+		return
+	}
+	if profile == nil {
+		utils.WriteError(w, http.StatusNotFound, "Artist not found")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, profile)
+}
+
+// GetArtistPastWorkshops serves one page of an artist's historical sessions
+// at /api/artists/{id}/past-workshops.
+func (s *Server) GetArtistPastWorkshops(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/artists/")
+	artistID := strings.TrimSuffix(rest, "/past-workshops")
+	if artistID == "" || artistID == rest {
+		utils.WriteError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	page, err := database.GetPastWorkshopsByArtist(s.cfg, artistID,
+		int(queryInt64(r, "page")), int(queryInt64(r, "page_size")))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch past workshops")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// GetWorkshopsBySong serves every upcoming session teaching one song across
+// studios, matched on normalized titles.
+func (s *Server) GetWorkshopsBySong(w http.ResponseWriter, r *http.Request) {
+	song := strings.TrimPrefix(r.URL.Path, "/api/workshops_by_song/")
+	if song == "" {
+		utils.WriteError(w, http.StatusBadRequest, "song is required")
+		return
+	}
+	sessions, err := database.GetWorkshopsBySong(s.cfg, song)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, sessions)
+}
+
+// GetWorkshopsByStudio serves the categorized schedule for one studio.
+func (s *Server) GetWorkshopsByStudio(w http.ResponseWriter, r *http.Request) {
+	studioID := strings.TrimPrefix(r.URL.Path, "/api/workshops_by_studio/")
+	if studioID == "" {
+		utils.WriteError(w, http.StatusBadRequest, "studio_id is required")
+		return
+	}
+	data, err := database.GetWorkshopsByStudioWhen(s.cfg, studioID, r.URL.Query().Get("when"))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	database.FilterCategorizedByPriceRange(&data, queryInt64(r, "min_price"), queryInt64(r, "max_price"))
+	database.SortCategorizedWorkshops(s.cfg, &data, r.URL.Query().Get("sort"))
+	utils.WriteJSON(w, http.StatusOK, data)
+}
+
+// HandleReactions creates (POST) or removes (DELETE) a reaction for the
+// authenticated user.
+func (s *Server) HandleReactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	var body request.ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		if err := s.reactionService.CheckRateLimit(userID); err != nil {
+			utils.WriteError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		// Shadow-limited accounts see success but their reactions are
+		// written soft-deleted so spam never surfaces.
+		reaction := services.Reaction{
+			UserID:     userID,
+			EntityID:   body.EntityID,
+			EntityType: body.EntityType,
+			Reaction:   body.Reaction,
+		}
+		var err error
+		if s.reactionService.IsShadowLimited(userID) {
+			err = s.reactionService.CreateShadowedReaction(reaction)
+		} else {
+			err = s.reactionService.CreateReaction(reaction)
+			s.reactionService.RecordReactionForAbuse(userID)
+		}
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to save reaction")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	case http.MethodDelete:
+		if err := s.reactionService.DeleteReaction(userID, body.EntityID, body.EntityType, body.Reaction); err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to delete reaction")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetUserReactions returns every reaction for the authenticated user.
+// With ?since=<epoch seconds> it serves a delta instead: only reactions
+// changed after the cursor, tombstones included, wrapped in a sync
+// envelope carrying the next cursor.
+func (s *Server) GetUserReactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		since, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || since < 0 {
+			utils.WriteError(w, http.StatusBadRequest, "since must be epoch seconds")
+			return
+		}
+		delta, err := s.reactionService.GetUserReactionsSince(userID, since)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch reactions")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, delta)
+		return
+	}
+	reactions, err := s.reactionService.GetUserReactions(userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch reactions")
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, reactions)
+}
+
+// RegisterDeviceToken stores a push token for the authenticated user.
+func (s *Server) RegisterDeviceToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	var body request.DeviceTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.DeviceToken == "" {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := s.notifService.RegisterDeviceToken(userID, body.DeviceToken, body.Platform); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to register device token")
+		return
+	}
+	go s.reactionService.CheckSharedDeviceToken(body.DeviceToken)
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// HandleNotificationPreferences reads (GET) or updates (PUT) the
+// authenticated user's notification delivery mode: immediate pushes or the
+// daily evening digest.
+func (s *Server) HandleNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		prefs, err := s.notifService.GetNotificationPreferences(userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch preferences")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, prefs)
+	case http.MethodPut:
+		var body struct {
+			Mode string `json:"mode"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err := s.notifService.SetNotificationPreferences(userID, body.Mode); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleModerationFlags serves the abuse moderation queue: GET lists
+// unresolved flags, POST resolves a user's flags and lifts the shadow limit.
+func (s *Server) HandleModerationFlags(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		flags, err := s.reactionService.GetFlaggedAccounts()
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch moderation queue")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, flags)
+	case http.MethodPost:
+		var body struct {
+			UserID string `json:"user_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.UserID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "user_id is required")
+			return
+		}
+		if err := s.reactionService.ResolveAbuseFlag(body.UserID); err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to resolve flag")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleOrderActions dispatches /api/orders/{id}/... actions for the
+// authenticated user. GET {id}/refund-quote evaluates the studio's refund
+// policy for cancelling now, so clients can show the computed refund
+// before asking for confirmation; POST {id}/cancel executes the
+// cancellation at whatever that same engine quotes at execution time.
+// GET {id}/stream-link reveals a streaming workshop's join link to a paid
+// order once the reveal window opens. POST {id}/share mints the order's
+// public ticket-sharing code. POST {id}/visibility opts the order in or
+// out of the "going with friends" listing.
+func (s *Server) HandleOrderActions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/api/orders/")
+	switch {
+	case strings.HasSuffix(rest, "/refund-quote") && r.Method == http.MethodGet:
+		orderID := strings.TrimSuffix(rest, "/refund-quote")
+		quote, err := s.orderService.QuoteRefund(orderID, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, quote)
+	case strings.HasSuffix(rest, "/stream-link") && r.Method == http.MethodGet:
+		orderID := strings.TrimSuffix(rest, "/stream-link")
+		access, err := s.orderService.GetStreamAccess(orderID, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, access)
+	case strings.HasSuffix(rest, "/visibility") && r.Method == http.MethodPost:
+		orderID := strings.TrimSuffix(rest, "/visibility")
+		var body struct {
+			Visible bool `json:"visible"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err := services.SetOrderVisibility(s.cfg, orderID, userID, body.Visible); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	case strings.HasSuffix(rest, "/share") && r.Method == http.MethodPost:
+		orderID := strings.TrimSuffix(rest, "/share")
+		code, err := services.EnsureShareCode(s.cfg, orderID, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]string{
+			"share_code": code,
+			"share_url":  "https://nachna.com/ticket/" + code,
+		})
+	case strings.HasSuffix(rest, "/cancel") && r.Method == http.MethodPost:
+		if err := s.orderService.CheckCancelRateLimit(userID); err != nil {
+			utils.WriteError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		orderID := strings.TrimSuffix(rest, "/cancel")
+		result, err := s.orderService.CancelOrder(orderID, userID)
+		if err != nil {
+			var stateErr *services.AccountStateError
+			if errors.As(err, &stateErr) {
+				utils.WriteError(w, http.StatusForbidden, stateErr.Message)
+				return
+			}
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, result)
+	default:
+		utils.WriteError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// HandleChoreoLinks lets a claimed artist submit choreo Instagram links
+// mapped to songs (POST) and list their own submissions (GET). Submissions
+// stay pending until an admin approves them.
+func (s *Server) HandleChoreoLinks(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		utils.WriteError(w, http.StatusUnauthorized, "Missing user identity")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		submissions, err := s.choreoService.GetSubmissionsByUser(userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch submissions")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, submissions)
+	case http.MethodPost:
+		var body request.ChoreoLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ArtistID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		submission, err := s.choreoService.SubmitChoreoLink(userID, body.ArtistID, body.Song, body.InstaLink)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, submission)
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleChoreoModeration serves the choreo link moderation queue: GET lists
+// submissions (?status= defaults to all), POST approves or rejects one.
+// Approval upserts the link into choreo_links and attaches it to the
+// artist's matching workshops.
+func (s *Server) HandleChoreoModeration(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		submissions, err := s.choreoService.GetSubmissionsByStatus(r.URL.Query().Get("status"))
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch submissions")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, submissions)
+	case http.MethodPost:
+		var body request.ChoreoReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.SubmissionID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "submission_id is required")
+			return
+		}
+		if err := s.choreoService.ReviewSubmission(body.SubmissionID, r.Header.Get("X-User-ID"), body.Approve); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// SendTestNotification pushes a test notification to all active devices on
+// both platforms, reporting per-platform delivery results.
+func (s *Server) SendTestNotification(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	results, err := s.notifService.SendTestNotificationWithResults(body.Title, body.Body)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to send notifications")
+		return
+	}
+	total := 0
+	for _, result := range results {
+		total += result.Targeted
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"devices_targeted": total,
+		"results":          results,
+	})
+}
+
+// HandleCampaign manages bulk push campaigns. POST {title, body} starts a
+// rate-controlled send to every active device and returns the campaign to
+// poll; GET ?campaign_id= reports one campaign's progress, GET without an
+// ID lists recent campaigns.
+func (s *Server) HandleCampaign(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Title string `json:"title"`
+			Body  string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Title == "" || body.Body == "" {
+			utils.WriteError(w, http.StatusBadRequest, "title and body are required")
+			return
+		}
+		campaign, err := s.notifService.StartCampaign(body.Title, body.Body)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to start campaign")
+			return
+		}
+		utils.WriteJSON(w, http.StatusAccepted, campaign)
+	case http.MethodGet:
+		if campaignID := r.URL.Query().Get("campaign_id"); campaignID != "" {
+			campaign, err := s.notifService.GetCampaign(campaignID)
+			if err != nil {
+				utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch campaign")
+				return
+			}
+			if campaign == nil {
+				utils.WriteError(w, http.StatusNotFound, "No such campaign")
+				return
+			}
+			utils.WriteJSON(w, http.StatusOK, campaign)
+			return
+		}
+		campaigns, err := s.notifService.ListCampaigns()
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, "Failed to fetch campaigns")
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, campaigns)
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleNotificationPreview renders the new-workshop push against real data
+// without touching anyone's devices. GET ?workshop_uuid=&user_id= returns
+// the exact per-platform payloads that would be sent (user_id defaults to
+// the admin); POST {workshop_uuid} delivers the rendered push to the
+// admin's own devices only.
+func (s *Server) HandleNotificationPreview(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Header.Get("X-User-ID")
+	switch r.Method {
+	case http.MethodGet:
+		workshopUUID := r.URL.Query().Get("workshop_uuid")
+		if workshopUUID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "workshop_uuid is required")
+			return
+		}
+		userID := r.URL.Query().Get("user_id")
+		if userID == "" {
+			userID = adminID
+		}
+		preview, err := s.notifService.PreviewWorkshopNotification(workshopUUID, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, preview)
+	case http.MethodPost:
+		var body struct {
+			WorkshopUUID string `json:"workshop_uuid"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.WorkshopUUID == "" {
+			utils.WriteError(w, http.StatusBadRequest, "workshop_uuid is required")
+			return
+		}
+		preview, err := s.notifService.SendPreviewToSelf(body.WorkshopUUID, adminID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, preview)
+	default:
+		utils.WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,400 @@
+// Package application hosts the long-running background jobs that run inside
+// the API server process: currently the QR code generation loop for paid
+// orders.
+package application
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/ai"
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/services"
+	"dance_api_go/studios"
+)
+
+// qrJobInterval is how often the QR generation loop scans for paid orders
+// that are missing their entry QR code.
+const qrJobInterval = 10 * time.Minute
+
+// StartBackgroundJobs launches all background loops. It returns immediately;
+// the loops run for the lifetime of the process.
+func StartBackgroundJobs(cfg *config.Config) {
+	go qrGenerationLoop(cfg)
+	go digestLoop(cfg)
+	go priceBackfillJob(cfg)
+	go uuidBackfillJob(cfg)
+	go eventExportLoop(cfg)
+	go revenueReportLoop(cfg)
+	go deadLinkLoop(cfg)
+	go reminderLoop(cfg)
+	go earlyBirdAlertLoop(cfg)
+	go retentionLoop(cfg)
+	go softDeletePurgeLoop(cfg)
+	go analysisRetryLoop(cfg)
+	go scrapeJobSweep(cfg)
+	go reviewSLALoop(cfg)
+	go campaignResumeSweep(cfg)
+	database.StartCollectionGrowthMonitor(cfg)
+}
+
+// retentionInterval is how often the retention policy engine runs; data
+// ages in years and months, so daily is plenty.
+const retentionInterval = 24 * time.Hour
+
+// retentionLoop executes the configured retention policies (order PII
+// anonymization, analytics expiry, deleted-account purge) and logs any
+// policy that touched data; each executed policy also writes its own
+// audit entry.
+func retentionLoop(cfg *config.Config) {
+	for {
+		if !database.IsReady() {
+			time.Sleep(retentionInterval)
+			continue
+		}
+		results, err := services.RunRetentionPolicies(cfg, false)
+		if err != nil {
+			log.Printf("retention pass failed: %v", err)
+		} else {
+			for _, result := range results {
+				if result.Affected > 0 {
+					log.Printf("retention %s: %s %d documents in %s",
+						result.Policy, result.Action, result.Affected, result.Collection)
+				}
+			}
+		}
+		time.Sleep(retentionInterval)
+	}
+}
+
+// Soft-deleted studios, artists, and workshops stay restorable for this
+// long before the purge sweep hard-deletes them.
+const softDeleteRetention = 30 * 24 * time.Hour
+
+// softDeletePurgeInterval is how often the purge sweep runs; retention is
+// measured in weeks, so daily is plenty.
+const softDeletePurgeInterval = 24 * time.Hour
+
+// softDeletePurgeLoop hard-deletes documents that were soft-deleted more
+// than the retention window ago, closing the loop on admin deletes.
+func softDeletePurgeLoop(cfg *config.Config) {
+	for {
+		if !database.IsReady() {
+			time.Sleep(softDeletePurgeInterval)
+			continue
+		}
+		purged, err := database.PurgeSoftDeleted(cfg, softDeleteRetention)
+		if err != nil {
+			log.Printf("soft delete purge failed: %v", err)
+		}
+		for collection, count := range purged {
+			log.Printf("soft delete purge: removed %d documents from %s", count, collection)
+		}
+		time.Sleep(softDeletePurgeInterval)
+	}
+}
+
+// reminderInterval is how often the reminder scheduler scans for sessions
+// entering the lead window; the reminders collection dedupes across passes.
+const reminderInterval = 30 * time.Minute
+
+// reminderLoop reminds booked and liking users before their workshop's
+// first session starts.
+func reminderLoop(cfg *config.Config) {
+	notif := services.NewNotificationService(cfg)
+	for {
+		if !database.IsReady() {
+			time.Sleep(reminderInterval)
+			continue
+		}
+		sent, err := notif.SendWorkshopReminders()
+		if err != nil {
+			log.Printf("reminder pass failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("sent %d workshop reminders", sent)
+		}
+		time.Sleep(reminderInterval)
+	}
+}
+
+// earlyBirdAlertInterval is how often the early-bird alert pass scans for
+// price tiers closing within the lead window; the early_bird_alerts
+// collection dedupes across passes.
+const earlyBirdAlertInterval = 30 * time.Minute
+
+// earlyBirdAlertLoop nudges users who liked a workshop when its early-bird
+// price is about to close.
+func earlyBirdAlertLoop(cfg *config.Config) {
+	notif := services.NewNotificationService(cfg)
+	for {
+		if !database.IsReady() {
+			time.Sleep(earlyBirdAlertInterval)
+			continue
+		}
+		sent, err := notif.SendEarlyBirdClosingAlerts()
+		if err != nil {
+			log.Printf("early bird alert pass failed: %v", err)
+		} else if sent > 0 {
+			log.Printf("sent %d early-bird closing alerts", sent)
+		}
+		time.Sleep(earlyBirdAlertInterval)
+	}
+}
+
+// analysisRetryInterval is how often the queue of failed AI analyses is
+// scanned for due tasks; per-task backoff lives on the tasks themselves.
+const analysisRetryInterval = 5 * time.Minute
+
+// analysisRetryLoop re-runs failed scrape analyses from their stored
+// screenshots and merges recovered workshops into the live set.
+func analysisRetryLoop(cfg *config.Config) {
+	analyzer := ai.NewAnalyzer(cfg)
+	for {
+		if !database.IsReady() {
+			time.Sleep(analysisRetryInterval)
+			continue
+		}
+		recovered, err := studios.RetryFailedAnalyses(context.Background(), cfg, analyzer)
+		if err != nil {
+			log.Printf("analysis retry pass failed: %v", err)
+		} else if recovered > 0 {
+			log.Printf("analysis retry recovered %d workshops", recovered)
+		}
+		time.Sleep(analysisRetryInterval)
+	}
+}
+
+// deadLinkCheckInterval is how often upcoming workshops' payment links are
+// probed; nightly is enough since links break when events change, not
+// continuously.
+const deadLinkCheckInterval = 24 * time.Hour
+
+// deadLinkLoop sweeps payment links on upcoming workshops, flagging broken
+// ones so listings hide the book button and admins get alerted.
+func deadLinkLoop(cfg *config.Config) {
+	for {
+		if !database.IsReady() {
+			time.Sleep(deadLinkCheckInterval)
+			continue
+		}
+		sweep, err := services.CheckPaymentLinks(cfg)
+		if err != nil {
+			log.Printf("dead link sweep failed: %v", err)
+		} else if sweep.Dead > 0 {
+			log.Printf("dead link sweep: %d of %d links dead (%d new, %d recovered)",
+				sweep.Dead, sweep.Checked, sweep.NewlyDead, sweep.Recovered)
+		}
+		time.Sleep(deadLinkCheckInterval)
+	}
+}
+
+// revenueReportInterval is how often the revenue reporting collection is
+// rebuilt from the order history.
+const revenueReportInterval = 6 * time.Hour
+
+// revenueReportLoop keeps the month-by-studio revenue_reports rows current
+// so /admin/api/revenue reads precomputed aggregates.
+func revenueReportLoop(cfg *config.Config) {
+	for {
+		if !database.IsReady() {
+			time.Sleep(revenueReportInterval)
+			continue
+		}
+		written, err := services.ComputeRevenueReports(cfg)
+		if err != nil {
+			log.Printf("revenue report pass failed: %v", err)
+		} else if written > 0 {
+			log.Printf("revenue reports: refreshed %d rows", written)
+		}
+		time.Sleep(revenueReportInterval)
+	}
+}
+
+// eventExportInterval is how often unexported domain events are pushed to
+// the analytics warehouse.
+const eventExportInterval = 15 * time.Minute
+
+// eventExportLoop ships domain events to the configured warehouse. The loop
+// still runs without an export URL (the push is then a no-op) so enabling
+// it only requires setting ANALYTICS_EXPORT_URL and restarting.
+func eventExportLoop(cfg *config.Config) {
+	for {
+		time.Sleep(eventExportInterval)
+		if !database.IsReady() {
+			continue
+		}
+		shipped, err := services.ExportDomainEvents(cfg)
+		if err != nil {
+			log.Printf("domain event export failed: %v", err)
+			continue
+		}
+		if shipped > 0 {
+			log.Printf("exported %d domain events", shipped)
+		}
+	}
+}
+
+// priceBackfillJob backfills the numeric min_price/max_price fields on
+// workshop documents imported before the pricing parser existed. It runs
+// once after the database is ready; fresh imports get the fields from the
+// scraper directly.
+func priceBackfillJob(cfg *config.Config) {
+	for !database.IsReady() {
+		time.Sleep(5 * time.Second)
+	}
+	updated, err := database.BackfillWorkshopPrices(cfg)
+	if err != nil {
+		log.Printf("price backfill failed: %v", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("price backfill: populated %d workshops", updated)
+	}
+}
+
+// uuidBackfillJob stamps a composite uuid onto workshop documents missing
+// one, so every workshop is addressable by the standard external ID. Runs
+// once after the database is ready.
+func uuidBackfillJob(cfg *config.Config) {
+	for !database.IsReady() {
+		time.Sleep(5 * time.Second)
+	}
+	updated, err := database.BackfillWorkshopUUIDs(cfg)
+	if err != nil {
+		log.Printf("uuid backfill failed: %v", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("uuid backfill: stamped %d workshops", updated)
+	}
+}
+
+// campaignResumeSweep restarts bulk push campaigns a previous process left
+// mid-send, each continuing from its checkpoint. Runs once after the
+// database is ready.
+func campaignResumeSweep(cfg *config.Config) {
+	for !database.IsReady() {
+		time.Sleep(5 * time.Second)
+	}
+	resumed, err := services.NewNotificationService(cfg).ResumeCampaigns()
+	if err != nil {
+		log.Printf("campaign resume sweep failed: %v", err)
+		return
+	}
+	if resumed > 0 {
+		log.Printf("campaign resume sweep: resumed %d campaigns", resumed)
+	}
+}
+
+// reviewSLAInterval is how often the review queue is checked against the
+// completion SLA.
+const reviewSLAInterval = time.Hour
+
+// reviewSLALoop alerts on data-completion tasks (missing artists, songs,
+// choreo links) that have sat in the review queue longer than the
+// configured SLA, so admins notice before listings go live incomplete.
+func reviewSLALoop(cfg *config.Config) {
+	for {
+		if !database.IsReady() {
+			time.Sleep(reviewSLAInterval)
+			continue
+		}
+		report, err := database.GetReviewSLAReport(cfg)
+		if err != nil {
+			log.Printf("review SLA check failed: %v", err)
+		} else if report.Overdue > 0 {
+			log.Printf("review SLA: %d tasks open longer than %dh (%d open total)",
+				report.Overdue, report.SLAHours, report.Open)
+		}
+		time.Sleep(reviewSLAInterval)
+	}
+}
+
+// scrapeJobSweep marks scrape jobs a previous process left queued or
+// running as abandoned. Jobs execute as in-process goroutines, so any
+// non-terminal job found at startup died with its process. Runs once after
+// the database is ready.
+func scrapeJobSweep(cfg *config.Config) {
+	for !database.IsReady() {
+		time.Sleep(5 * time.Second)
+	}
+	abandoned, err := studios.AbandonStaleScrapeJobs(cfg)
+	if err != nil {
+		log.Printf("scrape job sweep failed: %v", err)
+		return
+	}
+	if abandoned > 0 {
+		log.Printf("scrape job sweep: abandoned %d stale jobs", abandoned)
+	}
+}
+
+// digestLoop sends the daily notification digests every evening (IST) to
+// users who opted into digest mode.
+func digestLoop(cfg *config.Config) {
+	notif := services.NewNotificationService(cfg)
+	for {
+		time.Sleep(time.Until(services.NextDigestTime(time.Now())))
+		if !database.IsReady() {
+			continue
+		}
+		sent, err := notif.SendDailyDigests()
+		if err != nil {
+			log.Printf("digest pass failed: %v", err)
+			continue
+		}
+		if sent > 0 {
+			log.Printf("sent %d notification digests", sent)
+		}
+	}
+}
+
+// qrGenerationLoop periodically generates QR codes for paid orders that do
+// not have one yet, mirroring the Python background_qr_service.
+func qrGenerationLoop(cfg *config.Config) {
+	for {
+		if !database.IsReady() {
+			time.Sleep(qrJobInterval)
+			continue
+		}
+		if err := generateMissingQRCodes(cfg); err != nil {
+			log.Printf("QR generation pass failed: %v", err)
+		}
+		time.Sleep(qrJobInterval)
+	}
+}
+
+// generateMissingQRCodes finds paid orders without a QR code and marks them
+// for generation by the shared QR pipeline.
+func generateMissingQRCodes(cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := database.Collection(cfg, "orders").Find(ctx, bson.M{
+		"status":             "paid",
+		"qr_codes_generated": bson.M{"$ne": true},
+	})
+	if err != nil {
+		return err
+	}
+	var orders []bson.M
+	if err := cursor.All(ctx, &orders); err != nil {
+		return err
+	}
+	for _, order := range orders {
+		orderID, _ := order["order_id"].(string)
+		_, err := database.Collection(cfg, "orders").UpdateOne(ctx,
+			bson.M{"order_id": orderID},
+			bson.M{"$set": bson.M{"qr_generation_requested_at": time.Now().UTC()}})
+		if err != nil {
+			log.Printf("failed to request QR generation for order %s: %v", orderID, err)
+		}
+	}
+	if len(orders) > 0 {
+		log.Printf("requested QR generation for %d orders", len(orders))
+	}
+	return nil
+}
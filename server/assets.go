@@ -0,0 +1,77 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Templates and static assets are compiled into the binary so a deploy whose
+// working directory lacks templates/ still serves the web pages. Disk copies
+// take precedence in dev (EMBED_ASSETS=false) so designers can iterate
+// without rebuilding.
+
+//go:embed templates static
+var embeddedAssets embed.FS
+
+// readTemplate loads a template by file name, honoring the embedded-assets
+// config flag and falling back to the other source when the preferred one
+// is missing.
+func readTemplate(name string) ([]byte, error) {
+	diskPath := path.Join("templates", name)
+	embedPath := path.Join("templates", name)
+
+	if cfg.UseEmbeddedAssets {
+		if body, err := embeddedAssets.ReadFile(embedPath); err == nil {
+			return body, nil
+		}
+		return os.ReadFile(diskPath)
+	}
+	if body, err := os.ReadFile(diskPath); err == nil {
+		return body, nil
+	}
+	return embeddedAssets.ReadFile(embedPath)
+}
+
+// readStaticFile loads a file under static/ by its request path (e.g.
+// "/static/css/site.css"), with the same embedded/disk precedence rules as
+// readTemplate.
+func readStaticFile(requestPath string) ([]byte, error) {
+	rel := strings.TrimPrefix(path.Clean(requestPath), "/")
+	if !strings.HasPrefix(rel, "static/") {
+		return nil, fs.ErrNotExist
+	}
+
+	if cfg.UseEmbeddedAssets {
+		if body, err := embeddedAssets.ReadFile(rel); err == nil {
+			return body, nil
+		}
+		return os.ReadFile(rel)
+	}
+	if body, err := os.ReadFile(rel); err == nil {
+		return body, nil
+	}
+	return embeddedAssets.ReadFile(rel)
+}
+
+// staticContentType maps a static file extension to its Content-Type.
+func staticContentType(requestPath string) string {
+	switch path.Ext(requestPath) {
+	case ".css":
+		return "text/css; charset=utf-8"
+	case ".js":
+		return "application/javascript; charset=utf-8"
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".svg":
+		return "image/svg+xml"
+	case ".ico":
+		return "image/x-icon"
+	default:
+		return "application/octet-stream"
+	}
+}
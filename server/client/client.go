@@ -0,0 +1,129 @@
+// Package client is the typed Go client for the Nachna public API, generated
+// against the endpoint registry in the openapi package. Internal tools and
+// partners should use it instead of hand-writing HTTP calls; response types
+// are shared with the server's models/response package so they never drift.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"dance_api_go/models/response"
+)
+
+// Client calls the public Nachna API.
+type Client struct {
+	// BaseURL is the server root, e.g. "https://nachna.com".
+	BaseURL string
+	// APIKey, when set, is sent as X-API-Key so partner traffic bypasses
+	// anti-scraping throttles.
+	APIKey string
+
+	httpClient *http.Client
+}
+
+// New returns a Client for the given base URL.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetWorkshops fetches all upcoming workshops grouped into this week and
+// later.
+func (c *Client) GetWorkshops(ctx context.Context) (*response.CategorizedWorkshopResponse, error) {
+	var out response.CategorizedWorkshopResponse
+	if err := c.get(ctx, "/api/workshops", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetArtists fetches all artists; when hasWorkshops is true only artists
+// with at least one upcoming workshop are returned.
+func (c *Client) GetArtists(ctx context.Context, hasWorkshops bool) ([]response.Artist, error) {
+	query := url.Values{}
+	if hasWorkshops {
+		query.Set("has_workshops", "true")
+	}
+	var out []response.Artist
+	if err := c.get(ctx, "/api/artists", query, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetStudios fetches all studios.
+func (c *Client) GetStudios(ctx context.Context) ([]response.Studio, error) {
+	var out []response.Studio
+	if err := c.get(ctx, "/api/studios", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWorkshopsByArtist fetches the upcoming workshop sessions for an artist.
+func (c *Client) GetWorkshopsByArtist(ctx context.Context, artistID string) ([]response.WorkshopListItem, error) {
+	var out []response.WorkshopListItem
+	if err := c.get(ctx, "/api/workshops_by_artist/"+url.PathEscape(artistID), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetWorkshopsByStudio fetches a studio's upcoming workshops grouped by week.
+func (c *Client) GetWorkshopsByStudio(ctx context.Context, studioID string) (*response.CategorizedWorkshopResponse, error) {
+	var out response.CategorizedWorkshopResponse
+	if err := c.get(ctx, "/api/workshops_by_studio/"+url.PathEscape(studioID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// APIError is a non-2xx response from the server.
+type APIError struct {
+	StatusCode int
+	Detail     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("nachna api: %d: %s", e.StatusCode, e.Detail)
+}
+
+// get performs a GET request and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var detail struct {
+			Detail string `json:"detail"`
+		}
+		_ = json.Unmarshal(body, &detail)
+		return &APIError{StatusCode: resp.StatusCode, Detail: detail.Detail}
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,138 @@
+// replay re-runs a dev-mode recording (see services.RequestRecorder)
+// against another build and compares status codes, turning a recorded
+// manual session — clicking through the admin panel, exercising app
+// flows — into a quick regression check after a refactor. Each request
+// prints PASS or FAIL; the exit code is non-zero when any status differs.
+//
+// Mutating requests are skipped unless --mutations is set, so replaying a
+// recording against a shared environment is safe by default. Recorded
+// credentials never reach disk; --auth-token supplies a bearer token for
+// the replayed requests instead.
+//
+// Usage:
+//
+//	replay --file recordings/requests-20260830-120000.jsonl \
+//	    [--base-url http://localhost:8002] [--mux-url http://localhost:8003] \
+//	    [--auth-token TOKEN] [--mutations]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"dance_api_go/services"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+func main() {
+	file := flag.String("file", "", "recording file to replay (required)")
+	baseURL := flag.String("base-url", "http://localhost:8002", "fasthttp API server base URL")
+	muxURL := flag.String("mux-url", "http://localhost:8003", "mux server base URL")
+	authToken := flag.String("auth-token", "", "bearer token sent with every replayed request")
+	mutations := flag.Bool("mutations", false, "replay non-GET requests too")
+	flag.Parse()
+
+	if *file == "" {
+		fmt.Println("replay: --file is required")
+		os.Exit(1)
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Printf("replay: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	passed, failed, skipped := 0, 0, 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry services.RecordedRequest
+		if err := json.Unmarshal(line, &entry); err != nil {
+			fmt.Printf("SKIP unparseable line: %v\n", err)
+			skipped++
+			continue
+		}
+		if entry.Method != http.MethodGet && !*mutations {
+			fmt.Printf("SKIP %s %s (mutations disabled)\n", entry.Method, entry.Path)
+			skipped++
+			continue
+		}
+		if err := replayEntry(entry, *baseURL, *muxURL, *authToken); err != nil {
+			fmt.Printf("FAIL %s %s: %v\n", entry.Method, entry.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("PASS %s %s\n", entry.Method, entry.Path)
+		passed++
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("replay: read error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("replay: %d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// replayEntry re-sends one recorded request and compares the status code
+// against the recorded one.
+func replayEntry(entry services.RecordedRequest, baseURL, muxURL, authToken string) error {
+	target := baseURL
+	if entry.Server == "mux" {
+		target = muxURL
+	}
+	url := target + entry.Path
+	if entry.Query != "" {
+		url += "?" + entry.Query
+	}
+	req, err := http.NewRequest(entry.Method, url, strings.NewReader(entry.RequestBody))
+	if err != nil {
+		return err
+	}
+	if entry.ContentType != "" {
+		req.Header.Set("Content-Type", entry.ContentType)
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != entry.Status {
+		detail := responseDetail(resp)
+		if detail != "" {
+			return fmt.Errorf("status %d, recorded %d (%s)", resp.StatusCode, entry.Status, detail)
+		}
+		return fmt.Errorf("status %d, recorded %d", resp.StatusCode, entry.Status)
+	}
+	return nil
+}
+
+// responseDetail pulls the error detail out of a mismatched response body
+// so a FAIL line says why without dumping the whole payload.
+func responseDetail(resp *http.Response) string {
+	var body struct {
+		Detail string `json:"detail"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(body.Detail)
+}
@@ -0,0 +1,220 @@
+// smoketest exercises the real booking flow against a staging instance for
+// post-deploy verification: log in with the shared test number, browse
+// workshops, create an order in the gateway's test mode, simulate the
+// payment webhook, and verify QR generation was requested. Each step prints
+// PASS or FAIL; the exit code is non-zero when any step fails.
+//
+// Usage:
+//
+//	smoketest --base-url https://staging.nachna.com [--auth-url https://staging.nachna.com]
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"dance_api_go/client"
+	"dance_api_go/models/response"
+)
+
+// Test user from the shared testing setup; it exists in every staging
+// database and its OTP is accepted without an SMS round trip.
+const (
+	testMobileNumber = "9999999999"
+	testOTP          = "000000"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+type smoketest struct {
+	baseURL string
+	authURL string
+	failed  bool
+
+	authToken string
+	workshop  *response.WorkshopListItem
+	orderID   string
+}
+
+func main() {
+	baseURL := flag.String("base-url", "http://localhost:8002", "Golang API server base URL")
+	authURL := flag.String("auth-url", "http://localhost:8000", "auth/order API base URL")
+	flag.Parse()
+
+	s := &smoketest{baseURL: *baseURL, authURL: *authURL}
+	s.step("login with test number", s.login)
+	s.step("browse workshops", s.browseWorkshops)
+	s.step("create test-mode order", s.createOrder)
+	s.step("simulate payment webhook", s.simulateWebhook)
+	s.step("verify QR generation requested", s.verifyQRGeneration)
+
+	if s.failed {
+		fmt.Println("smoketest: FAIL")
+		os.Exit(1)
+	}
+	fmt.Println("smoketest: PASS")
+}
+
+// step runs one flow step and records its outcome. Steps after a failure
+// are skipped since each depends on the previous one's state.
+func (s *smoketest) step(name string, fn func() error) {
+	if s.failed {
+		fmt.Printf("SKIP %s\n", name)
+		return
+	}
+	if err := fn(); err != nil {
+		fmt.Printf("FAIL %s: %v\n", name, err)
+		s.failed = true
+		return
+	}
+	fmt.Printf("PASS %s\n", name)
+}
+
+// login performs the OTP flow with the test number, which staging accepts
+// with the fixed test OTP.
+func (s *smoketest) login() error {
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	err := s.postJSON(s.authURL+"/api/auth/verify-otp", map[string]string{
+		"mobile_number": testMobileNumber,
+		"otp":           testOTP,
+	}, &result)
+	if err != nil {
+		return err
+	}
+	if result.AccessToken == "" {
+		return fmt.Errorf("no access token in response")
+	}
+	s.authToken = result.AccessToken
+	return nil
+}
+
+// browseWorkshops fetches the public listing and picks the first workshop
+// with a Nachna payment link to book against.
+func (s *smoketest) browseWorkshops() error {
+	c := client.New(s.baseURL)
+	data, err := c.GetWorkshops(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, day := range data.ThisWeek {
+		for _, w := range day.Workshops {
+			if w.PaymentLink != "" {
+				copied := w
+				s.workshop = &copied
+				return nil
+			}
+		}
+	}
+	for _, w := range data.PostThisWeek {
+		if w.PaymentLink != "" {
+			copied := w
+			s.workshop = &copied
+			return nil
+		}
+	}
+	return fmt.Errorf("no bookable workshop found in listing")
+}
+
+// createOrder creates an order for the chosen workshop using the gateway's
+// test mode.
+func (s *smoketest) createOrder() error {
+	var result struct {
+		OrderID string `json:"order_id"`
+	}
+	err := s.postJSON(s.authURL+"/api/orders/create", map[string]interface{}{
+		"workshop_uuid": s.workshop.UUID,
+		"test_mode":     true,
+	}, &result)
+	if err != nil {
+		return err
+	}
+	if result.OrderID == "" {
+		return fmt.Errorf("no order_id in response")
+	}
+	s.orderID = result.OrderID
+	return nil
+}
+
+// simulateWebhook posts a synthetic payment.captured event for the order,
+// as the gateway's test mode would. The fresh event_id and timestamp are
+// required to pass the server's replay validation.
+func (s *smoketest) simulateWebhook() error {
+	return s.postJSON(s.authURL+"/api/orders/webhook/test", map[string]interface{}{
+		"event":     "payment.captured",
+		"event_id":  fmt.Sprintf("smoketest-%d", time.Now().UnixNano()),
+		"order_id":  s.orderID,
+		"timestamp": time.Now().Unix(),
+	}, nil)
+}
+
+// verifyQRGeneration polls the order until the background QR pipeline has
+// picked it up.
+func (s *smoketest) verifyQRGeneration() error {
+	deadline := time.Now().Add(2 * time.Minute)
+	for time.Now().Before(deadline) {
+		var order struct {
+			Status                  string `json:"status"`
+			QRGenerationRequestedAt string `json:"qr_generation_requested_at"`
+		}
+		if err := s.getJSON(s.authURL+"/api/orders/"+s.orderID, &order); err != nil {
+			return err
+		}
+		if order.Status == "paid" && order.QRGenerationRequestedAt != "" {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("QR generation not requested within 2 minutes")
+}
+
+func (s *smoketest) postJSON(url string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return s.do(req, out)
+}
+
+func (s *smoketest) getJSON(url string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, out)
+}
+
+func (s *smoketest) do(req *http.Request, out interface{}) error {
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	if out != nil {
+		return json.Unmarshal(body, out)
+	}
+	return nil
+}
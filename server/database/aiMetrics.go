@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// AIMetric is one analyzer call's cost and latency, recorded in ai_metrics
+// so providers can be compared on what they actually charge and how fast
+// they answer.
+type AIMetric struct {
+	Provider         string    `bson:"provider" json:"provider"`
+	Model            string    `bson:"model" json:"model"`
+	Success          bool      `bson:"success" json:"success"`
+	LatencyMS        int64     `bson:"latency_ms" json:"latency_ms"`
+	InputTokens      int       `bson:"input_tokens" json:"input_tokens"`
+	OutputTokens     int       `bson:"output_tokens" json:"output_tokens"`
+	EstimatedCostUSD float64   `bson:"estimated_cost_usd" json:"estimated_cost_usd"`
+	CreatedAt        time.Time `bson:"created_at" json:"created_at"`
+}
+
+// RecordAIMetric stores one analyzer call's metrics. Best effort — metric
+// bookkeeping must never fail an analysis.
+func RecordAIMetric(cfg *config.Config, metric AIMetric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := Collection(cfg, "ai_metrics").InsertOne(ctx, metric); err != nil {
+		log.Printf("ai metrics: record failed for %s: %v", metric.Provider, err)
+	}
+}
+
+// AIProviderMetrics is one provider/model pair's aggregate cost and
+// latency, as served to admins.
+type AIProviderMetrics struct {
+	Provider         string  `bson:"-" json:"provider"`
+	Model            string  `bson:"-" json:"model"`
+	Calls            int64   `bson:"calls" json:"calls"`
+	Failures         int64   `bson:"failures" json:"failures"`
+	AvgLatencyMS     float64 `bson:"avg_latency_ms" json:"avg_latency_ms"`
+	InputTokens      int64   `bson:"input_tokens" json:"input_tokens"`
+	OutputTokens     int64   `bson:"output_tokens" json:"output_tokens"`
+	EstimatedCostUSD float64 `bson:"estimated_cost_usd" json:"estimated_cost_usd"`
+}
+
+// GetAIProviderMetrics aggregates ai_metrics by provider and model.
+func GetAIProviderMetrics(cfg *config.Config) ([]AIProviderMetrics, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "ai_metrics").Aggregate(ctx, []bson.M{
+		{"$group": bson.M{
+			"_id":                bson.M{"provider": "$provider", "model": "$model"},
+			"calls":              bson.M{"$sum": 1},
+			"failures":           bson.M{"$sum": bson.M{"$cond": []interface{}{"$success", 0, 1}}},
+			"avg_latency_ms":     bson.M{"$avg": "$latency_ms"},
+			"input_tokens":       bson.M{"$sum": "$input_tokens"},
+			"output_tokens":      bson.M{"$sum": "$output_tokens"},
+			"estimated_cost_usd": bson.M{"$sum": "$estimated_cost_usd"},
+		}},
+		{"$sort": bson.M{"calls": -1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID struct {
+			Provider string `bson:"provider"`
+			Model    string `bson:"model"`
+		} `bson:"_id"`
+		AIProviderMetrics `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	metrics := make([]AIProviderMetrics, len(rows))
+	for i, row := range rows {
+		metrics[i] = row.AIProviderMetrics
+		metrics[i].Provider = row.ID.Provider
+		metrics[i].Model = row.ID.Model
+	}
+	return metrics, nil
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+)
+
+// Default branding applied when a studio has not configured its own. The
+// accent color matches the app's primary gradient start.
+const (
+	DefaultAccentColor = "#00D4FF"
+	DefaultSenderName  = "Nachna"
+)
+
+// BrandingForStudio resolves a studio's branding with the Nachna defaults
+// filled in for any field the studio left empty. Unknown studios get the
+// pure defaults, so callers can apply the result unconditionally.
+func BrandingForStudio(cfg *config.Config, studioID string) response.StudioBranding {
+	branding := response.StudioBranding{
+		AccentColor: DefaultAccentColor,
+		SenderName:  DefaultSenderName,
+	}
+	studios, err := GetStudioMap(cfg)
+	if err != nil {
+		return branding
+	}
+	studio, ok := studios[studioID]
+	if !ok || studio.Branding == nil {
+		return branding
+	}
+	if studio.Branding.AccentColor != "" {
+		branding.AccentColor = studio.Branding.AccentColor
+	}
+	if studio.Branding.LogoURL != "" {
+		branding.LogoURL = studio.Branding.LogoURL
+	}
+	if studio.Branding.SenderName != "" {
+		branding.SenderName = studio.Branding.SenderName
+	}
+	return branding
+}
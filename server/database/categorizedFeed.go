@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Aggregation-backed categorized feed: session expansion, date math,
+// past-session filtering, this-week/post-this-week categorization, day
+// labels and sorting all run inside MongoDB, so the main listing endpoints
+// stop pulling the whole collection into memory and re-deriving epochs per
+// request. Go only formats the surviving rows and folds them into the
+// response shape. The quick ?when= filters still go through the in-memory
+// path, which stays the reference implementation.
+
+// categorizedSessionRow is one unwound session as the pipeline returns it:
+// the workshop document (time_details re-wrapped to hold just this
+// session) plus the server-computed epoch, category and day label.
+type categorizedSessionRow struct {
+	response.Workshop `bson:",inline"`
+	TimestampEpoch    int64  `bson:"timestamp_epoch"`
+	Category          string `bson:"category"`
+	Day               string `bson:"day"`
+}
+
+// sessionEpochExpr computes the unwound session's unix epoch with
+// server-side date math, mirroring utils.GetTimestampEpoch: the clock is
+// parsed from a "H:MM AM/PM" start_time, defaulting to midnight IST when
+// missing or unparseable.
+func sessionEpochExpr() bson.M {
+	parsed := bson.M{"$regexFind": bson.M{
+		"input": bson.M{"$ifNull": []interface{}{"$time_details.start_time", ""}},
+		"regex": `^(\d{1,2}):(\d{2}) ([AaPp])[Mm]$`,
+	}}
+	capture := func(i int) bson.M {
+		return bson.M{"$arrayElemAt": []interface{}{"$$m.captures", i}}
+	}
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{"m": parsed},
+		"in": bson.M{"$let": bson.M{
+			"vars": bson.M{
+				"hour12": bson.M{"$cond": []interface{}{
+					bson.M{"$eq": []interface{}{"$$m", nil}}, 0, bson.M{"$toInt": capture(0)},
+				}},
+				"minute": bson.M{"$cond": []interface{}{
+					bson.M{"$eq": []interface{}{"$$m", nil}}, 0, bson.M{"$toInt": capture(1)},
+				}},
+				"pm": bson.M{"$cond": []interface{}{
+					bson.M{"$eq": []interface{}{"$$m", nil}}, false,
+					bson.M{"$in": []interface{}{capture(2), []interface{}{"P", "p"}}},
+				}},
+			},
+			"in": bson.M{"$toLong": bson.M{"$divide": []interface{}{
+				bson.M{"$toLong": bson.M{"$dateFromParts": bson.M{
+					"year":  "$time_details.year",
+					"month": "$time_details.month",
+					"day":   "$time_details.day",
+					"hour": bson.M{"$add": []interface{}{
+						bson.M{"$mod": []interface{}{"$$hour12", 12}},
+						bson.M{"$cond": []interface{}{"$$pm", 12, 0}},
+					}},
+					"minute":   "$$minute",
+					"timezone": "Asia/Kolkata",
+				}}},
+				1000,
+			}}},
+		}},
+	}}
+}
+
+// categorizedSessionPipeline builds the stages: unwind sessions, drop
+// undated ones, compute epochs, cut everything before today, sort, then
+// label each row with its category and IST weekday.
+func categorizedSessionPipeline(filter bson.M, now time.Time) []bson.M {
+	nowIST := now.In(utils.IST)
+	startOfDay := time.Date(nowIST.Year(), nowIST.Month(), nowIST.Day(), 0, 0, 0, 0, utils.IST)
+	// End of the current week: upcoming Sunday midnight, matching categorize.
+	daysUntilWeekEnd := (7 - int(nowIST.Weekday())) % 7
+	endOfWeek := startOfDay.AddDate(0, 0, daysUntilWeekEnd+1)
+
+	match := bson.M{"event_type": bson.M{"$in": []string{"workshop", "intensive"}}}
+	for k, v := range filter {
+		match[k] = v
+	}
+
+	return []bson.M{
+		{"$match": match},
+		{"$unwind": "$time_details"},
+		{"$match": bson.M{
+			"time_details.day":   bson.M{"$gt": 0},
+			"time_details.month": bson.M{"$gt": 0},
+			"time_details.year":  bson.M{"$gt": 0},
+		}},
+		{"$addFields": bson.M{"timestamp_epoch": sessionEpochExpr()}},
+		{"$match": bson.M{"timestamp_epoch": bson.M{"$gte": startOfDay.Unix()}}},
+		{"$sort": bson.M{"timestamp_epoch": 1, "uuid": 1}},
+		{"$addFields": bson.M{
+			"category": bson.M{"$cond": []interface{}{
+				bson.M{"$lt": []interface{}{"$timestamp_epoch", endOfWeek.Unix()}},
+				"this_week", "post_this_week",
+			}},
+			"day": bson.M{"$arrayElemAt": []interface{}{
+				[]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+				bson.M{"$subtract": []interface{}{
+					bson.M{"$dayOfWeek": bson.M{
+						"date":     bson.M{"$toDate": bson.M{"$multiply": []interface{}{"$timestamp_epoch", 1000}}},
+						"timezone": "Asia/Kolkata",
+					}},
+					1,
+				}},
+			}},
+			// Re-wrap the unwound session so the row decodes as a normal
+			// workshop document holding exactly this session.
+			"time_details": []interface{}{"$time_details"},
+		}},
+	}
+}
+
+// GetWorkshopsCategorizedAgg runs the categorized feed through the
+// aggregation pipeline and folds the pre-sorted, pre-labelled rows into
+// the response shape.
+func GetWorkshopsCategorizedAgg(cfg *config.Config, filter bson.M) (response.CategorizedWorkshopResponse, error) {
+	empty := response.CategorizedWorkshopResponse{ThisWeek: []response.DaySchedule{}, PostThisWeek: []response.WorkshopListItem{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "workshops_v2").Aggregate(ctx,
+		categorizedSessionPipeline(filter, time.Now()))
+	if err != nil {
+		return empty, err
+	}
+	var rows []categorizedSessionRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return empty, err
+	}
+	if len(rows) == 0 {
+		return empty, nil
+	}
+
+	studios, err := GetStudioMap(cfg)
+	if err != nil {
+		return empty, err
+	}
+	artists, err := artistLookup(cfg)
+	if err != nil {
+		return empty, err
+	}
+	deadLinks := GetDeadPaymentLinks(cfg)
+	rowWorkshops := make([]response.Workshop, len(rows))
+	for i, row := range rows {
+		rowWorkshops[i] = row.Workshop
+	}
+	tiers := currentTiersByUUID(cfg, rowWorkshops)
+
+	dayBuckets := make(map[string][]response.WorkshopListItem)
+	var dayOrder []string
+	postThisWeek := []response.WorkshopListItem{}
+	for _, row := range rows {
+		item := buildListItem(row.Workshop, row.Workshop.TimeDetails[0], studios, artists, deadLinks)
+		item.TimestampEpoch = row.TimestampEpoch
+		applyCurrentTier(&item, tiers[item.UUID])
+		if row.Category == "this_week" {
+			if _, seen := dayBuckets[row.Day]; !seen {
+				dayOrder = append(dayOrder, row.Day)
+			}
+			dayBuckets[row.Day] = append(dayBuckets[row.Day], item)
+		} else {
+			postThisWeek = append(postThisWeek, item)
+		}
+	}
+
+	thisWeek := make([]response.DaySchedule, 0, len(dayOrder))
+	for _, day := range dayOrder {
+		thisWeek = append(thisWeek, response.DaySchedule{Day: day, Workshops: dayBuckets[day]})
+	}
+	return response.CategorizedWorkshopResponse{ThisWeek: thisWeek, PostThisWeek: postThisWeek}, nil
+}
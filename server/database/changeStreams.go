@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/utils"
+)
+
+// Change stream based cache invalidation: instead of waiting for TTL
+// expiry, watchers on the core collections bust the affected response
+// cache prefixes (and the studios document cache) the moment a document
+// changes — including writes made by the Python server or a mongo shell,
+// which the in-process invalidation calls can never see.
+
+// watchedCollections maps each collection to the response cache prefixes
+// its changes invalidate.
+var watchedCollections = map[string][]string{
+	"workshops_v2": {"/api/workshops", "/api/workshop/"},
+	"studios":      {"/api/studios", "/api/workshops"},
+	"artists_v2":   {"/api/artists", "/api/workshops"},
+}
+
+// StartChangeStreamWatchers launches one watcher goroutine per collection
+// once MongoDB is ready. Change streams require a replica set; on
+// standalone deployments the watchers log once and retry with backoff, and
+// invalidation falls back to TTL expiry plus the invalidation bus.
+func StartChangeStreamWatchers(cfg *config.Config) {
+	go func() {
+		for !IsReady() {
+			time.Sleep(5 * time.Second)
+		}
+		for coll, prefixes := range watchedCollections {
+			go watchCollection(cfg, coll, prefixes)
+		}
+	}()
+}
+
+// watchCollection follows one collection's change stream forever,
+// resuming from the last seen token and reconnecting with backoff.
+func watchCollection(cfg *config.Config, collection string, prefixes []string) {
+	var resumeToken bson.Raw
+	backoff := time.Second
+	for {
+		opts := options.ChangeStream().SetMaxAwaitTime(10 * time.Second)
+		if resumeToken != nil {
+			opts.SetResumeAfter(resumeToken)
+		}
+		stream, err := Collection(cfg, collection).Watch(context.Background(), mongo.Pipeline{}, opts)
+		if err != nil {
+			log.Printf("change stream: watch %s failed, retrying in %v: %v", collection, backoff, err)
+			time.Sleep(backoff)
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			// A stale resume token (e.g. fell off the oplog) makes every
+			// retry fail; drop it and start fresh after the first minute.
+			if backoff >= time.Minute {
+				resumeToken = nil
+			}
+			continue
+		}
+		backoff = time.Second
+		log.Printf("change stream: watching %s", collection)
+
+		for stream.Next(context.Background()) {
+			resumeToken = stream.ResumeToken()
+			applyChangeInvalidation(collection, prefixes)
+		}
+		if err := stream.Err(); err != nil {
+			log.Printf("change stream: %s cursor error: %v", collection, err)
+		}
+		stream.Close(context.Background())
+	}
+}
+
+// applyChangeInvalidation busts the caches affected by a change on the
+// given collection. Only this instance is invalidated: every replica runs
+// its own watchers, so no bus publish is needed.
+func applyChangeInvalidation(collection string, prefixes []string) {
+	for _, prefix := range prefixes {
+		utils.ClearCache(prefix)
+	}
+	if collection == "studios" {
+		InvalidateStudiosCache()
+	}
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+)
+
+// Collection growth monitoring: hourly snapshots of document counts and
+// storage size feed growth-rate alerts (reaction spam, analytics floods)
+// and the trends block of the admin insights endpoint.
+
+// growthSnapshot is one per-collection measurement.
+type growthSnapshot struct {
+	Collection string    `bson:"collection" json:"collection"`
+	Count      int64     `bson:"count" json:"count"`
+	SizeBytes  int64     `bson:"size_bytes" json:"size_bytes"`
+	CapturedAt time.Time `bson:"captured_at" json:"captured_at"`
+}
+
+// growthMonitoredCollections are the collections worth watching for spikes.
+var growthMonitoredCollections = []string{
+	"workshops_v2", "artists_v2", "studios", "users", "orders",
+	"reactions", "device_tokens",
+}
+
+const (
+	growthSnapshotInterval = time.Hour
+	// A collection is flagged when it grows by more than 20% and by more
+	// than growthAlertMinDocs documents between consecutive snapshots.
+	growthAlertRatio   = 0.20
+	growthAlertMinDocs = 1000
+)
+
+// StartCollectionGrowthMonitor launches the hourly snapshot loop.
+func StartCollectionGrowthMonitor(cfg *config.Config) {
+	go func() {
+		for {
+			if IsReady() {
+				captureGrowthSnapshots(cfg)
+			}
+			time.Sleep(growthSnapshotInterval)
+		}
+	}()
+}
+
+// captureGrowthSnapshots measures every monitored collection, persists the
+// snapshots, and logs alerts for growth-rate spikes.
+func captureGrowthSnapshots(cfg *config.Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := GetMongoClient(cfg).Database(DatabaseName)
+
+	for _, coll := range growthMonitoredCollections {
+		var stats struct {
+			Count int64 `bson:"count"`
+			Size  int64 `bson:"size"`
+		}
+		if err := db.RunCommand(ctx, bson.D{{Key: "collStats", Value: coll}}).Decode(&stats); err != nil {
+			log.Printf("growth monitor: collStats %s failed: %v", coll, err)
+			continue
+		}
+
+		snapshot := growthSnapshot{
+			Collection: coll,
+			Count:      stats.Count,
+			SizeBytes:  stats.Size,
+			CapturedAt: time.Now().UTC(),
+		}
+
+		var previous growthSnapshot
+		err := Collection(cfg, "collection_growth").FindOne(ctx,
+			bson.M{"collection": coll},
+			options.FindOne().SetSort(bson.M{"captured_at": -1})).Decode(&previous)
+		if err == nil {
+			delta := snapshot.Count - previous.Count
+			if previous.Count > 0 && delta > growthAlertMinDocs &&
+				float64(delta)/float64(previous.Count) > growthAlertRatio {
+				log.Printf("[GROWTH ALERT] %s grew by %d docs (%.0f%%) since %s",
+					coll, delta, 100*float64(delta)/float64(previous.Count),
+					previous.CapturedAt.Format(time.RFC3339))
+			}
+		}
+
+		if _, err := Collection(cfg, "collection_growth").InsertOne(ctx, snapshot); err != nil {
+			log.Printf("growth monitor: persist snapshot for %s failed: %v", coll, err)
+		}
+	}
+}
+
+// GetCollectionGrowthTrends returns the snapshots captured within the last
+// given duration, newest first, keyed by collection.
+func GetCollectionGrowthTrends(cfg *config.Config, since time.Duration) (map[string][]growthSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "collection_growth").Find(ctx,
+		bson.M{"captured_at": bson.M{"$gte": time.Now().UTC().Add(-since)}},
+		options.Find().SetSort(bson.M{"captured_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []growthSnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+	trends := make(map[string][]growthSnapshot)
+	for _, s := range snapshots {
+		trends[s.Collection] = append(trends[s.Collection], s)
+	}
+	return trends, nil
+}
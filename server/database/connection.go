@@ -0,0 +1,113 @@
+// Package database implements all MongoDB operations for the Golang API
+// server against the shared "discovery" database.
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+)
+
+// DatabaseName is the shared MongoDB database used by all Nachna servers.
+const DatabaseName = "discovery"
+
+var (
+	clientMu sync.RWMutex
+	client   *mongo.Client
+	ready    bool
+)
+
+// Connect establishes the MongoDB connection pool with the same pool
+// settings as the Python DatabaseManager. On failure the server keeps
+// running in degraded mode and the reconnect loop retries.
+func Connect(cfg *config.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Client().
+		ApplyURI(cfg.MongoDBURI).
+		SetMaxPoolSize(100).
+		SetMinPoolSize(10).
+		SetMaxConnIdleTime(30 * time.Second).
+		SetRetryWrites(true).
+		SetMonitor(newCommandMonitor(time.Duration(cfg.SlowQueryThresholdMS) * time.Millisecond))
+
+	c, err := mongo.Connect(ctx, opts)
+	if err == nil {
+		err = c.Ping(ctx, nil)
+	}
+
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if err != nil {
+		ready = false
+		return err
+	}
+	client = c
+	ready = true
+	log.Println("Successfully established MongoDB connection pool")
+	return nil
+}
+
+// IsReady reports whether MongoDB is currently reachable. Data endpoints
+// return 503 while this is false; static/web routes keep serving.
+func IsReady() bool {
+	clientMu.RLock()
+	defer clientMu.RUnlock()
+	return ready
+}
+
+// GetMongoClient returns the shared MongoDB client, which may be nil before
+// the first successful Connect. Callers behind the readiness gate never see
+// nil.
+func GetMongoClient(cfg *config.Config) *mongo.Client {
+	clientMu.RLock()
+	defer clientMu.RUnlock()
+	return client
+}
+
+// StartReconnectLoop monitors the connection: it pings periodically, marks
+// the server degraded when MongoDB becomes unreachable, and reconnects with
+// exponential backoff.
+func StartReconnectLoop(cfg *config.Config) {
+	go func() {
+		backoff := time.Second
+		for {
+			if IsReady() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				err := GetMongoClient(cfg).Ping(ctx, nil)
+				cancel()
+				if err != nil {
+					log.Printf("MongoDB ping failed, entering degraded mode: %v", err)
+					clientMu.Lock()
+					ready = false
+					clientMu.Unlock()
+				} else {
+					time.Sleep(15 * time.Second)
+					continue
+				}
+			}
+
+			if err := Connect(cfg); err != nil {
+				log.Printf("MongoDB reconnect failed, retrying in %v: %v", backoff, err)
+				time.Sleep(backoff)
+				if backoff < time.Minute {
+					backoff *= 2
+				}
+				continue
+			}
+			backoff = time.Second
+		}
+	}()
+}
+
+// Collection is a shorthand for a collection in the discovery database.
+func Collection(cfg *config.Config, name string) *mongo.Collection {
+	return GetMongoClient(cfg).Database(DatabaseName).Collection(name)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// deadLinksCache holds the set of payment links the nightly sweep has
+// flagged as dead. Listings consult it on every expansion to hide the book
+// button for broken links, so it is cached in memory like the studio list.
+var (
+	deadLinksCache   map[string]bool
+	deadLinksCacheAt time.Time
+	deadLinksCacheMu sync.RWMutex
+)
+
+const deadLinksCacheTTL = 5 * time.Minute
+
+// GetDeadPaymentLinks returns the currently flagged payment links, served
+// from the in-memory cache when fresh. Lookups fail open: if the collection
+// cannot be read, no links are treated as dead.
+func GetDeadPaymentLinks(cfg *config.Config) map[string]bool {
+	deadLinksCacheMu.RLock()
+	if deadLinksCache != nil && time.Since(deadLinksCacheAt) < deadLinksCacheTTL {
+		cached := deadLinksCache
+		deadLinksCacheMu.RUnlock()
+		return cached
+	}
+	deadLinksCacheMu.RUnlock()
+
+	links := map[string]bool{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	values, err := Collection(cfg, "dead_links").Distinct(ctx, "payment_link", bson.M{})
+	if err != nil {
+		return links
+	}
+	for _, value := range values {
+		if link, ok := value.(string); ok {
+			links[link] = true
+		}
+	}
+
+	deadLinksCacheMu.Lock()
+	deadLinksCache = links
+	deadLinksCacheAt = time.Now()
+	deadLinksCacheMu.Unlock()
+	return links
+}
+
+// InvalidateDeadLinksCache drops the in-memory dead-link set so the next
+// listing read picks up the latest sweep results.
+func InvalidateDeadLinksCache() {
+	deadLinksCacheMu.Lock()
+	deadLinksCache = nil
+	deadLinksCacheMu.Unlock()
+}
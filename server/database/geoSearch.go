@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+)
+
+// Geo search: studios store their coordinates as a GeoJSON point, and the
+// location_2dsphere index bootstrapped in indexes.go makes $geoNear over
+// them cheap. Workshops inherit their studio's position — a workshop is
+// "nearby" when its studio is — so the search runs against studios first
+// and then expands upcoming workshops at the matched studios.
+
+// nearbyStudioDistances returns distance in km keyed by studio ID for
+// every located, non-deleted studio within radiusKm of the point.
+func nearbyStudioDistances(cfg *config.Config, lat, lng, radiusKm float64) (map[string]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "studios").Aggregate(ctx, []bson.M{
+		{"$geoNear": bson.M{
+			"near":          bson.M{"type": "Point", "coordinates": []float64{lng, lat}},
+			"distanceField": "distance_m",
+			"maxDistance":   radiusKm * 1000,
+			"spherical":     true,
+			"query":         bson.M{"is_deleted": notDeleted()},
+		}},
+		{"$project": bson.M{"studio_id": 1, "distance_m": 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		StudioID  string  `bson:"studio_id"`
+		DistanceM float64 `bson:"distance_m"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	distances := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		distances[row.StudioID] = row.DistanceM / 1000
+	}
+	return distances, nil
+}
+
+// GetNearbyWorkshops returns upcoming workshops at studios within radiusKm
+// of the point, sorted nearest first (first session time breaks ties).
+func GetNearbyWorkshops(cfg *config.Config, lat, lng, radiusKm float64) (response.NearbyWorkshopsResponse, error) {
+	result := response.NearbyWorkshopsResponse{
+		Lat:       lat,
+		Lng:       lng,
+		RadiusKm:  radiusKm,
+		Workshops: []response.NearbyWorkshopItem{},
+	}
+
+	distances, err := nearbyStudioDistances(cfg, lat, lng, radiusKm)
+	if err != nil {
+		return result, err
+	}
+	if len(distances) == 0 {
+		return result, nil
+	}
+
+	studioIDs := make([]string, 0, len(distances))
+	for id := range distances {
+		studioIDs = append(studioIDs, id)
+	}
+	filter := upcomingWorkshopFilter()
+	filter["studio_id"] = bson.M{"$in": studioIDs}
+	items, err := expandWorkshops(cfg, filter)
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now().Unix()
+	for _, item := range items {
+		if item.TimestampEpoch <= now {
+			continue
+		}
+		result.Workshops = append(result.Workshops, response.NearbyWorkshopItem{
+			DistanceKm: distances[item.StudioID],
+			Workshop:   item,
+		})
+	}
+	sort.Slice(result.Workshops, func(i, j int) bool {
+		if result.Workshops[i].DistanceKm != result.Workshops[j].DistanceKm {
+			return result.Workshops[i].DistanceKm < result.Workshops[j].DistanceKm
+		}
+		return result.Workshops[i].Workshop.TimestampEpoch < result.Workshops[j].Workshop.TimestampEpoch
+	})
+	return result, nil
+}
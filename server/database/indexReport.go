@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// QueryPattern is a filter shape the repositories are known to issue,
+// registered here so the index report can flag patterns with no covering
+// index.
+type QueryPattern struct {
+	Collection  string   `json:"collection"`
+	Fields      []string `json:"fields"`
+	Description string   `json:"description"`
+}
+
+// queryPatterns is the registry of access patterns used across the database
+// layer. Keep it in sync when adding new query shapes.
+var queryPatterns = []QueryPattern{
+	{"workshops_v2", []string{"studio_id"}, "workshops by studio"},
+	{"workshops_v2", []string{"artist_id_list"}, "workshops by artist"},
+	{"workshops_v2", []string{"uuid"}, "workshop detail lookup"},
+	{"studios", []string{"studio_id"}, "studio lookup"},
+	{"artists_v2", []string{"artist_id"}, "artist lookup"},
+	{"users", []string{"mobile_number"}, "login lookup"},
+	{"orders", []string{"order_id"}, "order lookup"},
+	{"orders", []string{"user_id", "status"}, "user order history"},
+	{"reactions", []string{"user_id", "entity_id", "entity_type", "reaction"}, "reaction upsert"},
+	{"reactions", []string{"entity_id", "entity_type", "reaction"}, "follower fan-out"},
+	{"device_tokens", []string{"user_id", "device_token"}, "token upsert"},
+	{"device_tokens", []string{"platform", "is_active"}, "active token scan"},
+}
+
+// IndexUsage is one index's $indexStats summary.
+type IndexUsage struct {
+	Collection string `json:"collection"`
+	Name       string `json:"name"`
+	Key        bson.M `json:"key"`
+	Ops        int64  `json:"ops"`
+}
+
+// IndexReport compares live index usage against the registered query
+// patterns.
+type IndexReport struct {
+	Indexes        []IndexUsage   `json:"indexes"`
+	UnusedIndexes  []IndexUsage   `json:"unused_indexes"`
+	MissingIndexes []QueryPattern `json:"missing_indexes"`
+	GeneratedAt    time.Time      `json:"generated_at"`
+}
+
+// GetIndexReport runs $indexStats on every collection with registered
+// patterns and flags unused indexes plus patterns lacking a covering index.
+func GetIndexReport(cfg *config.Config) (*IndexReport, error) {
+	collections := make(map[string]bool)
+	for _, p := range queryPatterns {
+		collections[p.Collection] = true
+	}
+
+	report := &IndexReport{GeneratedAt: time.Now().UTC()}
+	indexKeysByCollection := make(map[string][]bson.M)
+
+	for coll := range collections {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		cursor, err := Collection(cfg, coll).Aggregate(ctx, bson.A{bson.M{"$indexStats": bson.M{}}})
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		var stats []struct {
+			Name     string `bson:"name"`
+			Key      bson.M `bson:"key"`
+			Accesses struct {
+				Ops int64 `bson:"ops"`
+			} `bson:"accesses"`
+		}
+		err = cursor.All(ctx, &stats)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range stats {
+			usage := IndexUsage{Collection: coll, Name: s.Name, Key: s.Key, Ops: s.Accesses.Ops}
+			report.Indexes = append(report.Indexes, usage)
+			indexKeysByCollection[coll] = append(indexKeysByCollection[coll], s.Key)
+			if s.Accesses.Ops == 0 && s.Name != "_id_" {
+				report.UnusedIndexes = append(report.UnusedIndexes, usage)
+			}
+		}
+	}
+
+	for _, pattern := range queryPatterns {
+		if !patternCovered(pattern, indexKeysByCollection[pattern.Collection]) {
+			report.MissingIndexes = append(report.MissingIndexes, pattern)
+		}
+	}
+	return report, nil
+}
+
+// patternCovered reports whether any index key's leading fields cover the
+// pattern's fields (order-insensitive on the pattern side, prefix rule on
+// the index side).
+func patternCovered(pattern QueryPattern, indexKeys []bson.M) bool {
+	want := make(map[string]bool, len(pattern.Fields))
+	for _, f := range pattern.Fields {
+		want[f] = true
+	}
+	for _, key := range indexKeys {
+		covered := 0
+		for field := range key {
+			if want[strings.TrimSuffix(field, ".$**")] {
+				covered++
+			}
+		}
+		if covered == len(pattern.Fields) && len(key) >= len(pattern.Fields) {
+			// All pattern fields appear in the index; good enough for the
+			// advisory report even if not a strict prefix.
+			return true
+		}
+	}
+	return false
+}
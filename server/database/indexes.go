@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+)
+
+// Index bootstrap: every index the query patterns in this package depend
+// on is declared here and ensured at startup. CreateIndexes is idempotent
+// in MongoDB — an index that already exists with the same keys is a no-op
+// — so the bootstrap can run on every boot; the log distinguishes created
+// from already-present indexes. Keep this registry in sync with
+// queryPatterns in indexReport.go when adding new query shapes.
+
+// indexSpec declares one required index.
+type indexSpec struct {
+	Collection string
+	Name       string
+	Keys       bson.D
+	Unique     bool
+	// Sparse indexes skip documents missing the keyed field; used for
+	// optional unique fields like order share codes.
+	Sparse bool
+	// ExpireAfter > 0 makes this a TTL index on the (single) keyed
+	// time field.
+	ExpireAfter time.Duration
+}
+
+// requiredIndexes is the full set of indexes the Go server's queries rely
+// on, including the dedupe keys the upsert-based collections assume.
+var requiredIndexes = []indexSpec{
+	// Core entity lookups.
+	{Collection: "workshops_v2", Name: "studio_id_1", Keys: bson.D{{Key: "studio_id", Value: 1}}},
+	{Collection: "workshops_v2", Name: "artist_id_list_1", Keys: bson.D{{Key: "artist_id_list", Value: 1}}},
+	{Collection: "workshops_v2", Name: "uuid_1", Keys: bson.D{{Key: "uuid", Value: 1}}},
+	{Collection: "studios", Name: "studio_id_1", Keys: bson.D{{Key: "studio_id", Value: 1}}, Unique: true},
+	// Geo search: $geoNear on studio coordinates requires a 2dsphere
+	// index; studios without a location are simply absent from it.
+	{Collection: "studios", Name: "location_2dsphere", Keys: bson.D{{Key: "location", Value: "2dsphere"}}},
+	{Collection: "artists_v2", Name: "artist_id_1", Keys: bson.D{{Key: "artist_id", Value: 1}}, Unique: true},
+	{Collection: "users", Name: "mobile_number_1", Keys: bson.D{{Key: "mobile_number", Value: 1}}},
+
+	// Orders and payments.
+	{Collection: "orders", Name: "order_id_1", Keys: bson.D{{Key: "order_id", Value: 1}}, Unique: true},
+	{Collection: "orders", Name: "user_id_1_status_1", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}}},
+	{Collection: "orders", Name: "workshop_uuid_1_status_1", Keys: bson.D{{Key: "workshop_uuid", Value: 1}, {Key: "status", Value: 1}}},
+	{Collection: "orders", Name: "share_code_1", Keys: bson.D{{Key: "share_code", Value: 1}}, Unique: true, Sparse: true},
+
+	// Reactions.
+	{Collection: "reactions", Name: "user_entity_reaction", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "entity_type", Value: 1}, {Key: "reaction", Value: 1}}},
+	{Collection: "reactions", Name: "entity_reaction_fanout", Keys: bson.D{{Key: "entity_id", Value: 1}, {Key: "entity_type", Value: 1}, {Key: "reaction", Value: 1}}},
+	{Collection: "reactions", Name: "user_id_1_updated_at_1", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "updated_at", Value: 1}}},
+
+	// Push tokens.
+	{Collection: "device_tokens", Name: "user_id_1_device_token_1", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "device_token", Value: 1}}},
+	{Collection: "device_tokens", Name: "platform_1_is_active_1", Keys: bson.D{{Key: "platform", Value: 1}, {Key: "is_active", Value: 1}}},
+
+	// Upsert-dedupe keys: these collections rely on a unique pair to make
+	// "first occurrence wins" safe across replicas.
+	{Collection: "notified_workshops", Name: "user_workshop_unique", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "workshop_uuid", Value: 1}}, Unique: true},
+	{Collection: "reminders", Name: "user_workshop_unique", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "workshop_uuid", Value: 1}}, Unique: true},
+	{Collection: "stream_joins", Name: "order_id_1", Keys: bson.D{{Key: "order_id", Value: 1}}, Unique: true},
+	{Collection: "pass_products", Name: "product_id_1", Keys: bson.D{{Key: "product_id", Value: 1}}, Unique: true},
+	{Collection: "passes", Name: "order_id_1", Keys: bson.D{{Key: "order_id", Value: 1}}, Unique: true},
+	{Collection: "passes", Name: "user_id_1_status_1", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}}},
+	{Collection: "friends", Name: "user_friend_unique", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "friend_user_id", Value: 1}}, Unique: true},
+	{Collection: "friend_invites", Name: "from_to_workshop_unique", Keys: bson.D{{Key: "from_user_id", Value: 1}, {Key: "to_user_id", Value: 1}, {Key: "workshop_uuid", Value: 1}}, Unique: true},
+
+	// Feature collections.
+	{Collection: "streaming_links", Name: "workshop_uuid_1", Keys: bson.D{{Key: "workshop_uuid", Value: 1}}, Unique: true},
+	{Collection: "announcements", Name: "announcement_id_1", Keys: bson.D{{Key: "announcement_id", Value: 1}}, Unique: true},
+	{Collection: "announcement_dismissals", Name: "user_announcement_unique", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "announcement_id", Value: 1}}, Unique: true},
+	{Collection: "workshop_recaps", Name: "recap_id_1", Keys: bson.D{{Key: "recap_id", Value: 1}}, Unique: true},
+	{Collection: "workshop_recaps", Name: "artist_id_list_1", Keys: bson.D{{Key: "artist_id_list", Value: 1}}},
+	{Collection: "workshop_recaps", Name: "studio_id_1", Keys: bson.D{{Key: "studio_id", Value: 1}}},
+	{Collection: "page_snapshots", Name: "workshop_captured", Keys: bson.D{{Key: "workshop_uuid", Value: 1}, {Key: "captured_at", Value: -1}}},
+	{Collection: "ticket_share_clicks", Name: "share_code_1", Keys: bson.D{{Key: "share_code", Value: 1}}},
+	{Collection: "scraper_health", Name: "studio_id_1", Keys: bson.D{{Key: "studio_id", Value: 1}}, Unique: true},
+	{Collection: "audit_logs", Name: "logged_at_-1", Keys: bson.D{{Key: "logged_at", Value: -1}}},
+	{Collection: "audit_logs", Name: "entity_logged", Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "logged_at", Value: -1}}},
+	{Collection: "audit_logs", Name: "user_logged", Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "logged_at", Value: -1}}},
+	{Collection: "scrape_runs", Name: "studio_started", Keys: bson.D{{Key: "studio_id", Value: 1}, {Key: "started_at", Value: -1}}},
+
+	// Time-bounded collections: TTL indexes let MongoDB expire documents
+	// instead of application sweeps. The replay guard keeps its own prune
+	// loop as a belt-and-braces measure; the TTL covers replicas that die
+	// before sweeping.
+	{Collection: "replay_nonces", Name: "seen_at_ttl", Keys: bson.D{{Key: "seen_at", Value: 1}}, ExpireAfter: time.Hour},
+	// Kiosk tokens stop verifying at expires_at; the TTL (with a day of
+	// slack) just reaps the dead documents.
+	{Collection: "scrape_jobs", Name: "job_id_1", Keys: bson.D{{Key: "job_id", Value: 1}}, Unique: true},
+	{Collection: "scrape_diffs", Name: "diff_id_1", Keys: bson.D{{Key: "diff_id", Value: 1}}, Unique: true},
+	{Collection: "analysis_cache", Name: "content_hash_1", Keys: bson.D{{Key: "content_hash", Value: 1}}, Unique: true},
+	{Collection: "analysis_cache", Name: "created_at_ttl", Keys: bson.D{{Key: "created_at", Value: 1}}, ExpireAfter: 7 * 24 * time.Hour},
+	{Collection: "review_tasks", Name: "payment_link_1_field_1", Keys: bson.D{{Key: "payment_link", Value: 1}, {Key: "field", Value: 1}}},
+	{Collection: "push_campaigns", Name: "campaign_id_1", Keys: bson.D{{Key: "campaign_id", Value: 1}}, Unique: true},
+	{Collection: "experiment_exposures", Name: "experiment_1_user_id_1", Keys: bson.D{{Key: "experiment", Value: 1}, {Key: "user_id", Value: 1}}, Unique: true},
+	{Collection: "kiosk_tokens", Name: "token_1", Keys: bson.D{{Key: "token", Value: 1}}, Unique: true},
+	{Collection: "kiosk_tokens", Name: "expires_at_ttl", Keys: bson.D{{Key: "expires_at", Value: 1}}, ExpireAfter: 24 * time.Hour},
+	{Collection: "domain_events", Name: "emitted_at_1", Keys: bson.D{{Key: "emitted_at", Value: 1}}},
+}
+
+// EnsureIndexes waits for MongoDB to become reachable, then ensures every
+// registered index exists, logging created versus already-present. Run it
+// in a goroutine at startup; it never blocks serving.
+func EnsureIndexes(cfg *config.Config) {
+	for !IsReady() {
+		time.Sleep(5 * time.Second)
+	}
+
+	created, existing, failed := 0, 0, 0
+	for _, spec := range requiredIndexes {
+		existed, err := ensureIndex(cfg, spec)
+		switch {
+		case err != nil:
+			// One bad index (e.g. a conflicting legacy definition) must not
+			// stop the rest of the bootstrap.
+			log.Printf("index bootstrap: %s.%s failed: %v", spec.Collection, spec.Name, err)
+			failed++
+		case existed:
+			existing++
+		default:
+			log.Printf("index bootstrap: created %s.%s", spec.Collection, spec.Name)
+			created++
+		}
+	}
+	log.Printf("index bootstrap: %d created, %d already present, %d failed", created, existing, failed)
+}
+
+// ensureIndex creates one index, reporting whether it already existed.
+func ensureIndex(cfg *config.Config, spec indexSpec) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	coll := Collection(cfg, spec.Collection)
+	if existed, err := indexExists(ctx, coll, spec.Name); err != nil || existed {
+		return existed, err
+	}
+
+	idxOpts := options.Index().SetName(spec.Name)
+	if spec.Unique {
+		idxOpts.SetUnique(true)
+	}
+	if spec.Sparse {
+		idxOpts.SetSparse(true)
+	}
+	if spec.ExpireAfter > 0 {
+		idxOpts.SetExpireAfterSeconds(int32(spec.ExpireAfter / time.Second))
+	}
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: spec.Keys, Options: idxOpts})
+	return false, err
+}
+
+// indexExists reports whether the collection already has an index with the
+// given name.
+func indexExists(ctx context.Context, coll *mongo.Collection, name string) (bool, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return false, err
+	}
+	var indexes []struct {
+		Name string `bson:"name"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return false, err
+	}
+	for _, idx := range indexes {
+		if idx.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
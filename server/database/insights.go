@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// AppInsights is the admin overview of platform totals and growth trends.
+type AppInsights struct {
+	Totals       map[string]int64            `json:"totals"`
+	GrowthTrends map[string][]growthSnapshot `json:"growth_trends"`
+	// ReviewSLA summarizes how long data-completion tasks (missing
+	// artists, songs, choreo links) sit in the review queue.
+	ReviewSLA   *ReviewSLAReport `json:"review_sla"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// GetAppInsights computes document totals per core collection and attaches
+// the last 24h of growth snapshots.
+func GetAppInsights(cfg *config.Config) (*AppInsights, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	totals := make(map[string]int64, len(growthMonitoredCollections))
+	for _, coll := range growthMonitoredCollections {
+		count, err := Collection(cfg, coll).CountDocuments(ctx, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		totals[coll] = count
+	}
+
+	trends, err := GetCollectionGrowthTrends(cfg, 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewSLA, err := GetReviewSLAReport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppInsights{
+		Totals:       totals,
+		GrowthTrends: trends,
+		ReviewSLA:    reviewSLA,
+		GeneratedAt:  time.Now().UTC(),
+	}, nil
+}
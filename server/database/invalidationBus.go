@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/utils"
+)
+
+// The invalidation bus keeps the per-instance in-memory caches coherent when
+// multiple replicas run behind the load balancer: every invalidation is
+// published to a capped collection that all instances tail, so a write on
+// one replica busts the caches on the others too.
+
+const (
+	invalidationCollection = "cache_invalidations"
+	invalidationCapBytes   = 1 << 20 // 1 MiB is months of invalidation traffic
+)
+
+// instanceID identifies this process so it can skip its own messages.
+var instanceID = uuid.NewString()
+
+// invalidationMessage is one published invalidation.
+type invalidationMessage struct {
+	Instance  string    `bson:"instance"`
+	Kind      string    `bson:"kind"` // "prefix" or "studios"
+	Prefix    string    `bson:"prefix,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// StartInvalidationBus ensures the capped collection exists and starts the
+// tailing goroutine that applies remote invalidations locally. Setup waits
+// for MongoDB readiness so a degraded startup doesn't panic.
+func StartInvalidationBus(cfg *config.Config) {
+	go func() {
+		for !IsReady() {
+			time.Sleep(5 * time.Second)
+		}
+		setupInvalidationBus(cfg)
+		tailInvalidations(cfg)
+	}()
+}
+
+// setupInvalidationBus creates the capped collection and seeds it so a
+// tailable cursor has a document to start from.
+func setupInvalidationBus(cfg *config.Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := GetMongoClient(cfg).Database(DatabaseName)
+	// CreateCollection fails harmlessly when the collection already exists.
+	err := db.CreateCollection(ctx, invalidationCollection,
+		options.CreateCollection().SetCapped(true).SetSizeInBytes(invalidationCapBytes))
+	if err != nil {
+		log.Printf("invalidation bus: create collection: %v", err)
+	}
+	// Capped tailable cursors need at least one document to start from.
+	_, _ = db.Collection(invalidationCollection).InsertOne(ctx, invalidationMessage{
+		Instance: instanceID, Kind: "prefix", CreatedAt: time.Now().UTC(),
+	})
+}
+
+// publishInvalidation broadcasts one invalidation to the cluster.
+func publishInvalidation(cfg *config.Config, msg invalidationMessage) {
+	msg.Instance = instanceID
+	msg.CreatedAt = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := Collection(cfg, invalidationCollection).InsertOne(ctx, msg); err != nil {
+		log.Printf("invalidation bus: publish failed: %v", err)
+	}
+}
+
+// InvalidateStudiosCacheCluster invalidates the studios cache locally and on
+// every other replica.
+func InvalidateStudiosCacheCluster(cfg *config.Config) {
+	InvalidateStudiosCache()
+	publishInvalidation(cfg, invalidationMessage{Kind: "studios"})
+}
+
+// ClearCacheCluster clears response cache entries under prefix locally and
+// on every other replica.
+func ClearCacheCluster(cfg *config.Config, prefix string) {
+	utils.ClearCache(prefix)
+	publishInvalidation(cfg, invalidationMessage{Kind: "prefix", Prefix: prefix})
+}
+
+// applyInvalidation executes a remote invalidation on this instance.
+func applyInvalidation(msg invalidationMessage) {
+	switch msg.Kind {
+	case "studios":
+		InvalidateStudiosCache()
+	case "prefix":
+		utils.ClearCache(msg.Prefix)
+	}
+}
+
+// tailInvalidations follows the capped collection with a tailable cursor,
+// reconnecting with backoff when the cursor dies.
+func tailInvalidations(cfg *config.Config) {
+	startedAt := time.Now().UTC()
+	backoff := time.Second
+	for {
+		opts := options.Find().
+			SetCursorType(options.TailableAwait).
+			SetMaxAwaitTime(10 * time.Second)
+		cursor, err := Collection(cfg, invalidationCollection).Find(context.Background(),
+			bson.M{"created_at": bson.M{"$gt": startedAt}}, opts)
+		if err != nil {
+			log.Printf("invalidation bus: tail failed, retrying in %v: %v", backoff, err)
+			time.Sleep(backoff)
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+
+		for cursor.Next(context.Background()) {
+			var msg invalidationMessage
+			if err := cursor.Decode(&msg); err != nil {
+				continue
+			}
+			startedAt = msg.CreatedAt
+			if msg.Instance == instanceID {
+				continue
+			}
+			applyInvalidation(msg)
+		}
+		if err := cursor.Err(); err != nil {
+			log.Printf("invalidation bus: cursor error: %v", err)
+		}
+		cursor.Close(context.Background())
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"strings"
+
+	"dance_api_go/config"
+)
+
+// Declarative cache invalidation: every repository write names the entity
+// it touched, and this table says which cached endpoints that entity feeds.
+// Before this, each write site hand-picked prefixes to clear and most
+// picked too few — only the studios cache was busted reliably. The rules
+// live in one place so adding a cached endpoint means extending the table,
+// not auditing every write path.
+
+// entityCachePrefixes maps an entity type to the response-cache prefixes
+// serving it. {studio_id} and {artist_id} placeholders expand from the
+// write's scope; entries with a placeholder are skipped when the scope
+// does not carry the ID. ClearCache matches by string prefix, so the bare
+// "/api/workshops" entry also covers /api/workshops_by_studio/... and
+// /api/workshops_by_artist/...; the scoped entries are kept anyway so the
+// rule reads as the full list of what a write affects.
+var entityCachePrefixes = map[string][]string{
+	"workshop": {
+		"/api/workshops",
+		"/api/structured/workshops",
+		"/api/workshops_by_studio/{studio_id}",
+		"/api/workshops_by_artist/{artist_id}",
+		"/api/search",
+	},
+	// Studio names, currency, and branding are denormalized into the
+	// workshop feeds, so studio writes bust those too.
+	"studio": {
+		"/api/studios",
+		"/api/workshops",
+		"/api/search",
+	},
+	// Artist images and Instagram links ride along on every list item.
+	"artist": {
+		"/api/artists",
+		"/api/workshops",
+		"/api/search",
+	},
+}
+
+// EntityWrite describes one repository write for invalidation purposes:
+// what kind of entity changed and, where known, which studio and artists
+// it is scoped to.
+type EntityWrite struct {
+	Entity    string
+	StudioID  string
+	ArtistIDs []string
+}
+
+// InvalidateForWrite expands the entity's rule into concrete prefixes and
+// busts each cluster-wide. Studio writes additionally drop the in-memory
+// studios document cache, which sits below the response cache.
+func InvalidateForWrite(cfg *config.Config, w EntityWrite) {
+	for _, pattern := range entityCachePrefixes[w.Entity] {
+		switch {
+		case strings.Contains(pattern, "{studio_id}"):
+			if w.StudioID != "" {
+				ClearCacheCluster(cfg, strings.ReplaceAll(pattern, "{studio_id}", w.StudioID))
+			}
+		case strings.Contains(pattern, "{artist_id}"):
+			for _, artistID := range w.ArtistIDs {
+				ClearCacheCluster(cfg, strings.ReplaceAll(pattern, "{artist_id}", artistID))
+			}
+		default:
+			ClearCacheCluster(cfg, pattern)
+		}
+	}
+	if w.Entity == "studio" {
+		InvalidateStudiosCacheCluster(cfg)
+	}
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// The command monitor hangs off the Mongo client and observes every query
+// the server issues, so slow-query visibility no longer depends on handlers
+// remembering to wrap calls.
+
+// commandInfo is what we remember between CommandStarted and its
+// corresponding Succeeded/Failed event.
+type commandInfo struct {
+	Name       string
+	Collection string
+	Filter     string
+}
+
+var (
+	inflightCommands   = make(map[int64]commandInfo)
+	inflightCommandsMu sync.Mutex
+
+	slowQueryCounts   = make(map[string]int64)
+	slowQueryCountsMu sync.Mutex
+)
+
+// monitoredCommands are the commands worth timing; heartbeat chatter like
+// ping/hello is ignored.
+var monitoredCommands = map[string]bool{
+	"find": true, "aggregate": true, "insert": true, "update": true,
+	"delete": true, "count": true, "distinct": true, "findAndModify": true,
+}
+
+// newCommandMonitor builds the monitor installed on the client, logging
+// queries slower than threshold with their redacted filters and counting
+// slow queries per collection.
+func newCommandMonitor(threshold time.Duration) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			if !monitoredCommands[e.CommandName] {
+				return
+			}
+			info := commandInfo{Name: e.CommandName}
+			if coll, err := e.Command.LookupErr(e.CommandName); err == nil {
+				info.Collection, _ = coll.StringValueOK()
+			}
+			if filter, err := e.Command.LookupErr("filter"); err == nil {
+				if doc, ok := filter.DocumentOK(); ok {
+					info.Filter = redactDocument(doc)
+				}
+			}
+			inflightCommandsMu.Lock()
+			inflightCommands[e.RequestID] = info
+			inflightCommandsMu.Unlock()
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finishCommand(e.RequestID, e.Duration, threshold, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finishCommand(e.RequestID, e.Duration, threshold, e.Failure)
+		},
+	}
+}
+
+// finishCommand pairs a completion event with its start info and records the
+// duration.
+func finishCommand(requestID int64, duration time.Duration, threshold time.Duration, failure string) {
+	inflightCommandsMu.Lock()
+	info, ok := inflightCommands[requestID]
+	delete(inflightCommands, requestID)
+	inflightCommandsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	if failure != "" {
+		log.Printf("[QUERY FAILED] %s %s: %s", info.Name, info.Collection, failure)
+		return
+	}
+	if duration > threshold {
+		slowQueryCountsMu.Lock()
+		slowQueryCounts[info.Collection]++
+		slowQueryCountsMu.Unlock()
+		log.Printf("[SLOW QUERY] %s %s took %v filter=%s", info.Name, info.Collection, duration, info.Filter)
+	}
+}
+
+// redactDocument renders a filter with its values replaced so logs never
+// contain user data, keeping only the shape (keys and operators).
+func redactDocument(doc bson.Raw) string {
+	elements, err := doc.Elements()
+	if err != nil {
+		return "{?}"
+	}
+	out := "{"
+	for i, el := range elements {
+		if i > 0 {
+			out += ", "
+		}
+		value := el.Value()
+		if nested, ok := value.DocumentOK(); ok {
+			out += fmt.Sprintf("%s: %s", el.Key(), redactDocument(nested))
+		} else {
+			out += fmt.Sprintf("%s: ?", el.Key())
+		}
+	}
+	return out + "}"
+}
+
+// GetSlowQueryCounts returns a snapshot of slow-query counts per collection.
+func GetSlowQueryCounts() map[string]int64 {
+	slowQueryCountsMu.Lock()
+	defer slowQueryCountsMu.Unlock()
+	snapshot := make(map[string]int64, len(slowQueryCounts))
+	for coll, count := range slowQueryCounts {
+		snapshot[coll] = count
+	}
+	return snapshot
+}
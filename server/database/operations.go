@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// studiosCache holds the studio list in memory since it changes rarely and is
+// read on every workshop listing to resolve studio names.
+var (
+	studiosCache   []response.Studio
+	studiosCacheAt time.Time
+	studiosCacheMu sync.RWMutex
+)
+
+const studiosCacheTTL = time.Hour
+
+// GetStudios returns all studios, served from the in-memory cache when fresh.
+func GetStudios(cfg *config.Config) ([]response.Studio, error) {
+	studiosCacheMu.RLock()
+	if studiosCache != nil && time.Since(studiosCacheAt) < studiosCacheTTL {
+		cached := studiosCache
+		studiosCacheMu.RUnlock()
+		return cached, nil
+	}
+	studiosCacheMu.RUnlock()
+
+	var studios []response.Studio
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "studios").Find(ctx, bson.M{"is_deleted": notDeleted()})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &studios); err != nil {
+		return nil, err
+	}
+
+	studiosCacheMu.Lock()
+	studiosCache = studios
+	studiosCacheAt = time.Now()
+	studiosCacheMu.Unlock()
+	return studios, nil
+}
+
+// InvalidateStudiosCache drops the in-memory studio cache so the next read
+// hits MongoDB. Called after studio CRUD operations.
+func InvalidateStudiosCache() {
+	studiosCacheMu.Lock()
+	studiosCache = nil
+	studiosCacheMu.Unlock()
+}
+
+// GetStudioByID returns one studio's full profile, including location and
+// amenities. Returns (nil, nil) for unknown or deleted studios.
+func GetStudioByID(cfg *config.Config, studioID string) (*response.Studio, error) {
+	var studio response.Studio
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := Collection(cfg, "studios").FindOne(ctx, bson.M{"studio_id": studioID, "is_deleted": notDeleted()}).Decode(&studio)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &studio, nil
+}
+
+// GetStudioMap returns studios keyed by studio ID for name lookups.
+func GetStudioMap(cfg *config.Config) (map[string]response.Studio, error) {
+	studios, err := GetStudios(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]response.Studio, len(studios))
+	for _, s := range studios {
+		m[s.ID] = s
+	}
+	return m, nil
+}
+
+// GetAllArtistsFromDB returns all artists. When hasWorkshops is true, artists
+// without any upcoming workshop are filtered out.
+func GetAllArtistsFromDB(cfg *config.Config, hasWorkshops bool) ([]response.Artist, error) {
+	var artists []response.Artist
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "artists_v2").Find(ctx, bson.M{"is_deleted": notDeleted()})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &artists); err != nil {
+		return nil, err
+	}
+	if !hasWorkshops {
+		return artists, nil
+	}
+
+	// One workshop scan answers "which artists have an upcoming session"
+	// for every artist at once; the per-artist query this replaces issued
+	// hundreds of round trips.
+	workshops, err := fetchWorkshops(cfg, upcomingWorkshopFilter())
+	if err != nil {
+		return nil, err
+	}
+	active := collectUpcomingArtistIDs(workshops, time.Now().Unix())
+	filtered := make([]response.Artist, 0, len(artists))
+	for _, artist := range artists {
+		if active[artist.ID] {
+			filtered = append(filtered, artist)
+		}
+	}
+	return filtered, nil
+}
+
+// collectUpcomingArtistIDs returns the set of artist IDs listed on at least
+// one workshop with a dated session at or after now.
+func collectUpcomingArtistIDs(workshops []response.Workshop, now int64) map[string]bool {
+	active := map[string]bool{}
+	for _, w := range workshops {
+		if len(w.ArtistIDList) == 0 {
+			continue
+		}
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			if utils.GetTimestampEpoch(td) >= now {
+				for _, id := range w.ArtistIDList {
+					active[id] = true
+				}
+				break
+			}
+		}
+	}
+	return active
+}
+
+// GetArtistByID returns a single artist profile or mongo.ErrNoDocuments.
+func GetArtistByID(cfg *config.Config, artistID string) (*response.Artist, error) {
+	var artist response.Artist
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := Collection(cfg, "artists_v2").FindOne(ctx, bson.M{"artist_id": artistID, "is_deleted": notDeleted()}).Decode(&artist); err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// GetArtistProfile assembles the enriched artist detail: the profile
+// document plus the approved choreo video portfolio from choreo_links.
+// Returns (nil, nil) for unknown or deleted artists.
+func GetArtistProfile(cfg *config.Config, artistID string) (*response.ArtistProfile, error) {
+	artist, err := GetArtistByID(cfg, artistID)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "choreo_links").Find(ctx,
+		bson.M{"artist_id": artistID}, options.Find().SetSort(bson.D{{Key: "song", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	videos := []response.ChoreoVideo{}
+	if err := cursor.All(ctx, &videos); err != nil {
+		return nil, err
+	}
+	return &response.ArtistProfile{Artist: *artist, ChoreoVideos: videos}, nil
+}
+
+// CreateStudio inserts a studio document.
+func CreateStudio(cfg *config.Config, studio bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "studios").InsertOne(ctx, studio)
+	studioID, _ := studio["studio_id"].(string)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "studio", StudioID: studioID})
+	return err
+}
+
+// UpdateStudio updates a studio document by studio_id.
+func UpdateStudio(cfg *config.Config, studioID string, update bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "studios").UpdateOne(ctx, bson.M{"studio_id": studioID}, bson.M{"$set": update})
+	InvalidateForWrite(cfg, EntityWrite{Entity: "studio", StudioID: studioID})
+	return err
+}
+
+// DeleteStudio soft-deletes a studio document by studio_id; RestoreStudio
+// undoes it and the purge sweep removes it for good after the retention
+// window.
+func DeleteStudio(cfg *config.Config, studioID string) error {
+	err := setDeletedFlag(cfg, "studios", studioID, true)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "studio", StudioID: studioID})
+	return err
+}
+
+// CreateArtist inserts an artist document.
+func CreateArtist(cfg *config.Config, artist bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "artists_v2").InsertOne(ctx, artist)
+	artistID, _ := artist["artist_id"].(string)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "artist", ArtistIDs: []string{artistID}})
+	return err
+}
+
+// UpdateArtist updates an artist document by artist_id.
+func UpdateArtist(cfg *config.Config, artistID string, update bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "artists_v2").UpdateOne(ctx, bson.M{"artist_id": artistID}, bson.M{"$set": update})
+	InvalidateForWrite(cfg, EntityWrite{Entity: "artist", ArtistIDs: []string{artistID}})
+	return err
+}
+
+// DeleteArtist soft-deletes an artist document by artist_id; RestoreArtist
+// undoes it.
+func DeleteArtist(cfg *config.Config, artistID string) error {
+	err := setDeletedFlag(cfg, "artists_v2", artistID, true)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "artist", ArtistIDs: []string{artistID}})
+	return err
+}
+
+// workshopIDFilter matches a workshop by either external ID form. The
+// composite uuid is the standard going forward; 24-char ObjectID hex is
+// still accepted during the deprecation window because older admin tools
+// address workshops by Mongo _id. The two forms cannot collide — uuids
+// embed an underscore and dashes, which hex never contains.
+func workshopIDFilter(workshopID string) bson.M {
+	if oid, err := primitive.ObjectIDFromHex(workshopID); err == nil {
+		return bson.M{"_id": oid}
+	}
+	return bson.M{"uuid": workshopID}
+}
+
+// AssignArtistToWorkshop sets the artist list (and display name) on a
+// workshop addressed by its uuid (or legacy Mongo ObjectID hex), locking
+// both fields against scraper overwrites on later refreshes.
+func AssignArtistToWorkshop(cfg *config.Config, workshopID string, artistIDList []string, artistNames []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "workshops_v2").UpdateOne(ctx, workshopIDFilter(workshopID), bson.M{
+		"$set": bson.M{
+			"artist_id_list": artistIDList,
+			"by":             utils.JoinArtistNames(artistNames),
+		},
+		"$addToSet": lockFields("artist_id_list", "by"),
+	})
+	if err == nil && len(artistIDList) > 0 {
+		ResolveReviewTasksForWorkshop(cfg, workshopID, ReviewFieldArtists)
+	}
+	InvalidateForWrite(cfg, EntityWrite{Entity: "workshop", ArtistIDs: artistIDList})
+	return err
+}
+
+// AssignSongToWorkshop sets the song on a workshop addressed by its uuid
+// (or legacy Mongo ObjectID hex), locking it against scraper overwrites on
+// later refreshes.
+func AssignSongToWorkshop(cfg *config.Config, workshopID string, song string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "workshops_v2").UpdateOne(ctx, workshopIDFilter(workshopID), bson.M{
+		"$set":      bson.M{"song": song},
+		"$addToSet": lockFields("song"),
+	})
+	if err == nil && song != "" {
+		ResolveReviewTasksForWorkshop(cfg, workshopID, ReviewFieldSong)
+	}
+	InvalidateForWrite(cfg, EntityWrite{Entity: "workshop"})
+	return err
+}
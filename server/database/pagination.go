@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/queryopts"
+)
+
+// Limit/offset pagination for the public list endpoints. Pagination is
+// opt-in: handlers only switch to the paged response shape when the caller
+// sends ?limit=, so existing clients keep getting the full list. Bounds
+// and clamping live in the shared queryopts package.
+
+// PageInfo describes one page of a paginated response.
+type PageInfo struct {
+	Limit   int64 `json:"limit"`
+	Offset  int64 `json:"offset"`
+	Total   int64 `json:"total"`
+	HasMore bool  `json:"has_more"`
+	// NextOffset is the offset for the following page; omitted on the last.
+	NextOffset *int64 `json:"next_offset,omitempty"`
+}
+
+// ArtistsPage is one page of artists.
+type ArtistsPage struct {
+	Artists []response.Artist `json:"artists"`
+	Page    PageInfo          `json:"page"`
+}
+
+// StudiosPage is one page of studios.
+type StudiosPage struct {
+	Studios []response.Studio `json:"studios"`
+	Page    PageInfo          `json:"page"`
+}
+
+// WorkshopsPage is one page of workshop list items in session-time order.
+// Paged workshop responses are flat rather than categorized: week buckets
+// do not survive slicing, and paging clients render their own grouping.
+type WorkshopsPage struct {
+	Workshops []response.WorkshopListItem `json:"workshops"`
+	Page      PageInfo                    `json:"page"`
+}
+
+// clampPage normalizes limit and offset through the shared bounds.
+func clampPage(limit, offset int64) (int64, int64) {
+	opts := queryopts.Options{Limit: limit, Offset: offset}.
+		Clamp(queryopts.DefaultLimit, queryopts.MaxLimit)
+	return opts.Limit, opts.Offset
+}
+
+// pageInfo builds the page descriptor for a slice of the given total.
+func pageInfo(limit, offset, total int64) PageInfo {
+	info := PageInfo{Limit: limit, Offset: offset, Total: total}
+	if offset+limit < total {
+		info.HasMore = true
+		next := offset + limit
+		info.NextOffset = &next
+	}
+	return info
+}
+
+// GetArtistsPage returns one page of artists. Without the hasWorkshops
+// filter the skip/limit runs in MongoDB; with it the filtered list is
+// sliced in memory, since workshop presence cannot be expressed in the
+// artists query.
+func GetArtistsPage(cfg *config.Config, hasWorkshops bool, limit, offset int64) (*ArtistsPage, error) {
+	limit, offset = clampPage(limit, offset)
+
+	if hasWorkshops {
+		artists, err := GetAllArtistsFromDB(cfg, true)
+		if err != nil {
+			return nil, err
+		}
+		return &ArtistsPage{
+			Artists: sliceArtists(artists, limit, offset),
+			Page:    pageInfo(limit, offset, int64(len(artists))),
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	coll := Collection(cfg, "artists_v2")
+	total, err := coll.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := coll.Find(ctx, bson.M{},
+		queryopts.Options{Limit: limit, Offset: offset, Sort: "artist_id"}.FindOptions())
+	if err != nil {
+		return nil, err
+	}
+	artists := []response.Artist{}
+	if err := cursor.All(ctx, &artists); err != nil {
+		return nil, err
+	}
+	return &ArtistsPage{Artists: artists, Page: pageInfo(limit, offset, total)}, nil
+}
+
+// GetStudiosPage returns one page of studios, sliced from the in-memory
+// studio cache (the full list is already resident for name lookups).
+func GetStudiosPage(cfg *config.Config, limit, offset int64) (*StudiosPage, error) {
+	limit, offset = clampPage(limit, offset)
+	studios, err := GetStudios(cfg)
+	if err != nil {
+		return nil, err
+	}
+	page := &StudiosPage{
+		Studios: []response.Studio{},
+		Page:    pageInfo(limit, offset, int64(len(studios))),
+	}
+	if start, end, ok := (queryopts.Options{Limit: limit, Offset: offset}).SliceBounds(int64(len(studios))); ok {
+		page.Studios = studios[start:end]
+	}
+	return page, nil
+}
+
+// GetWorkshopsPage returns one page of upcoming workshop sessions as a flat
+// list in first-session order, after the when/price filters and sort key
+// have been applied to the full result.
+func GetWorkshopsPage(cfg *config.Config, when, sortKey string, minPrice, maxPrice, limit, offset int64) (*WorkshopsPage, error) {
+	limit, offset = clampPage(limit, offset)
+	items, err := expandWorkshops(cfg, upcomingWorkshopFilter())
+	if err != nil {
+		return nil, err
+	}
+	items = filterItemsByWhen(items, when)
+	items = filterFlatByPriceRange(items, minPrice, maxPrice)
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].TimestampEpoch < items[b].TimestampEpoch
+	})
+	if less := itemLessFunc(cfg, sortKey); less != nil {
+		sort.SliceStable(items, func(a, b int) bool { return less(items[a], items[b]) })
+	}
+
+	page := &WorkshopsPage{
+		Workshops: []response.WorkshopListItem{},
+		Page:      pageInfo(limit, offset, int64(len(items))),
+	}
+	if start, end, ok := (queryopts.Options{Limit: limit, Offset: offset}).SliceBounds(int64(len(items))); ok {
+		page.Workshops = items[start:end]
+	}
+	return page, nil
+}
+
+// filterFlatByPriceRange applies the same price bounds as the categorized
+// filter to a flat item list.
+func filterFlatByPriceRange(items []response.WorkshopListItem, minPrice, maxPrice int64) []response.WorkshopListItem {
+	if minPrice <= 0 && maxPrice <= 0 {
+		return items
+	}
+	resp := response.CategorizedWorkshopResponse{PostThisWeek: items}
+	FilterCategorizedByPriceRange(&resp, minPrice, maxPrice)
+	return resp.PostThisWeek
+}
+
+// sliceArtists pages an in-memory artist list.
+func sliceArtists(artists []response.Artist, limit, offset int64) []response.Artist {
+	start, end, ok := (queryopts.Options{Limit: limit, Offset: offset}).SliceBounds(int64(len(artists)))
+	if !ok {
+		return []response.Artist{}
+	}
+	return artists[start:end]
+}
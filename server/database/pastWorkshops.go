@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/utils"
+)
+
+// Historical artist archive: past sessions come from archived workshop
+// documents plus upcoming documents whose sessions have already happened,
+// so artist profiles can show a track record rather than only what is
+// bookable.
+
+// PastWorkshop is one historical session on an artist's profile.
+type PastWorkshop struct {
+	UUID            string  `json:"uuid"`
+	Date            string  `json:"date"`
+	Time            string  `json:"time"`
+	Song            *string `json:"song"`
+	StudioID        string  `json:"studio_id"`
+	Artist          *string `json:"artist"`
+	TimestampEpoch  int64   `json:"timestamp_epoch"`
+	ChoreoInstaLink *string `json:"choreo_insta_link"`
+	// AttendeeCount is the number of paid orders, included only when the
+	// workshop was sold through Nachna; 0 otherwise.
+	AttendeeCount int64 `json:"attendee_count"`
+}
+
+// PastWorkshopsPage is one page of an artist's historical sessions.
+type PastWorkshopsPage struct {
+	Workshops []PastWorkshop `json:"workshops"`
+	Page      int            `json:"page"`
+	PageSize  int            `json:"page_size"`
+	Total     int            `json:"total"`
+}
+
+// GetPastWorkshopsByArtist returns the artist's past sessions newest first,
+// paginated. page is 1-based; pageSize is clamped to 50.
+func GetPastWorkshopsByArtist(cfg *config.Config, artistID string, page, pageSize int) (*PastWorkshopsPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	workshops, err := fetchWorkshops(cfg, bson.M{"artist_id_list": artistID, "is_deleted": notDeleted()})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	past := []PastWorkshop{}
+	for _, w := range workshops {
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			epoch := utils.GetTimestampEpoch(td)
+			if epoch >= now {
+				continue
+			}
+			past = append(past, PastWorkshop{
+				UUID:            w.UUID,
+				Date:            utils.GetFormattedDate(td),
+				Time:            utils.GetFormattedTime(td),
+				Song:            w.Song,
+				StudioID:        w.StudioID,
+				Artist:          w.By,
+				TimestampEpoch:  epoch,
+				ChoreoInstaLink: w.ChoreoInstaLink,
+				AttendeeCount:   paidOrderCount(cfg, w.UUID),
+			})
+		}
+	}
+	sort.Slice(past, func(i, j int) bool { return past[i].TimestampEpoch > past[j].TimestampEpoch })
+
+	total := len(past)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return &PastWorkshopsPage{
+		Workshops: past[start:end],
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     total,
+	}, nil
+}
+
+// paidOrderCount counts paid orders for a workshop; attendance is only
+// public for workshops sold through Nachna, and external bookings simply
+// count zero.
+func paidOrderCount(cfg *config.Config, workshopUUID string) int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	count, err := Collection(cfg, "orders").CountDocuments(ctx,
+		bson.M{"workshop_uuid": workshopUUID, "status": "paid"})
+	if err != nil {
+		return 0
+	}
+	return count
+}
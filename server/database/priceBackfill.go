@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/utils"
+)
+
+// BackfillWorkshopPrices populates min_price/max_price on workshop
+// documents that have pricing text but no parsed numbers yet, returning the
+// count updated. Documents whose pricing text yields nothing are stamped
+// with zeroes so the job does not rescan them forever.
+func BackfillWorkshopPrices(cfg *config.Config) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := Collection(cfg, "workshops_v2").Find(ctx, bson.M{
+		"pricing_info": bson.M{"$type": "string", "$ne": ""},
+		"min_price":    bson.M{"$exists": false},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var workshops []struct {
+		MongoID     interface{} `bson:"_id"`
+		PricingInfo string      `bson:"pricing_info"`
+	}
+	if err := cursor.All(ctx, &workshops); err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, w := range workshops {
+		_, err := Collection(cfg, "workshops_v2").UpdateOne(ctx,
+			bson.M{"_id": w.MongoID},
+			bson.M{"$set": bson.M{
+				"min_price": utils.ParseMinPrice(w.PricingInfo),
+				"max_price": utils.ParseMaxPrice(w.PricingInfo),
+			}})
+		if err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+)
+
+// Dynamic pricing: workshops can carry an ordered list of price tiers
+// (early bird, then standard). A tier stays active until its deadline
+// passes or its seat cap fills; the first still-open tier is the one
+// charged. Resolution happens server-side — feed responses surface the
+// active tier for display, and order creation re-resolves it so a stale
+// client price can never undercut a closed early bird.
+
+// ValidatePriceTiers rejects tier configurations that could leave a
+// workshop without a chargeable price.
+func ValidatePriceTiers(tiers []response.PriceTier) error {
+	for i, tier := range tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("tier %d: name is required", i)
+		}
+		if tier.Price <= 0 {
+			return fmt.Errorf("tier %q: price must be positive", tier.Name)
+		}
+		if tier.UntilEpoch < 0 || tier.SeatLimit < 0 {
+			return fmt.Errorf("tier %q: deadline and seat limit cannot be negative", tier.Name)
+		}
+		// Every tier but the last must close somehow, or the tiers after
+		// it are unreachable.
+		if i < len(tiers)-1 && tier.UntilEpoch == 0 && tier.SeatLimit == 0 {
+			return fmt.Errorf("tier %q: non-final tiers need a deadline or seat limit", tier.Name)
+		}
+	}
+	if n := len(tiers); n > 0 {
+		last := tiers[n-1]
+		if last.UntilEpoch != 0 || last.SeatLimit != 0 {
+			return fmt.Errorf("tier %q: the final tier must stay open as the fallback", last.Name)
+		}
+	}
+	return nil
+}
+
+// activePriceTier resolves which tier applies right now given the paid
+// seat count. Returns nil when the workshop has no tiers configured.
+func activePriceTier(w response.Workshop, now int64, paidSeats int64) *response.PriceTier {
+	for i := range w.PriceTiers {
+		tier := w.PriceTiers[i]
+		if tier.UntilEpoch != 0 && now >= tier.UntilEpoch {
+			continue
+		}
+		if tier.SeatLimit != 0 && paidSeats >= tier.SeatLimit {
+			continue
+		}
+		return &tier
+	}
+	return nil
+}
+
+// paidSeatCount counts paid orders for a workshop — the seat number tier
+// caps are measured against. The status literal matches
+// services.OrderStatusPaid; the services package sits above this one, so
+// the constant cannot be imported here.
+func paidSeatCount(cfg *config.Config, workshopUUID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return Collection(cfg, "orders").CountDocuments(ctx,
+		bson.M{"workshop_uuid": workshopUUID, "status": "paid"})
+}
+
+// paidSeatCounts counts paid orders per workshop UUID in one aggregation,
+// queried only for the given UUIDs.
+func paidSeatCounts(cfg *config.Config, uuids []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(uuids))
+	if len(uuids) == 0 {
+		return counts, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "orders").Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"workshop_uuid": bson.M{"$in": uuids}, "status": "paid"}},
+		{"$group": bson.M{"_id": "$workshop_uuid", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		UUID  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.UUID] = row.Count
+	}
+	return counts, nil
+}
+
+// currentTiersByUUID resolves the active tier for every tiered workshop in
+// the slice, fetching seat counts in a single query. Best-effort: a count
+// failure logs and leaves the feed unannotated rather than failing it.
+func currentTiersByUUID(cfg *config.Config, workshops []response.Workshop) map[string]*response.PriceTier {
+	var tieredUUIDs []string
+	for _, w := range workshops {
+		if len(w.PriceTiers) > 0 {
+			tieredUUIDs = append(tieredUUIDs, w.UUID)
+		}
+	}
+	if len(tieredUUIDs) == 0 {
+		return nil
+	}
+	seats, err := paidSeatCounts(cfg, tieredUUIDs)
+	if err != nil {
+		log.Printf("price tiers: seat count failed: %v", err)
+		return nil
+	}
+	now := time.Now().Unix()
+	tiers := make(map[string]*response.PriceTier, len(tieredUUIDs))
+	for _, w := range workshops {
+		if len(w.PriceTiers) == 0 {
+			continue
+		}
+		if tier := activePriceTier(w, now, seats[w.UUID]); tier != nil {
+			tiers[w.UUID] = tier
+		}
+	}
+	return tiers
+}
+
+// applyCurrentTier stamps the active tier onto a list item.
+func applyCurrentTier(item *response.WorkshopListItem, tier *response.PriceTier) {
+	if tier == nil {
+		return
+	}
+	item.CurrentTier = &tier.Name
+	item.CurrentPrice = &tier.Price
+}
+
+// GetTieredUpcomingWorkshops returns upcoming workshops that have price
+// tiers configured — the set the early-bird-closing alert pass scans.
+func GetTieredUpcomingWorkshops(cfg *config.Config) ([]response.Workshop, error) {
+	filter := upcomingWorkshopFilter()
+	filter["price_tiers.0"] = bson.M{"$exists": true}
+	return fetchWorkshops(cfg, filter)
+}
+
+// CurrentWorkshopTier resolves the tier an order for the workshop should
+// be charged at right now. Returns (nil, nil) when the workshop does not
+// exist or has no tiers configured — callers fall back to the
+// client-supplied amount as before.
+func CurrentWorkshopTier(cfg *config.Config, workshopUUID string) (*response.PriceTier, error) {
+	workshop, err := GetWorkshopByUUID(cfg, workshopUUID)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(workshop.PriceTiers) == 0 {
+		return nil, nil
+	}
+	seats, err := paidSeatCount(cfg, workshopUUID)
+	if err != nil {
+		return nil, err
+	}
+	return activePriceTier(*workshop, time.Now().Unix(), seats), nil
+}
+
+// SetWorkshopPriceTiers replaces a workshop's tier configuration; an empty
+// slice removes automatic pricing entirely.
+func SetWorkshopPriceTiers(cfg *config.Config, workshopUUID string, tiers []response.PriceTier) error {
+	if err := ValidatePriceTiers(tiers); err != nil {
+		return err
+	}
+	update := bson.M{"$set": bson.M{"price_tiers": tiers}}
+	if len(tiers) == 0 {
+		update = bson.M{"$unset": bson.M{"price_tiers": ""}}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := Collection(cfg, "workshops_v2").UpdateOne(ctx,
+		bson.M{"uuid": workshopUUID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("workshop %s not found", workshopUUID)
+	}
+	InvalidateForWrite(cfg, EntityWrite{Entity: "workshop"})
+	return nil
+}
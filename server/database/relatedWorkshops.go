@@ -0,0 +1,110 @@
+package database
+
+import (
+	"sort"
+	"time"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Related workshop suggestions for detail responses: same artist on other
+// dates, same studio the same weekend, and the same song by other artists.
+// The result rides the per-path response cache, so the fan-out of lookups
+// only runs on cache misses.
+
+// relatedLimit caps each suggestion bucket.
+const relatedLimit = 5
+
+// RelatedWorkshops groups the suggestion buckets on a detail response.
+type RelatedWorkshops struct {
+	SameArtist []response.WorkshopListItem `json:"same_artist"`
+	SameStudio []response.WorkshopListItem `json:"same_studio"`
+	SameSong   []response.WorkshopListItem `json:"same_song"`
+}
+
+// WorkshopDetail is the single-workshop response with related suggestions.
+type WorkshopDetail struct {
+	Workshop response.Workshop `json:"workshop"`
+	Related  RelatedWorkshops  `json:"related"`
+}
+
+// GetWorkshopDetail returns one workshop by uuid with its related
+// suggestions computed from the upcoming listing.
+func GetWorkshopDetail(cfg *config.Config, uuid string) (*WorkshopDetail, error) {
+	workshop, err := GetWorkshopByUUID(cfg, uuid)
+	if err != nil {
+		return nil, err
+	}
+	items, err := expandWorkshops(cfg, upcomingWorkshopFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &WorkshopDetail{
+		Workshop: *workshop,
+		Related:  relatedFor(*workshop, items),
+	}, nil
+}
+
+// relatedFor picks the suggestion buckets for a workshop out of the
+// expanded upcoming list items.
+func relatedFor(w response.Workshop, items []response.WorkshopListItem) RelatedWorkshops {
+	artistSet := make(map[string]bool, len(w.ArtistIDList))
+	for _, id := range w.ArtistIDList {
+		artistSet[id] = true
+	}
+	var song string
+	if w.Song != nil {
+		song = NormalizeSong(*w.Song)
+	}
+
+	related := RelatedWorkshops{
+		SameArtist: []response.WorkshopListItem{},
+		SameStudio: []response.WorkshopListItem{},
+		SameSong:   []response.WorkshopListItem{},
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].TimestampEpoch < items[j].TimestampEpoch })
+	for _, item := range items {
+		if item.UUID == w.UUID {
+			continue
+		}
+		if len(related.SameArtist) < relatedLimit && sharesArtist(item, artistSet) {
+			related.SameArtist = append(related.SameArtist, item)
+		}
+		if len(related.SameStudio) < relatedLimit && item.StudioID == w.StudioID && sameWeekend(w, item) {
+			related.SameStudio = append(related.SameStudio, item)
+		}
+		if len(related.SameSong) < relatedLimit && song != "" &&
+			item.Song != nil && NormalizeSong(*item.Song) == song && !sharesArtist(item, artistSet) {
+			related.SameSong = append(related.SameSong, item)
+		}
+	}
+	return related
+}
+
+func sharesArtist(item response.WorkshopListItem, artistSet map[string]bool) bool {
+	for _, id := range item.ArtistIDList {
+		if artistSet[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// sameWeekend reports whether the item falls on the Saturday/Sunday of the
+// week containing the workshop's first session.
+func sameWeekend(w response.Workshop, item response.WorkshopListItem) bool {
+	if len(w.TimeDetails) == 0 {
+		return false
+	}
+	base := time.Unix(utils.GetTimestampEpoch(w.TimeDetails[0]), 0).In(utils.IST)
+	daysUntilSaturday := (int(time.Saturday) - int(base.Weekday()) + 7) % 7
+	saturday := time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, utils.IST).
+		AddDate(0, 0, daysUntilSaturday)
+	if base.Weekday() == time.Sunday {
+		saturday = saturday.AddDate(0, 0, -7)
+	}
+	ts := time.Unix(item.TimestampEpoch, 0).In(utils.IST)
+	return !ts.Before(saturday) && ts.Before(saturday.AddDate(0, 0, 2))
+}
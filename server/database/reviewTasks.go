@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+)
+
+// Review-queue SLA tracking: scraped workshops routinely arrive with no
+// artists, song, or choreo link, and admins fill those in by hand. Each
+// missing field opens a task in review_tasks, keyed by payment link —
+// stable across the scraper's delete-and-reinsert cycle, unlike the
+// workshop uuid — and filling the field, whether by an admin or by a later
+// scrape that extracted it, stamps resolved_at. The open/resolved
+// timestamps feed the aging buckets and median resolution time on
+// /admin/api/app-insights, and the SLA sweep alerts on tasks open longer
+// than REVIEW_SLA_HOURS.
+
+// Review task field names, matching the workshop fields admins complete.
+const (
+	ReviewFieldArtists    = "artists"
+	ReviewFieldSong       = "song"
+	ReviewFieldChoreoLink = "choreo_insta_link"
+)
+
+// ReviewTask is one open or resolved data-completion item.
+type ReviewTask struct {
+	PaymentLink  string     `bson:"payment_link" json:"payment_link"`
+	Field        string     `bson:"field" json:"field"`
+	StudioID     string     `bson:"studio_id" json:"studio_id"`
+	WorkshopUUID string     `bson:"workshop_uuid" json:"workshop_uuid"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+	ResolvedAt   *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// SyncReviewTasks reconciles the review queue against a freshly scraped
+// workshop set: a missing field opens a task (keeping the original
+// created_at if one is already open), a present field resolves any open
+// task for it. Best effort — queue bookkeeping must never fail a refresh.
+func SyncReviewTasks(cfg *config.Config, studioID string, workshops []bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	for _, w := range workshops {
+		link, _ := w["payment_link"].(string)
+		if link == "" {
+			continue
+		}
+		workshopUUID, _ := w["uuid"].(string)
+		for field, missing := range map[string]bool{
+			ReviewFieldArtists:    len(reviewArtistIDs(w["artist_id_list"])) == 0,
+			ReviewFieldSong:       reviewStringMissing(w["song"]),
+			ReviewFieldChoreoLink: reviewStringMissing(w["choreo_insta_link"]),
+		} {
+			if !missing {
+				resolveReviewTasks(ctx, cfg, bson.M{"payment_link": link, "field": field}, now)
+				continue
+			}
+			_, err := Collection(cfg, "review_tasks").UpdateOne(ctx,
+				bson.M{"payment_link": link, "field": field, "resolved_at": bson.M{"$exists": false}},
+				bson.M{
+					"$set":         bson.M{"studio_id": studioID, "workshop_uuid": workshopUUID},
+					"$setOnInsert": bson.M{"payment_link": link, "field": field, "created_at": now},
+				},
+				options.Update().SetUpsert(true))
+			if err != nil {
+				log.Printf("review tasks: open failed for %s/%s: %v", link, field, err)
+			}
+		}
+	}
+}
+
+// ResolveReviewTasksForWorkshop stamps resolved_at on open tasks for one
+// workshop field, called when an admin fills the field in. Best effort;
+// a task missed here (e.g. the admin addressed the workshop by legacy
+// ObjectID) is closed by the next refresh's sync once the lock carries
+// the value forward.
+func ResolveReviewTasksForWorkshop(cfg *config.Config, workshopUUID, field string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	resolveReviewTasks(ctx, cfg, bson.M{"workshop_uuid": workshopUUID, "field": field}, time.Now().UTC())
+}
+
+// resolveReviewTasks closes whatever open tasks match the filter.
+func resolveReviewTasks(ctx context.Context, cfg *config.Config, filter bson.M, now time.Time) {
+	filter["resolved_at"] = bson.M{"$exists": false}
+	_, err := Collection(cfg, "review_tasks").UpdateMany(ctx, filter,
+		bson.M{"$set": bson.M{"resolved_at": now}})
+	if err != nil {
+		log.Printf("review tasks: resolve failed for %v: %v", filter, err)
+	}
+}
+
+// reviewStringMissing reports whether a workshop string field is absent,
+// handling both the *string of freshly built docs and the plain string a
+// locked override carries back from Mongo.
+func reviewStringMissing(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case *string:
+		return v == nil || *v == ""
+	default:
+		return true
+	}
+}
+
+// reviewArtistIDs normalizes artist_id_list, which is []string on fresh
+// docs but []interface{} after a locked override's Mongo round trip.
+func reviewArtistIDs(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		ids := make([]string, 0, len(list))
+		for _, v := range list {
+			if id, ok := v.(string); ok && id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	default:
+		return nil
+	}
+}
+
+// reviewResolutionSample caps how many recent resolutions feed the median;
+// the queue is small, so the last few hundred describe it fine.
+const reviewResolutionSample = 500
+
+// ReviewSLAReport summarizes how long data-completion tasks sit open.
+type ReviewSLAReport struct {
+	Open int64 `json:"open"`
+	// AgingBuckets counts open tasks by age: under_24h, 24h_to_72h,
+	// over_72h.
+	AgingBuckets map[string]int64 `json:"aging_buckets"`
+	// Overdue counts open tasks older than SLAHours.
+	Overdue  int64 `json:"overdue"`
+	SLAHours int   `json:"sla_hours"`
+	// MedianResolutionHours is nil until at least one task has resolved.
+	MedianResolutionHours *float64 `json:"median_resolution_hours"`
+}
+
+// GetReviewSLAReport computes the review queue's aging buckets, overdue
+// count, and median resolution time.
+func GetReviewSLAReport(cfg *config.Config) (*ReviewSLAReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	report := &ReviewSLAReport{
+		AgingBuckets: map[string]int64{"under_24h": 0, "24h_to_72h": 0, "over_72h": 0},
+		SLAHours:     cfg.ReviewSLAHours,
+	}
+	now := time.Now().UTC()
+
+	cursor, err := Collection(cfg, "review_tasks").Find(ctx,
+		bson.M{"resolved_at": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	var open []ReviewTask
+	if err := cursor.All(ctx, &open); err != nil {
+		return nil, err
+	}
+	report.Open = int64(len(open))
+	for _, task := range open {
+		age := now.Sub(task.CreatedAt)
+		switch {
+		case age < 24*time.Hour:
+			report.AgingBuckets["under_24h"]++
+		case age < 72*time.Hour:
+			report.AgingBuckets["24h_to_72h"]++
+		default:
+			report.AgingBuckets["over_72h"]++
+		}
+		if age > time.Duration(cfg.ReviewSLAHours)*time.Hour {
+			report.Overdue++
+		}
+	}
+
+	cursor, err = Collection(cfg, "review_tasks").Find(ctx,
+		bson.M{"resolved_at": bson.M{"$exists": true}},
+		options.Find().SetSort(bson.M{"resolved_at": -1}).SetLimit(reviewResolutionSample))
+	if err != nil {
+		return nil, err
+	}
+	var resolved []ReviewTask
+	if err := cursor.All(ctx, &resolved); err != nil {
+		return nil, err
+	}
+	if len(resolved) > 0 {
+		hours := make([]float64, len(resolved))
+		for i, task := range resolved {
+			hours[i] = task.ResolvedAt.Sub(task.CreatedAt).Hours()
+		}
+		sort.Float64s(hours)
+		median := hours[len(hours)/2]
+		if len(hours)%2 == 0 {
+			median = (hours[len(hours)/2-1] + hours[len(hours)/2]) / 2
+		}
+		report.MedianResolutionHours = &median
+	}
+	return report, nil
+}
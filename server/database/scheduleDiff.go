@@ -0,0 +1,168 @@
+package database
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Schedule diff for studios: studios verify what Nachna lists against their
+// internal sheet by uploading it as CSV. Rows are matched to sessions on
+// normalized song plus date — the same key the scraper dedupe uses — and
+// the diff reports what is missing, extra, or mismatched.
+
+// ScheduleRow is one row of a studio's uploaded schedule.
+type ScheduleRow struct {
+	Song  string `json:"song"`
+	Date  string `json:"date"`
+	Time  string `json:"time"`
+	Price string `json:"price"`
+}
+
+// ScheduleMismatch pairs a matched row with the differing Nachna values.
+type ScheduleMismatch struct {
+	Row            ScheduleRow `json:"row"`
+	NachnaTime     string      `json:"nachna_time"`
+	NachnaPriceMin int64       `json:"nachna_price_min"`
+	Field          string      `json:"field"`
+}
+
+// ScheduleDiff is the structured comparison result.
+type ScheduleDiff struct {
+	MissingOnNachna []ScheduleRow      `json:"missing_on_nachna"`
+	ExtraOnNachna   []ScheduleRow      `json:"extra_on_nachna"`
+	Mismatched      []ScheduleMismatch `json:"mismatched"`
+}
+
+// DiffStudioSchedule parses a CSV upload (columns: song, date, start_time,
+// end_time, price; date as YYYY-MM-DD) and diffs it against the studio's
+// listed sessions.
+func DiffStudioSchedule(cfg *config.Config, studioID string, csvData []byte) (*ScheduleDiff, error) {
+	rows, err := parseScheduleCSV(csvData)
+	if err != nil {
+		return nil, err
+	}
+
+	workshops, err := fetchWorkshops(cfg, bson.M{
+		"studio_id":   studioID,
+		"is_archived": bson.M{"$ne": true},
+		"is_deleted":  notDeleted(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type listed struct {
+		row      ScheduleRow
+		minPrice int64
+	}
+	listedByKey := map[string]listed{}
+	for _, w := range workshops {
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			song := ""
+			if w.Song != nil {
+				song = *w.Song
+			}
+			date := fmt.Sprintf("%04d-%02d-%02d", *td.Year, *td.Month, *td.Day)
+			listedByKey[scheduleKey(song, date)] = listed{
+				row: ScheduleRow{
+					Song:  song,
+					Date:  date,
+					Time:  utils.GetFormattedTime(td),
+					Price: priceString(w),
+				},
+				minPrice: w.MinPrice,
+			}
+		}
+	}
+
+	diff := &ScheduleDiff{
+		MissingOnNachna: []ScheduleRow{},
+		ExtraOnNachna:   []ScheduleRow{},
+		Mismatched:      []ScheduleMismatch{},
+	}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		key := scheduleKey(row.Song, row.Date)
+		seen[key] = true
+		l, ok := listedByKey[key]
+		if !ok {
+			diff.MissingOnNachna = append(diff.MissingOnNachna, row)
+			continue
+		}
+		if row.Time != "" && !strings.EqualFold(strings.TrimSpace(row.Time), l.row.Time) {
+			diff.Mismatched = append(diff.Mismatched, ScheduleMismatch{
+				Row: row, NachnaTime: l.row.Time, NachnaPriceMin: l.minPrice, Field: "time",
+			})
+			continue
+		}
+		if row.Price != "" && l.minPrice > 0 && utils.ParseMinPrice(row.Price) != l.minPrice {
+			diff.Mismatched = append(diff.Mismatched, ScheduleMismatch{
+				Row: row, NachnaTime: l.row.Time, NachnaPriceMin: l.minPrice, Field: "price",
+			})
+		}
+	}
+	for key, l := range listedByKey {
+		if !seen[key] {
+			diff.ExtraOnNachna = append(diff.ExtraOnNachna, l.row)
+		}
+	}
+	return diff, nil
+}
+
+// scheduleKey is the dedupe matching key: normalized song plus date.
+func scheduleKey(song, date string) string {
+	return NormalizeSong(song) + "|" + strings.TrimSpace(date)
+}
+
+// parseScheduleCSV reads the uploaded sheet. The first row is treated as a
+// header when it does not parse as a date in column two.
+func parseScheduleCSV(data []byte) ([]ScheduleRow, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	rows := []ScheduleRow{}
+	for i, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		date := strings.TrimSpace(record[1])
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			if i == 0 {
+				continue // header row
+			}
+			return nil, fmt.Errorf("row %d: date %q is not YYYY-MM-DD", i+1, date)
+		}
+		row := ScheduleRow{Song: strings.TrimSpace(record[0]), Date: date}
+		if len(record) > 2 {
+			row.Time = strings.TrimSpace(record[2])
+		}
+		if len(record) > 3 {
+			row.Price = strings.TrimSpace(record[3])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// priceString renders the listed price for diff output.
+func priceString(w response.Workshop) string {
+	if w.PricingInfo != nil {
+		return *w.PricingInfo
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"sort"
+	"strings"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+)
+
+// Unified search across workshops, artists and studios. The corpus is
+// small (hundreds of upcoming workshops, tens of artists and studios) and
+// the endpoint sits behind the response cache, so scoring runs in memory
+// over the same cached reads the listing endpoints use rather than a
+// server-side text index. Matching is token-based: exact tokens score
+// highest, then prefixes, then single-edit typos, and every query token
+// must match somewhere for an entity to count as a hit.
+
+// searchFacetLimit caps how many hits each entity facet returns.
+const searchFacetLimit = 10
+
+// Per-token match scores: exact beats prefix beats a one-edit typo.
+const (
+	scoreExact  = 1.0
+	scorePrefix = 0.7
+	scoreFuzzy  = 0.5
+)
+
+// SearchAll runs the query against songs, artist names and studio names,
+// returning relevance-sorted hits grouped by entity type.
+func SearchAll(cfg *config.Config, query string) (*response.SearchResponse, error) {
+	tokens := strings.Fields(strings.ToLower(query))
+	result := &response.SearchResponse{
+		Query:     query,
+		Workshops: []response.WorkshopSearchHit{},
+		Artists:   []response.ArtistSearchHit{},
+		Studios:   []response.StudioSearchHit{},
+	}
+	if len(tokens) == 0 {
+		return result, nil
+	}
+
+	items, err := GetUpcomingWorkshopItems(cfg)
+	if err != nil {
+		return nil, err
+	}
+	seenWorkshops := map[string]bool{}
+	for _, item := range items {
+		// Multi-session workshops appear once per session; one hit per
+		// workshop is enough.
+		if seenWorkshops[item.UUID] {
+			continue
+		}
+		fields := []string{item.StudioName}
+		if item.Song != nil {
+			fields = append(fields, *item.Song)
+		}
+		if item.By != nil {
+			fields = append(fields, *item.By)
+		}
+		if score := scoreEntity(tokens, fields); score > 0 {
+			seenWorkshops[item.UUID] = true
+			result.Workshops = append(result.Workshops,
+				response.WorkshopSearchHit{Score: score, Workshop: item})
+		}
+	}
+
+	artists, err := GetAllArtistsFromDB(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, artist := range artists {
+		if score := scoreEntity(tokens, []string{artist.Name}); score > 0 {
+			result.Artists = append(result.Artists,
+				response.ArtistSearchHit{Score: score, Artist: artist})
+		}
+	}
+
+	studios, err := GetStudios(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, studio := range studios {
+		if score := scoreEntity(tokens, []string{studio.Name}); score > 0 {
+			result.Studios = append(result.Studios,
+				response.StudioSearchHit{Score: score, Studio: studio})
+		}
+	}
+
+	sort.SliceStable(result.Workshops, func(a, b int) bool {
+		return result.Workshops[a].Score > result.Workshops[b].Score
+	})
+	sort.SliceStable(result.Artists, func(a, b int) bool {
+		return result.Artists[a].Score > result.Artists[b].Score
+	})
+	sort.SliceStable(result.Studios, func(a, b int) bool {
+		return result.Studios[a].Score > result.Studios[b].Score
+	})
+	if len(result.Workshops) > searchFacetLimit {
+		result.Workshops = result.Workshops[:searchFacetLimit]
+	}
+	if len(result.Artists) > searchFacetLimit {
+		result.Artists = result.Artists[:searchFacetLimit]
+	}
+	if len(result.Studios) > searchFacetLimit {
+		result.Studios = result.Studios[:searchFacetLimit]
+	}
+	result.Total = len(result.Workshops) + len(result.Artists) + len(result.Studios)
+	return result, nil
+}
+
+// scoreEntity scores an entity's searchable fields against the query
+// tokens. Every query token must match at least one field token; the
+// entity's score is the sum of the best match per query token.
+func scoreEntity(queryTokens []string, fields []string) float64 {
+	var fieldTokens []string
+	for _, field := range fields {
+		fieldTokens = append(fieldTokens, strings.Fields(strings.ToLower(field))...)
+	}
+	total := 0.0
+	for _, qt := range queryTokens {
+		best := 0.0
+		for _, ft := range fieldTokens {
+			if score := scoreToken(qt, ft); score > best {
+				best = score
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+// scoreToken scores one query token against one field token. Fuzzy
+// matching only kicks in for tokens long enough that a one-letter typo is
+// plausibly the same word.
+func scoreToken(queryToken, fieldToken string) float64 {
+	if queryToken == fieldToken {
+		return scoreExact
+	}
+	if strings.HasPrefix(fieldToken, queryToken) {
+		return scorePrefix
+	}
+	if len(queryToken) >= 4 && withinOneEdit(queryToken, fieldToken) {
+		return scoreFuzzy
+	}
+	return 0
+}
+
+// withinOneEdit reports whether two tokens differ by at most one
+// substitution, insertion or deletion.
+func withinOneEdit(a, b string) bool {
+	if a == b {
+		return true
+	}
+	la, lb := len(a), len(b)
+	if la > lb {
+		a, b, la, lb = b, a, lb, la
+	}
+	if lb-la > 1 {
+		return false
+	}
+	for i := 0; i < la; i++ {
+		if a[i] != b[i] {
+			if la == lb {
+				// Substitution: the rest must match exactly.
+				return a[i+1:] == b[i+1:]
+			}
+			// Insertion in the longer token.
+			return a[i:] == b[i+1:]
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// Soft delete: admin deletes flag documents with is_deleted/deleted_at —
+// the same convention the user collection already uses — instead of
+// removing them, so a mistaken delete is reversible through the restore
+// endpoints. Public queries exclude flagged documents via notDeleted();
+// PurgeSoftDeleted hard-deletes documents that have stayed flagged past
+// the retention window.
+
+// softDeleteCollections maps each soft-deletable collection to its natural
+// key field, shared by the flag/restore helpers and the purge sweep.
+var softDeleteCollections = map[string]string{
+	"studios":      "studio_id",
+	"artists_v2":   "artist_id",
+	"workshops_v2": "uuid",
+}
+
+// notDeleted is the filter clause excluding soft-deleted documents; absent
+// flags count as live so pre-existing documents need no backfill.
+func notDeleted() bson.M {
+	return bson.M{"$ne": true}
+}
+
+// setDeletedFlag flags or unflags one document by its natural key,
+// erroring when no document matches.
+func setDeletedFlag(cfg *config.Config, collection, id string, deleted bool) error {
+	key, ok := softDeleteCollections[collection]
+	if !ok {
+		return fmt.Errorf("collection %s does not support soft delete", collection)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"is_deleted": false}, "$unset": bson.M{"deleted_at": ""}}
+	if deleted {
+		update = bson.M{"$set": bson.M{"is_deleted": true, "deleted_at": time.Now().UTC()}}
+	}
+	result, err := Collection(cfg, collection).UpdateOne(ctx, bson.M{key: id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s %s not found", key, id)
+	}
+	return nil
+}
+
+// RestoreStudio clears a studio's soft-delete flag so it reappears in
+// listings.
+func RestoreStudio(cfg *config.Config, studioID string) error {
+	err := setDeletedFlag(cfg, "studios", studioID, false)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "studio", StudioID: studioID})
+	return err
+}
+
+// RestoreArtist clears an artist's soft-delete flag.
+func RestoreArtist(cfg *config.Config, artistID string) error {
+	err := setDeletedFlag(cfg, "artists_v2", artistID, false)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "artist", ArtistIDs: []string{artistID}})
+	return err
+}
+
+// SoftDeleteWorkshop flags one workshop by UUID so it drops out of every
+// public listing without losing the document.
+func SoftDeleteWorkshop(cfg *config.Config, workshopUUID string) error {
+	err := setDeletedFlag(cfg, "workshops_v2", workshopUUID, true)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "workshop"})
+	return err
+}
+
+// RestoreWorkshop clears a workshop's soft-delete flag.
+func RestoreWorkshop(cfg *config.Config, workshopUUID string) error {
+	err := setDeletedFlag(cfg, "workshops_v2", workshopUUID, false)
+	InvalidateForWrite(cfg, EntityWrite{Entity: "workshop"})
+	return err
+}
+
+// PurgeSoftDeleted hard-deletes documents that have been soft-deleted for
+// longer than the retention window, returning documents removed per
+// collection.
+func PurgeSoftDeleted(cfg *config.Config, olderThan time.Duration) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cutoff := time.Now().UTC().Add(-olderThan)
+	purged := map[string]int64{}
+	for collection := range softDeleteCollections {
+		result, err := Collection(cfg, collection).DeleteMany(ctx, bson.M{
+			"is_deleted": true,
+			"deleted_at": bson.M{"$lt": cutoff},
+		})
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount > 0 {
+			purged[collection] = result.DeletedCount
+		}
+	}
+	return purged, nil
+}
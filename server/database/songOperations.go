@@ -0,0 +1,96 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Song lookups: dancers search by routine, so the listing needs an index
+// from normalized song titles to every upcoming session teaching them.
+
+// NormalizeSong canonicalizes a scraped or user-supplied song title for
+// matching: lowercased with whitespace collapsed.
+func NormalizeSong(song string) string {
+	return strings.Join(strings.Fields(strings.ToLower(song)), " ")
+}
+
+// GetWorkshopsBySong returns every upcoming session teaching the given song
+// across studios, sorted by start time. Matching is done on normalized
+// titles so casing and spacing differences between studios do not split the
+// catalog.
+func GetWorkshopsBySong(cfg *config.Config, song string) ([]response.WorkshopSession, error) {
+	normalized := NormalizeSong(song)
+	if normalized == "" {
+		return []response.WorkshopSession{}, nil
+	}
+
+	workshops, err := fetchWorkshops(cfg, upcomingWorkshopFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	sessions := []response.WorkshopSession{}
+	for _, w := range workshops {
+		if w.Song == nil || NormalizeSong(*w.Song) != normalized {
+			continue
+		}
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			epoch := utils.GetTimestampEpoch(td)
+			if epoch < now {
+				continue
+			}
+			studioID := w.StudioID
+			eventType := w.EventType
+			sessions = append(sessions, response.WorkshopSession{
+				UUID:            w.UUID,
+				Date:            utils.GetFormattedDate(td),
+				Time:            utils.GetFormattedTime(td),
+				Song:            w.Song,
+				StudioID:        &studioID,
+				Artist:          w.By,
+				ArtistIDList:    w.ArtistIDList,
+				PaymentLink:     w.PaymentLink,
+				PaymentLinkType: w.PaymentLinkType,
+				PricingInfo:     w.PricingInfo,
+				TimestampEpoch:  epoch,
+				EventType:       &eventType,
+				ChoreoInstaLink: w.ChoreoInstaLink,
+			})
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].TimestampEpoch < sessions[j].TimestampEpoch })
+	return sessions, nil
+}
+
+// GetSongCatalog returns the distinct normalized song titles with upcoming
+// sessions, for search facets and typeahead.
+func GetSongCatalog(cfg *config.Config) ([]string, error) {
+	workshops, err := fetchWorkshops(cfg, upcomingWorkshopFilter())
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	songs := []string{}
+	for _, w := range workshops {
+		if w.Song == nil {
+			continue
+		}
+		normalized := NormalizeSong(*w.Song)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		songs = append(songs, normalized)
+	}
+	sort.Strings(songs)
+	return songs, nil
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// BackfillWorkshopUUIDs assigns a composite uuid to workshop documents
+// that predate the scraper stamping one — the uuid is the standard
+// external workshop ID, so every document must carry it. The format
+// matches the scraper's: "{studio_id}_{uuid4}". Runs once after the
+// database is ready; fresh imports get their uuid from the scraper
+// directly.
+func BackfillWorkshopUUIDs(cfg *config.Config) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := Collection(cfg, "workshops_v2").Find(ctx, bson.M{"$or": []bson.M{
+		{"uuid": bson.M{"$exists": false}},
+		{"uuid": ""},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	var workshops []struct {
+		MongoID  interface{} `bson:"_id"`
+		StudioID string      `bson:"studio_id"`
+	}
+	if err := cursor.All(ctx, &workshops); err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, w := range workshops {
+		_, err := Collection(cfg, "workshops_v2").UpdateOne(ctx,
+			bson.M{"_id": w.MongoID},
+			bson.M{"$set": bson.M{"uuid": fmt.Sprintf("%s_%s", w.StudioID, uuid.NewString())}})
+		if err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
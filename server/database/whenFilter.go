@@ -0,0 +1,81 @@
+package database
+
+import (
+	"time"
+
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Quick time filters for the workshop list endpoints: ?when=today|tomorrow|
+// weekend|next_7_days, computed server-side in IST so every client agrees on
+// what "today" means.
+
+// lateNightGrace extends each window past midnight so a session starting at
+// 1 AM after a Saturday social still counts as part of Saturday.
+const lateNightGrace = 4 * time.Hour
+
+// whenWindow returns the [start, end) IST window for a named filter. The
+// second return is false for unknown filter names.
+func whenWindow(when string, now time.Time) (time.Time, time.Time, bool) {
+	nowIST := now.In(utils.IST)
+	startOfDay := time.Date(nowIST.Year(), nowIST.Month(), nowIST.Day(), 0, 0, 0, 0, utils.IST)
+
+	switch when {
+	case "today":
+		return startOfDay, startOfDay.AddDate(0, 0, 1).Add(lateNightGrace), true
+	case "tomorrow":
+		start := startOfDay.AddDate(0, 0, 1)
+		return start, start.AddDate(0, 0, 1).Add(lateNightGrace), true
+	case "weekend":
+		// Upcoming Saturday through Sunday; mid-weekend the window starts
+		// today so the remaining sessions still show.
+		daysUntilSaturday := (int(time.Saturday) - int(nowIST.Weekday()) + 7) % 7
+		start := startOfDay.AddDate(0, 0, daysUntilSaturday)
+		if nowIST.Weekday() == time.Sunday {
+			start = startOfDay
+		}
+		end := start.AddDate(0, 0, 2)
+		if nowIST.Weekday() == time.Sunday {
+			end = startOfDay.AddDate(0, 0, 1)
+		}
+		return start, end.Add(lateNightGrace), true
+	case "next_7_days":
+		return startOfDay, startOfDay.AddDate(0, 0, 7).Add(lateNightGrace), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// filterItemsByWhen keeps list items whose session start falls in the named
+// window. An empty or unknown filter name returns the items unchanged.
+func filterItemsByWhen(items []response.WorkshopListItem, when string) []response.WorkshopListItem {
+	start, end, ok := whenWindow(when, time.Now())
+	if !ok {
+		return items
+	}
+	filtered := items[:0]
+	for _, item := range items {
+		ts := time.Unix(item.TimestampEpoch, 0).In(utils.IST)
+		if !ts.Before(start) && ts.Before(end) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// filterSessionsByWhen is filterItemsByWhen for the session row shape used
+// by the artist schedule endpoint.
+func filterSessionsByWhen(sessions []response.WorkshopSession, when string) []response.WorkshopSession {
+	start, end, ok := whenWindow(when, time.Now())
+	if !ok {
+		return sessions
+	}
+	filtered := sessions[:0]
+	for _, s := range sessions {
+		ts := time.Unix(s.TimestampEpoch, 0).In(utils.IST)
+		if !ts.Before(start) && ts.Before(end) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Server-side filtering for the workshop listing: a date range, an event
+// style, and price bounds can all be narrowed before expansion so clients
+// fetch only the sessions they will render. What can be expressed against
+// the document shape is pushed into the MongoDB query; the rest (exact day
+// boundaries, parse-fallback pricing) is refined in memory on the expanded
+// items, matching how the when filter works.
+
+// WorkshopFilters are the narrowing options for workshop listings. Dates
+// are YYYY-MM-DD and interpreted in IST; Style matches the workshop's
+// event_type (workshop/intensive); zero price bounds are open.
+type WorkshopFilters struct {
+	When      string
+	StartDate string
+	EndDate   string
+	Style     string
+	MinPrice  int64
+	MaxPrice  int64
+}
+
+// GetWorkshopsFiltered returns the categorized listing narrowed by the
+// given filters.
+func GetWorkshopsFiltered(cfg *config.Config, f WorkshopFilters) (response.CategorizedWorkshopResponse, error) {
+	empty := response.CategorizedWorkshopResponse{ThisWeek: []response.DaySchedule{}, PostThisWeek: []response.WorkshopListItem{}}
+
+	filter := upcomingWorkshopFilter()
+	applyWorkshopQueryFilters(filter, f)
+	items, err := expandWorkshops(cfg, filter)
+	if err != nil {
+		return empty, err
+	}
+	items = filterItemsByWhen(items, f.When)
+	items = filterItemsByDateRange(items, f.StartDate, f.EndDate)
+	resp := categorize(items)
+	FilterCategorizedByPriceRange(&resp, f.MinPrice, f.MaxPrice)
+	return resp, nil
+}
+
+// applyWorkshopQueryFilters translates the filters into the MongoDB query.
+// The date range narrows on session years (day-level precision needs the
+// expanded epoch, which only exists in memory), the style matches
+// event_type exactly, and the price bounds exclude documents whose stored
+// min_price definitively falls outside the range — documents without a
+// parsed price pass through to the in-memory refinement.
+func applyWorkshopQueryFilters(filter bson.M, f WorkshopFilters) {
+	if f.Style != "" {
+		filter["event_type"] = f.Style
+	}
+	yearBounds := bson.M{}
+	if start, err := time.ParseInLocation("2006-01-02", f.StartDate, utils.IST); err == nil {
+		yearBounds["$gte"] = start.Year()
+	}
+	if end, err := time.ParseInLocation("2006-01-02", f.EndDate, utils.IST); err == nil {
+		yearBounds["$lte"] = end.Year()
+	}
+	if len(yearBounds) > 0 {
+		filter["time_details.year"] = yearBounds
+	}
+	if f.MinPrice > 0 || f.MaxPrice > 0 {
+		priced := bson.M{}
+		if f.MinPrice > 0 {
+			priced["$gte"] = f.MinPrice
+		}
+		if f.MaxPrice > 0 {
+			priced["$lte"] = f.MaxPrice
+		}
+		filter["$or"] = []bson.M{
+			{"min_price": bson.M{"$in": []interface{}{nil, int64(0)}}},
+			{"min_price": priced},
+		}
+	}
+}
+
+// filterItemsByDateRange keeps list items whose session start falls within
+// [startDate, endDate] in IST, with the usual late-night grace on the end.
+// Malformed or empty bounds are open.
+func filterItemsByDateRange(items []response.WorkshopListItem, startDate, endDate string) []response.WorkshopListItem {
+	start, startErr := time.ParseInLocation("2006-01-02", startDate, utils.IST)
+	end, endErr := time.ParseInLocation("2006-01-02", endDate, utils.IST)
+	if startErr != nil && endErr != nil {
+		return items
+	}
+	filtered := items[:0]
+	for _, item := range items {
+		ts := time.Unix(item.TimestampEpoch, 0).In(utils.IST)
+		if startErr == nil && ts.Before(start) {
+			continue
+		}
+		if endErr == nil && !ts.Before(end.AddDate(0, 0, 1).Add(lateNightGrace)) {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+)
+
+// Field-level locks: admin edits stamp the corrected field names into a
+// locked_fields array on the workshop document, and the scraper carries
+// locked values forward across its delete-and-reinsert refresh cycle
+// (matching on payment link, the stable workshop identity) instead of
+// silently reverting manual corrections to whatever the AI extracted.
+
+// LockedFieldOverride is one workshop's locked field names plus their
+// admin-corrected values, keyed for carry-over by payment link.
+type LockedFieldOverride struct {
+	LockedFields []string
+	Values       bson.M
+}
+
+// GetLockedWorkshopFields returns, per payment link, the locked fields and
+// their current values for a studio's workshops.
+func GetLockedWorkshopFields(cfg *config.Config, studioID string) (map[string]LockedFieldOverride, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "workshops_v2").Find(ctx, bson.M{
+		"studio_id":     studioID,
+		"locked_fields": bson.M{"$exists": true, "$ne": []interface{}{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]LockedFieldOverride{}
+	for _, doc := range docs {
+		link, _ := doc["payment_link"].(string)
+		if link == "" {
+			continue
+		}
+		locked := stringList(doc["locked_fields"])
+		if len(locked) == 0 {
+			continue
+		}
+		values := bson.M{}
+		for _, field := range locked {
+			if value, ok := doc[field]; ok {
+				values[field] = value
+			}
+		}
+		overrides[link] = LockedFieldOverride{LockedFields: locked, Values: values}
+	}
+	return overrides, nil
+}
+
+// lockFields adds field names to a workshop's locked_fields set; used by
+// the admin edit operations alongside their $set.
+func lockFields(fields ...string) bson.M {
+	return bson.M{"locked_fields": bson.M{"$each": fields}}
+}
+
+// stringList normalizes a []string/[]interface{} bson value.
+func stringList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, v := range list {
+			if s, ok := v.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case bson.A:
+		out := make([]string, 0, len(list))
+		for _, v := range list {
+			if s, ok := v.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
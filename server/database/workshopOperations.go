@@ -0,0 +1,396 @@
+package database
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// upcomingWorkshopFilter matches non-archived, non-deleted workshops with
+// at least one session that has not started yet.
+func upcomingWorkshopFilter() bson.M {
+	return bson.M{"is_archived": bson.M{"$ne": true}, "is_deleted": notDeleted()}
+}
+
+// fetchWorkshops loads raw workshop documents matching the filter.
+func fetchWorkshops(cfg *config.Config, filter bson.M) ([]response.Workshop, error) {
+	var workshops []response.Workshop
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "workshops_v2").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &workshops); err != nil {
+		return nil, err
+	}
+	return workshops, nil
+}
+
+// artistLookup returns artist image URLs and Instagram links keyed by ID.
+func artistLookup(cfg *config.Config) (map[string]response.Artist, error) {
+	artists, err := GetAllArtistsFromDB(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]response.Artist, len(artists))
+	for _, a := range artists {
+		m[a.ID] = a
+	}
+	return m, nil
+}
+
+// buildListItem expands one session of a workshop document into the list
+// item shape the API serves. Links flagged by the dead-link sweep are
+// blanked so clients hide the book button until the studio fixes them.
+func buildListItem(w response.Workshop, td response.TimeDetail, studios map[string]response.Studio, artists map[string]response.Artist, deadLinks map[string]bool) response.WorkshopListItem {
+	date := utils.GetFormattedDate(td)
+	timeStr := utils.GetFormattedTime(td)
+	eventType := w.EventType
+
+	imageURLs := make([]*string, 0, len(w.ArtistIDList))
+	instaLinks := make([]string, 0, len(w.ArtistIDList))
+	for _, id := range w.ArtistIDList {
+		if a, ok := artists[id]; ok {
+			imageURLs = append(imageURLs, a.ImageURL)
+			instaLinks = append(instaLinks, a.InstagramLink)
+		} else {
+			imageURLs = append(imageURLs, nil)
+			instaLinks = append(instaLinks, "")
+		}
+	}
+
+	studioName := w.StudioID
+	currency := utils.DefaultCurrency
+	if s, ok := studios[w.StudioID]; ok {
+		studioName = s.Name
+		currency = utils.NormalizeCurrency(s.Currency)
+	}
+
+	paymentLink, paymentLinkType := w.PaymentLink, w.PaymentLinkType
+	if deadLinks[w.PaymentLink] {
+		paymentLink, paymentLinkType = "", ""
+	}
+
+	return response.WorkshopListItem{
+		UUID:                 w.UUID,
+		PaymentLink:          paymentLink,
+		PaymentLinkType:      paymentLinkType,
+		StudioID:             w.StudioID,
+		StudioName:           studioName,
+		UpdatedAt:            w.UpdatedAt,
+		By:                   w.By,
+		Song:                 w.Song,
+		PricingInfo:          w.PricingInfo,
+		MinPrice:             w.MinPrice,
+		MaxPrice:             w.MaxPrice,
+		Currency:             currency,
+		TimestampEpoch:       utils.GetTimestampEpoch(td),
+		ArtistIDList:         w.ArtistIDList,
+		ArtistImageURLs:      imageURLs,
+		ArtistInstagramLinks: instaLinks,
+		Date:                 &date,
+		Time:                 &timeStr,
+		EventType:            &eventType,
+		ChoreoInstaLink:      w.ChoreoInstaLink,
+	}
+}
+
+// categorize splits list items into this-week day buckets and the
+// post-this-week tail, both sorted by first-session epoch.
+func categorize(items []response.WorkshopListItem) response.CategorizedWorkshopResponse {
+	sort.Slice(items, func(i, j int) bool { return items[i].TimestampEpoch < items[j].TimestampEpoch })
+
+	now := time.Now().In(utils.IST)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, utils.IST)
+	// End of the current week: upcoming Sunday midnight.
+	daysUntilWeekEnd := (7 - int(now.Weekday())) % 7
+	endOfWeek := startOfDay.AddDate(0, 0, daysUntilWeekEnd+1)
+
+	dayBuckets := make(map[string][]response.WorkshopListItem)
+	var dayOrder []string
+	var postThisWeek []response.WorkshopListItem
+
+	for _, item := range items {
+		ts := time.Unix(item.TimestampEpoch, 0).In(utils.IST)
+		if ts.Before(startOfDay) {
+			continue
+		}
+		if ts.Before(endOfWeek) {
+			day := ts.Format("Monday")
+			if _, seen := dayBuckets[day]; !seen {
+				dayOrder = append(dayOrder, day)
+			}
+			dayBuckets[day] = append(dayBuckets[day], item)
+		} else {
+			postThisWeek = append(postThisWeek, item)
+		}
+	}
+
+	thisWeek := make([]response.DaySchedule, 0, len(dayOrder))
+	for _, day := range dayOrder {
+		thisWeek = append(thisWeek, response.DaySchedule{Day: day, Workshops: dayBuckets[day]})
+	}
+	return response.CategorizedWorkshopResponse{ThisWeek: thisWeek, PostThisWeek: postThisWeek}
+}
+
+// GetWorkshops returns all upcoming workshops categorized by week. All
+// documents are pulled into memory and formatted across a worker pool.
+func GetWorkshops(cfg *config.Config) (response.CategorizedWorkshopResponse, error) {
+	return GetAllWorkshopsCategorized(cfg, upcomingWorkshopFilter())
+}
+
+// GetWorkshopsWhen is GetWorkshops narrowed to an IST quick filter
+// (today/tomorrow/weekend/next_7_days). An empty filter name returns the
+// full listing.
+func GetWorkshopsWhen(cfg *config.Config, when string) (response.CategorizedWorkshopResponse, error) {
+	return getAllWorkshopsCategorizedWhen(cfg, upcomingWorkshopFilter(), when)
+}
+
+// GetWorkshopsByStudio returns upcoming workshops for one studio, categorized
+// by week.
+func GetWorkshopsByStudio(cfg *config.Config, studioID string) (response.CategorizedWorkshopResponse, error) {
+	return GetWorkshopsByStudioWhen(cfg, studioID, "")
+}
+
+// GetWorkshopsByStudioWhen is GetWorkshopsByStudio narrowed to an IST quick
+// filter.
+func GetWorkshopsByStudioWhen(cfg *config.Config, studioID, when string) (response.CategorizedWorkshopResponse, error) {
+	filter := upcomingWorkshopFilter()
+	filter["studio_id"] = studioID
+	return getAllWorkshopsCategorizedWhen(cfg, filter, when)
+}
+
+// GetAllWorkshopsCategorized fetches workshops matching the filter, expands
+// every session, and categorizes them into this-week/post-this-week in memory.
+func GetAllWorkshopsCategorized(cfg *config.Config, filter bson.M) (response.CategorizedWorkshopResponse, error) {
+	return getAllWorkshopsCategorizedWhen(cfg, filter, "")
+}
+
+func getAllWorkshopsCategorizedWhen(cfg *config.Config, filter bson.M, when string) (response.CategorizedWorkshopResponse, error) {
+	empty := response.CategorizedWorkshopResponse{ThisWeek: []response.DaySchedule{}, PostThisWeek: []response.WorkshopListItem{}}
+
+	// The full (unfiltered) listing — the hot path behind /api/workshops and
+	// the studio schedule — runs through the aggregation pipeline, where
+	// session expansion, epoch math, categorization and sorting happen
+	// server-side instead of over the whole collection in memory. The quick
+	// filters reuse the in-memory path, which filterItemsByWhen is built on.
+	if when == "" {
+		return GetWorkshopsCategorizedAgg(cfg, filter)
+	}
+
+	items, err := expandWorkshops(cfg, filter)
+	if err != nil {
+		return empty, err
+	}
+	return categorize(filterItemsByWhen(items, when)), nil
+}
+
+// expandWorkshops fetches workshops matching the filter and expands every
+// session into the list item shape.
+func expandWorkshops(cfg *config.Config, filter bson.M) ([]response.WorkshopListItem, error) {
+	workshops, err := fetchWorkshops(cfg, filter)
+	if err != nil {
+		return nil, err
+	}
+	studios, err := GetStudioMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+	artists, err := artistLookup(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	items := buildListItems(workshops, studios, artists, GetDeadPaymentLinks(cfg))
+	tiers := currentTiersByUUID(cfg, workshops)
+	for i := range items {
+		applyCurrentTier(&items[i], tiers[items[i].UUID])
+	}
+	return items, nil
+}
+
+// GetUpcomingWorkshopsRaw returns raw upcoming workshop documents,
+// optionally narrowed to one studio or artist. The calendar feeds use this:
+// they need the underlying time_details rather than the formatted list shape.
+func GetUpcomingWorkshopsRaw(cfg *config.Config, studioID, artistID string) ([]response.Workshop, error) {
+	filter := upcomingWorkshopFilter()
+	if studioID != "" {
+		filter["studio_id"] = studioID
+	}
+	if artistID != "" {
+		filter["artist_id_list"] = artistID
+	}
+	return fetchWorkshops(cfg, filter)
+}
+
+// GetUpcomingWorkshopItems returns every upcoming session as a flat list,
+// uncategorized — the shape the agent-facing structured endpoint serves.
+func GetUpcomingWorkshopItems(cfg *config.Config) ([]response.WorkshopListItem, error) {
+	return expandWorkshops(cfg, upcomingWorkshopFilter())
+}
+
+// buildListItems expands every session of every listable workshop into the
+// list item shape. Formatting is split across a worker pool — per-session
+// date/time parsing dominates this path — with each worker writing into its
+// own slot of a pre-sized slice so the output order matches the sequential
+// version exactly.
+func buildListItems(workshops []response.Workshop, studios map[string]response.Studio, artists map[string]response.Artist, deadLinks map[string]bool) []response.WorkshopListItem {
+	type session struct {
+		workshop response.Workshop
+		detail   response.TimeDetail
+	}
+	var sessions []session
+	for _, w := range workshops {
+		if w.EventType != "workshop" && w.EventType != "intensive" {
+			continue
+		}
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			sessions = append(sessions, session{workshop: w, detail: td})
+		}
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(sessions) {
+		workers = len(sessions)
+	}
+
+	items := make([]response.WorkshopListItem, len(sessions))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				items[i] = buildListItem(sessions[i].workshop, sessions[i].detail, studios, artists, deadLinks)
+			}
+		}()
+	}
+	for i := range sessions {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return items
+}
+
+// GetWorkshopsByArtist returns all upcoming sessions for an artist sorted by
+// start time.
+func GetWorkshopsByArtist(cfg *config.Config, artistID string) ([]response.WorkshopSession, error) {
+	return GetWorkshopsByArtistWhen(cfg, artistID, "")
+}
+
+// GetWorkshopsByArtistWhen is GetWorkshopsByArtist narrowed to an IST quick
+// filter.
+func GetWorkshopsByArtistWhen(cfg *config.Config, artistID, when string) ([]response.WorkshopSession, error) {
+	filter := upcomingWorkshopFilter()
+	filter["artist_id_list"] = artistID
+	workshops, err := fetchWorkshops(cfg, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	deadLinks := GetDeadPaymentLinks(cfg)
+	sessions := []response.WorkshopSession{}
+	for _, w := range workshops {
+		paymentLink, paymentLinkType := w.PaymentLink, w.PaymentLinkType
+		if deadLinks[w.PaymentLink] {
+			paymentLink, paymentLinkType = "", ""
+		}
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			epoch := utils.GetTimestampEpoch(td)
+			if epoch < now {
+				continue
+			}
+			studioID := w.StudioID
+			eventType := w.EventType
+			sessions = append(sessions, response.WorkshopSession{
+				UUID:            w.UUID,
+				Date:            utils.GetFormattedDate(td),
+				Time:            utils.GetFormattedTime(td),
+				Song:            w.Song,
+				StudioID:        &studioID,
+				Artist:          w.By,
+				ArtistIDList:    w.ArtistIDList,
+				PaymentLink:     paymentLink,
+				PaymentLinkType: paymentLinkType,
+				PricingInfo:     w.PricingInfo,
+				TimestampEpoch:  epoch,
+				EventType:       &eventType,
+				ChoreoInstaLink: w.ChoreoInstaLink,
+			})
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].TimestampEpoch < sessions[j].TimestampEpoch })
+	return filterSessionsByWhen(sessions, when), nil
+}
+
+// GetWorkshopByUUID returns one workshop document by its public uuid.
+func GetWorkshopByUUID(cfg *config.Config, uuid string) (*response.Workshop, error) {
+	var w response.Workshop
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := Collection(cfg, "workshops_v2").FindOne(ctx, bson.M{"uuid": uuid, "is_archived": bson.M{"$ne": true}, "is_deleted": notDeleted()}).Decode(&w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// GetWorkshopPaymentLinks returns the payment links currently listed for a
+// studio. Payment link is the scraper's stable identity for a workshop
+// (UUIDs are regenerated on every refresh), so this set is what a refresh
+// diffs against to spot newly published workshops.
+func GetWorkshopPaymentLinks(cfg *config.Config, studioID string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	links, err := Collection(cfg, "workshops_v2").Distinct(ctx, "payment_link", bson.M{"studio_id": studioID})
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool, len(links))
+	for _, link := range links {
+		if s, ok := link.(string); ok {
+			set[s] = true
+		}
+	}
+	return set, nil
+}
+
+// RemoveWorkshopsGivenStudioId deletes every workshop for a studio, used by
+// the scraper before re-inserting a fresh snapshot.
+func RemoveWorkshopsGivenStudioId(cfg *config.Config, studioID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "workshops_v2").DeleteMany(ctx, bson.M{"studio_id": studioID})
+	return err
+}
+
+// InsertWorkshops bulk-inserts scraped workshop documents.
+func InsertWorkshops(cfg *config.Config, workshops []interface{}) error {
+	if len(workshops) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := Collection(cfg, "workshops_v2").InsertMany(ctx, workshops)
+	return err
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// benchmarkFixtures builds a synthetic workshop set shaped like a busy
+// production listing: n workshops, two sessions each, spread across a
+// handful of studios and artists. No Mongo involved — this exercises only
+// the in-memory expansion stage that dominates the workshops endpoint.
+func benchmarkFixtures(n int) ([]response.Workshop, map[string]response.Studio, map[string]response.Artist) {
+	studios := make(map[string]response.Studio)
+	artists := make(map[string]response.Artist)
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("studio-%d", i)
+		studios[id] = response.Studio{ID: id, Name: "Studio " + id}
+	}
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("artist-%d", i)
+		link := fmt.Sprintf("https://instagram.com/%s", id)
+		artists[id] = response.Artist{ID: id, Name: "Artist " + id, InstagramLink: link}
+	}
+
+	workshops := make([]response.Workshop, 0, n)
+	for i := 0; i < n; i++ {
+		day := intPtr(1 + i%28)
+		month := intPtr(1 + i%12)
+		year := intPtr(2026)
+		start := strPtr("06:00 PM")
+		end := strPtr("07:30 PM")
+		song := "Song Title"
+		workshops = append(workshops, response.Workshop{
+			UUID:         fmt.Sprintf("uuid-%d", i),
+			StudioID:     fmt.Sprintf("studio-%d", i%5),
+			Song:         &song,
+			ArtistIDList: []string{fmt.Sprintf("artist-%d", i%20), fmt.Sprintf("artist-%d", (i+1)%20)},
+			EventType:    "workshop",
+			TimeDetails: []response.TimeDetail{
+				{Day: day, Month: month, Year: year, StartTime: start, EndTime: end},
+				{Day: day, Month: month, Year: year, StartTime: start, EndTime: end},
+			},
+		})
+	}
+	return workshops, studios, artists
+}
+
+func intPtr(v int) *int       { return &v }
+func strPtr(v string) *string { return &v }
+
+// buildListItemsSequential is the pre-worker-pool implementation, kept here
+// as the benchmark baseline and as a correctness oracle for ordering.
+func buildListItemsSequential(workshops []response.Workshop, studios map[string]response.Studio, artists map[string]response.Artist, deadLinks map[string]bool) []response.WorkshopListItem {
+	var items []response.WorkshopListItem
+	for _, w := range workshops {
+		if w.EventType != "workshop" && w.EventType != "intensive" {
+			continue
+		}
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			items = append(items, buildListItem(w, td, studios, artists, deadLinks))
+		}
+	}
+	return items
+}
+
+// TestBuildListItemsOrdering checks the worker pool preserves the exact
+// output order of the sequential expansion.
+func TestBuildListItemsOrdering(t *testing.T) {
+	workshops, studios, artists := benchmarkFixtures(100)
+	got := buildListItems(workshops, studios, artists, nil)
+	want := buildListItemsSequential(workshops, studios, artists, nil)
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].UUID != want[i].UUID || *got[i].Date != *want[i].Date || *got[i].Time != *want[i].Time {
+			t.Fatalf("item %d differs: got %s %s, want %s %s", i, got[i].UUID, *got[i].Date, want[i].UUID, *want[i].Date)
+		}
+	}
+}
+
+func BenchmarkBuildListItems(b *testing.B) {
+	workshops, studios, artists := benchmarkFixtures(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildListItems(workshops, studios, artists, nil)
+	}
+}
+
+func BenchmarkBuildListItemsSequential(b *testing.B) {
+	workshops, studios, artists := benchmarkFixtures(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildListItemsSequential(workshops, studios, artists, nil)
+	}
+}
+
+// artistsWithSessionsPerArtist mirrors the replaced N+1 shape of the
+// hasWorkshops filter: one full workshop scan per artist. Kept as the
+// benchmark baseline for the single-pass set build.
+func artistsWithSessionsPerArtist(workshops []response.Workshop, artistIDs []string, now int64) map[string]bool {
+	active := map[string]bool{}
+	for _, artistID := range artistIDs {
+		for _, w := range workshops {
+			listed := false
+			for _, id := range w.ArtistIDList {
+				if id == artistID {
+					listed = true
+					break
+				}
+			}
+			if !listed {
+				continue
+			}
+			for _, td := range w.TimeDetails {
+				if td.Day == nil || td.Month == nil || td.Year == nil {
+					continue
+				}
+				if utils.GetTimestampEpoch(td) >= now {
+					active[artistID] = true
+					break
+				}
+			}
+			if active[artistID] {
+				break
+			}
+		}
+	}
+	return active
+}
+
+// TestCollectUpcomingArtistIDs checks the single-pass set matches the
+// per-artist baseline on the shared fixtures.
+func TestCollectUpcomingArtistIDs(t *testing.T) {
+	workshops, _, artists := benchmarkFixtures(200)
+	artistIDs := make([]string, 0, len(artists))
+	for id := range artists {
+		artistIDs = append(artistIDs, id)
+	}
+	got := collectUpcomingArtistIDs(workshops, 0)
+	want := artistsWithSessionsPerArtist(workshops, artistIDs, 0)
+	if len(got) != len(want) {
+		t.Fatalf("got %d active artists, want %d", len(got), len(want))
+	}
+	for id := range want {
+		if !got[id] {
+			t.Fatalf("artist %s missing from single-pass set", id)
+		}
+	}
+}
+
+func BenchmarkCollectUpcomingArtistIDs(b *testing.B) {
+	workshops, _, artists := benchmarkFixtures(500)
+	_ = artists
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		collectUpcomingArtistIDs(workshops, 0)
+	}
+}
+
+func BenchmarkArtistsWithSessionsPerArtist(b *testing.B) {
+	workshops, _, artists := benchmarkFixtures(500)
+	artistIDs := make([]string, 0, len(artists))
+	for id := range artists {
+		artistIDs = append(artistIDs, id)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		artistsWithSessionsPerArtist(workshops, artistIDs, 0)
+	}
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Sort options for the public workshop list endpoints. The default order
+// (first-session epoch) is preserved when sort is empty or unknown; other
+// keys reorder within each day bucket and the post-this-week tail so week
+// grouping stays intact.
+
+// SortCategorizedWorkshops reorders a categorized response in place by the
+// given key: price_asc, price_desc, popularity, or recently_added.
+func SortCategorizedWorkshops(cfg *config.Config, resp *response.CategorizedWorkshopResponse, sortKey string) {
+	less := itemLessFunc(cfg, sortKey)
+	if less == nil {
+		return
+	}
+	for i := range resp.ThisWeek {
+		items := resp.ThisWeek[i].Workshops
+		sort.SliceStable(items, func(a, b int) bool { return less(items[a], items[b]) })
+	}
+	items := resp.PostThisWeek
+	sort.SliceStable(items, func(a, b int) bool { return less(items[a], items[b]) })
+}
+
+// itemLessFunc builds the comparison for a sort key, or nil for the default
+// epoch order.
+func itemLessFunc(cfg *config.Config, sortKey string) func(a, b response.WorkshopListItem) bool {
+	switch sortKey {
+	case "price_asc":
+		return func(a, b response.WorkshopListItem) bool {
+			return itemMinPrice(a) < itemMinPrice(b)
+		}
+	case "price_desc":
+		return func(a, b response.WorkshopListItem) bool {
+			return itemMinPrice(a) > itemMinPrice(b)
+		}
+	case "popularity":
+		counts := workshopLikeCounts(cfg)
+		return func(a, b response.WorkshopListItem) bool {
+			return counts[a.UUID] > counts[b.UUID]
+		}
+	case "recently_added":
+		return func(a, b response.WorkshopListItem) bool {
+			return a.UpdatedAt > b.UpdatedAt
+		}
+	}
+	return nil
+}
+
+// itemMinPrice returns the stored numeric price, falling back to parsing
+// the free-text pricing blob for documents the backfill has not reached.
+// Workshops without a parseable price sort last in ascending order.
+func itemMinPrice(item response.WorkshopListItem) int64 {
+	if item.MinPrice > 0 {
+		return item.MinPrice
+	}
+	if item.PricingInfo == nil {
+		return 1 << 50
+	}
+	price := utils.ParseMinPrice(*item.PricingInfo)
+	if price <= 0 {
+		return 1 << 50
+	}
+	return price
+}
+
+// FilterCategorizedByPriceRange drops list items outside [minPrice,
+// maxPrice] in place. Zero bounds are open; workshops without a parsed
+// price are kept only when no lower bound is set.
+func FilterCategorizedByPriceRange(resp *response.CategorizedWorkshopResponse, minPrice, maxPrice int64) {
+	if minPrice <= 0 && maxPrice <= 0 {
+		return
+	}
+	inRange := func(item response.WorkshopListItem) bool {
+		price := item.MinPrice
+		if price <= 0 && item.PricingInfo != nil {
+			price = utils.ParseMinPrice(*item.PricingInfo)
+		}
+		if price <= 0 {
+			return minPrice <= 0
+		}
+		if minPrice > 0 && price < minPrice {
+			return false
+		}
+		if maxPrice > 0 && price > maxPrice {
+			return false
+		}
+		return true
+	}
+
+	thisWeek := resp.ThisWeek[:0]
+	for _, day := range resp.ThisWeek {
+		kept := day.Workshops[:0]
+		for _, item := range day.Workshops {
+			if inRange(item) {
+				kept = append(kept, item)
+			}
+		}
+		if len(kept) > 0 {
+			day.Workshops = kept
+			thisWeek = append(thisWeek, day)
+		}
+	}
+	resp.ThisWeek = thisWeek
+
+	post := resp.PostThisWeek[:0]
+	for _, item := range resp.PostThisWeek {
+		if inRange(item) {
+			post = append(post, item)
+		}
+	}
+	resp.PostThisWeek = post
+}
+
+// workshopLikeCounts aggregates live like counts per workshop UUID.
+func workshopLikeCounts(cfg *config.Config) map[string]int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	cursor, err := Collection(cfg, "reactions").Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"entity_type": "WORKSHOP", "reaction": "LIKE", "is_deleted": false}},
+		{"$group": bson.M{"_id": "$entity_id", "count": bson.M{"$sum": 1}}},
+	})
+	counts := make(map[string]int64)
+	if err != nil {
+		return counts
+	}
+	var rows []struct {
+		ID    string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return counts
+	}
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"dance_api_go/openapi"
+)
+
+// API documentation surface: the spec itself at /api/openapi.json plus a
+// Swagger UI page at /docs that renders it. The spec is generated from the
+// route registry in the openapi package, so there is nothing to hand-edit
+// here when endpoints change.
+
+// swaggerPage loads Swagger UI from its CDN and points it at our spec.
+const swaggerPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Nachna API Docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({
+      url: "/api/openapi.json",
+      dom_id: "#swagger-ui",
+      deepLinking: true,
+    });
+  </script>
+</body>
+</html>
+`
+
+// handleOpenAPISpec serves the generated OpenAPI 3 document.
+func handleOpenAPISpec(ctx *fasthttp.RequestCtx) {
+	writeJSON(ctx, fasthttp.StatusOK, openapi.Spec())
+}
+
+// handleDocs serves the Swagger UI page.
+func handleDocs(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.SetBodyString(swaggerPage)
+}
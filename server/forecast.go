@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+
+	"github.com/valyala/fasthttp"
+
+	"dance_api_go/services"
+)
+
+// handleStudioForecast serves the capacity forecast for one upcoming
+// workshop: GET ?workshop_uuid= returns the expected bookings blended from
+// historical fill per artist, studio, and time slot, so studios can pick a
+// room size before opening sales. The forecast is derived from order data,
+// so the route requires an admin bearer token.
+func handleStudioForecast(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	forecast, err := services.NewForecastService(cfg).ForecastWorkshop(workshopUUID)
+	if err != nil {
+		log.Printf("forecast failed for %s: %v", workshopUUID, err)
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, forecast)
+}
@@ -0,0 +1,107 @@
+// Package formatting centralizes the human-readable rendering of workshop
+// schedule data — date ordinals, session time ranges, and artist name joins
+// — so the API servers and the scraper render listings identically. The
+// behaviour mirrors utils/utils.py at the repository root, including its
+// "TBA" fallbacks.
+package formatting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dance_api_go/models/response"
+)
+
+// IST is the timezone all workshop schedules are interpreted in.
+var IST = time.FixedZone("IST", 5*3600+1800)
+
+// daySuffix returns the ordinal suffix for a day of month (1st, 2nd, 23rd...).
+func daySuffix(day int) string {
+	if day >= 11 && day <= 13 {
+		return "th"
+	}
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+// GetFormattedDate formats time details as e.g. "07th Feb (Thu)".
+func GetFormattedDate(td response.TimeDetail) string {
+	if td.Day == nil || td.Month == nil || td.Year == nil {
+		return ""
+	}
+	d := time.Date(*td.Year, time.Month(*td.Month), *td.Day, 0, 0, 0, 0, IST)
+	return fmt.Sprintf("%02d%s %s (%s)", d.Day(), daySuffix(d.Day()), d.Format("Jan"), d.Format("Mon"))
+}
+
+// GetFormattedDateWithDay returns the formatted date plus the full weekday
+// name (e.g. "Thursday") used for this-week day grouping.
+func GetFormattedDateWithDay(td response.TimeDetail) (string, string) {
+	if td.Day == nil || td.Month == nil || td.Year == nil {
+		return "", ""
+	}
+	d := time.Date(*td.Year, time.Month(*td.Month), *td.Day, 0, 0, 0, 0, IST)
+	return GetFormattedDate(td), d.Format("Monday")
+}
+
+// GetFormattedTime formats a session time range like "6-7:30 PM", matching
+// the Python get_formatted_time behaviour including the "TBA" fallbacks.
+func GetFormattedTime(td response.TimeDetail) string {
+	if td.StartTime == nil || *td.StartTime == "" {
+		return "TBA"
+	}
+	start := *td.StartTime
+	if !strings.Contains(start, " ") {
+		return "TBA"
+	}
+	parts := strings.SplitN(start, " ", 2)
+	startTime, startFormat := parts[0], parts[1]
+	if !strings.Contains(startTime, ":") {
+		return "TBA"
+	}
+	startStr := trimClock(startTime)
+
+	if td.EndTime == nil || *td.EndTime == "" {
+		return fmt.Sprintf("%s %s", startStr, startFormat)
+	}
+	end := *td.EndTime
+	if !strings.Contains(end, " ") {
+		return fmt.Sprintf("%s %s", startStr, startFormat)
+	}
+	parts = strings.SplitN(end, " ", 2)
+	endTime, endFormat := parts[0], parts[1]
+	if !strings.Contains(endTime, ":") {
+		return fmt.Sprintf("%s %s", startStr, startFormat)
+	}
+	endStr := trimClock(endTime)
+
+	if startFormat == endFormat {
+		return fmt.Sprintf("%s-%s %s", startStr, endStr, startFormat)
+	}
+	return fmt.Sprintf("%s %s - %s %s", startStr, startFormat, endStr, endFormat)
+}
+
+// trimClock strips leading zeros from "06:30" style clock strings, dropping
+// the minutes entirely when they are zero ("6").
+func trimClock(clock string) string {
+	pieces := strings.SplitN(clock, ":", 2)
+	hour := strings.TrimLeft(pieces[0], "0")
+	minute := strings.TrimLeft(pieces[1], "0")
+	if minute == "" {
+		return hour
+	}
+	return fmt.Sprintf("%s:%s", hour, minute)
+}
+
+// JoinArtistNames joins multiple artist names the way listings display them.
+func JoinArtistNames(names []string) string {
+	return strings.Join(names, " X ")
+}
@@ -0,0 +1,99 @@
+package formatting
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dance_api_go/models/response"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden file with the current output")
+
+func intPtr(v int) *int       { return &v }
+func strPtr(v string) *string { return &v }
+
+// session builds a TimeDetail; empty start/end strings become nil pointers.
+func session(day, month, year int, start, end string) response.TimeDetail {
+	td := response.TimeDetail{Day: intPtr(day), Month: intPtr(month), Year: intPtr(year)}
+	if start != "" {
+		td.StartTime = strPtr(start)
+	}
+	if end != "" {
+		td.EndTime = strPtr(end)
+	}
+	return td
+}
+
+// TestFormattingGolden renders every formatting edge case into one document
+// and compares it against testdata/formatting.golden. Run with -update to
+// regenerate the golden file after an intentional behaviour change.
+func TestFormattingGolden(t *testing.T) {
+	var buf bytes.Buffer
+	// Empty values render as a bare "name:" line so the golden file carries
+	// no trailing whitespace for editors to strip.
+	render := func(name, value string) {
+		buf.WriteString(name + ":")
+		if value != "" {
+			buf.WriteString(" " + value)
+		}
+		buf.WriteString("\n")
+	}
+
+	// Date ordinals, including the 11th-13th teens exception.
+	render("date_07th", GetFormattedDate(session(7, 2, 2026, "", "")))
+	render("date_02nd", GetFormattedDate(session(2, 6, 2024, "", "")))
+	render("date_03rd", GetFormattedDate(session(3, 6, 2024, "", "")))
+	render("date_11th", GetFormattedDate(session(11, 6, 2024, "", "")))
+	render("date_12th", GetFormattedDate(session(12, 6, 2024, "", "")))
+	render("date_13th", GetFormattedDate(session(13, 6, 2024, "", "")))
+	render("date_21st", GetFormattedDate(session(21, 6, 2024, "", "")))
+	render("date_31st", GetFormattedDate(session(31, 12, 2024, "", "")))
+	render("date_missing_year", GetFormattedDate(response.TimeDetail{Day: intPtr(7), Month: intPtr(2)}))
+
+	date, weekday := GetFormattedDateWithDay(session(7, 2, 2026, "", ""))
+	render("date_with_day", date+" / "+weekday)
+
+	// Time ranges: same and crossing meridiems, midnight, TBA fallbacks.
+	render("time_same_meridiem", GetFormattedTime(session(7, 2, 2026, "6:00 PM", "7:30 PM")))
+	render("time_zero_minutes", GetFormattedTime(session(7, 2, 2026, "06:30 PM", "08:00 PM")))
+	render("time_midnight_no_end", GetFormattedTime(session(7, 2, 2026, "12:00 AM", "")))
+	render("time_cross_meridiem", GetFormattedTime(session(7, 2, 2026, "11:30 AM", "1:30 PM")))
+	render("time_over_midnight", GetFormattedTime(session(7, 2, 2026, "10:00 PM", "12:30 AM")))
+	render("time_missing_start", GetFormattedTime(session(7, 2, 2026, "", "7:30 PM")))
+	render("time_start_no_meridiem", GetFormattedTime(session(7, 2, 2026, "evening", "")))
+	render("time_start_no_colon", GetFormattedTime(session(7, 2, 2026, "6 PM", "")))
+	render("time_unparseable_end", GetFormattedTime(session(7, 2, 2026, "6:00 PM", "late")))
+
+	// Artist joins.
+	render("join_two_artists", JoinArtistNames([]string{"Alok", "Mitra"}))
+	render("join_single_artist", JoinArtistNames([]string{"Solo"}))
+	render("join_no_artists", JoinArtistNames(nil))
+
+	// Multi-day event: each session renders independently.
+	multiDay := []response.TimeDetail{
+		session(14, 6, 2024, "6:00 PM", "8:00 PM"),
+		session(15, 6, 2024, "11:00 AM", "1:00 PM"),
+	}
+	for i, td := range multiDay {
+		render(fmt.Sprintf("multi_day_%d", i+1),
+			GetFormattedDate(td)+" "+GetFormattedTime(td))
+	}
+
+	goldenPath := filepath.Join("testdata", "formatting.golden")
+	if *update {
+		if err := os.WriteFile(goldenPath, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("formatting output differs from golden file\n--- got ---\n%s--- want ---\n%s", buf.Bytes(), want)
+	}
+}
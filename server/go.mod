@@ -0,0 +1,29 @@
+module dance_api_go
+
+go 1.21
+
+require (
+	github.com/chai2010/webp v1.1.1
+	github.com/golang-jwt/jwt/v5 v5.2.0
+	github.com/google/uuid v1.5.0
+	github.com/graphql-go/graphql v0.8.1
+	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
+	github.com/sashabaranov/go-openai v1.17.9
+	github.com/valyala/fasthttp v1.51.0
+	go.mongodb.org/mongo-driver v1.13.1
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.5 // indirect
+	github.com/golang/snappy v0.0.1 // indirect
+	github.com/klauspost/compress v1.17.0 // indirect
+	github.com/montanaflynn/stats v0.0.0-20171201202039-1bf9dbcd8cbe // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20181117223130-1be2e3e5546d // indirect
+	golang.org/x/crypto v0.14.0 // indirect
+	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4 // indirect
+	golang.org/x/text v0.13.0 // indirect
+)
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+
+	"github.com/chai2010/webp"
+	"github.com/nfnt/resize"
+	"github.com/valyala/fasthttp"
+)
+
+// Image variants for the proxy: mobile clients render artist and studio
+// thumbnails at a fraction of the origin size, so ?w=400&h=400&format=webp
+// lets them pull exactly the pixels they display. Resizing preserves aspect
+// ratio (w/h are maximum bounds) and each variant is cached separately.
+
+// imageVariantMaxDimension bounds requested dimensions so the proxy cannot
+// be made to upscale into huge allocations.
+const imageVariantMaxDimension = 2000
+
+// jpegVariantQuality and webpVariantQuality balance thumbnail size against
+// visible artifacts.
+const (
+	jpegVariantQuality = 85
+	webpVariantQuality = 80
+)
+
+// imageVariant is a requested transformation of the origin image.
+type imageVariant struct {
+	Width  int
+	Height int
+	Format string
+}
+
+// parseImageVariant reads the transform parameters off the request.
+func parseImageVariant(ctx *fasthttp.RequestCtx) (imageVariant, error) {
+	var v imageVariant
+	var err error
+	if v.Width, err = parseDimension(ctx, "w"); err != nil {
+		return v, err
+	}
+	if v.Height, err = parseDimension(ctx, "h"); err != nil {
+		return v, err
+	}
+	switch format := string(ctx.QueryArgs().Peek("format")); format {
+	case "", "jpeg", "jpg", "png", "webp":
+		v.Format = format
+	default:
+		return v, fmt.Errorf("unsupported format: %s", format)
+	}
+	return v, nil
+}
+
+// parseDimension parses one of the w/h parameters, 0 when absent.
+func parseDimension(ctx *fasthttp.RequestCtx, name string) (int, error) {
+	raw := string(ctx.QueryArgs().Peek(name))
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 1 || value > imageVariantMaxDimension {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, imageVariantMaxDimension)
+	}
+	return value, nil
+}
+
+// isOriginal reports whether the variant requests no transformation.
+func (v imageVariant) isOriginal() bool {
+	return v.Width == 0 && v.Height == 0 && v.Format == ""
+}
+
+// cacheSuffix distinguishes this variant's disk cache entry from the
+// original's.
+func (v imageVariant) cacheSuffix() string {
+	if v.isOriginal() {
+		return ""
+	}
+	return fmt.Sprintf("|w=%d|h=%d|format=%s", v.Width, v.Height, v.Format)
+}
+
+// transformImage decodes, resizes, and re-encodes the image per the variant.
+func transformImage(body []byte, v imageVariant) ([]byte, string, error) {
+	img, originalFormat, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if v.Width > 0 || v.Height > 0 {
+		// Thumbnail treats the dimensions as maximum bounds and preserves
+		// aspect ratio; a single dimension bounds that axis only.
+		width, height := uint(v.Width), uint(v.Height)
+		if width == 0 {
+			width = imageVariantMaxDimension
+		}
+		if height == 0 {
+			height = imageVariantMaxDimension
+		}
+		img = resize.Thumbnail(width, height, img, resize.Lanczos3)
+	}
+
+	format := v.Format
+	if format == "" {
+		format = originalFormat
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg", "jpg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegVariantQuality})
+		return buf.Bytes(), "image/jpeg", err
+	case "png":
+		err = png.Encode(&buf, img)
+		return buf.Bytes(), "image/png", err
+	case "webp":
+		err = webp.Encode(&buf, img, &webp.Options{Quality: webpVariantQuality})
+		return buf.Bytes(), "image/webp", err
+	default:
+		// Resized but keeping an origin format we cannot encode (e.g. gif):
+		// fall back to JPEG rather than failing the request.
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegVariantQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+}
@@ -0,0 +1,1748 @@
+// dance_api_go is the fasthttp-based Golang API server for Nachna. It serves
+// the public workshop/artist/studio APIs with response caching, the legacy
+// admin CRUD endpoints, the image proxy, and the web templates, and starts
+// the mux server for authenticated user routes alongside.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/ai"
+	"dance_api_go/api"
+	"dance_api_go/application"
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/middleware"
+	"dance_api_go/models/request"
+	"dance_api_go/models/response"
+	"dance_api_go/queryopts"
+	"dance_api_go/services"
+	"dance_api_go/studios"
+	"dance_api_go/utils"
+)
+
+var cfg *config.Config
+
+var proxyClient = &http.Client{Timeout: 20 * time.Second}
+
+func main() {
+	var err error
+	cfg, err = config.ParseArgs()
+	if err != nil {
+		log.Fatalf("config error: %v", err)
+	}
+
+	adminAuth = services.NewAuthService(cfg)
+
+	if err := database.Connect(cfg); err != nil {
+		log.Printf("MongoDB unavailable at startup, serving in degraded mode: %v", err)
+	}
+	database.StartReconnectLoop(cfg)
+	go database.EnsureIndexes(cfg)
+	database.StartInvalidationBus(cfg)
+	database.StartChangeStreamWatchers(cfg)
+	services.StartEventStream(cfg)
+	application.StartBackgroundJobs(cfg)
+	go func() {
+		for !database.IsReady() {
+			time.Sleep(5 * time.Second)
+		}
+		loadBlocklist()
+		loadMaintenanceState()
+	}()
+
+	// Non-prod builds record sanitized request/response traffic on both
+	// servers for cmd/replay.
+	var recorder *services.RequestRecorder
+	if cfg.Env != "prod" {
+		if recorder, err = services.NewRequestRecorder(cfg.RecordingsDir); err != nil {
+			log.Printf("request recorder disabled: %v", err)
+			recorder = nil
+		}
+	}
+
+	// Authenticated user routes stay on the mux server during the fasthttp
+	// migration.
+	mux := http.NewServeMux()
+	api.NewServer(cfg).RegisterRoutes(mux)
+	var muxHandler http.Handler = mux
+	if recorder != nil {
+		muxHandler = recorder.WrapHTTP(muxHandler)
+	}
+	go func() {
+		muxServer := &http.Server{
+			Addr:         ":" + strconv.Itoa(cfg.MuxPort),
+			Handler:      muxHandler,
+			ReadTimeout:  cfg.ServerReadTimeout,
+			WriteTimeout: cfg.ServerWriteTimeout,
+		}
+		log.Printf("mux server listening on :%d", cfg.MuxPort)
+		if err := muxServer.ListenAndServe(); err != nil {
+			log.Fatalf("mux server error: %v", err)
+		}
+	}()
+
+	fastHandler := corsMiddleware(router)
+	if recorder != nil {
+		fastHandler = recordMiddleware(recorder, fastHandler)
+	}
+	server := &fasthttp.Server{
+		Handler:      fastHandler,
+		ReadTimeout:  cfg.ServerReadTimeout,
+		WriteTimeout: cfg.ServerWriteTimeout,
+	}
+	log.Printf("dance_api_go listening on :%d", cfg.HTTPPort)
+	if err := server.ListenAndServe(":" + strconv.Itoa(cfg.HTTPPort)); err != nil {
+		log.Fatalf("fasthttp server error: %v", err)
+	}
+}
+
+// router dispatches fasthttp requests by path.
+func router(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	if enabled, message := maintenanceActive(); enabled && !maintenanceExemptPath(path) {
+		serveMaintenance(ctx, message)
+		return
+	}
+	switch {
+	case path == "/":
+		allowMethods(handleHome, fasthttp.MethodGet)(ctx)
+	case path == "/health":
+		allowMethods(handleHealth, fasthttp.MethodGet)(ctx)
+	case path == "/api/version":
+		allowMethods(handleVersion, fasthttp.MethodGet)(ctx)
+	case path == "/llms.txt":
+		allowMethods(handleLlmsTxt, fasthttp.MethodGet)(ctx)
+	case path == "/api/openapi.json":
+		allowMethods(handleOpenAPISpec, fasthttp.MethodGet)(ctx)
+	case path == "/docs":
+		allowMethods(handleDocs, fasthttp.MethodGet)(ctx)
+	case path == "/api/structured/workshops":
+		publicGet(requireMongo(handleStructuredWorkshops))(ctx)
+	case path == "/api/search":
+		publicGet(requireMongo(handleSearch))(ctx)
+	case path == "/api/workshops":
+		publicGet(requireMongo(handleGetWorkshops))(ctx)
+	case path == "/api/workshops/nearby":
+		publicGet(requireMongo(handleNearbyWorkshops))(ctx)
+	case hasPrefix(path, "/api/workshops/") && strings.HasSuffix(path, "/ical"):
+		rawGetChain.Then(requireMongo(handleWorkshopICal))(ctx)
+	case hasPrefix(path, "/api/artists/") && strings.HasSuffix(path, "/calendar.ics"):
+		rawGetChain.Then(requireMongo(handleArtistCalendar))(ctx)
+	case hasPrefix(path, "/api/studios/") && strings.HasSuffix(path, "/calendar.ics"):
+		rawGetChain.Then(requireMongo(handleStudioCalendar))(ctx)
+	case path == "/api/artists":
+		publicGet(requireMongo(handleGetArtists))(ctx)
+	case hasPrefix(path, "/api/artists/") && strings.HasSuffix(path, "/past-workshops"):
+		publicGet(requireMongo(handleGetPastWorkshops))(ctx)
+	case hasPrefix(path, "/api/artists/") && strings.HasSuffix(path, "/recaps"):
+		publicGet(requireMongo(handleArtistRecaps))(ctx)
+	case hasPrefix(path, "/api/studios/") && strings.HasSuffix(path, "/recaps"):
+		publicGet(requireMongo(handleStudioRecaps))(ctx)
+	case hasPrefix(path, "/api/recap/") && strings.HasSuffix(path, "/video"):
+		rawGetChain.Then(requireMongo(handleRecapVideo))(ctx)
+	case path == "/api/studios":
+		publicGet(requireMongo(handleGetStudios))(ctx)
+	case hasPrefix(path, "/api/studios/"):
+		publicGet(requireMongo(handleGetStudioDetail))(ctx)
+	case hasPrefix(path, "/api/workshops_by_artist/"):
+		publicGet(requireMongo(handleGetWorkshopsByArtist))(ctx)
+	case hasPrefix(path, "/api/workshop/"):
+		publicGet(requireMongo(handleGetWorkshopDetail))(ctx)
+	case hasPrefix(path, "/api/workshops_by_song/"):
+		publicGet(requireMongo(handleGetWorkshopsBySong))(ctx)
+	case hasPrefix(path, "/api/workshops_by_studio/"):
+		publicGet(requireMongo(handleGetWorkshopsByStudio))(ctx)
+	case path == "/api/internal/export":
+		// Honeypot: not linked from any client; only crawlers find it.
+		handleHoneypot(ctx)
+	case hasPrefix(path, "/ticket/"):
+		rawGetChain.Then(requireMongo(handleTicketPage))(ctx)
+	case hasPrefix(path, "/proxy-image/"):
+		rawGetChain.Then(handleProxyImage)(ctx)
+	// The studio portal routes serve order-derived data, so they carry
+	// the same admin auth as /admin/api/ rather than answering anonymous
+	// callers.
+	case path == "/studio/api/schedule-diff":
+		allowMethods(requireMongo(utils.ValidateCSRFFast(requireAdmin(handleScheduleDiff))), fasthttp.MethodPost)(ctx)
+	case path == "/studio/api/forecast":
+		allowMethods(requireMongo(requireAdmin(handleStudioForecast)), fasthttp.MethodGet)(ctx)
+	case path == "/studio/api/kiosk-token":
+		allowMethods(requireMongo(utils.ValidateCSRFFast(requireAdmin(handleIssueKioskToken))), fasthttp.MethodPost)(ctx)
+	case path == "/kiosk/api/checkin":
+		allowMethods(requireMongo(handleKioskCheckin), fasthttp.MethodPost)(ctx)
+	case hasPrefix(path, "/admin/api/"):
+		requireMongo(utils.ValidateCSRFFast(requireAdmin(handleAdmin)))(ctx)
+	case hasPrefix(path, "/static/"):
+		allowMethods(handleStatic, fasthttp.MethodGet)(ctx)
+	default:
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Not found")
+	}
+}
+
+// fastMethods gates a fasthttp handler to the given methods, chain-shaped.
+func fastMethods(allowed ...string) middleware.Middleware[fasthttp.RequestHandler] {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return allowMethods(next, allowed...)
+	}
+}
+
+// Route groups for the fasthttp server, declared once as middleware chains.
+// publicGetChain is what every public GET data endpoint goes through:
+// method gating, rate limiting, anti-scrape throttling, and the response
+// cache (the MongoDB readiness gate is supplied per route). rawGetChain
+// serves the non-JSON GET endpoints — calendars, ticket pages, media — that
+// must skip the response cache.
+var (
+	publicGetChain = middleware.New(fastMethods(fasthttp.MethodGet), rateLimitMiddleware, antiScrapeMiddleware, cacheMiddleware)
+	rawGetChain    = middleware.New(fastMethods(fasthttp.MethodGet), rateLimitMiddleware)
+)
+
+// publicGet applies the public GET route group.
+func publicGet(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return publicGetChain.Then(next)
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
+
+// corsMiddleware adds CORS headers and answers preflight requests. With
+// CORS_ALLOWED_ORIGINS set only listed origins are allowed (echoed back
+// with Vary: Origin); unset keeps the historical wildcard.
+func corsMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if origin, ok := corsOriginFor(string(ctx.Request.Header.Peek("Origin"))); ok {
+			ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
+			if origin != "*" {
+				ctx.Response.Header.Set("Vary", "Origin")
+			}
+		}
+		ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		ctx.Response.Header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-User-ID")
+		if string(ctx.Method()) == fasthttp.MethodOptions {
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// corsOriginFor resolves the Allow-Origin value for a request origin: the
+// wildcard when no origins are configured, the echoed origin when it is on
+// the configured list, nothing otherwise.
+func corsOriginFor(origin string) (string, bool) {
+	if len(cfg.CORSAllowedOrigins) == 0 {
+		return "*", true
+	}
+	for _, allowed := range cfg.CORSAllowedOrigins {
+		if allowed == origin {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// cacheMiddleware serves GET responses from the in-memory response cache.
+// TTLs come from config: CACHE_TTL_SECONDS by default, overridable per
+// path via CACHE_TTL_OVERRIDES without code changes.
+func cacheMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ttl := cfg.CacheTTLFor(string(ctx.Path()))
+		key := string(ctx.Path())
+		if len(ctx.URI().QueryString()) > 0 {
+			key += "?" + string(ctx.URI().QueryString())
+		}
+		ctx.Response.Header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(ttl/time.Second)))
+		if body, contentType, etag, ok := utils.GetCachedResponse(key); ok {
+			if writeNotModified(ctx, etag) {
+				return
+			}
+			ctx.Response.Header.Set("ETag", etag)
+			ctx.SetContentType(contentType)
+			ctx.SetBody(body)
+			return
+		}
+		next(ctx)
+		if ctx.Response.StatusCode() == fasthttp.StatusOK {
+			etag := utils.SetCachedResponse(key, append([]byte(nil), ctx.Response.Body()...),
+				string(ctx.Response.Header.ContentType()), ttl)
+			if writeNotModified(ctx, etag) {
+				return
+			}
+			ctx.Response.Header.Set("ETag", etag)
+		}
+	}
+}
+
+// writeNotModified answers with an empty 304 when the client's If-None-Match
+// already names the current ETag, and reports whether it did.
+func writeNotModified(ctx *fasthttp.RequestCtx, etag string) bool {
+	if string(ctx.Request.Header.Peek("If-None-Match")) != etag {
+		return false
+	}
+	ctx.Response.ResetBody()
+	ctx.SetStatusCode(fasthttp.StatusNotModified)
+	ctx.Response.Header.Set("ETag", etag)
+	return true
+}
+
+// handleHealth reports liveness plus MongoDB readiness.
+func handleHealth(ctx *fasthttp.RequestCtx) {
+	if !database.IsReady() {
+		writeJSON(ctx, fasthttp.StatusServiceUnavailable, map[string]string{"status": "degraded", "mongodb": "down"})
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]string{"status": "ok", "mongodb": "up"})
+}
+
+// requireMongo gates data endpoints behind MongoDB readiness: while the
+// database is unreachable they return 503 with a retry hint instead of
+// panicking on a nil client. Static and web routes are not gated.
+func requireMongo(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if !database.IsReady() {
+			ctx.Response.Header.Set("Retry-After", "10")
+			writeJSON(ctx, fasthttp.StatusServiceUnavailable, map[string]string{
+				"detail": "Service temporarily unavailable, please retry shortly",
+			})
+			return
+		}
+		next(ctx)
+	}
+}
+
+func writeJSON(ctx *fasthttp.RequestCtx, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+	ctx.SetStatusCode(status)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(body)
+}
+
+func writeJSONError(ctx *fasthttp.RequestCtx, status int, detail string) {
+	writeJSON(ctx, status, map[string]string{"detail": detail})
+}
+
+func handleGetWorkshops(ctx *fasthttp.RequestCtx) {
+	when := string(ctx.QueryArgs().Peek("when"))
+	if opts := queryopts.FromFastHTTP(ctx.QueryArgs()); opts.Requested {
+		page, err := database.GetWorkshopsPage(cfg, when, opts.Sort,
+			queryInt64(ctx, "min_price"), queryInt64(ctx, "max_price"), opts.Limit, opts.Offset)
+		if err != nil {
+			log.Printf("GetWorkshopsPage failed: %v", err)
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch workshops")
+			return
+		}
+		writeJSON(ctx, fasthttp.StatusOK, page)
+		return
+	}
+	data, err := database.GetWorkshopsFiltered(cfg, database.WorkshopFilters{
+		When:      when,
+		StartDate: string(ctx.QueryArgs().Peek("start_date")),
+		EndDate:   string(ctx.QueryArgs().Peek("end_date")),
+		Style:     string(ctx.QueryArgs().Peek("style")),
+		MinPrice:  queryInt64(ctx, "min_price"),
+		MaxPrice:  queryInt64(ctx, "max_price"),
+	})
+	if err != nil {
+		log.Printf("GetWorkshops failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	database.SortCategorizedWorkshops(cfg, &data, string(ctx.QueryArgs().Peek("sort")))
+	writeJSON(ctx, fasthttp.StatusOK, data)
+}
+
+// nearbyDefaultRadiusKm and nearbyMaxRadiusKm bound the geo-search radius:
+// the default covers a city neighbourhood, the cap keeps $geoNear from
+// scanning the whole studio set.
+const (
+	nearbyDefaultRadiusKm = 10.0
+	nearbyMaxRadiusKm     = 100.0
+)
+
+// handleNearbyWorkshops serves GET /api/workshops/nearby?lat=..&lng=..
+// [&radius_km=..]: upcoming workshops at studios within the radius, sorted
+// nearest first with the distance to each studio included.
+func handleNearbyWorkshops(ctx *fasthttp.RequestCtx) {
+	lat, latErr := strconv.ParseFloat(string(ctx.QueryArgs().Peek("lat")), 64)
+	lng, lngErr := strconv.ParseFloat(string(ctx.QueryArgs().Peek("lng")), 64)
+	if latErr != nil || lngErr != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "lat and lng query parameters are required")
+		return
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "lat/lng out of range")
+		return
+	}
+	radiusKm := nearbyDefaultRadiusKm
+	if raw := string(ctx.QueryArgs().Peek("radius_km")); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, "radius_km must be a positive number")
+			return
+		}
+		radiusKm = parsed
+	}
+	if radiusKm > nearbyMaxRadiusKm {
+		radiusKm = nearbyMaxRadiusKm
+	}
+	result, err := database.GetNearbyWorkshops(cfg, lat, lng, radiusKm)
+	if err != nil {
+		log.Printf("GetNearbyWorkshops failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch nearby workshops")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, result)
+}
+
+// queryInt64 parses an integer query parameter, returning 0 when absent or
+// malformed.
+func queryInt64(ctx *fasthttp.RequestCtx, name string) int64 {
+	n, err := strconv.ParseInt(string(ctx.QueryArgs().Peek(name)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func handleGetArtists(ctx *fasthttp.RequestCtx) {
+	hasWorkshops := string(ctx.QueryArgs().Peek("has_workshops")) == "true"
+	if opts := queryopts.FromFastHTTP(ctx.QueryArgs()); opts.Requested {
+		page, err := database.GetArtistsPage(cfg, hasWorkshops, opts.Limit, opts.Offset)
+		if err != nil {
+			log.Printf("GetArtistsPage failed: %v", err)
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch artists")
+			return
+		}
+		writeJSON(ctx, fasthttp.StatusOK, page)
+		return
+	}
+	artists, err := database.GetAllArtistsFromDB(cfg, hasWorkshops)
+	if err != nil {
+		log.Printf("GetAllArtistsFromDB failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch artists")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, artists)
+}
+
+func handleGetStudios(ctx *fasthttp.RequestCtx) {
+	if opts := queryopts.FromFastHTTP(ctx.QueryArgs()); opts.Requested {
+		page, err := database.GetStudiosPage(cfg, opts.Limit, opts.Offset)
+		if err != nil {
+			log.Printf("GetStudiosPage failed: %v", err)
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch studios")
+			return
+		}
+		writeJSON(ctx, fasthttp.StatusOK, page)
+		return
+	}
+	studioList, err := database.GetStudios(cfg)
+	if err != nil {
+		log.Printf("GetStudios failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch studios")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, studioList)
+}
+
+// handleGetStudioDetail serves one studio's full profile — including
+// address, coordinates, and amenities — at /api/studios/{studio_id}.
+func handleGetStudioDetail(ctx *fasthttp.RequestCtx) {
+	studioID := strings.TrimPrefix(string(ctx.Path()), "/api/studios/")
+	if studioID == "" || strings.Contains(studioID, "/") {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Studio not found")
+		return
+	}
+	studio, err := database.GetStudioByID(cfg, studioID)
+	if err != nil {
+		log.Printf("GetStudioByID failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch studio")
+		return
+	}
+	if studio == nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Studio not found")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, studio)
+}
+
+func handleGetWorkshopsByArtist(ctx *fasthttp.RequestCtx) {
+	artistID := string(ctx.Path())[len("/api/workshops_by_artist/"):]
+	when := string(ctx.QueryArgs().Peek("when"))
+	sessions, err := database.GetWorkshopsByArtistWhen(cfg, artistID, when)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, sessions)
+}
+
+func handleGetWorkshopsByStudio(ctx *fasthttp.RequestCtx) {
+	studioID := string(ctx.Path())[len("/api/workshops_by_studio/"):]
+	when := string(ctx.QueryArgs().Peek("when"))
+	data, err := database.GetWorkshopsByStudioWhen(cfg, studioID, when)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	database.FilterCategorizedByPriceRange(&data, queryInt64(ctx, "min_price"), queryInt64(ctx, "max_price"))
+	database.SortCategorizedWorkshops(cfg, &data, string(ctx.QueryArgs().Peek("sort")))
+	writeJSON(ctx, fasthttp.StatusOK, data)
+}
+
+// handleGetWorkshopDetail serves one workshop by uuid with related
+// suggestions (same artist, same studio same weekend, same song).
+func handleGetWorkshopDetail(ctx *fasthttp.RequestCtx) {
+	uuid := string(ctx.Path())[len("/api/workshop/"):]
+	if uuid == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "uuid is required")
+		return
+	}
+	detail, err := database.GetWorkshopDetail(cfg, uuid)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Workshop not found")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, detail)
+}
+
+// writeICS serves a rendered iCalendar document with the calendar MIME type.
+func writeICS(ctx *fasthttp.RequestCtx, filename, calendar string) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.Response.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	ctx.SetBodyString(calendar)
+}
+
+// handleWorkshopICal serves one workshop's sessions as an iCalendar file.
+func handleWorkshopICal(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	uuid := strings.TrimSuffix(strings.TrimPrefix(path, "/api/workshops/"), "/ical")
+	if uuid == "" || strings.Contains(uuid, "/") {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "uuid is required")
+		return
+	}
+	calendar, err := services.RenderWorkshopICS(cfg, uuid)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Workshop not found")
+		return
+	}
+	writeICS(ctx, uuid+".ics", calendar)
+}
+
+// handleArtistCalendar serves an artist's upcoming workshops as a
+// subscribable calendar feed.
+func handleArtistCalendar(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	artistID := strings.TrimSuffix(strings.TrimPrefix(path, "/api/artists/"), "/calendar.ics")
+	if artistID == "" || strings.Contains(artistID, "/") {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "artist_id is required")
+		return
+	}
+	calendar, err := services.RenderArtistICS(cfg, artistID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to render calendar")
+		return
+	}
+	writeICS(ctx, artistID+".ics", calendar)
+}
+
+// handleStudioCalendar serves a studio's upcoming workshops as a
+// subscribable calendar feed.
+func handleStudioCalendar(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	studioID := strings.TrimSuffix(strings.TrimPrefix(path, "/api/studios/"), "/calendar.ics")
+	if studioID == "" || strings.Contains(studioID, "/") {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	calendar, err := services.RenderStudioICS(cfg, studioID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to render calendar")
+		return
+	}
+	writeICS(ctx, studioID+".ics", calendar)
+}
+
+// handleGetPastWorkshops serves an artist's historical sessions, paginated.
+func handleGetPastWorkshops(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	artistID := strings.TrimSuffix(strings.TrimPrefix(path, "/api/artists/"), "/past-workshops")
+	if artistID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "artist_id is required")
+		return
+	}
+	page, err := database.GetPastWorkshopsByArtist(cfg, artistID,
+		int(queryInt64(ctx, "page")), int(queryInt64(ctx, "page_size")))
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch past workshops")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, page)
+}
+
+// handleArtistRecaps lists an artist's recap videos for their profile.
+func handleArtistRecaps(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	artistID := strings.TrimSuffix(strings.TrimPrefix(path, "/api/artists/"), "/recaps")
+	if artistID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "artist_id is required")
+		return
+	}
+	recaps, err := services.GetRecapsByArtist(cfg, artistID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch recaps")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, recaps)
+}
+
+// handleStudioRecaps lists a studio's recap videos for its profile.
+func handleStudioRecaps(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	studioID := strings.TrimSuffix(strings.TrimPrefix(path, "/api/studios/"), "/recaps")
+	if studioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	recaps, err := services.GetRecapsByStudio(cfg, studioID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch recaps")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, recaps)
+}
+
+// handleRecapVideo streams a stored recap's bytes; link-only recaps have
+// no stored video and 404.
+func handleRecapVideo(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	recapID := strings.TrimSuffix(strings.TrimPrefix(path, "/api/recap/"), "/video")
+	if recapID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "recap_id is required")
+		return
+	}
+	video, contentType, err := services.GetRecapVideo(cfg, recapID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch recap video")
+		return
+	}
+	if video == nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Recap video not found")
+		return
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType(contentType)
+	ctx.SetBody(video)
+}
+
+func handleGetWorkshopsBySong(ctx *fasthttp.RequestCtx) {
+	song, err := url.PathUnescape(string(ctx.Path())[len("/api/workshops_by_song/"):])
+	if err != nil || song == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "song is required")
+		return
+	}
+	sessions, err := database.GetWorkshopsBySong(cfg, song)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch workshops")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, sessions)
+}
+
+// handleSearch runs the unified search across workshops, artists and
+// studios; results come back faceted by entity type.
+func handleSearch(ctx *fasthttp.RequestCtx) {
+	query := string(ctx.QueryArgs().Peek("q"))
+	if strings.TrimSpace(query) == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "q is required")
+		return
+	}
+	results, err := database.SearchAll(cfg, query)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Search failed")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, results)
+}
+
+// handleHome serves the landing page template (embedded or from disk).
+func handleHome(ctx *fasthttp.RequestCtx) {
+	body, err := readTemplate("website.html")
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Not found")
+		return
+	}
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.SetBody(body)
+}
+
+// handleStatic serves files under static/ (embedded or from disk).
+func handleStatic(ctx *fasthttp.RequestCtx) {
+	requestPath := string(ctx.Path())
+	body, err := readStaticFile(requestPath)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Not found")
+		return
+	}
+	ctx.SetContentType(staticContentType(requestPath))
+	ctx.SetBody(body)
+}
+
+// handleScheduleDiff diffs a studio's uploaded CSV schedule against what
+// Nachna lists. POST with ?studio_id= and the CSV as the request body;
+// requires an admin bearer token like the rest of the studio portal.
+func handleScheduleDiff(ctx *fasthttp.RequestCtx) {
+	studioID := string(ctx.QueryArgs().Peek("studio_id"))
+	if studioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	body := ctx.PostBody()
+	// Multipart uploads carry the sheet in the "schedule" file field.
+	if form, err := ctx.MultipartForm(); err == nil {
+		if files := form.File["schedule"]; len(files) > 0 {
+			f, err := files[0].Open()
+			if err == nil {
+				uploaded, readErr := io.ReadAll(f)
+				f.Close()
+				if readErr == nil {
+					body = uploaded
+				}
+			}
+		}
+	}
+	if len(body) == 0 {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "CSV body is required")
+		return
+	}
+	diff, err := database.DiffStudioSchedule(cfg, studioID, body)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, diff)
+}
+
+// adminRouteMethods maps admin paths dispatched by method in handleAdmin to
+// their allowed method sets, so a known path with the wrong method gets a
+// 405 with Allow instead of a 404. Paths whose handlers dispatch on method
+// themselves (blocklist, maintenance) are not listed.
+var adminRouteMethods = map[string][]string{
+	"/admin/api/index-report":            {fasthttp.MethodGet},
+	"/admin/api/app-insights":            {fasthttp.MethodGet},
+	"/admin/api/revenue":                 {fasthttp.MethodGet},
+	"/admin/api/snapshot":                {fasthttp.MethodGet},
+	"/admin/api/ai-decision":             {fasthttp.MethodGet},
+	"/admin/api/ai-metrics":              {fasthttp.MethodGet},
+	"/admin/api/retention":               {fasthttp.MethodGet},
+	"/admin/api/announcement":            {fasthttp.MethodPost, fasthttp.MethodPut, fasthttp.MethodDelete},
+	"/admin/api/streaming-link":          {fasthttp.MethodPost, fasthttp.MethodDelete},
+	"/admin/api/stream-joins":            {fasthttp.MethodGet},
+	"/admin/api/recap":                   {fasthttp.MethodPost, fasthttp.MethodDelete},
+	"/admin/api/recap/upload":            {fasthttp.MethodPost},
+	"/admin/api/studio":                  {fasthttp.MethodPost, fasthttp.MethodPut, fasthttp.MethodDelete},
+	"/admin/api/studio/restore":          {fasthttp.MethodPost},
+	"/admin/api/artist":                  {fasthttp.MethodPost, fasthttp.MethodPut, fasthttp.MethodDelete},
+	"/admin/api/artist/restore":          {fasthttp.MethodPost},
+	"/admin/api/workshop":                {fasthttp.MethodDelete},
+	"/admin/api/workshop/restore":        {fasthttp.MethodPost},
+	"/admin/api/workshop/price-tiers":    {fasthttp.MethodPost},
+	"/admin/api/pass-product":            {fasthttp.MethodGet, fasthttp.MethodPost},
+	"/admin/api/audit-logs":              {fasthttp.MethodGet},
+	"/admin/api/cache/stats":             {fasthttp.MethodGet},
+	"/admin/api/scraper/health":          {fasthttp.MethodGet},
+	"/admin/api/scraper/enable":          {fasthttp.MethodPost},
+	"/admin/api/workshops/import":        {fasthttp.MethodPost},
+	"/admin/api/workshops/export":        {fasthttp.MethodGet},
+	"/admin/api/workshops/refresh":       {fasthttp.MethodPost},
+	"/admin/api/workshops/diff":          {fasthttp.MethodGet},
+	"/admin/api/workshops/approve-diff":  {fasthttp.MethodPost},
+	"/admin/api/jobs":                    {fasthttp.MethodGet},
+	"/admin/api/workshops/assign-artist": {fasthttp.MethodPost},
+	"/admin/api/workshops/assign-song":   {fasthttp.MethodPost},
+}
+
+// handleAdmin dispatches the admin CRUD and refresh endpoints.
+func handleAdmin(ctx *fasthttp.RequestCtx) {
+	path := string(ctx.Path())
+	method := string(ctx.Method())
+
+	switch {
+	case path == "/admin/api/index-report" && method == fasthttp.MethodGet:
+		handleIndexReport(ctx)
+	case path == "/admin/api/app-insights" && method == fasthttp.MethodGet:
+		handleAppInsights(ctx)
+	case path == "/admin/api/revenue" && method == fasthttp.MethodGet:
+		handleRevenueReport(ctx)
+	case path == "/admin/api/snapshot" && method == fasthttp.MethodGet:
+		handleWorkshopSnapshot(ctx)
+	case path == "/admin/api/ai-decision" && method == fasthttp.MethodGet:
+		handleAIDecision(ctx)
+	case path == "/admin/api/ai-metrics" && method == fasthttp.MethodGet:
+		handleAIMetrics(ctx)
+	case path == "/admin/api/retention" && method == fasthttp.MethodGet:
+		handleRetentionReport(ctx)
+	case path == "/admin/api/announcement" && (method == fasthttp.MethodPost || method == fasthttp.MethodPut):
+		handleUpsertAnnouncement(ctx)
+	case path == "/admin/api/announcement" && method == fasthttp.MethodDelete:
+		handleDeleteAnnouncement(ctx)
+	case path == "/admin/api/streaming-link" && method == fasthttp.MethodPost:
+		handleSetStreamingLink(ctx)
+	case path == "/admin/api/streaming-link" && method == fasthttp.MethodDelete:
+		handleRemoveStreamingLink(ctx)
+	case path == "/admin/api/stream-joins" && method == fasthttp.MethodGet:
+		handleStreamJoinReport(ctx)
+	case path == "/admin/api/recap" && method == fasthttp.MethodPost:
+		handleAttachRecapLink(ctx)
+	case path == "/admin/api/recap" && method == fasthttp.MethodDelete:
+		handleDeleteRecap(ctx)
+	case path == "/admin/api/recap/upload" && method == fasthttp.MethodPost:
+		handleUploadRecap(ctx)
+	case path == "/admin/api/blocklist":
+		handleBlocklist(ctx)
+	case path == "/admin/api/maintenance":
+		handleMaintenance(ctx)
+	case path == "/admin/api/studio" && method == fasthttp.MethodPost:
+		handleCreateStudio(ctx)
+	case path == "/admin/api/studio" && method == fasthttp.MethodPut:
+		handleUpdateStudio(ctx)
+	case path == "/admin/api/studio" && method == fasthttp.MethodDelete:
+		handleDeleteStudio(ctx)
+	case path == "/admin/api/studio/restore" && method == fasthttp.MethodPost:
+		handleRestoreStudio(ctx)
+	case path == "/admin/api/artist" && method == fasthttp.MethodPost:
+		handleCreateArtist(ctx)
+	case path == "/admin/api/artist" && method == fasthttp.MethodPut:
+		handleUpdateArtist(ctx)
+	case path == "/admin/api/artist" && method == fasthttp.MethodDelete:
+		handleDeleteArtist(ctx)
+	case path == "/admin/api/artist/restore" && method == fasthttp.MethodPost:
+		handleRestoreArtist(ctx)
+	case path == "/admin/api/workshop" && method == fasthttp.MethodDelete:
+		handleDeleteWorkshop(ctx)
+	case path == "/admin/api/workshop/restore" && method == fasthttp.MethodPost:
+		handleRestoreWorkshop(ctx)
+	case path == "/admin/api/workshop/price-tiers" && method == fasthttp.MethodPost:
+		handleSetPriceTiers(ctx)
+	case path == "/admin/api/pass-product" && method == fasthttp.MethodGet:
+		handleListPassProducts(ctx)
+	case path == "/admin/api/pass-product" && method == fasthttp.MethodPost:
+		handleUpsertPassProduct(ctx)
+	case path == "/admin/api/audit-logs" && method == fasthttp.MethodGet:
+		handleAuditLogs(ctx)
+	case path == "/admin/api/cache/stats" && method == fasthttp.MethodGet:
+		handleCacheStats(ctx)
+	case path == "/admin/api/scraper/health" && method == fasthttp.MethodGet:
+		handleScraperHealth(ctx)
+	case path == "/admin/api/scraper/enable" && method == fasthttp.MethodPost:
+		handleEnableScraper(ctx)
+	case path == "/admin/api/workshops/import" && method == fasthttp.MethodPost:
+		handleImportWorkshops(ctx)
+	case path == "/admin/api/workshops/export" && method == fasthttp.MethodGet:
+		handleExportWorkshops(ctx)
+	case path == "/admin/api/workshops/refresh" && method == fasthttp.MethodPost:
+		handleRefreshWorkshops(ctx)
+	case path == "/admin/api/workshops/diff" && method == fasthttp.MethodGet:
+		handleScrapeDiff(ctx)
+	case path == "/admin/api/workshops/approve-diff" && method == fasthttp.MethodPost:
+		handleApproveScrapeDiff(ctx)
+	case path == "/admin/api/jobs" && method == fasthttp.MethodGet:
+		handleScrapeJobs(ctx)
+	case path == "/admin/api/workshops/assign-artist" && method == fasthttp.MethodPost:
+		handleAssignArtist(ctx)
+	case path == "/admin/api/workshops/assign-song" && method == fasthttp.MethodPost:
+		handleAssignSong(ctx)
+	default:
+		if methods, known := adminRouteMethods[path]; known {
+			ctx.Response.Header.Set("Allow", strings.Join(append(methods, fasthttp.MethodOptions), ", "))
+			writeJSONError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Not found")
+	}
+}
+
+// handleIndexReport serves the index advisor report: live $indexStats usage
+// compared against the query patterns registered in the database layer.
+func handleIndexReport(ctx *fasthttp.RequestCtx) {
+	report, err := database.GetIndexReport(cfg)
+	if err != nil {
+		log.Printf("index report failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to build index report")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, report)
+}
+
+// handleAppInsights serves platform totals and collection growth trends for
+// the admin dashboard.
+func handleAppInsights(ctx *fasthttp.RequestCtx) {
+	insights, err := database.GetAppInsights(cfg)
+	if err != nil {
+		log.Printf("app insights failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to build app insights")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, insights)
+}
+
+// handleRevenueReport serves the precomputed month-by-studio revenue rows:
+// GMV, take, refunds, and first-time/returning buyer cohorts. ?month=
+// (YYYY-MM) and ?studio_id= narrow the result.
+func handleRevenueReport(ctx *fasthttp.RequestCtx) {
+	reports, err := services.GetRevenueReports(cfg,
+		string(ctx.QueryArgs().Peek("month")), string(ctx.QueryArgs().Peek("studio_id")))
+	if err != nil {
+		log.Printf("revenue report read failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch revenue reports")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, reports)
+}
+
+// handleRetentionReport runs the retention policy engine in dry-run mode
+// and returns what each policy would touch, so compliance checks don't
+// have to wait for (or trigger) the nightly execution.
+func handleRetentionReport(ctx *fasthttp.RequestCtx) {
+	results, err := services.RunRetentionPolicies(cfg, true)
+	if err != nil {
+		log.Printf("retention dry run failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to run retention report")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, results)
+}
+
+// handleWorkshopSnapshot serves the archived scrape of a workshop's event
+// page: ?workshop_uuid= selects the workshop (latest run unless ?run_id=
+// is given) and ?kind=html or ?kind=screenshot returns the raw capture;
+// without a kind the snapshot metadata is returned as JSON.
+func handleWorkshopSnapshot(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	snapshot, err := services.GetLatestSnapshot(cfg, workshopUUID, string(ctx.QueryArgs().Peek("run_id")))
+	if err != nil {
+		log.Printf("snapshot read failed for %s: %v", workshopUUID, err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch snapshot")
+		return
+	}
+	if snapshot == nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "No snapshot archived for this workshop")
+		return
+	}
+	switch string(ctx.QueryArgs().Peek("kind")) {
+	case "screenshot":
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetContentType("image/png")
+		ctx.SetBody(snapshot.Screenshot)
+	case "html":
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.SetContentType("text/plain; charset=utf-8")
+		ctx.SetBody(snapshot.HTML)
+	default:
+		writeJSON(ctx, fasthttp.StatusOK, snapshot)
+	}
+}
+
+// handleIssueKioskToken mints a door-tablet token for one of the studio's
+// workshops (?studio_id= and ?workshop_uuid=), valid until shortly after
+// the event ends. The token only works on the kiosk check-in endpoint.
+// Issuance requires an admin bearer token — a kiosk token lets its holder
+// stamp check-ins on the workshop's orders, so minting one must not be
+// open to anonymous callers.
+func handleIssueKioskToken(ctx *fasthttp.RequestCtx) {
+	studioID := string(ctx.QueryArgs().Peek("studio_id"))
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if studioID == "" || workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id and workshop_uuid are required")
+		return
+	}
+	token, err := services.IssueKioskToken(cfg, studioID, workshopUUID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, token)
+}
+
+// handleKioskCheckin verifies a scanned ticket at the door. The tablet
+// sends its kiosk token in X-Kiosk-Token and the scanned order ID in the
+// body; the response says whether the ticket is valid and whether it was
+// already used.
+func handleKioskCheckin(ctx *fasthttp.RequestCtx) {
+	token := string(ctx.Request.Header.Peek("X-Kiosk-Token"))
+	if token == "" {
+		writeJSONError(ctx, fasthttp.StatusUnauthorized, "X-Kiosk-Token header is required")
+		return
+	}
+	var body struct {
+		OrderID string `json:"order_id"`
+	}
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.OrderID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "order_id is required")
+		return
+	}
+	result, err := services.KioskCheckIn(cfg, token, body.OrderID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, result)
+}
+
+// handleAIDecision serves the AI decision log entry for a workshop —
+// ?workshop_uuid= selects it — showing how the model read the page on the
+// most recent import: raw output, confidence, prompt version, and model.
+func handleAIDecision(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	decision, err := services.GetLatestAIDecision(cfg, workshopUUID)
+	if err != nil {
+		log.Printf("ai decision read failed for %s: %v", workshopUUID, err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch AI decision")
+		return
+	}
+	if decision == nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "No AI decision recorded for this workshop")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, decision)
+}
+
+// handleAIMetrics serves per-provider analyzer aggregates — calls,
+// failures, average latency, token usage, and estimated cost — for
+// comparing the configured AI backends.
+func handleAIMetrics(ctx *fasthttp.RequestCtx) {
+	metrics, err := database.GetAIProviderMetrics(cfg)
+	if err != nil {
+		log.Printf("ai metrics read failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch AI metrics")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, metrics)
+}
+
+// handleUpsertAnnouncement creates or updates an in-app announcement;
+// POST and PUT behave identically since announcements are keyed by their
+// caller-chosen announcement_id.
+func handleUpsertAnnouncement(ctx *fasthttp.RequestCtx) {
+	var body request.AdminAnnouncementRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.AnnouncementID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	announcement := services.Announcement{
+		AnnouncementID: body.AnnouncementID,
+		Title:          body.Title,
+		Body:           body.Body,
+		Severity:       body.Severity,
+		Platform:       body.Platform,
+		MinAppVersion:  body.MinAppVersion,
+		City:           body.City,
+		Dismissible:    body.Dismissible,
+	}
+	if body.StartsAt != "" {
+		t, err := time.Parse(time.RFC3339, body.StartsAt)
+		if err != nil {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, "starts_at must be RFC3339")
+			return
+		}
+		announcement.StartsAt = t
+	}
+	if body.EndsAt != "" {
+		t, err := time.Parse(time.RFC3339, body.EndsAt)
+		if err != nil {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, "ends_at must be RFC3339")
+			return
+		}
+		announcement.EndsAt = t
+	}
+	if err := services.NewAnnouncementService(cfg).UpsertAnnouncement(announcement); err != nil {
+		log.Printf("announcement upsert failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to save announcement")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleDeleteAnnouncement soft-deletes an announcement so it stops
+// serving immediately.
+func handleDeleteAnnouncement(ctx *fasthttp.RequestCtx) {
+	announcementID := string(ctx.QueryArgs().Peek("announcement_id"))
+	if announcementID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "announcement_id is required")
+		return
+	}
+	if err := services.NewAnnouncementService(cfg).DeleteAnnouncement(announcementID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to delete announcement")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleSetStreamingLink stores a workshop's online stream link, which
+// paid orders can reveal through the join endpoint.
+func handleSetStreamingLink(ctx *fasthttp.RequestCtx) {
+	var body request.AdminStreamingLinkRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.WorkshopUUID == "" || body.OnlineLink == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid and online_link are required")
+		return
+	}
+	if err := services.SetStreamingLink(cfg, body.WorkshopUUID, body.OnlineLink); err != nil {
+		log.Printf("streaming link save failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to save streaming link")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleRemoveStreamingLink deletes a workshop's stream link.
+func handleRemoveStreamingLink(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	if err := services.RemoveStreamingLink(cfg, workshopUUID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to remove streaming link")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleStreamJoinReport lists per-order stream joins for one workshop,
+// for the studio's attendance report.
+func handleStreamJoinReport(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	joins, err := services.GetStreamJoinReport(cfg, workshopUUID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch stream joins")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]interface{}{
+		"workshop_uuid": workshopUUID,
+		"joins":         joins,
+		"unique_orders": len(joins),
+	})
+}
+
+// handleAttachRecapLink attaches an externally hosted recap video to a
+// workshop.
+func handleAttachRecapLink(ctx *fasthttp.RequestCtx) {
+	var body request.AdminRecapRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.WorkshopUUID == "" || body.VideoURL == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid and video_url are required")
+		return
+	}
+	recap, err := services.SaveRecapLink(cfg, body.WorkshopUUID, body.VideoURL)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, recap)
+}
+
+// handleUploadRecap stores an uploaded recap video; the raw body is the
+// video, ?workshop_uuid= names the workshop.
+func handleUploadRecap(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	recap, err := services.SaveRecapUpload(cfg, workshopUUID,
+		string(ctx.Request.Header.ContentType()), ctx.PostBody())
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, recap)
+}
+
+// handleDeleteRecap removes one recap by ID.
+func handleDeleteRecap(ctx *fasthttp.RequestCtx) {
+	recapID := string(ctx.QueryArgs().Peek("recap_id"))
+	if recapID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "recap_id is required")
+		return
+	}
+	if err := services.DeleteRecap(cfg, recapID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// studioAmenities is the amenity taxonomy admin edits are validated
+// against; slugs keep profile pages and future filters consistent.
+var studioAmenities = map[string]bool{
+	"parking":          true,
+	"changing-room":    true,
+	"washroom":         true,
+	"drinking-water":   true,
+	"air-conditioning": true,
+	"wooden-floor":     true,
+	"mirrors":          true,
+	"sound-system":     true,
+	"waiting-area":     true,
+	"lockers":          true,
+	"wifi":             true,
+}
+
+// validateStudioLocation checks the geo fields: coordinates must come in
+// pairs and lie in valid ranges, and every amenity must be a known slug.
+func validateStudioLocation(body request.AdminStudioRequest) error {
+	if (body.Latitude == nil) != (body.Longitude == nil) {
+		return fmt.Errorf("latitude and longitude must be provided together")
+	}
+	if body.Latitude != nil {
+		if *body.Latitude < -90 || *body.Latitude > 90 {
+			return fmt.Errorf("latitude must be between -90 and 90")
+		}
+		if *body.Longitude < -180 || *body.Longitude > 180 {
+			return fmt.Errorf("longitude must be between -180 and 180")
+		}
+	}
+	for _, amenity := range body.Amenities {
+		if !studioAmenities[amenity] {
+			return fmt.Errorf("unknown amenity %q", amenity)
+		}
+	}
+	return nil
+}
+
+// studioGeoDoc builds the location/amenity document fields from an admin
+// studio request. Coordinates are stored as a GeoJSON point (longitude
+// first, per the spec) so a 2dsphere index can serve geo queries.
+func studioGeoDoc(body request.AdminStudioRequest) bson.M {
+	doc := bson.M{
+		"address":   body.Address,
+		"city":      body.City,
+		"amenities": body.Amenities,
+	}
+	if body.Latitude != nil && body.Longitude != nil {
+		doc["location"] = bson.M{
+			"type":        "Point",
+			"coordinates": []float64{*body.Longitude, *body.Latitude},
+		}
+	}
+	return doc
+}
+
+func handleCreateStudio(ctx *fasthttp.RequestCtx) {
+	var body request.AdminStudioRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.StudioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validateStudioLocation(body); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	doc := bson.M{
+		"studio_id":      body.StudioID,
+		"studio_name":    body.StudioName,
+		"image_url":      body.ImageURL,
+		"instagram_link": body.InstagramLink,
+		"currency":       utils.NormalizeCurrency(body.Currency),
+		"branding":       studioBrandingDoc(body),
+		"refund_policy":  refundPolicyDocs(body.RefundPolicy),
+	}
+	for key, value := range studioGeoDoc(body) {
+		doc[key] = value
+	}
+	if err := database.CreateStudio(cfg, doc); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to create studio")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// refundPolicyDocs converts configured refund tiers to the document shape.
+func refundPolicyDocs(tiers []request.RefundTierRequest) []bson.M {
+	docs := make([]bson.M, 0, len(tiers))
+	for _, tier := range tiers {
+		docs = append(docs, bson.M{
+			"min_hours_before": tier.MinHoursBefore,
+			"refund_percent":   tier.RefundPercent,
+		})
+	}
+	return docs
+}
+
+// studioBrandingDoc builds the branding subdocument from an admin studio
+// request; empty fields are stored as-is and fall back to defaults on read.
+func studioBrandingDoc(body request.AdminStudioRequest) bson.M {
+	return bson.M{
+		"accent_color": body.AccentColor,
+		"logo_url":     body.LogoURL,
+		"sender_name":  body.SenderName,
+	}
+}
+
+func handleUpdateStudio(ctx *fasthttp.RequestCtx) {
+	var body request.AdminStudioRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.StudioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validateStudioLocation(body); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	update := bson.M{
+		"studio_name":    body.StudioName,
+		"image_url":      body.ImageURL,
+		"instagram_link": body.InstagramLink,
+		"currency":       utils.NormalizeCurrency(body.Currency),
+		"branding":       studioBrandingDoc(body),
+		"refund_policy":  refundPolicyDocs(body.RefundPolicy),
+	}
+	for key, value := range studioGeoDoc(body) {
+		update[key] = value
+	}
+	if err := database.UpdateStudio(cfg, body.StudioID, update); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to update studio")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+func handleDeleteStudio(ctx *fasthttp.RequestCtx) {
+	studioID := string(ctx.QueryArgs().Peek("studio_id"))
+	if studioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	if err := database.DeleteStudio(cfg, studioID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to delete studio")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// artistDanceStyles is the dance style taxonomy admin edits are validated
+// against; slugs are what listings filter and search on, so free-form
+// variants ("HipHop", "hip hop") are rejected rather than normalized.
+var artistDanceStyles = map[string]bool{
+	"bollywood":     true,
+	"hip-hop":       true,
+	"contemporary":  true,
+	"classical":     true,
+	"kathak":        true,
+	"bharatanatyam": true,
+	"salsa":         true,
+	"bachata":       true,
+	"locking":       true,
+	"popping":       true,
+	"breaking":      true,
+	"house":         true,
+	"waacking":      true,
+	"heels":         true,
+	"jazz":          true,
+	"krump":         true,
+	"freestyle":     true,
+}
+
+// validateDanceStyles checks every submitted style against the taxonomy.
+func validateDanceStyles(styles []string) error {
+	for _, style := range styles {
+		if !artistDanceStyles[style] {
+			return fmt.Errorf("unknown dance style %q", style)
+		}
+	}
+	return nil
+}
+
+// artistDoc builds the artist document fields shared by create and update,
+// including the enrichment fields (bio, dance styles).
+func artistDoc(body request.AdminArtistRequest) bson.M {
+	doc := bson.M{
+		"artist_name":    body.ArtistName,
+		"image_url":      body.ImageURL,
+		"instagram_link": body.InstagramLink,
+		"bio":            body.Bio,
+	}
+	if body.DanceStyles != nil {
+		doc["dance_styles"] = body.DanceStyles
+	}
+	return doc
+}
+
+func handleCreateArtist(ctx *fasthttp.RequestCtx) {
+	var body request.AdminArtistRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.ArtistID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validateDanceStyles(body.DanceStyles); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	doc := artistDoc(body)
+	doc["artist_id"] = body.ArtistID
+	if err := database.CreateArtist(cfg, doc); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to create artist")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+func handleUpdateArtist(ctx *fasthttp.RequestCtx) {
+	var body request.AdminArtistRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.ArtistID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validateDanceStyles(body.DanceStyles); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	if err := database.UpdateArtist(cfg, body.ArtistID, artistDoc(body)); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to update artist")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+func handleDeleteArtist(ctx *fasthttp.RequestCtx) {
+	artistID := string(ctx.QueryArgs().Peek("artist_id"))
+	if artistID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "artist_id is required")
+		return
+	}
+	if err := database.DeleteArtist(cfg, artistID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to delete artist")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleRestoreStudio undoes a studio soft delete so the studio reappears
+// in listings.
+func handleRestoreStudio(ctx *fasthttp.RequestCtx) {
+	var body request.AdminStudioRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.StudioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	if err := database.RestoreStudio(cfg, body.StudioID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleRestoreArtist undoes an artist soft delete.
+func handleRestoreArtist(ctx *fasthttp.RequestCtx) {
+	var body request.AdminArtistRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.ArtistID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "artist_id is required")
+		return
+	}
+	if err := database.RestoreArtist(cfg, body.ArtistID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleDeleteWorkshop soft-deletes one workshop by UUID, hiding it from
+// every public listing until restored or purged.
+func handleDeleteWorkshop(ctx *fasthttp.RequestCtx) {
+	workshopUUID := string(ctx.QueryArgs().Peek("workshop_uuid"))
+	if workshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	if err := database.SoftDeleteWorkshop(cfg, workshopUUID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleListPassProducts serves every pass product, active or not, for
+// the admin panel.
+func handleListPassProducts(ctx *fasthttp.RequestCtx) {
+	products, err := services.GetPassProducts(cfg, false)
+	if err != nil {
+		log.Printf("pass product list failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch pass products")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, products)
+}
+
+// handleUpsertPassProduct creates or updates a pass product keyed by its
+// caller-chosen product_id.
+func handleUpsertPassProduct(ctx *fasthttp.RequestCtx) {
+	var product services.PassProduct
+	if err := json.Unmarshal(ctx.PostBody(), &product); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := services.UpsertPassProduct(cfg, product); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleRestoreWorkshop undoes a workshop soft delete.
+func handleRestoreWorkshop(ctx *fasthttp.RequestCtx) {
+	var body request.AdminWorkshopRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.WorkshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	if err := database.RestoreWorkshop(cfg, body.WorkshopUUID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleSetPriceTiers replaces a workshop's automatic price tiers. The
+// configuration is validated (non-final tiers must close, the final tier
+// must stay open) before it is written, and the workshop cache is cleared
+// so feeds pick up the new active tier.
+func handleSetPriceTiers(ctx *fasthttp.RequestCtx) {
+	var body request.AdminPriceTiersRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.WorkshopUUID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "workshop_uuid is required")
+		return
+	}
+	tiers := make([]response.PriceTier, len(body.PriceTiers))
+	for i, t := range body.PriceTiers {
+		tiers[i] = response.PriceTier{Name: t.Name, Price: t.Price, UntilEpoch: t.UntilEpoch, SeatLimit: t.SeatLimit}
+	}
+	if err := database.SetWorkshopPriceTiers(cfg, body.WorkshopUUID, tiers); err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleAuditLogs serves admin mutation audit entries newest-first, narrowed
+// by ?user_id=, ?entity_type=, ?entity_id=, and ?path=, paginated with
+// ?page= and ?page_size=.
+func handleAuditLogs(ctx *fasthttp.RequestCtx) {
+	page, err := services.GetAuditLogs(cfg,
+		string(ctx.QueryArgs().Peek("user_id")),
+		string(ctx.QueryArgs().Peek("entity_type")),
+		string(ctx.QueryArgs().Peek("entity_id")),
+		string(ctx.QueryArgs().Peek("path")),
+		int(queryInt64(ctx, "page")), int(queryInt64(ctx, "page_size")))
+	if err != nil {
+		log.Printf("audit log query failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch audit logs")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, page)
+}
+
+// handleCacheStats serves per-key-prefix response cache statistics for TTL
+// tuning.
+func handleCacheStats(ctx *fasthttp.RequestCtx) {
+	writeJSON(ctx, fasthttp.StatusOK, map[string]interface{}{"prefixes": utils.CacheStats()})
+}
+
+// handleScraperHealth serves every scrape source's rolling health for the
+// admin dashboard.
+func handleScraperHealth(ctx *fasthttp.RequestCtx) {
+	health, err := studios.GetScraperHealth(cfg)
+	if err != nil {
+		log.Printf("scraper health report failed: %v", err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to load scraper health")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]interface{}{"sources": health})
+}
+
+// handleEnableScraper re-enables an auto-disabled scrape source.
+func handleEnableScraper(ctx *fasthttp.RequestCtx) {
+	var body request.AdminWorkshopRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.StudioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	if err := studios.EnableScraperSource(cfg, body.StudioID); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to enable scraper")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+// handleImportWorkshops bulk-upserts workshops from a CSV or JSON body.
+// ?format=csv|json picks the parser (default json); ?dry_run=true validates
+// and reports per-row outcomes without writing.
+func handleImportWorkshops(ctx *fasthttp.RequestCtx) {
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = "json"
+	}
+	dryRun := string(ctx.QueryArgs().Peek("dry_run")) == "true"
+	body := ctx.PostBody()
+	if len(body) == 0 {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "request body is required")
+		return
+	}
+	summary, err := services.ImportWorkshops(cfg, body, format, dryRun)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, summary)
+}
+
+// handleExportWorkshops serves every non-archived workshop in the import
+// format: ?format=csv|json (default json).
+func handleExportWorkshops(ctx *fasthttp.RequestCtx) {
+	format := string(ctx.QueryArgs().Peek("format"))
+	if format == "" {
+		format = "json"
+	}
+	body, contentType, err := services.ExportWorkshops(cfg, format)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.Response.Header.Set("Content-Type", contentType)
+	ctx.Response.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "workshops."+format))
+	ctx.SetBody(body)
+}
+
+// handleRefreshWorkshops enqueues a scrape job for the studio and returns
+// it immediately; the admin polls /admin/api/jobs for progress. With
+// "dry_run": true the job computes a pending diff instead of persisting;
+// the finished job carries its diff_id for review and approval.
+func handleRefreshWorkshops(ctx *fasthttp.RequestCtx) {
+	var body request.AdminWorkshopRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.StudioID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "studio_id is required")
+		return
+	}
+	job, err := studios.EnqueueScrapeJob(cfg, body.StudioID, body.DryRun, ai.NewAnalyzer(cfg))
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusAccepted, job)
+}
+
+// handleScrapeDiff serves a dry run's computed changeset — new, removed,
+// and changed workshops — for review before approval; ?diff_id= names it.
+func handleScrapeDiff(ctx *fasthttp.RequestCtx) {
+	diffID := string(ctx.QueryArgs().Peek("diff_id"))
+	if diffID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "diff_id is required")
+		return
+	}
+	diff, err := studios.GetScrapeDiff(cfg, diffID)
+	if err != nil {
+		log.Printf("scrape diff read failed for %s: %v", diffID, err)
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch scrape diff")
+		return
+	}
+	if diff == nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "No such scrape diff")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, diff)
+}
+
+// handleApproveScrapeDiff applies a pending dry-run diff, persisting the
+// workshop set it previewed.
+func handleApproveScrapeDiff(ctx *fasthttp.RequestCtx) {
+	var body request.AdminWorkshopRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.DiffID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "diff_id is required")
+		return
+	}
+	diff, err := studios.ApplyScrapeDiff(cfg, body.DiffID)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, diff)
+}
+
+// handleScrapeJobs reports scrape job status: ?job_id= for one job, else
+// recent jobs newest first, optionally filtered by ?studio_id=.
+func handleScrapeJobs(ctx *fasthttp.RequestCtx) {
+	if jobID := string(ctx.QueryArgs().Peek("job_id")); jobID != "" {
+		job, err := studios.GetScrapeJob(cfg, jobID)
+		if err != nil {
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch scrape job")
+			return
+		}
+		if job == nil {
+			writeJSONError(ctx, fasthttp.StatusNotFound, "No such scrape job")
+			return
+		}
+		writeJSON(ctx, fasthttp.StatusOK, job)
+		return
+	}
+	jobs, err := studios.ListScrapeJobs(cfg, string(ctx.QueryArgs().Peek("studio_id")))
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to fetch scrape jobs")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, jobs)
+}
+
+func handleAssignArtist(ctx *fasthttp.RequestCtx) {
+	var body request.AssignArtistRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.WorkshopID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := database.AssignArtistToWorkshop(cfg, body.WorkshopID, body.ArtistIDList, body.ArtistNames); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to assign artist")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
+
+func handleAssignSong(ctx *fasthttp.RequestCtx) {
+	var body request.AssignSongRequest
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil || body.WorkshopID == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := database.AssignSongToWorkshop(cfg, body.WorkshopID, body.Song); err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to assign song")
+		return
+	}
+	writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+}
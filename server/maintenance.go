@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+)
+
+// Maintenance mode for schema migrations: public endpoints return 503 with
+// a friendly message while health, admin, and webhook routes stay live. The
+// flag persists in the app_settings collection so it survives restarts.
+
+// defaultMaintenanceMessage is shown when no custom message is set.
+const defaultMaintenanceMessage = "Nachna is down for scheduled maintenance. We'll be back shortly!"
+
+var (
+	maintenanceMu      sync.RWMutex
+	maintenanceOn      bool
+	maintenanceMessage string
+)
+
+// maintenanceActive returns the current flag and message.
+func maintenanceActive() (bool, string) {
+	maintenanceMu.RLock()
+	defer maintenanceMu.RUnlock()
+	msg := maintenanceMessage
+	if msg == "" {
+		msg = defaultMaintenanceMessage
+	}
+	return maintenanceOn, msg
+}
+
+// setMaintenance flips the flag in memory and persists it.
+func setMaintenance(enabled bool, message string) error {
+	maintenanceMu.Lock()
+	maintenanceOn = enabled
+	maintenanceMessage = message
+	maintenanceMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "app_settings").UpdateOne(ctx,
+		bson.M{"_id": "maintenance"},
+		bson.M{"$set": bson.M{"enabled": enabled, "message": message, "updated_at": time.Now().UTC()}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// loadMaintenanceState hydrates the flag from app_settings at startup.
+func loadMaintenanceState() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var doc struct {
+		Enabled bool   `bson:"enabled"`
+		Message string `bson:"message"`
+	}
+	err := database.Collection(cfg, "app_settings").FindOne(ctx, bson.M{"_id": "maintenance"}).Decode(&doc)
+	if err != nil {
+		return
+	}
+	maintenanceMu.Lock()
+	maintenanceOn = doc.Enabled
+	maintenanceMessage = doc.Message
+	maintenanceMu.Unlock()
+	if doc.Enabled {
+		log.Println("maintenance mode is active (restored from app_settings)")
+	}
+}
+
+// maintenanceExemptPath reports whether a path stays live during
+// maintenance: liveness, admin tooling, and payment webhooks must not go
+// dark.
+func maintenanceExemptPath(path string) bool {
+	return path == "/health" ||
+		path == "/api/version" ||
+		hasPrefix(path, "/admin/") ||
+		hasPrefix(path, "/static/") ||
+		strings.Contains(path, "/webhook")
+}
+
+// serveMaintenance answers a blocked request with 503: HTML for browsers,
+// the standard JSON error shape for API clients.
+func serveMaintenance(ctx *fasthttp.RequestCtx, message string) {
+	ctx.Response.Header.Set("Retry-After", "300")
+	if strings.Contains(string(ctx.Request.Header.Peek("Accept")), "text/html") {
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		ctx.SetContentType("text/html; charset=utf-8")
+		ctx.SetBodyString("<!DOCTYPE html><html><head><title>Maintenance</title></head><body><h1>Be right back</h1><p>" + message + "</p></body></html>")
+		return
+	}
+	writeJSONError(ctx, fasthttp.StatusServiceUnavailable, message)
+}
+
+// handleMaintenance is the admin toggle: GET reads the state, POST sets it.
+func handleMaintenance(ctx *fasthttp.RequestCtx) {
+	switch string(ctx.Method()) {
+	case fasthttp.MethodGet:
+		enabled, message := maintenanceActive()
+		writeJSON(ctx, fasthttp.StatusOK, map[string]interface{}{"enabled": enabled, "message": message})
+	case fasthttp.MethodPost:
+		var body struct {
+			Enabled bool   `json:"enabled"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+			writeJSONError(ctx, fasthttp.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err := setMaintenance(body.Enabled, body.Message); err != nil {
+			log.Printf("maintenance: persist failed: %v", err)
+			writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to persist maintenance state")
+			return
+		}
+		writeJSON(ctx, fasthttp.StatusOK, map[string]bool{"success": true})
+	default:
+		writeJSONError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// allowMethods restricts a fasthttp route to the given methods. HEAD is
+// accepted wherever GET is (fasthttp skips the response body on the wire),
+// OPTIONS is answered with the Allow header, and anything else gets a 405
+// carrying the same Allow header instead of running the handler.
+func allowMethods(next fasthttp.RequestHandler, methods ...string) fasthttp.RequestHandler {
+	allowed := make(map[string]bool, len(methods))
+	allowList := append([]string{}, methods...)
+	for _, m := range methods {
+		allowed[m] = true
+	}
+	if allowed[fasthttp.MethodGet] {
+		allowed[fasthttp.MethodHead] = true
+		allowList = append(allowList, fasthttp.MethodHead)
+	}
+	allowValue := strings.Join(append(allowList, fasthttp.MethodOptions), ", ")
+
+	return func(ctx *fasthttp.RequestCtx) {
+		switch method := string(ctx.Method()); {
+		case method == fasthttp.MethodOptions:
+			ctx.Response.Header.Set("Allow", allowValue)
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+		case allowed[method]:
+			next(ctx)
+		default:
+			ctx.Response.Header.Set("Allow", allowValue)
+			writeJSONError(ctx, fasthttp.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package middleware provides the handler-agnostic chain abstraction both
+// HTTP servers compose their route groups from. The fasthttp server and the
+// net/http mux server use different handler types, so the chain is generic
+// over the handler: each server declares its cross-cutting stack (method
+// gating, rate limiting, auth, caching, CSRF) once as a chain and derives
+// route groups from it with Use, instead of hand-wrapping every route.
+package middleware
+
+// Middleware wraps a handler of type H with cross-cutting behavior.
+type Middleware[H any] func(H) H
+
+// Chain is an ordered middleware stack. The first middleware runs
+// outermost. Chains are immutable: Use returns a new chain, so route groups
+// can safely derive from a shared base.
+type Chain[H any] struct {
+	stack []Middleware[H]
+}
+
+// New builds a chain from middlewares in execution order.
+func New[H any](middlewares ...Middleware[H]) Chain[H] {
+	return Chain[H]{stack: middlewares}
+}
+
+// Use returns a new chain with the given middlewares appended (running
+// inside the existing stack).
+func (c Chain[H]) Use(middlewares ...Middleware[H]) Chain[H] {
+	combined := make([]Middleware[H], 0, len(c.stack)+len(middlewares))
+	combined = append(combined, c.stack...)
+	combined = append(combined, middlewares...)
+	return Chain[H]{stack: combined}
+}
+
+// Then applies the chain to a handler, first middleware outermost.
+func (c Chain[H]) Then(handler H) H {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		handler = c.stack[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,154 @@
+// Package request defines the JSON request bodies accepted by the admin and
+// user-facing APIs.
+package request
+
+// AdminStudioRequest is the body for studio create/update endpoints.
+type AdminStudioRequest struct {
+	StudioID      string  `json:"studio_id"`
+	StudioName    string  `json:"studio_name"`
+	ImageURL      *string `json:"image_url"`
+	InstagramLink string  `json:"instagram_link"`
+	// Currency is the ISO 4217 code workshops at this studio are priced
+	// in; empty keeps the INR default.
+	Currency string `json:"currency"`
+	// Branding overrides for the studio's pages and notifications; empty
+	// fields keep the Nachna defaults.
+	AccentColor string `json:"accent_color"`
+	LogoURL     string `json:"logo_url"`
+	SenderName  string `json:"sender_name"`
+	// RefundPolicy replaces the studio's cancellation tiers; empty keeps
+	// the platform default policy.
+	RefundPolicy []RefundTierRequest `json:"refund_policy"`
+	// Address and City locate the studio for profile pages.
+	Address *string `json:"address"`
+	City    string  `json:"city"`
+	// Latitude/Longitude are the studio's coordinates; both must be set
+	// together and are stored as a GeoJSON point.
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	// Amenities are taxonomy slugs; validated against the server's
+	// amenity taxonomy on write.
+	Amenities []string `json:"amenities"`
+}
+
+// RefundTierRequest is one cancellation tier: bookings cancelled at least
+// MinHoursBefore hours before the session refund RefundPercent.
+type RefundTierRequest struct {
+	MinHoursBefore int64 `json:"min_hours_before"`
+	RefundPercent  int64 `json:"refund_percent"`
+}
+
+// AdminArtistRequest is the body for artist create/update endpoints.
+type AdminArtistRequest struct {
+	ArtistID      string  `json:"artist_id"`
+	ArtistName    string  `json:"artist_name"`
+	ImageURL      *string `json:"image_url"`
+	InstagramLink string  `json:"instagram_link"`
+	// Bio is the artist's free-text introduction; nil leaves it unset.
+	Bio *string `json:"bio"`
+	// DanceStyles are taxonomy slugs; validated against the server's
+	// style taxonomy on write.
+	DanceStyles []string `json:"dance_styles"`
+}
+
+// AdminWorkshopRequest is the body for workshop create/update and refresh
+// endpoints.
+type AdminWorkshopRequest struct {
+	StudioID string `json:"studio_id"`
+	// WorkshopUUID addresses a single workshop for delete/restore.
+	WorkshopUUID string `json:"workshop_uuid"`
+	// DryRun previews a refresh: the scrape and analysis run in full but
+	// the result is a pending diff instead of a persisted workshop set.
+	DryRun bool `json:"dry_run"`
+	// DiffID addresses a stored dry-run diff for approval.
+	DiffID string `json:"diff_id"`
+}
+
+// AdminPriceTiersRequest replaces a workshop's automatic price tiers; an
+// empty list removes them.
+type AdminPriceTiersRequest struct {
+	WorkshopUUID string             `json:"workshop_uuid"`
+	PriceTiers   []PriceTierRequest `json:"price_tiers"`
+}
+
+// PriceTierRequest is one configured price tier: Price applies until
+// UntilEpoch passes or SeatLimit paid orders exist, whichever comes first.
+// Zero means no deadline / no cap.
+type PriceTierRequest struct {
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`
+	UntilEpoch int64  `json:"until_epoch"`
+	SeatLimit  int64  `json:"seat_limit"`
+}
+
+// AssignArtistRequest assigns artists to a workshop. WorkshopID is the
+// workshop's uuid; legacy Mongo ObjectID hex is still accepted during the
+// deprecation window.
+type AssignArtistRequest struct {
+	WorkshopID   string   `json:"workshop_id"`
+	ArtistIDList []string `json:"artist_id_list"`
+	ArtistNames  []string `json:"artist_names"`
+}
+
+// AssignSongRequest assigns a song title to a workshop, addressed the same
+// way as AssignArtistRequest.
+type AssignSongRequest struct {
+	WorkshopID string `json:"workshop_id"`
+	Song       string `json:"song"`
+}
+
+// DeviceTokenRequest registers a push token for the authenticated user.
+type DeviceTokenRequest struct {
+	DeviceToken string `json:"device_token"`
+	Platform    string `json:"platform"`
+}
+
+// ChoreoLinkRequest submits a choreo Instagram link for a song from a
+// claimed artist profile.
+type ChoreoLinkRequest struct {
+	ArtistID  string `json:"artist_id"`
+	Song      string `json:"song"`
+	InstaLink string `json:"insta_link"`
+}
+
+// ChoreoReviewRequest approves or rejects a pending choreo link submission.
+type ChoreoReviewRequest struct {
+	SubmissionID string `json:"submission_id"`
+	Approve      bool   `json:"approve"`
+}
+
+// ReactionRequest creates a like/follow/notify reaction on an entity.
+type ReactionRequest struct {
+	EntityID   string `json:"entity_id"`
+	EntityType string `json:"entity_type"`
+	Reaction   string `json:"reaction"`
+}
+
+// AdminAnnouncementRequest is the body for announcement create/update
+// endpoints. Empty targeting fields mean "all"; zero times mean an
+// always-on window.
+type AdminAnnouncementRequest struct {
+	AnnouncementID string `json:"announcement_id"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	Severity       string `json:"severity"`
+	Platform       string `json:"platform"`
+	MinAppVersion  string `json:"min_app_version"`
+	City           string `json:"city"`
+	StartsAt       string `json:"starts_at"`
+	EndsAt         string `json:"ends_at"`
+	Dismissible    bool   `json:"dismissible"`
+}
+
+// AdminStreamingLinkRequest sets a workshop's online stream link.
+type AdminStreamingLinkRequest struct {
+	WorkshopUUID string `json:"workshop_uuid"`
+	OnlineLink   string `json:"online_link"`
+}
+
+// AdminRecapRequest attaches an externally hosted recap video to a
+// workshop.
+type AdminRecapRequest struct {
+	WorkshopUUID string `json:"workshop_uuid"`
+	VideoURL     string `json:"video_url"`
+}
@@ -0,0 +1,69 @@
+package response
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// v2 compatibility shim for the casing audit. When a field's wire name is
+// corrected to snake_case for v3, existing v2 clients still read the old
+// name. Tag the field with compat:"<oldName>" and serve it through
+// MarshalWithAliases from the v2 handler: the response then carries the
+// value under both keys, with the compat tag doubling as the deprecation
+// record. v3 handlers marshal the struct directly and serve only the
+// canonical snake_case name; the alias (and its tag) is deleted once v2 is
+// retired.
+
+// MarshalWithAliases marshals v and duplicates every top-level field tagged
+// compat:"oldName" under its deprecated v2 name. Non-struct values and
+// structs without compat tags marshal exactly as json.Marshal would.
+func MarshalWithAliases(v interface{}) ([]byte, error) {
+	aliases := compatAliases(v)
+	if len(aliases) == 0 {
+		return json.Marshal(v)
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body, nil
+	}
+	for canonical, deprecated := range aliases {
+		if value, ok := doc[canonical]; ok {
+			doc[deprecated] = value
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// compatAliases maps canonical wire names to their deprecated v2 names for
+// the struct's compat-tagged fields.
+func compatAliases(v interface{}) map[string]string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	var aliases map[string]string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		deprecated := field.Tag.Get("compat")
+		if deprecated == "" {
+			continue
+		}
+		canonical := strings.Split(field.Tag.Get("json"), ",")[0]
+		if canonical == "" || canonical == "-" {
+			continue
+		}
+		if aliases == nil {
+			aliases = map[string]string{}
+		}
+		aliases[canonical] = deprecated
+	}
+	return aliases
+}
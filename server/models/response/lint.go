@@ -0,0 +1,86 @@
+package response
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Response-model linting for the v3 casing audit. Historically a few
+// responses leaked Go field names (CamelCase) because a struct was
+// marshalled without json tags, while the rest of the API is snake_case.
+// LintResponseModel walks a model reflectively and reports every exported
+// field whose wire name would not be snake_case, so the lint test catches a
+// casing regression before it ships.
+
+// LintViolation is one field that would serialize with a non-snake_case
+// wire name.
+type LintViolation struct {
+	Model   string
+	Field   string
+	Problem string
+}
+
+func (v LintViolation) String() string {
+	return fmt.Sprintf("%s.%s: %s", v.Model, v.Field, v.Problem)
+}
+
+// snakeCaseName matches the wire names the API allows: lower-case ASCII,
+// digits, and underscores.
+var snakeCaseName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// LintResponseModel checks every exported field reachable from the model
+// (including nested structs, slices, maps, and pointers) for a snake_case
+// json tag. Fields tagged json:"-" are skipped; fields with no json tag at
+// all are violations because they would serialize under the Go field name.
+func LintResponseModel(model interface{}) []LintViolation {
+	var violations []LintViolation
+	lintType(reflect.TypeOf(model), map[reflect.Type]bool{}, &violations)
+	return violations
+}
+
+func lintType(t reflect.Type, seen map[reflect.Type]bool, violations *[]LintViolation) {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported, never serialized
+		}
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		switch {
+		case name == "-":
+			continue
+		case tag == "":
+			*violations = append(*violations, LintViolation{
+				Model:   t.String(),
+				Field:   field.Name,
+				Problem: "missing json tag, would serialize as " + field.Name,
+			})
+		case name == "":
+			// json:",omitempty" style: still serializes the Go name.
+			*violations = append(*violations, LintViolation{
+				Model:   t.String(),
+				Field:   field.Name,
+				Problem: "json tag has no name, would serialize as " + field.Name,
+			})
+		// "_id" is MongoDB's document key, served to v2 clients as-is; it
+		// predates the casing audit and is the one allowed exception.
+		case name != "_id" && !snakeCaseName.MatchString(name):
+			*violations = append(*violations, LintViolation{
+				Model:   t.String(),
+				Field:   field.Name,
+				Problem: "wire name " + name + " is not snake_case",
+			})
+		}
+		lintType(field.Type, seen, violations)
+	}
+}
@@ -0,0 +1,68 @@
+package response_test
+
+import (
+	"testing"
+
+	"dance_api_go/models/response"
+	"dance_api_go/services"
+)
+
+// v3ResponseModels is the registry of every model served to API clients.
+// New response structs must be added here; the lint below fails the build
+// the moment one of them would serialize a non-snake_case wire name.
+var v3ResponseModels = []interface{}{
+	response.TimeDetail{},
+	response.Workshop{},
+	response.WorkshopListItem{},
+	response.WorkshopSession{},
+	response.DaySchedule{},
+	response.CategorizedWorkshopResponse{},
+	response.Artist{},
+	response.ArtistProfile{},
+	response.Studio{},
+	response.RefundTier{},
+	response.StudioBranding{},
+	response.SearchResponse{},
+	response.NearbyWorkshopsResponse{},
+	response.ErrorResponse{},
+	services.Order{},
+	services.CancelResult{},
+	services.RefundQuote{},
+	services.Reaction{},
+	services.ReactionSyncResult{},
+	services.StreamEvent{},
+	services.AIDecision{},
+	services.KioskToken{},
+	services.KioskCheckInResult{},
+	services.AbuseFlag{},
+	services.ChoreoSubmission{},
+	services.NotificationPreferences{},
+	services.NotificationPreview{},
+	services.PushDeliveryResult{},
+	services.Campaign{},
+	services.RetentionResult{},
+	services.Announcement{},
+	services.StreamAccess{},
+	services.StreamJoin{},
+	services.WorkshopRecap{},
+	services.ImportSummary{},
+	services.AuditLogPage{},
+	services.WebhookSimulationResult{},
+	services.ContactSyncResult{},
+	services.FriendsGoingResult{},
+	services.PassProduct{},
+	services.Pass{},
+	services.PassRedemptionResult{},
+}
+
+// TestResponseModelsAreSnakeCase is the casing lint: every exported field
+// reachable from a registered response model must carry a snake_case json
+// tag. Renames for v3 keep their old v2 name via a compat tag served
+// through MarshalWithAliases instead of loosening this check.
+func TestResponseModelsAreSnakeCase(t *testing.T) {
+	for _, model := range v3ResponseModels {
+		for _, violation := range response.LintResponseModel(model) {
+			t.Errorf("%s", violation)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package response defines the JSON response models served by the public and
+// admin APIs. Field names mirror the Pydantic models in app/models so the
+// Flutter client can consume either server interchangeably.
+package response
+
+// TimeDetail is a single session's date/time information as stored on a
+// workshop document.
+type TimeDetail struct {
+	Day       *int    `json:"day" bson:"day"`
+	Month     *int    `json:"month" bson:"month"`
+	Year      *int    `json:"year" bson:"year"`
+	StartTime *string `json:"start_time" bson:"start_time"`
+	EndTime   *string `json:"end_time" bson:"end_time"`
+}
+
+// Workshop is the full workshop document shape used by admin endpoints and
+// the scraper pipeline.
+type Workshop struct {
+	MongoID         string   `json:"_id,omitempty" bson:"_id,omitempty"`
+	UUID            string   `json:"uuid" bson:"uuid"`
+	PaymentLink     string   `json:"payment_link" bson:"payment_link"`
+	PaymentLinkType string   `json:"payment_link_type" bson:"payment_link_type"`
+	StudioID        string   `json:"studio_id" bson:"studio_id"`
+	By              *string  `json:"by" bson:"by"`
+	Song            *string  `json:"song" bson:"song"`
+	PricingInfo     *string  `json:"pricing_info" bson:"pricing_info"`
+	MinPrice        int64    `json:"min_price" bson:"min_price"`
+	MaxPrice        int64    `json:"max_price" bson:"max_price"`
+	ArtistIDList    []string `json:"artist_id_list" bson:"artist_id_list"`
+	EventType       string   `json:"event_type" bson:"event_type"`
+	// SourceLanguage is the ISO 639-1 code of the scraped page's dominant
+	// language (fields themselves are normalized to English); empty on
+	// workshops imported before language detection.
+	SourceLanguage  string       `json:"source_language,omitempty" bson:"source_language,omitempty"`
+	TimeDetails     []TimeDetail `json:"time_details" bson:"time_details"`
+	UpdatedAt       float64      `json:"updated_at" bson:"updated_at"`
+	ChoreoInstaLink *string      `json:"choreo_insta_link" bson:"choreo_insta_link"`
+	// LockedFields names fields an admin has corrected by hand; the
+	// scraper carries locked values across refreshes instead of
+	// overwriting them.
+	LockedFields []string `json:"locked_fields,omitempty" bson:"locked_fields,omitempty"`
+	// PriceTiers, when set, configure automatic price transitions (e.g.
+	// early bird, then standard). Tiers are evaluated in order; the first
+	// still-open tier wins. Nil means the scraped pricing applies as-is.
+	PriceTiers []PriceTier `json:"price_tiers,omitempty" bson:"price_tiers,omitempty"`
+}
+
+// PriceTier is one rung of a workshop's automatic pricing: the tier's price
+// applies until its deadline passes or its seat cap fills, whichever comes
+// first. Zero UntilEpoch means no deadline; zero SeatLimit means no cap.
+// The final tier should leave both unset so there is always a fallback.
+type PriceTier struct {
+	Name       string `json:"name" bson:"name"`
+	Price      int64  `json:"price" bson:"price"`
+	UntilEpoch int64  `json:"until_epoch,omitempty" bson:"until_epoch,omitempty"`
+	SeatLimit  int64  `json:"seat_limit,omitempty" bson:"seat_limit,omitempty"`
+}
+
+// WorkshopListItem is a single workshop entry in categorized list responses.
+type WorkshopListItem struct {
+	UUID                 string    `json:"uuid"`
+	PaymentLink          string    `json:"payment_link"`
+	PaymentLinkType      string    `json:"payment_link_type"`
+	StudioID             string    `json:"studio_id"`
+	StudioName           string    `json:"studio_name"`
+	UpdatedAt            float64   `json:"updated_at"`
+	By                   *string   `json:"by"`
+	Song                 *string   `json:"song"`
+	PricingInfo          *string   `json:"pricing_info"`
+	MinPrice             int64     `json:"min_price"`
+	MaxPrice             int64     `json:"max_price"`
+	Currency             string    `json:"currency"`
+	TimestampEpoch       int64     `json:"timestamp_epoch"`
+	ArtistIDList         []string  `json:"artist_id_list"`
+	ArtistImageURLs      []*string `json:"artist_image_urls"`
+	ArtistInstagramLinks []string  `json:"artist_instagram_links"`
+	Date                 *string   `json:"date"`
+	Time                 *string   `json:"time"`
+	EventType            *string   `json:"event_type"`
+	ChoreoInstaLink      *string   `json:"choreo_insta_link"`
+	// CurrentTier/CurrentPrice name the price tier active right now for
+	// workshops with automatic pricing configured; nil otherwise.
+	CurrentTier  *string `json:"current_tier,omitempty"`
+	CurrentPrice *int64  `json:"current_price,omitempty"`
+}
+
+// WorkshopSession is a single session row on artist/studio schedule endpoints.
+type WorkshopSession struct {
+	UUID            string   `json:"uuid"`
+	Date            string   `json:"date"`
+	Time            string   `json:"time"`
+	Song            *string  `json:"song"`
+	StudioID        *string  `json:"studio_id"`
+	Artist          *string  `json:"artist"`
+	ArtistIDList    []string `json:"artist_id_list"`
+	PaymentLink     string   `json:"payment_link"`
+	PaymentLinkType string   `json:"payment_link_type"`
+	PricingInfo     *string  `json:"pricing_info"`
+	TimestampEpoch  int64    `json:"timestamp_epoch"`
+	EventType       *string  `json:"event_type"`
+	ChoreoInstaLink *string  `json:"choreo_insta_link"`
+}
+
+// DaySchedule groups this-week workshops under a day name.
+type DaySchedule struct {
+	Day       string             `json:"day"`
+	Workshops []WorkshopListItem `json:"workshops"`
+}
+
+// CategorizedWorkshopResponse is the /api/workshops response shape.
+type CategorizedWorkshopResponse struct {
+	ThisWeek     []DaySchedule      `json:"this_week"`
+	PostThisWeek []WorkshopListItem `json:"post_this_week"`
+}
+
+// Artist is the public artist profile.
+type Artist struct {
+	ID            string  `json:"id" bson:"artist_id"`
+	Name          string  `json:"name" bson:"artist_name"`
+	ImageURL      *string `json:"image_url" bson:"image_url"`
+	InstagramLink string  `json:"instagram_link" bson:"instagram_link"`
+	// Bio is the artist's free-text introduction; nil when not set.
+	Bio *string `json:"bio,omitempty" bson:"bio,omitempty"`
+	// DanceStyles are taxonomy slugs (e.g. "hip-hop", "bollywood") the
+	// artist teaches; empty when not curated yet.
+	DanceStyles []string `json:"dance_styles,omitempty" bson:"dance_styles,omitempty"`
+}
+
+// ChoreoVideo is one approved choreography video in an artist's portfolio,
+// sourced from choreo_links.
+type ChoreoVideo struct {
+	Song      string `json:"song" bson:"song"`
+	InstaLink string `json:"insta_link" bson:"choreo_insta_link"`
+}
+
+// ArtistProfile is the enriched artist detail served at
+// /api/artists/{artist_id}: the base profile plus the artist's approved
+// choreo video portfolio.
+type ArtistProfile struct {
+	Artist       Artist        `json:"artist"`
+	ChoreoVideos []ChoreoVideo `json:"choreo_videos"`
+}
+
+// Studio is the public studio profile.
+type Studio struct {
+	ID            string  `json:"id" bson:"studio_id"`
+	Name          string  `json:"name" bson:"studio_name"`
+	ImageURL      *string `json:"image_url" bson:"image_url"`
+	InstagramLink string  `json:"instagram_link" bson:"instagram_link"`
+	// Currency is the ISO 4217 code workshops at this studio are priced
+	// in; empty means INR.
+	Currency string `json:"currency" bson:"currency"`
+	// Branding holds the studio's visual identity overrides; nil means the
+	// Nachna defaults apply.
+	Branding *StudioBranding `json:"branding,omitempty" bson:"branding,omitempty"`
+	// RefundPolicy is the studio's cancellation tiers; nil means the
+	// platform default policy applies.
+	RefundPolicy []RefundTier `json:"refund_policy,omitempty" bson:"refund_policy,omitempty"`
+	// Address is the studio's street address; nil when not curated.
+	Address *string `json:"address,omitempty" bson:"address,omitempty"`
+	// City the studio is in; empty when not curated.
+	City string `json:"city,omitempty" bson:"city,omitempty"`
+	// Location is the studio's coordinates as a GeoJSON point
+	// ([longitude, latitude]), enabling geo queries; nil when not set.
+	Location *GeoPoint `json:"location,omitempty" bson:"location,omitempty"`
+	// Amenities are taxonomy slugs for what the studio offers (e.g.
+	// "parking", "changing-room"); empty when not curated.
+	Amenities []string `json:"amenities,omitempty" bson:"amenities,omitempty"`
+}
+
+// GeoPoint is a GeoJSON point; Coordinates are [longitude, latitude] per
+// the GeoJSON spec, which is what MongoDB 2dsphere indexes expect.
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// RefundTier is one rung of a studio's refund policy: cancellations at
+// least MinHoursBefore hours before the session start refund RefundPercent
+// of the amount paid. Tiers are evaluated highest threshold first.
+type RefundTier struct {
+	MinHoursBefore int64 `json:"min_hours_before" bson:"min_hours_before"`
+	RefundPercent  int64 `json:"refund_percent" bson:"refund_percent"`
+}
+
+// StudioBranding is a studio's configured look for detail pages, wallet
+// passes, and notifications. Empty fields fall back to the Nachna defaults.
+type StudioBranding struct {
+	AccentColor string `json:"accent_color" bson:"accent_color"`
+	LogoURL     string `json:"logo_url" bson:"logo_url"`
+	SenderName  string `json:"sender_name" bson:"sender_name"`
+}
+
+// WorkshopSearchHit is one scored workshop result in a search response.
+type WorkshopSearchHit struct {
+	Score    float64          `json:"score"`
+	Workshop WorkshopListItem `json:"workshop"`
+}
+
+// ArtistSearchHit is one scored artist result in a search response.
+type ArtistSearchHit struct {
+	Score  float64 `json:"score"`
+	Artist Artist  `json:"artist"`
+}
+
+// StudioSearchHit is one scored studio result in a search response.
+type StudioSearchHit struct {
+	Score  float64 `json:"score"`
+	Studio Studio  `json:"studio"`
+}
+
+// SearchResponse is the /api/search response: relevance-sorted hits
+// faceted by entity type.
+type SearchResponse struct {
+	Query     string              `json:"query"`
+	Workshops []WorkshopSearchHit `json:"workshops"`
+	Artists   []ArtistSearchHit   `json:"artists"`
+	Studios   []StudioSearchHit   `json:"studios"`
+	Total     int                 `json:"total"`
+}
+
+// NearbyWorkshopItem is one workshop in a geo-search response, tagged with
+// the distance from the query point to its studio.
+type NearbyWorkshopItem struct {
+	DistanceKm float64          `json:"distance_km"`
+	Workshop   WorkshopListItem `json:"workshop"`
+}
+
+// NearbyWorkshopsResponse is the /api/workshops/nearby response: upcoming
+// workshops at studios within RadiusKm of the query point, nearest first.
+type NearbyWorkshopsResponse struct {
+	Lat       float64              `json:"lat"`
+	Lng       float64              `json:"lng"`
+	RadiusKm  float64              `json:"radius_km"`
+	Workshops []NearbyWorkshopItem `json:"workshops"`
+}
+
+// ErrorResponse is the uniform error body for non-2xx API responses.
+type ErrorResponse struct {
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,265 @@
+// Package openapi generates the machine-readable description of the HTTP
+// API. Routes are declared in a central registry rather than annotations —
+// the same trade this codebase makes everywhere: one boring list that is
+// easy to review over magic scattered across handlers. Schemas are derived
+// by reflection from the request/response models, so a model change shows
+// up in the spec without touching this package.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"dance_api_go/models/request"
+	"dance_api_go/models/response"
+	"dance_api_go/services"
+)
+
+// route is one registry entry.
+type route struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Params   []param
+	Request  interface{}
+	Response interface{}
+	// Auth is "" (public), "user", or "admin".
+	Auth string
+}
+
+// param is one query or path parameter.
+type param struct {
+	Name     string
+	In       string // "query" or "path"
+	Required bool
+	Desc     string
+}
+
+// routes is the API surface the spec describes. Paths with trailing
+// segments use OpenAPI path templating.
+var routes = []route{
+	{Method: "get", Path: "/api/workshops", Tag: "workshops",
+		Summary:  "List upcoming workshops, categorized by week; paginated with ?limit=",
+		Params:   []param{q("when", "IST quick filter: today/tomorrow/weekend/next_7_days"), q("limit", "page size; switches to a flat paginated response"), q("offset", "page offset"), q("sort", "sort key"), q("min_price", "minimum price filter"), q("max_price", "maximum price filter")},
+		Response: response.CategorizedWorkshopResponse{}},
+	{Method: "get", Path: "/api/workshop/{uuid}", Tag: "workshops",
+		Summary:  "Fetch one workshop by UUID",
+		Params:   []param{p("uuid", "workshop UUID")},
+		Response: response.Workshop{}},
+	{Method: "get", Path: "/api/structured/workshops", Tag: "workshops",
+		Summary: "Flat agent-friendly feed of upcoming sessions with explicit units"},
+	{Method: "get", Path: "/api/search", Tag: "search",
+		Summary:  "Unified search across workshops, artists and studios",
+		Params:   []param{{Name: "q", In: "query", Required: true, Desc: "search query"}},
+		Response: response.SearchResponse{}},
+	{Method: "get", Path: "/api/artists", Tag: "artists",
+		Summary:  "List artists; ?has_workshops=true filters to artists with upcoming sessions",
+		Params:   []param{q("has_workshops", "only artists with upcoming sessions"), q("limit", "page size"), q("offset", "page offset")},
+		Response: response.Artist{}},
+	{Method: "get", Path: "/api/studios", Tag: "studios",
+		Summary:  "List studios",
+		Params:   []param{q("limit", "page size"), q("offset", "page offset")},
+		Response: response.Studio{}},
+	{Method: "get", Path: "/api/workshops_by_artist/{artist_id}", Tag: "workshops",
+		Summary:  "Upcoming sessions for one artist",
+		Params:   []param{p("artist_id", "artist ID")},
+		Response: response.WorkshopSession{}},
+	{Method: "get", Path: "/api/workshops_by_studio/{studio_id}", Tag: "workshops",
+		Summary:  "Upcoming workshops at one studio, categorized by week",
+		Params:   []param{p("studio_id", "studio ID")},
+		Response: response.CategorizedWorkshopResponse{}},
+	{Method: "post", Path: "/api/reactions", Tag: "reactions", Auth: "user",
+		Summary:  "Create a like/follow/notify reaction",
+		Request:  request.ReactionRequest{},
+		Response: services.Reaction{}},
+	{Method: "delete", Path: "/api/reactions", Tag: "reactions", Auth: "user",
+		Summary: "Soft-delete a reaction",
+		Request: request.ReactionRequest{}},
+	{Method: "get", Path: "/api/user/reactions", Tag: "reactions", Auth: "user",
+		Summary:  "List the caller's reactions",
+		Response: services.Reaction{}},
+	{Method: "post", Path: "/api/notifications/register-token", Tag: "notifications", Auth: "user",
+		Summary: "Register a device push token",
+		Request: request.DeviceTokenRequest{}},
+	{Method: "get", Path: "/api/announcements", Tag: "announcements", Auth: "user",
+		Summary:  "Active in-app announcements for the calling client",
+		Params:   []param{q("platform", "client platform"), q("app_version", "client app version"), q("city", "client city")},
+		Response: services.Announcement{}},
+	{Method: "post", Path: "/api/announcements/dismiss", Tag: "announcements", Auth: "user",
+		Summary: "Dismiss an announcement for the calling user"},
+	{Method: "get", Path: "/api/version", Tag: "meta",
+		Summary: "Build and feature identification"},
+	{Method: "get", Path: "/health", Tag: "meta",
+		Summary: "Liveness and database readiness"},
+}
+
+func q(name, desc string) param { return param{Name: name, In: "query", Desc: desc} }
+func p(name, desc string) param { return param{Name: name, In: "path", Required: true, Desc: desc} }
+
+var (
+	specOnce   sync.Once
+	cachedSpec map[string]interface{}
+)
+
+// Spec returns the OpenAPI 3 document, built once.
+func Spec() map[string]interface{} {
+	specOnce.Do(func() { cachedSpec = buildSpec() })
+	return cachedSpec
+}
+
+func buildSpec() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+
+	for _, r := range routes {
+		operation := map[string]interface{}{
+			"summary": r.Summary,
+			"tags":    []string{r.Tag},
+		}
+		if len(r.Params) > 0 {
+			params := make([]interface{}, 0, len(r.Params))
+			for _, pr := range r.Params {
+				params = append(params, map[string]interface{}{
+					"name":        pr.Name,
+					"in":          pr.In,
+					"required":    pr.Required,
+					"description": pr.Desc,
+					"schema":      map[string]string{"type": "string"},
+				})
+			}
+			operation["parameters"] = params
+		}
+		if r.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schemaRef(reflect.TypeOf(r.Request), schemas),
+					},
+				},
+			}
+		}
+		responseSchema := map[string]interface{}{"type": "object"}
+		if r.Response != nil {
+			responseSchema = schemaRef(reflect.TypeOf(r.Response), schemas)
+		}
+		operation["responses"] = map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "Success",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": responseSchema},
+				},
+			},
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schemaRef(reflect.TypeOf(response.ErrorResponse{}), schemas),
+					},
+				},
+			},
+		}
+		if r.Auth != "" {
+			operation["security"] = []map[string][]string{{"bearerAuth": {}}}
+			operation["description"] = fmt.Sprintf("Requires a %s token.", r.Auth)
+		}
+
+		entry, ok := paths[r.Path].(map[string]interface{})
+		if !ok {
+			entry = map[string]interface{}{}
+			paths[r.Path] = entry
+		}
+		entry[r.Method] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Nachna API",
+			"description": "Dance workshop discovery and booking platform API.",
+			"version":     "1.0.0",
+		},
+		"servers": []map[string]string{{"url": "https://nachna.com"}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]string{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+		},
+	}
+}
+
+// schemaRef registers the type's schema under components and returns a
+// $ref to it; non-struct types inline directly.
+func schemaRef(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return schemaOf(t, schemas)
+	}
+	name := t.Name()
+	if _, done := schemas[name]; !done {
+		// Reserve the slot first so self-referential models terminate.
+		schemas[name] = map[string]interface{}{"type": "object"}
+		schemas[name] = structSchema(t, schemas)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+// structSchema reflects a struct into an object schema keyed by json tags.
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type, schemas)
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// schemaOf maps a Go type to its JSON schema.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Struct:
+		if t.String() == "time.Time" {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		return schemaRef(t, schemas)
+	default:
+		return map[string]interface{}{}
+	}
+}
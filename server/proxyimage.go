@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Image proxy hardening: the proxy used to fetch whatever URL the client
+// supplied, which let it be aimed at internal addresses, and it re-fetched
+// every image from the origin once the response cache expired. Fetches are
+// now restricted to an allowlist of image hosts, capped in size, validated
+// to actually be images, and cached on disk with a configurable TTL so an
+// Instagram CDN image is downloaded once, not once per cache expiry.
+
+// imageProxyDefaultHosts are always allowed: the Instagram CDN domains the
+// scraper stores and our own hosted assets. Studio sites and anything else
+// go through IMAGE_PROXY_ALLOWED_HOSTS.
+var imageProxyDefaultHosts = []string{
+	"cdninstagram.com",
+	"fbcdn.net",
+	"nachna.com",
+}
+
+// imageCacheSweepInterval bounds how often the eviction scan runs.
+const imageCacheSweepInterval = 10 * time.Minute
+
+var (
+	imageCacheMu    sync.Mutex
+	imageCacheSwept time.Time
+)
+
+// handleProxyImage fetches a remote image on behalf of the client so the app
+// avoids hotlinking restrictions, serving from the disk cache when possible.
+// ?w=, ?h=, and ?format= request a resized or transcoded variant, each
+// cached separately from the original.
+func handleProxyImage(ctx *fasthttp.RequestCtx) {
+	imageURL := string(ctx.QueryArgs().Peek("url"))
+	if imageURL == "" {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, "url parameter is required")
+		return
+	}
+	if !imageHostAllowed(imageURL) {
+		writeJSONError(ctx, fasthttp.StatusForbidden, "Image host not allowed")
+		return
+	}
+	variant, err := parseImageVariant(ctx)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	cacheKey := imageURL + variant.cacheSuffix()
+	if body, contentType, ok := readCachedImage(cacheKey); ok {
+		ctx.SetContentType(contentType)
+		ctx.SetBody(body)
+		return
+	}
+
+	body, contentType, ok := fetchOriginImage(ctx, imageURL)
+	if !ok {
+		return
+	}
+	if !variant.isOriginal() {
+		body, contentType, err = transformImage(body, variant)
+		if err != nil {
+			writeJSONError(ctx, fasthttp.StatusBadGateway, "Failed to transform image")
+			return
+		}
+		writeCachedImage(cacheKey, body, contentType)
+	}
+	ctx.SetContentType(contentType)
+	ctx.SetBody(body)
+}
+
+// fetchOriginImage returns the original image, from the disk cache or the
+// origin. On failure it writes the error response and returns ok=false.
+func fetchOriginImage(ctx *fasthttp.RequestCtx, imageURL string) ([]byte, string, bool) {
+	if body, contentType, ok := readCachedImage(imageURL); ok {
+		return body, contentType, true
+	}
+
+	resp, err := proxyClient.Get(imageURL)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadGateway, "Failed to fetch image")
+		return nil, "", false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fasthttp.StatusOK {
+		writeJSONError(ctx, fasthttp.StatusBadGateway, "Failed to fetch image")
+		return nil, "", false
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		writeJSONError(ctx, fasthttp.StatusBadGateway, "Origin did not return an image")
+		return nil, "", false
+	}
+	// Read one byte past the cap to distinguish at-limit from over it.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(cfg.ImageProxyMaxBytes)+1))
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusBadGateway, "Failed to read image")
+		return nil, "", false
+	}
+	if len(body) > cfg.ImageProxyMaxBytes {
+		writeJSONError(ctx, fasthttp.StatusBadGateway, "Image exceeds size limit")
+		return nil, "", false
+	}
+
+	writeCachedImage(imageURL, body, contentType)
+	return body, contentType, true
+}
+
+// imageHostAllowed reports whether the URL points at an allowlisted image
+// host over http(s).
+func imageHostAllowed(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+	host := parsed.Hostname()
+	for _, allowed := range append(imageProxyDefaultHosts, cfg.ImageProxyAllowedHosts...) {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageCachePaths returns the body and content-type sidecar paths for a URL.
+func imageCachePaths(imageURL string) (string, string) {
+	sum := sha256.Sum256([]byte(imageURL))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(cfg.ImageCacheDir, name), filepath.Join(cfg.ImageCacheDir, name+".ct")
+}
+
+// readCachedImage serves a cached image if present and within the TTL.
+func readCachedImage(imageURL string) ([]byte, string, bool) {
+	bodyPath, ctPath := imageCachePaths(imageURL)
+	info, err := os.Stat(bodyPath)
+	if err != nil || time.Since(info.ModTime()) > cfg.ImageCacheTTL {
+		return nil, "", false
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, "", false
+	}
+	contentType, err := os.ReadFile(ctPath)
+	if err != nil {
+		return nil, "", false
+	}
+	return body, string(contentType), true
+}
+
+// writeCachedImage persists a fetched image and triggers eviction. Cache
+// writes are best-effort: a full disk degrades to origin fetches, it never
+// fails the request.
+func writeCachedImage(imageURL string, body []byte, contentType string) {
+	if err := os.MkdirAll(cfg.ImageCacheDir, 0o755); err != nil {
+		log.Printf("image cache: create dir failed: %v", err)
+		return
+	}
+	bodyPath, ctPath := imageCachePaths(imageURL)
+	if err := os.WriteFile(bodyPath, body, 0o644); err != nil {
+		log.Printf("image cache: write failed: %v", err)
+		return
+	}
+	if err := os.WriteFile(ctPath, []byte(contentType), 0o644); err != nil {
+		log.Printf("image cache: write content type failed: %v", err)
+	}
+	evictImageCache()
+}
+
+// evictImageCache drops expired entries and, when the cache is over its size
+// budget, the oldest entries until it fits. Runs at most once per sweep
+// interval.
+func evictImageCache() {
+	imageCacheMu.Lock()
+	defer imageCacheMu.Unlock()
+	if time.Since(imageCacheSwept) < imageCacheSweepInterval {
+		return
+	}
+	imageCacheSwept = time.Now()
+
+	entries, err := os.ReadDir(cfg.ImageCacheDir)
+	if err != nil {
+		return
+	}
+	type cacheFile struct {
+		path    string
+		modTime time.Time
+		size    int64
+	}
+	var files []cacheFile
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(cfg.ImageCacheDir, entry.Name())
+		if time.Since(info.ModTime()) > cfg.ImageCacheTTL {
+			_ = os.Remove(path)
+			continue
+		}
+		files = append(files, cacheFile{path: path, modTime: info.ModTime(), size: info.Size()})
+		total += info.Size()
+	}
+	if total <= cfg.ImageCacheMaxBytes {
+		return
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].modTime.Before(files[b].modTime) })
+	for _, f := range files {
+		if total <= cfg.ImageCacheMaxBytes {
+			break
+		}
+		_ = os.Remove(f.path)
+		total -= f.size
+	}
+}
@@ -0,0 +1,103 @@
+// Package queryopts is the shared parser for list-endpoint query options:
+// limit/offset pagination and a sort key. Every list endpoint had grown
+// its own parsing and clamping; this package gives the api handlers and
+// database layer one definition of "a page request" — parse from either
+// HTTP stack, clamp to bounds, and apply to a Mongo find or an in-memory
+// slice.
+package queryopts
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultLimit and MaxLimit are the platform-wide page size bounds,
+// matching the values the pagination endpoints have always used.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Options is one parsed set of list query options.
+type Options struct {
+	Limit  int64
+	Offset int64
+	Sort   string
+	// Requested reports whether the caller sent a positive ?limit= at all;
+	// pagination is opt-in, so handlers serve the full list when false.
+	Requested bool
+}
+
+// FromRequest parses options from a net/http request's query string.
+func FromRequest(r *http.Request) Options {
+	q := r.URL.Query()
+	return parse(q.Get("limit"), q.Get("offset"), q.Get("sort"))
+}
+
+// FromFastHTTP parses options from fasthttp query args.
+func FromFastHTTP(args *fasthttp.Args) Options {
+	return parse(string(args.Peek("limit")), string(args.Peek("offset")), string(args.Peek("sort")))
+}
+
+// parse builds clamped options from the raw parameter strings; malformed
+// numbers read as absent.
+func parse(limit, offset, sort string) Options {
+	opts := Options{Sort: sort}
+	if n, err := strconv.ParseInt(limit, 10, 64); err == nil && n > 0 {
+		opts.Limit = n
+		opts.Requested = true
+	}
+	if n, err := strconv.ParseInt(offset, 10, 64); err == nil && n > 0 {
+		opts.Offset = n
+	}
+	return opts.Clamp(DefaultLimit, MaxLimit)
+}
+
+// Clamp bounds the options to [1, max] with the given default for an
+// unset limit, and a non-negative offset.
+func (o Options) Clamp(defaultLimit, maxLimit int64) Options {
+	if o.Limit <= 0 {
+		o.Limit = defaultLimit
+	}
+	if o.Limit > maxLimit {
+		o.Limit = maxLimit
+	}
+	if o.Offset < 0 {
+		o.Offset = 0
+	}
+	return o
+}
+
+// FindOptions returns Mongo find options applying the page and, when a
+// sort field is set, an ascending sort on it.
+func (o Options) FindOptions() *mongoopts.FindOptions {
+	find := mongoopts.Find().SetSkip(o.Offset).SetLimit(o.Limit)
+	if o.Sort != "" {
+		find.SetSort(bson.M{o.Sort: 1})
+	}
+	return find
+}
+
+// PipelineStages returns the $skip/$limit stages equivalent to the page,
+// for endpoints built on aggregations.
+func (o Options) PipelineStages() []bson.M {
+	return []bson.M{{"$skip": o.Offset}, {"$limit": o.Limit}}
+}
+
+// SliceBounds resolves the page against an in-memory list of the given
+// length, returning the [start, end) range; ok is false when the offset
+// is past the end.
+func (o Options) SliceBounds(total int64) (int64, int64, bool) {
+	if o.Offset >= total {
+		return 0, 0, false
+	}
+	end := o.Offset + o.Limit
+	if end > total {
+		end = total
+	}
+	return o.Offset, end, true
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Global rate limiting for the public endpoints: the anti-scrape layer
+// targets sustained farming, but nothing stopped a single client from
+// hammering /api/workshops or /proxy-image/ in short spikes. Each client
+// gets a token bucket refilled at RateLimitRPS with RateLimitBurst
+// headroom, keyed by client IP. These routes carry no verified identity —
+// the X-User-ID header here is whatever the client sent — so there is
+// deliberately no per-user bucket; keying on the header would let anyone
+// drain a victim's bucket by naming them. Registered partners and the
+// configured internal caller IPs bypass the limiter entirely.
+
+// rateBucketIdleEviction drops buckets not seen for this long.
+const rateBucketIdleEviction = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var (
+	rateBuckets       = make(map[string]*tokenBucket)
+	rateBucketsMu     sync.Mutex
+	rateBucketsPruned time.Time
+)
+
+// rateLimitMiddleware enforces the token bucket on a public route;
+// rejections get 429 with a Retry-After hint.
+func rateLimitMiddleware(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if cfg.RateLimitRPS <= 0 || isPartnerRequest(ctx) {
+			next(ctx)
+			return
+		}
+		ip := clientIP(ctx)
+		if isExemptIP(ip) {
+			next(ctx)
+			return
+		}
+
+		if retryAfter, ok := takeToken("ip:" + ip); !ok {
+			ctx.Response.Header.Set("Retry-After", strconv.Itoa(retryAfter))
+			writeJSONError(ctx, fasthttp.StatusTooManyRequests, "Too many requests")
+			return
+		}
+		next(ctx)
+	}
+}
+
+// takeToken refills and drains one token from the key's bucket. When the
+// bucket is empty it returns the whole seconds until a token is available.
+func takeToken(key string) (int, bool) {
+	rateBucketsMu.Lock()
+	defer rateBucketsMu.Unlock()
+
+	now := time.Now()
+	pruneRateBuckets(now)
+
+	bucket, ok := rateBuckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(cfg.RateLimitBurst), last: now}
+		rateBuckets[key] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * cfg.RateLimitRPS
+	if max := float64(cfg.RateLimitBurst); bucket.tokens > max {
+		bucket.tokens = max
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		retryAfter := int((1-bucket.tokens)/cfg.RateLimitRPS) + 1
+		return retryAfter, false
+	}
+	bucket.tokens--
+	return 0, true
+}
+
+// pruneRateBuckets evicts idle buckets; called with the lock held.
+func pruneRateBuckets(now time.Time) {
+	if now.Sub(rateBucketsPruned) < rateBucketIdleEviction {
+		return
+	}
+	rateBucketsPruned = now
+	for key, bucket := range rateBuckets {
+		if now.Sub(bucket.last) > rateBucketIdleEviction {
+			delete(rateBuckets, key)
+		}
+	}
+}
+
+// clientIP resolves the caller's IP. X-Forwarded-For is honored only when
+// the connection actually comes from a configured trusted proxy — from
+// anyone else the header is attacker-controlled, and trusting it would
+// hand out a fresh rate-limit bucket (and anti-scrape fingerprint) per
+// spoofed value. The last entry is the one our proxy appended; anything
+// before it arrived in the client's own header and is ignored.
+func clientIP(ctx *fasthttp.RequestCtx) string {
+	remote := ctx.RemoteIP().String()
+	if !isTrustedProxy(remote) {
+		return remote
+	}
+	if header := string(ctx.Request.Header.Peek("X-Forwarded-For")); header != "" {
+		hops := strings.Split(header, ",")
+		if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+			return ip
+		}
+	}
+	return remote
+}
+
+// isTrustedProxy reports whether the peer address is one of the configured
+// reverse proxies.
+func isTrustedProxy(ip string) bool {
+	for _, proxy := range cfg.TrustedProxyIPs {
+		if ip == proxy {
+			return true
+		}
+	}
+	return false
+}
+
+// isExemptIP reports whether the IP is on the internal-caller exemption
+// list.
+func isExemptIP(ip string) bool {
+	for _, exempt := range cfg.RateLimitExemptIPs {
+		if ip == exempt {
+			return true
+		}
+	}
+	return false
+}
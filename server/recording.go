@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"dance_api_go/services"
+)
+
+// recordMiddleware feeds fasthttp traffic into the dev-mode request
+// recorder. Static assets, proxied images, and media responses are passed
+// through unrecorded — replaying them would only compare bytes the API
+// did not produce.
+func recordMiddleware(rec *services.RequestRecorder, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		path := string(ctx.Path())
+		if hasPrefix(path, "/static/") || hasPrefix(path, "/proxy-image/") {
+			next(ctx)
+			return
+		}
+		requestBody := string(ctx.PostBody())
+		next(ctx)
+		if skipRecordedContentType(string(ctx.Response.Header.ContentType())) {
+			return
+		}
+		rec.Record(services.RecordedRequest{
+			Server:       "api",
+			Method:       string(ctx.Method()),
+			Path:         path,
+			Query:        string(ctx.URI().QueryString()),
+			ContentType:  string(ctx.Request.Header.ContentType()),
+			RequestBody:  requestBody,
+			Status:       ctx.Response.StatusCode(),
+			ResponseBody: string(ctx.Response.Body()),
+		})
+	}
+}
+
+// skipRecordedContentType reports whether a response is binary media the
+// recorder should not capture (screenshots, recap videos).
+func skipRecordedContentType(contentType string) bool {
+	return hasPrefix(contentType, "image/") || hasPrefix(contentType, "video/")
+}
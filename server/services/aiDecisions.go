@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/ai"
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// AI decision log: every workshop the import pipeline accepts keeps a
+// sidecar record of how the model read its page — the verbatim raw output,
+// the model's self-reported confidence, and which model and prompt revision
+// produced it. When a listing looks wrong, admins can see exactly why it
+// was parsed the way it was (next to the page snapshot of what the model
+// saw) instead of guessing. Records live in the ai_decisions collection;
+// logging is on by default and switched off via AI_DECISION_LOG.
+
+// AIDecision is one logged extraction for an imported workshop.
+type AIDecision struct {
+	WorkshopUUID  string    `bson:"workshop_uuid" json:"workshop_uuid"`
+	StudioID      string    `bson:"studio_id" json:"studio_id"`
+	PageURL       string    `bson:"page_url" json:"page_url"`
+	Model         string    `bson:"model" json:"model"`
+	PromptVersion int       `bson:"prompt_version" json:"prompt_version"`
+	Confidence    *float64  `bson:"confidence" json:"confidence"`
+	RawOutput     string    `bson:"raw_output" json:"raw_output"`
+	AnalyzedAt    time.Time `bson:"analyzed_at" json:"analyzed_at"`
+}
+
+// RecordAIDecision logs how the model read one imported workshop's page.
+// Best-effort: a logging failure never fails the import that produced the
+// workshop, and the call is a no-op when decision logging is disabled.
+func RecordAIDecision(cfg *config.Config, studioID, workshopUUID, pageURL string, summary *ai.EventSummary) {
+	if !cfg.LogAIDecisions || summary == nil || summary.Decision == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "ai_decisions").InsertOne(ctx, AIDecision{
+		WorkshopUUID:  workshopUUID,
+		StudioID:      studioID,
+		PageURL:       pageURL,
+		Model:         summary.Decision.Model,
+		PromptVersion: summary.Decision.PromptVersion,
+		Confidence:    summary.Confidence,
+		RawOutput:     summary.Decision.RawOutput,
+		AnalyzedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("ai decisions: record failed for %s: %v", pageURL, err)
+	}
+}
+
+// GetLatestAIDecision returns the most recent logged extraction for a
+// workshop, or (nil, nil) when none was recorded.
+func GetLatestAIDecision(cfg *config.Config, workshopUUID string) (*AIDecision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var decision AIDecision
+	err := database.Collection(cfg, "ai_decisions").FindOne(ctx,
+		bson.M{"workshop_uuid": workshopUUID},
+		options.FindOne().SetSort(bson.M{"analyzed_at": -1})).Decode(&decision)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// In-app announcements: banners for downtime notices and feature news,
+// managed through the admin CRUD and served to clients without an app
+// release. Each announcement can target a platform, a minimum app
+// version, and a city; scheduling is a start/end window; dismissals are
+// tracked per user so a closed banner stays closed across devices.
+
+// Announcement is one banner document in the announcements collection.
+type Announcement struct {
+	AnnouncementID string    `bson:"announcement_id" json:"announcement_id"`
+	Title          string    `bson:"title" json:"title"`
+	Body           string    `bson:"body" json:"body"`
+	Severity       string    `bson:"severity" json:"severity"`
+	Platform       string    `bson:"platform" json:"platform"`
+	MinAppVersion  string    `bson:"min_app_version" json:"min_app_version"`
+	City           string    `bson:"city" json:"city"`
+	StartsAt       time.Time `bson:"starts_at" json:"starts_at"`
+	EndsAt         time.Time `bson:"ends_at" json:"ends_at"`
+	Dismissible    bool      `bson:"dismissible" json:"dismissible"`
+	IsDeleted      bool      `bson:"is_deleted" json:"-"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// AnnouncementServiceImpl manages announcements and their dismissals.
+type AnnouncementServiceImpl struct {
+	cfg *config.Config
+}
+
+// NewAnnouncementService builds the announcement service.
+func NewAnnouncementService(cfg *config.Config) *AnnouncementServiceImpl {
+	return &AnnouncementServiceImpl{cfg: cfg}
+}
+
+// UpsertAnnouncement creates or updates an announcement by its ID.
+// Severity defaults to "info" and the window defaults to always-on.
+func (s *AnnouncementServiceImpl) UpsertAnnouncement(a Announcement) error {
+	if a.AnnouncementID == "" || a.Title == "" {
+		return fmt.Errorf("announcement_id and title are required")
+	}
+	if a.Severity == "" {
+		a.Severity = "info"
+	}
+	a.IsDeleted = false
+	a.UpdatedAt = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "announcements").UpdateOne(ctx,
+		bson.M{"announcement_id": a.AnnouncementID},
+		bson.M{
+			"$set":         a,
+			"$setOnInsert": bson.M{"created_at": a.UpdatedAt},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// DeleteAnnouncement soft-deletes an announcement so it stops serving
+// immediately while dismissal history stays intact.
+func (s *AnnouncementServiceImpl) DeleteAnnouncement(announcementID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "announcements").UpdateOne(ctx,
+		bson.M{"announcement_id": announcementID},
+		bson.M{"$set": bson.M{"is_deleted": true, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+// GetActiveAnnouncements returns the announcements the given client should
+// show right now: inside their window, matching the client's platform,
+// version, and city, and not yet dismissed by the user.
+func (s *AnnouncementServiceImpl) GetActiveAnnouncements(userID, platform, appVersion, city string) ([]Announcement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "announcements").Find(ctx,
+		bson.M{"is_deleted": false})
+	if err != nil {
+		return nil, err
+	}
+	var all []Announcement
+	if err := cursor.All(ctx, &all); err != nil {
+		return nil, err
+	}
+
+	dismissed, err := s.dismissedSet(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	active := []Announcement{}
+	for _, a := range all {
+		if dismissed[a.AnnouncementID] {
+			continue
+		}
+		if !a.StartsAt.IsZero() && now.Before(a.StartsAt) {
+			continue
+		}
+		if !a.EndsAt.IsZero() && now.After(a.EndsAt) {
+			continue
+		}
+		if a.Platform != "" && platform != "" && a.Platform != platform {
+			continue
+		}
+		if a.City != "" && city != "" && !strings.EqualFold(a.City, city) {
+			continue
+		}
+		if a.MinAppVersion != "" && appVersion != "" && compareVersions(appVersion, a.MinAppVersion) < 0 {
+			continue
+		}
+		active = append(active, a)
+	}
+	return active, nil
+}
+
+// DismissAnnouncement records that a user closed a banner; subsequent
+// fetches on any of their devices exclude it.
+func (s *AnnouncementServiceImpl) DismissAnnouncement(userID, announcementID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "announcement_dismissals").UpdateOne(ctx,
+		bson.M{"user_id": userID, "announcement_id": announcementID},
+		bson.M{"$setOnInsert": bson.M{
+			"user_id":         userID,
+			"announcement_id": announcementID,
+			"dismissed_at":    time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// dismissedSet returns the announcement IDs the user has dismissed; an
+// empty user (unauthenticated preview) dismisses nothing.
+func (s *AnnouncementServiceImpl) dismissedSet(userID string) (map[string]bool, error) {
+	if userID == "" {
+		return map[string]bool{}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	values, err := database.Collection(s.cfg, "announcement_dismissals").Distinct(ctx,
+		"announcement_id", bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	dismissed := make(map[string]bool, len(values))
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			dismissed[id] = true
+		}
+	}
+	return dismissed, nil
+}
+
+// compareVersions compares dotted numeric versions ("1.4.0" style),
+// returning -1, 0, or 1. Non-numeric segments compare as 0.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		if an != bn {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"dance_api_go/config"
+)
+
+const apnsHost = "https://api.push.apple.com"
+
+var (
+	apnsClient     = &http.Client{Timeout: 10 * time.Second}
+	apnsToken      string
+	apnsTokenAt    time.Time
+	apnsTokenMutex sync.Mutex
+)
+
+// apnsProviderToken returns a cached ES256 provider token, re-signing when
+// the previous one is older than Apple's 60 minute limit.
+func apnsProviderToken(cfg *config.Config) (string, error) {
+	apnsTokenMutex.Lock()
+	defer apnsTokenMutex.Unlock()
+
+	if apnsToken != "" && time.Since(apnsTokenAt) < 50*time.Minute {
+		return apnsToken, nil
+	}
+
+	keyBytes, err := os.ReadFile(cfg.APNSKeyPath)
+	if err != nil {
+		return "", fmt.Errorf("read APNs key: %w", err)
+	}
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return "", fmt.Errorf("invalid APNs key PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("parse APNs key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("APNs key is not ECDSA")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": cfg.APNSTeamID,
+		"iat": time.Now().Unix(),
+	})
+	token.Header["kid"] = cfg.APNSKeyID
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+	apnsToken = signed
+	apnsTokenAt = time.Now()
+	return signed, nil
+}
+
+// apnsPush delivers one notification payload to a device token.
+func apnsPush(cfg *config.Config, deviceToken string, payload map[string]interface{}) error {
+	providerToken, err := apnsProviderToken(cfg)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/3/device/%s", apnsHost, deviceToken), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", cfg.APNSTopic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := apnsClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("APNs responded %d", resp.StatusCode)
+	}
+	return nil
+}
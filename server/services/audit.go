@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Audit log subsystem for admin mutations. The admin middleware snapshots
+// the targeted entity before and after the handler runs (resolved from the
+// IDs present in the request), so every assign-artist, assign-song, studio/
+// artist/workshop edit lands in audit_logs with the acting user, a
+// before/after diff, a request ID, and a timestamp. Entries are queryable
+// with filters and pagination via GET /admin/api/audit-logs.
+
+// auditPageSizeDefault and auditPageSizeMax bound the query endpoint.
+const (
+	auditPageSizeDefault = 50
+	auditPageSizeMax     = 200
+)
+
+// AuditLogEntry is one recorded admin mutation.
+type AuditLogEntry struct {
+	RequestID     string    `bson:"request_id" json:"request_id"`
+	UserID        string    `bson:"user_id" json:"user_id"`
+	Method        string    `bson:"method" json:"method"`
+	Path          string    `bson:"path" json:"path"`
+	Status        int       `bson:"status" json:"status"`
+	EntityType    string    `bson:"entity_type,omitempty" json:"entity_type,omitempty"`
+	EntityID      string    `bson:"entity_id,omitempty" json:"entity_id,omitempty"`
+	Before        bson.M    `bson:"before,omitempty" json:"before,omitempty"`
+	After         bson.M    `bson:"after,omitempty" json:"after,omitempty"`
+	ChangedFields []string  `bson:"changed_fields,omitempty" json:"changed_fields,omitempty"`
+	Body          string    `bson:"body,omitempty" json:"body,omitempty"`
+	LoggedAt      time.Time `bson:"logged_at" json:"logged_at"`
+}
+
+// AuditLogPage is the paginated audit query response.
+type AuditLogPage struct {
+	Logs     []AuditLogEntry `json:"logs"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+// ResolveAuditEntity maps the IDs found in a mutation request onto the
+// entity it targets and returns that entity's current document, or an
+// empty snapshot for requests that don't address one directly (bulk
+// import, refresh). Called once before and once after the handler, the two
+// snapshots form the diff.
+func ResolveAuditEntity(cfg *config.Config, ids map[string]string) (entityType, entityID string, doc bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if id := ids["workshop_id"]; id != "" {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			return "workshop", id, fetchAuditDoc(ctx, cfg, "workshops_v2", bson.M{"_id": oid})
+		}
+	}
+	if id := ids["workshop_uuid"]; id != "" {
+		return "workshop", id, fetchAuditDoc(ctx, cfg, "workshops_v2", bson.M{"uuid": id})
+	}
+	if id := ids["studio_id"]; id != "" {
+		return "studio", id, fetchAuditDoc(ctx, cfg, "studios", bson.M{"studio_id": id})
+	}
+	if id := ids["artist_id"]; id != "" {
+		return "artist", id, fetchAuditDoc(ctx, cfg, "artists_v2", bson.M{"artist_id": id})
+	}
+	return "", "", nil
+}
+
+// fetchAuditDoc reads one document for a snapshot; a miss (deleted, not yet
+// created) is a nil snapshot, not an error.
+func fetchAuditDoc(ctx context.Context, cfg *config.Config, collection string, filter bson.M) bson.M {
+	var doc bson.M
+	if err := database.Collection(cfg, collection).FindOne(ctx, filter).Decode(&doc); err != nil {
+		return nil
+	}
+	delete(doc, "_id")
+	return doc
+}
+
+// DiffAuditDocs lists the top-level fields that changed between the two
+// snapshots.
+func DiffAuditDocs(before, after bson.M) []string {
+	var changed []string
+	seen := map[string]bool{}
+	for key, value := range before {
+		seen[key] = true
+		if fmt.Sprintf("%v", after[key]) != fmt.Sprintf("%v", value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range after {
+		if !seen[key] {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// RecordAuditLog appends one entry to audit_logs. Best-effort: a write
+// failure is logged, never surfaced, so auditing can't fail the mutation it
+// describes.
+func RecordAuditLog(cfg *config.Config, entry AuditLogEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := database.Collection(cfg, "audit_logs").InsertOne(ctx, entry); err != nil {
+		log.Printf("audit: recording %s %s by %s failed: %v", entry.Method, entry.Path, entry.UserID, err)
+	}
+}
+
+// GetAuditLogs returns audit entries newest-first, narrowed by any of the
+// optional filters (acting user, entity type, entity ID, path).
+func GetAuditLogs(cfg *config.Config, userID, entityType, entityID, path string, page, pageSize int) (*AuditLogPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = auditPageSizeDefault
+	}
+	if pageSize > auditPageSizeMax {
+		pageSize = auditPageSizeMax
+	}
+
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	if entityType != "" {
+		filter["entity_type"] = entityType
+	}
+	if entityID != "" {
+		filter["entity_id"] = entityID
+	}
+	if path != "" {
+		filter["path"] = path
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	coll := database.Collection(cfg, "audit_logs")
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"logged_at": -1}).
+		SetSkip(int64((page-1)*pageSize)).
+		SetLimit(int64(pageSize)))
+	if err != nil {
+		return nil, err
+	}
+	logs := []AuditLogEntry{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+	return &AuditLogPage{Logs: logs, Total: total, Page: page, PageSize: pageSize}, nil
+}
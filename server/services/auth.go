@@ -0,0 +1,158 @@
+// Package services contains the business-logic layer of the Golang API
+// server: authentication, orders, notifications, and reactions.
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Account states beyond active. Suspensions carry an expiry and lift
+// automatically; bans are permanent until an admin clears them.
+const (
+	AccountStateSuspended = "suspended"
+	AccountStateBanned    = "banned"
+)
+
+// Suspension reason codes stored on the user document.
+const (
+	SuspensionReasonAbuse        = "abuse"
+	SuspensionReasonPaymentFraud = "payment_fraud"
+	SuspensionReasonSpam         = "spam"
+	SuspensionReasonManualReview = "manual_review"
+)
+
+// User is the authenticated user document shape the Go server needs.
+type User struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	MobileNumber string             `bson:"mobile_number"`
+	Name         *string            `bson:"name"`
+	IsAdmin      bool               `bson:"is_admin"`
+	IsDeleted    bool               `bson:"is_deleted"`
+	// AccountState is empty for active accounts, or one of the
+	// AccountState* constants.
+	AccountState     string     `bson:"account_state"`
+	SuspensionReason string     `bson:"suspension_reason"`
+	SuspendedUntil   *time.Time `bson:"suspended_until"`
+}
+
+// AuthService validates tokens issued by the Python auth server.
+type AuthService interface {
+	VerifyToken(token string) (string, error)
+	GetUser(userID string) (*User, error)
+}
+
+// AuthServiceImpl verifies the shared-secret JWTs issued at login.
+type AuthServiceImpl struct {
+	cfg *config.Config
+}
+
+// NewAuthService builds the auth service for the given config.
+func NewAuthService(cfg *config.Config) *AuthServiceImpl {
+	return &AuthServiceImpl{cfg: cfg}
+}
+
+// ErrInvalidToken is returned for malformed, expired, or mis-signed tokens.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// AccountStateError is returned when an otherwise valid token belongs to a
+// suspended or banned account. Message is safe to show to the user.
+type AccountStateError struct {
+	State   string
+	Reason  string
+	Message string
+}
+
+func (e *AccountStateError) Error() string { return e.Message }
+
+// accountStateError builds the user-facing error for a restricted account.
+func accountStateError(user *User) *AccountStateError {
+	if user.AccountState == AccountStateBanned {
+		return &AccountStateError{
+			State:   AccountStateBanned,
+			Reason:  user.SuspensionReason,
+			Message: "Your account has been banned. Contact support if you believe this is a mistake.",
+		}
+	}
+	msg := "Your account is temporarily suspended."
+	if user.SuspendedUntil != nil {
+		msg = "Your account is suspended until " + user.SuspendedUntil.Format("02 Jan 2006") + "."
+	}
+	return &AccountStateError{
+		State:   AccountStateSuspended,
+		Reason:  user.SuspensionReason,
+		Message: msg,
+	}
+}
+
+// CheckAccountState returns an AccountStateError if the account is currently
+// suspended or banned. Expired temporary suspensions are treated as active
+// and lazily cleared from the document.
+func (s *AuthServiceImpl) CheckAccountState(user *User) error {
+	switch user.AccountState {
+	case AccountStateBanned:
+		return accountStateError(user)
+	case AccountStateSuspended:
+		if user.SuspendedUntil != nil && time.Now().UTC().After(*user.SuspendedUntil) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, _ = database.Collection(s.cfg, "users").UpdateOne(ctx,
+				bson.M{"_id": user.ID},
+				bson.M{"$unset": bson.M{"account_state": "", "suspension_reason": "", "suspended_until": ""}})
+			return nil
+		}
+		return accountStateError(user)
+	}
+	return nil
+}
+
+// VerifyToken parses and validates a JWT, returning the user ID claim.
+func (s *AuthServiceImpl) VerifyToken(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(s.cfg.JWTSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return "", ErrInvalidToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok || sub == "" {
+		return "", ErrInvalidToken
+	}
+	return sub, nil
+}
+
+// GetUser loads a user document by its hex ObjectID.
+func (s *AuthServiceImpl) GetUser(userID string) (*User, error) {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var user User
+	if err := database.Collection(s.cfg, "users").FindOne(ctx, bson.M{"_id": oid}).Decode(&user); err != nil {
+		return nil, err
+	}
+	if user.IsDeleted {
+		return nil, ErrInvalidToken
+	}
+	if err := s.CheckAccountState(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
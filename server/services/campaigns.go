@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+)
+
+// Bulk push campaigns: an all-users send used to blast every device token
+// in one tight loop, which hammers APNs into rate limits, holds the
+// request open for minutes, and loses all progress if the process dies
+// halfway. Campaigns instead run in the background under a send-rate
+// controller — pushes paced to PUSH_SEND_RATE per second across at most
+// PUSH_SEND_WORKERS concurrent senders — and work through the token set
+// in chunks, checkpointing the last token sent after each chunk. A
+// campaign found still "running" at startup belongs to a dead process and
+// is resumed from its checkpoint rather than restarted or dropped.
+
+// Campaign lifecycle states.
+const (
+	CampaignRunning   = "running"
+	CampaignCompleted = "completed"
+	CampaignFailed    = "failed"
+)
+
+// campaignChunkSize is how many device tokens are loaded and sent between
+// checkpoints; small enough that a crash repeats little work, large enough
+// that checkpoint writes are noise next to the sends.
+const campaignChunkSize = 500
+
+// Campaign is one bulk push send, persisted in push_campaigns.
+type Campaign struct {
+	CampaignID string `bson:"campaign_id" json:"campaign_id"`
+	Title      string `bson:"title" json:"title"`
+	Body       string `bson:"body" json:"body"`
+	Status     string `bson:"status" json:"status"`
+	Targeted   int64  `bson:"targeted" json:"targeted"`
+	Delivered  int64  `bson:"delivered" json:"delivered"`
+	Failed     int64  `bson:"failed" json:"failed"`
+	// ResumeAfter is the checkpoint: the last device token a finished
+	// chunk sent to. Resuming continues with tokens sorting after it.
+	ResumeAfter string     `bson:"resume_after" json:"resume_after,omitempty"`
+	Error       string     `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	EndedAt     *time.Time `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+}
+
+// sendRateLimiter paces sends to a fixed rate shared across workers: each
+// wait claims the next send slot and sleeps until it arrives.
+type sendRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newSendRateLimiter builds a limiter for ratePerSecond sends.
+func newSendRateLimiter(ratePerSecond int) *sendRateLimiter {
+	if ratePerSecond < 1 {
+		ratePerSecond = 1
+	}
+	return &sendRateLimiter{interval: time.Second / time.Duration(ratePerSecond)}
+}
+
+// wait blocks until this caller's send slot.
+func (l *sendRateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(time.Until(slot))
+}
+
+// StartCampaign records a bulk send to every active device and starts
+// working through it in the background, returning the campaign to poll.
+func (s *NotificationServiceImpl) StartCampaign(title, body string) (*Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	targeted, err := database.Collection(s.cfg, "device_tokens").CountDocuments(ctx,
+		bson.M{"is_active": true})
+	if err != nil {
+		return nil, err
+	}
+	campaign := Campaign{
+		CampaignID: uuid.NewString(),
+		Title:      title,
+		Body:       body,
+		Status:     CampaignRunning,
+		Targeted:   targeted,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if _, err := database.Collection(s.cfg, "push_campaigns").InsertOne(ctx, campaign); err != nil {
+		return nil, err
+	}
+	go s.runCampaign(campaign)
+	return &campaign, nil
+}
+
+// ResumeCampaigns restarts campaigns a previous process left running, each
+// from its checkpoint. Called once at startup.
+func (s *NotificationServiceImpl) ResumeCampaigns() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "push_campaigns").Find(ctx,
+		bson.M{"status": CampaignRunning})
+	if err != nil {
+		return 0, err
+	}
+	var campaigns []Campaign
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return 0, err
+	}
+	for _, campaign := range campaigns {
+		go s.runCampaign(campaign)
+	}
+	return len(campaigns), nil
+}
+
+// runCampaign works through the active token set from the campaign's
+// checkpoint: load a chunk, fan it out to the paced worker pool, record
+// the counters and the new checkpoint, repeat until the chunk comes back
+// short.
+func (s *NotificationServiceImpl) runCampaign(campaign Campaign) {
+	limiter := newSendRateLimiter(s.cfg.PushSendRate)
+	resumeAfter := campaign.ResumeAfter
+	for {
+		tokens, err := s.campaignChunk(resumeAfter)
+		if err != nil {
+			log.Printf("campaign %s: loading tokens after %q failed: %v",
+				campaign.CampaignID, truncateToken(resumeAfter), err)
+			s.updateCampaign(campaign.CampaignID, bson.M{"$set": bson.M{
+				"status": CampaignFailed, "error": err.Error(), "ended_at": time.Now().UTC(),
+			}})
+			return
+		}
+		if len(tokens) == 0 {
+			break
+		}
+
+		var delivered, failed int64
+		jobs := make(chan DeviceToken)
+		var wg sync.WaitGroup
+		for w := 0; w < s.campaignWorkers(); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for token := range jobs {
+					limiter.wait()
+					if err := s.sendToDevice(token, campaign.Title, campaign.Body, nil); err != nil {
+						log.Printf("campaign %s: send failed for token %s...: %v",
+							campaign.CampaignID, truncateToken(token.DeviceToken), err)
+						atomic.AddInt64(&failed, 1)
+						continue
+					}
+					atomic.AddInt64(&delivered, 1)
+				}
+			}()
+		}
+		for _, token := range tokens {
+			jobs <- token
+		}
+		close(jobs)
+		wg.Wait()
+
+		resumeAfter = tokens[len(tokens)-1].DeviceToken
+		s.updateCampaign(campaign.CampaignID, bson.M{
+			"$inc": bson.M{"delivered": delivered, "failed": failed},
+			"$set": bson.M{"resume_after": resumeAfter},
+		})
+		if len(tokens) < campaignChunkSize {
+			break
+		}
+	}
+	s.updateCampaign(campaign.CampaignID, bson.M{"$set": bson.M{
+		"status": CampaignCompleted, "ended_at": time.Now().UTC(),
+	}})
+}
+
+// campaignChunk loads the next chunk of active tokens after the
+// checkpoint, in token order so progress is resumable.
+func (s *NotificationServiceImpl) campaignChunk(resumeAfter string) ([]DeviceToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "device_tokens").Find(ctx,
+		bson.M{"is_active": true, "device_token": bson.M{"$gt": resumeAfter}},
+		options.Find().SetSort(bson.M{"device_token": 1}).SetLimit(campaignChunkSize))
+	if err != nil {
+		return nil, err
+	}
+	var tokens []DeviceToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// campaignWorkers is the concurrent sender cap.
+func (s *NotificationServiceImpl) campaignWorkers() int {
+	if s.cfg.PushSendWorkers < 1 {
+		return 1
+	}
+	return s.cfg.PushSendWorkers
+}
+
+// updateCampaign applies one progress write; failures are logged — losing
+// a checkpoint only means a resumed campaign repeats one chunk.
+func (s *NotificationServiceImpl) updateCampaign(campaignID string, update bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "push_campaigns").UpdateOne(ctx,
+		bson.M{"campaign_id": campaignID}, update)
+	if err != nil {
+		log.Printf("campaign %s: progress write failed: %v", campaignID, err)
+	}
+}
+
+// GetCampaign returns one campaign by ID, or (nil, nil) when unknown.
+func (s *NotificationServiceImpl) GetCampaign(campaignID string) (*Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var campaign Campaign
+	err := database.Collection(s.cfg, "push_campaigns").FindOne(ctx,
+		bson.M{"campaign_id": campaignID}).Decode(&campaign)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// campaignHistoryLimit bounds the campaign listing.
+const campaignHistoryLimit = 50
+
+// ListCampaigns returns recent campaigns, newest first.
+func (s *NotificationServiceImpl) ListCampaigns() ([]Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "push_campaigns").Find(ctx, bson.M{},
+		options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(campaignHistoryLimit))
+	if err != nil {
+		return nil, err
+	}
+	campaigns := []Campaign{}
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return nil, err
+	}
+	return campaigns, nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+)
+
+// Self-service cancellation: a paid order is cancelled at the refund the
+// studio's policy quotes right now — the same engine the pre-confirmation
+// quote ran, so the user is never shown one number and given another. The
+// refund goes back through the payment gateway when the order carries
+// gateway payment details, otherwise it lands as a reward-wallet credit.
+// Cancelling revokes the order's QR codes and wallet passes and emits an
+// OrderCancelled domain event so the booking flow can release the seat back
+// to capacity and promote any waitlist.
+
+const (
+	// cancelRateLimit cancellations per cancelRateWindow per user; genuine
+	// plan changes never hit this, scripted churn does.
+	cancelRateLimit  = 3
+	cancelRateWindow = 24 * time.Hour
+	// cancelChurnThreshold cancellations inside cancelChurnWindow flags the
+	// account into the moderation queue (book-and-cancel seat hoarding).
+	cancelChurnThreshold = 5
+	cancelChurnWindow    = 30 * 24 * time.Hour
+)
+
+// Refund settlement methods recorded on a cancelled order.
+const (
+	RefundMethodGateway = "gateway"
+	RefundMethodWallet  = "wallet_credit"
+	RefundMethodNone    = "none"
+)
+
+// CancelResult is the outcome of a completed cancellation.
+type CancelResult struct {
+	OrderID       string      `json:"order_id"`
+	Status        OrderStatus `json:"status"`
+	RefundMethod  string      `json:"refund_method"`
+	RefundPercent int64       `json:"refund_percent"`
+	RefundAmount  int64       `json:"refund_amount"`
+	Currency      string      `json:"currency"`
+	CancelledAt   time.Time   `json:"cancelled_at"`
+}
+
+// CheckCancelRateLimit rejects users cancelling more orders than the daily
+// limit allows.
+func (s *OrderServiceImpl) CheckCancelRateLimit(userID string) error {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+
+	cutoff := time.Now().Add(-cancelRateWindow)
+	kept := s.recentCancels[userID][:0]
+	for _, t := range s.recentCancels[userID] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= cancelRateLimit {
+		s.recentCancels[userID] = kept
+		return fmt.Errorf("too many cancellations, try again later")
+	}
+	s.recentCancels[userID] = append(kept, time.Now())
+	return nil
+}
+
+// CancelOrder cancels a paid order for its owner at the refund the studio's
+// policy evaluates now. Suspended and banned accounts cannot cancel; the
+// caller is expected to have run CheckCancelRateLimit first.
+func (s *OrderServiceImpl) CancelOrder(orderID, userID string) (*CancelResult, error) {
+	if _, err := s.auth.GetUser(userID); err != nil {
+		return nil, err
+	}
+	quote, err := s.QuoteRefund(orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	method := RefundMethodNone
+	if quote.RefundAmount > 0 {
+		if s.hasGatewayPayment(orderID) {
+			method = RefundMethodGateway
+		} else {
+			method = RefundMethodWallet
+		}
+	}
+
+	// The status filter makes the cancellation idempotent under races: two
+	// concurrent requests cannot both flip the order and refund it twice.
+	now := time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(s.cfg, "orders").UpdateOne(ctx,
+		bson.M{"order_id": orderID, "user_id": userID, "status": OrderStatusPaid},
+		bson.M{"$set": bson.M{
+			"status":            OrderStatusCancelled,
+			"cancelled_at":      now,
+			"refund_method":     method,
+			"refund_percent":    quote.RefundPercent,
+			"refund_amount":     quote.RefundAmount,
+			"qr_invalidated_at": now,
+			"updated_at":        now,
+		}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("order is no longer cancellable: %s", orderID)
+	}
+
+	switch method {
+	case RefundMethodGateway:
+		s.requestGatewayRefund(orderID, quote.RefundAmount)
+	case RefundMethodWallet:
+		s.creditWallet(userID, quote.RefundAmount)
+	}
+
+	// Seat bookkeeping lives with the booking flow: the event tells it to
+	// release this seat back to capacity and promote any waitlist entry.
+	EmitEvent(s.cfg, EventOrderCancelled, bson.M{
+		"order_id":      orderID,
+		"user_id":       userID,
+		"workshop_uuid": quote.WorkshopUUID,
+		"refund_method": method,
+		"refund_amount": quote.RefundAmount,
+	})
+	s.recordCancelForAbuse(userID)
+
+	return &CancelResult{
+		OrderID:       orderID,
+		Status:        OrderStatusCancelled,
+		RefundMethod:  method,
+		RefundPercent: quote.RefundPercent,
+		RefundAmount:  quote.RefundAmount,
+		Currency:      quote.Currency,
+		CancelledAt:   now,
+	}, nil
+}
+
+// hasGatewayPayment reports whether the order carries gateway payment
+// details a refund can be issued against.
+func (s *OrderServiceImpl) hasGatewayPayment(orderID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc struct {
+		Details bson.M `bson:"payment_gateway_details"`
+	}
+	if err := database.Collection(s.cfg, "orders").FindOne(ctx,
+		bson.M{"order_id": orderID}).Decode(&doc); err != nil {
+		return false
+	}
+	return len(doc.Details) > 0
+}
+
+// requestGatewayRefund queues the refund for the payment worker; the
+// gateway's refund.processed webhook later moves the order to refunded.
+// Queueing is best-effort: a failure is logged for reconciliation, the
+// cancellation itself has already committed.
+func (s *OrderServiceImpl) requestGatewayRefund(orderID string, amount int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "refund_requests").UpdateOne(ctx,
+		bson.M{"order_id": orderID},
+		bson.M{"$setOnInsert": bson.M{
+			"order_id":     orderID,
+			"amount":       amount,
+			"status":       "pending",
+			"requested_at": time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("cancellation: queue gateway refund for %s failed: %v", orderID, err)
+	}
+}
+
+// creditWallet credits the refund to the user's reward wallet, mirroring the
+// Python rewards flow's balance bookkeeping.
+func (s *OrderServiceImpl) creditWallet(userID string, amount int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	_, err := database.GetMongoClient(s.cfg).Database("dance_app").
+		Collection("reward_wallets").UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$inc": bson.M{
+				"total_balance":     float64(amount),
+				"available_balance": float64(amount),
+				"lifetime_earned":   float64(amount),
+			},
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("cancellation: wallet credit for %s failed: %v", userID, err)
+	}
+}
+
+// recordCancelForAbuse flags accounts churning through bookings into the
+// moderation queue. Detection is advisory: errors are logged, never
+// returned to the caller.
+func (s *OrderServiceImpl) recordCancelForAbuse(userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	count, err := database.Collection(s.cfg, "orders").CountDocuments(ctx, bson.M{
+		"user_id":      userID,
+		"status":       OrderStatusCancelled,
+		"cancelled_at": bson.M{"$gte": time.Now().UTC().Add(-cancelChurnWindow)},
+	})
+	if err != nil {
+		log.Printf("abuse check: cancellation count failed for %s: %v", userID, err)
+		return
+	}
+	if count < cancelChurnThreshold {
+		return
+	}
+	_, err = database.Collection(s.cfg, "abuse_flags").UpdateOne(ctx,
+		bson.M{"user_id": userID, "reason": "cancellation_churn", "resolved": false},
+		bson.M{"$setOnInsert": AbuseFlag{
+			UserID:    userID,
+			Reason:    "cancellation_churn",
+			Detail:    "repeated book-and-cancel across recent orders",
+			FlaggedAt: time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("abuse: persist cancellation flag for %s failed: %v", userID, err)
+	}
+}
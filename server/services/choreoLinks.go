@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Choreo link submissions: artists who have claimed their profile can map
+// their own choreography Instagram links to songs. Submissions sit in a
+// pending state until an admin approves them; approval upserts the link
+// into choreo_links and attaches it to matching workshops, so the artist
+// never writes to workshop documents directly.
+
+// Submission statuses.
+const (
+	ChoreoStatusPending  = "pending"
+	ChoreoStatusApproved = "approved"
+	ChoreoStatusRejected = "rejected"
+)
+
+// ChoreoSubmission is one pending or reviewed choreo link submission.
+type ChoreoSubmission struct {
+	SubmissionID string     `bson:"submission_id" json:"submission_id"`
+	ArtistID     string     `bson:"artist_id" json:"artist_id"`
+	UserID       string     `bson:"user_id" json:"user_id"`
+	Song         string     `bson:"song" json:"song"`
+	InstaLink    string     `bson:"insta_link" json:"insta_link"`
+	Status       string     `bson:"status" json:"status"`
+	SubmittedAt  time.Time  `bson:"submitted_at" json:"submitted_at"`
+	ReviewedAt   *time.Time `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	ReviewedBy   string     `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+}
+
+// ChoreoLinkServiceImpl manages choreo link submissions and moderation.
+type ChoreoLinkServiceImpl struct {
+	cfg *config.Config
+}
+
+// NewChoreoLinkService builds the choreo link service.
+func NewChoreoLinkService(cfg *config.Config) *ChoreoLinkServiceImpl {
+	return &ChoreoLinkServiceImpl{cfg: cfg}
+}
+
+// SubmitChoreoLink records a pending submission for the artist profile the
+// user has claimed. The claim lives on the artist document as
+// claimed_by_user_id (set by an admin when verifying ownership).
+func (s *ChoreoLinkServiceImpl) SubmitChoreoLink(userID, artistID, song, instaLink string) (*ChoreoSubmission, error) {
+	if song == "" || !strings.Contains(instaLink, "instagram.com/") {
+		return nil, fmt.Errorf("a song and an instagram.com link are required")
+	}
+	if err := s.verifyClaim(userID, artistID); err != nil {
+		return nil, err
+	}
+	submission := &ChoreoSubmission{
+		SubmissionID: uuid.NewString(),
+		ArtistID:     artistID,
+		UserID:       userID,
+		Song:         song,
+		InstaLink:    instaLink,
+		Status:       ChoreoStatusPending,
+		SubmittedAt:  time.Now().UTC(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := database.Collection(s.cfg, "choreo_link_submissions").InsertOne(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// verifyClaim checks that the user has claimed the artist profile.
+func (s *ChoreoLinkServiceImpl) verifyClaim(userID, artistID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var artist struct {
+		ClaimedBy string `bson:"claimed_by_user_id"`
+	}
+	err := database.Collection(s.cfg, "artists_v2").FindOne(ctx, bson.M{"artist_id": artistID}).Decode(&artist)
+	if err != nil {
+		return fmt.Errorf("artist not found: %s", artistID)
+	}
+	if artist.ClaimedBy == "" || artist.ClaimedBy != userID {
+		return fmt.Errorf("artist profile is not claimed by this account")
+	}
+	return nil
+}
+
+// GetSubmissionsByUser returns the user's own submissions, newest first.
+func (s *ChoreoLinkServiceImpl) GetSubmissionsByUser(userID string) ([]ChoreoSubmission, error) {
+	return s.findSubmissions(bson.M{"user_id": userID})
+}
+
+// GetSubmissionsByStatus lists submissions for the moderation queue.
+func (s *ChoreoLinkServiceImpl) GetSubmissionsByStatus(status string) ([]ChoreoSubmission, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	return s.findSubmissions(filter)
+}
+
+func (s *ChoreoLinkServiceImpl) findSubmissions(filter bson.M) ([]ChoreoSubmission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "choreo_link_submissions").Find(ctx, filter,
+		options.Find().SetSort(bson.M{"submitted_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	submissions := []ChoreoSubmission{}
+	if err := cursor.All(ctx, &submissions); err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// ReviewSubmission approves or rejects a pending submission. Approval
+// upserts the link into choreo_links and attaches it to the artist's
+// workshops teaching the same song that have no choreo link yet.
+func (s *ChoreoLinkServiceImpl) ReviewSubmission(submissionID, reviewerID string, approve bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var submission ChoreoSubmission
+	err := database.Collection(s.cfg, "choreo_link_submissions").FindOne(ctx,
+		bson.M{"submission_id": submissionID, "status": ChoreoStatusPending}).Decode(&submission)
+	if err != nil {
+		return fmt.Errorf("pending submission not found: %s", submissionID)
+	}
+
+	status := ChoreoStatusRejected
+	if approve {
+		status = ChoreoStatusApproved
+	}
+	now := time.Now().UTC()
+	_, err = database.Collection(s.cfg, "choreo_link_submissions").UpdateOne(ctx,
+		bson.M{"submission_id": submissionID},
+		bson.M{"$set": bson.M{"status": status, "reviewed_at": now, "reviewed_by": reviewerID}})
+	if err != nil {
+		return err
+	}
+	if !approve {
+		return nil
+	}
+
+	_, err = database.Collection(s.cfg, "choreo_links").UpdateOne(ctx,
+		bson.M{"artist_id": submission.ArtistID, "song": database.NormalizeSong(submission.Song)},
+		bson.M{"$set": bson.M{
+			"artist_id":         submission.ArtistID,
+			"song":              database.NormalizeSong(submission.Song),
+			"choreo_insta_link": submission.InstaLink,
+			"updated_at":        now,
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return s.attachToWorkshops(ctx, submission)
+}
+
+// attachToWorkshops sets the approved link on the artist's workshops
+// teaching the submitted song that do not carry a choreo link yet.
+func (s *ChoreoLinkServiceImpl) attachToWorkshops(ctx context.Context, submission ChoreoSubmission) error {
+	cursor, err := database.Collection(s.cfg, "workshops_v2").Find(ctx, bson.M{
+		"artist_id_list": submission.ArtistID,
+		"is_archived":    bson.M{"$ne": true},
+	})
+	if err != nil {
+		return err
+	}
+	var workshops []struct {
+		ID              interface{} `bson:"_id"`
+		UUID            string      `bson:"uuid"`
+		Song            *string     `bson:"song"`
+		ChoreoInstaLink *string     `bson:"choreo_insta_link"`
+	}
+	if err := cursor.All(ctx, &workshops); err != nil {
+		return err
+	}
+	want := database.NormalizeSong(submission.Song)
+	for _, w := range workshops {
+		if w.Song == nil || database.NormalizeSong(*w.Song) != want {
+			continue
+		}
+		if w.ChoreoInstaLink != nil && *w.ChoreoInstaLink != "" {
+			continue
+		}
+		_, err := database.Collection(s.cfg, "workshops_v2").UpdateOne(ctx,
+			bson.M{"_id": w.ID},
+			bson.M{"$set": bson.M{"choreo_insta_link": submission.InstaLink}})
+		if err != nil {
+			return err
+		}
+		database.ResolveReviewTasksForWorkshop(s.cfg, w.UUID, database.ReviewFieldChoreoLink)
+	}
+	database.InvalidateForWrite(s.cfg, database.EntityWrite{Entity: "workshop", ArtistIDs: []string{submission.ArtistID}})
+	return nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Contact sync: friend discovery without raw phone numbers leaving the
+// device. The client hashes each contact's number locally (SHA-256 of the
+// last ten digits) and uploads only the hashes; the server hashes its
+// registered numbers the same way and returns the intersection. Raw
+// contact numbers are never stored. Matching respects the per-user privacy
+// flags on the user document: privacy.discoverable_by_contacts (may this
+// user appear in other people's sync results at all) and
+// privacy.share_attendance (may their upcoming workshops be shown). All
+// privacy flags default to true when unset.
+
+// contactSyncMaxHashes caps one sync request; larger address books are
+// synced in batches by the client.
+const contactSyncMaxHashes = 2000
+
+// FriendWorkshop is one upcoming session a discovered friend holds a paid
+// order for.
+type FriendWorkshop struct {
+	WorkshopUUID string  `json:"workshop_uuid"`
+	StudioID     string  `json:"studio_id"`
+	Song         *string `json:"song"`
+	Artist       *string `json:"artist"`
+	Date         string  `json:"date"`
+	Time         string  `json:"time"`
+}
+
+// FriendMatch is one registered user found in the caller's contacts.
+// MobileHash echoes the matched hash so the client can map the result back
+// to a local contact without the server naming the number.
+type FriendMatch struct {
+	UserID     string           `json:"user_id"`
+	Name       *string          `json:"name"`
+	MobileHash string           `json:"mobile_hash"`
+	Workshops  []FriendWorkshop `json:"workshops"`
+}
+
+// ContactSyncResult is the contact sync response.
+type ContactSyncResult struct {
+	Friends []FriendMatch `json:"friends"`
+}
+
+// contactUser is the slice of the user document contact matching reads.
+type contactUser struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	MobileNumber string             `bson:"mobile_number"`
+	Name         *string            `bson:"name"`
+	IsDeleted    bool               `bson:"is_deleted"`
+	Privacy      userPrivacy        `bson:"privacy"`
+}
+
+// userPrivacy holds the per-user social privacy flags. Pointers distinguish
+// "unset" (treated as true) from an explicit opt-out.
+type userPrivacy struct {
+	DiscoverableByContacts *bool `bson:"discoverable_by_contacts"`
+	ShareAttendance        *bool `bson:"share_attendance"`
+	AllowFriendInvites     *bool `bson:"allow_friend_invites"`
+}
+
+// allows treats an unset flag as permission; only an explicit false opts
+// out.
+func allows(flag *bool) bool {
+	return flag == nil || *flag
+}
+
+// HashMobileNumber normalizes a phone number to its last ten digits
+// (dropping the country code) and returns the lowercase hex SHA-256 —
+// the same derivation the client applies to contacts before upload.
+func HashMobileNumber(number string) string {
+	var digits strings.Builder
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	normalized := digits.String()
+	if len(normalized) > 10 {
+		normalized = normalized[len(normalized)-10:]
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// SyncContacts matches the uploaded contact hashes against registered
+// users and returns the friends found, each with the upcoming workshops
+// they are attending when their privacy settings allow it. The caller
+// never appears in their own results.
+func SyncContacts(cfg *config.Config, userID string, hashes []string) (*ContactSyncResult, error) {
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("contact_hashes is required")
+	}
+	if len(hashes) > contactSyncMaxHashes {
+		return nil, fmt.Errorf("at most %d contact hashes per request", contactSyncMaxHashes)
+	}
+	wanted := make(map[string]struct{}, len(hashes))
+	for _, h := range hashes {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if len(h) != 64 {
+			return nil, fmt.Errorf("contact hashes must be 64-character hex SHA-256 digests")
+		}
+		wanted[h] = struct{}{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	cursor, err := database.Collection(cfg, "users").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{
+			"mobile_number": 1, "name": 1, "is_deleted": 1, "privacy": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var users []contactUser
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	result := &ContactSyncResult{Friends: []FriendMatch{}}
+	for _, u := range users {
+		if u.IsDeleted || u.ID.Hex() == userID || !allows(u.Privacy.DiscoverableByContacts) {
+			continue
+		}
+		hash := HashMobileNumber(u.MobileNumber)
+		if _, ok := wanted[hash]; !ok {
+			continue
+		}
+		match := FriendMatch{
+			UserID:     u.ID.Hex(),
+			Name:       u.Name,
+			MobileHash: hash,
+			Workshops:  []FriendWorkshop{},
+		}
+		if allows(u.Privacy.ShareAttendance) {
+			workshops, err := friendUpcomingWorkshops(ctx, cfg, match.UserID)
+			if err != nil {
+				return nil, err
+			}
+			match.Workshops = workshops
+		}
+		result.Friends = append(result.Friends, match)
+	}
+	recordFriendEdges(ctx, cfg, userID, result.Friends)
+	return result, nil
+}
+
+// friendUpcomingWorkshops lists the upcoming workshops a user holds a paid
+// order for, formatted for display alongside the friend.
+func friendUpcomingWorkshops(ctx context.Context, cfg *config.Config, userID string) ([]FriendWorkshop, error) {
+	cursor, err := database.Collection(cfg, "orders").Find(ctx,
+		bson.M{"user_id": userID, "status": OrderStatusPaid})
+	if err != nil {
+		return nil, err
+	}
+	var orders []Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	uuids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		uuids = append(uuids, order.WorkshopUUID)
+	}
+	if len(uuids) == 0 {
+		return []FriendWorkshop{}, nil
+	}
+
+	wcursor, err := database.Collection(cfg, "workshops_v2").Find(ctx,
+		bson.M{"uuid": bson.M{"$in": uuids}, "is_archived": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, err
+	}
+	var workshops []response.Workshop
+	if err := wcursor.All(ctx, &workshops); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	upcoming := []FriendWorkshop{}
+	for _, w := range workshops {
+		for _, td := range w.TimeDetails {
+			epoch := utils.GetTimestampEpoch(td)
+			if epoch == 0 || epoch < now {
+				continue
+			}
+			upcoming = append(upcoming, FriendWorkshop{
+				WorkshopUUID: w.UUID,
+				StudioID:     w.StudioID,
+				Song:         w.Song,
+				Artist:       w.By,
+				Date:         utils.GetFormattedDate(td),
+				Time:         utils.GetFormattedTime(td),
+			})
+			break
+		}
+	}
+	return upcoming, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Dead-link detection: studio booking URLs expire or 404 after events
+// change, leaving a book button that leads nowhere. A nightly sweep
+// HEAD-checks the payment link of every upcoming workshop, records broken
+// ones in the dead_links collection (which listings consult to hide the
+// book button), and pushes an alert to admin devices when new breakage
+// appears.
+
+// deadLinkClient performs the link probes; booking pages can be slow, so
+// the timeout is generous.
+var deadLinkClient = &http.Client{Timeout: 15 * time.Second}
+
+// DeadLinkSweep summarizes one pass of the checker.
+type DeadLinkSweep struct {
+	Checked   int       `json:"checked"`
+	Dead      int       `json:"dead"`
+	NewlyDead int       `json:"newly_dead"`
+	Recovered int       `json:"recovered"`
+	SweptAt   time.Time `json:"swept_at"`
+}
+
+// CheckPaymentLinks probes every distinct payment link on upcoming
+// workshops and reconciles the dead_links collection: newly broken links
+// are flagged, recovered ones unflagged. Admins get one push per sweep
+// that found new breakage.
+func CheckPaymentLinks(cfg *config.Config) (*DeadLinkSweep, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	values, err := database.Collection(cfg, "workshops_v2").Distinct(ctx, "payment_link",
+		bson.M{"is_archived": bson.M{"$ne": true}})
+	if err != nil {
+		return nil, err
+	}
+
+	previouslyDead := database.GetDeadPaymentLinks(cfg)
+	sweep := &DeadLinkSweep{SweptAt: time.Now().UTC()}
+	alive := []string{}
+
+	for _, value := range values {
+		link, ok := value.(string)
+		if !ok || !strings.HasPrefix(link, "http") {
+			continue
+		}
+		sweep.Checked++
+		statusCode, dead := probeLink(link)
+		if !dead {
+			alive = append(alive, link)
+			if previouslyDead[link] {
+				sweep.Recovered++
+			}
+			continue
+		}
+		sweep.Dead++
+		if !previouslyDead[link] {
+			sweep.NewlyDead++
+		}
+		recordDeadLink(cfg, link, statusCode)
+	}
+
+	if len(alive) > 0 {
+		dbCtx, dbCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if _, err := database.Collection(cfg, "dead_links").DeleteMany(dbCtx,
+			bson.M{"payment_link": bson.M{"$in": alive}}); err != nil {
+			log.Printf("dead links: cleanup failed: %v", err)
+		}
+		dbCancel()
+	}
+	database.InvalidateDeadLinksCache()
+
+	if sweep.NewlyDead > 0 {
+		notifyAdminsOfDeadLinks(cfg, sweep)
+	}
+	return sweep, nil
+}
+
+// probeLink HEAD-checks one URL, falling back to GET for servers that
+// reject HEAD. Dead means the URL is gone (404/410) or unreachable; other
+// statuses — including auth walls and rate limits — count as alive so a
+// flaky origin does not hide working book buttons.
+func probeLink(link string) (int, bool) {
+	resp, err := deadLinkClient.Head(link)
+	if err != nil {
+		return 0, true
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		resp, err = deadLinkClient.Get(link)
+		if err != nil {
+			return 0, true
+		}
+		resp.Body.Close()
+	}
+	dead := resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone
+	return resp.StatusCode, dead
+}
+
+// recordDeadLink upserts one broken link into the dead_links collection.
+func recordDeadLink(cfg *config.Config, link string, statusCode int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "dead_links").UpdateOne(ctx,
+		bson.M{"payment_link": link},
+		bson.M{
+			"$set":         bson.M{"status_code": statusCode, "last_checked_at": time.Now().UTC()},
+			"$setOnInsert": bson.M{"payment_link": link, "first_flagged_at": time.Now().UTC()},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("dead links: record failed for %s: %v", link, err)
+	}
+}
+
+// notifyAdminsOfDeadLinks pushes one alert per sweep to every admin's
+// devices. Best-effort: a failed push is logged, never returned.
+func notifyAdminsOfDeadLinks(cfg *config.Config, sweep *DeadLinkSweep) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "users").Find(ctx, bson.M{"is_admin": true})
+	if err != nil {
+		log.Printf("dead links: admin lookup failed: %v", err)
+		return
+	}
+	var admins []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &admins); err != nil {
+		log.Printf("dead links: admin lookup failed: %v", err)
+		return
+	}
+
+	notif := NewNotificationService(cfg)
+	title := "Dead payment links detected"
+	body := fmt.Sprintf("%d booking links are failing (%d new). Check the affected studios.", sweep.Dead, sweep.NewlyDead)
+	for _, admin := range admins {
+		userID := admin.ID.Hex()
+		if err := notif.sendToUser(userID, title, body, map[string]string{"deep_link": "nachna://admin/dead-links"}); err != nil {
+			log.Printf("dead links: admin push failed for %s: %v", userID, err)
+		}
+	}
+}
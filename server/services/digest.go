@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+	"dance_api_go/utils"
+)
+
+// Notification digest mode: users following many artists get one push per
+// new workshop, which trains them to disable notifications. With the digest
+// preference enabled, matches are buffered in notification_digest_queue and
+// sent as a single evening push deep-linking to the digest screen.
+
+// Delivery modes selectable through the notification preferences API.
+const (
+	NotificationModeImmediate = "immediate"
+	NotificationModeDigest    = "digest"
+)
+
+// digestSendHourIST is the IST hour at which daily digests go out.
+const digestSendHourIST = 19
+
+// NotificationPreferences is a user's notification delivery settings.
+type NotificationPreferences struct {
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Mode      string    `bson:"mode" json:"mode"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// digestEntry is one buffered workshop match awaiting the evening digest.
+type digestEntry struct {
+	UserID       string    `bson:"user_id"`
+	WorkshopUUID string    `bson:"workshop_uuid"`
+	ArtistName   string    `bson:"artist_name"`
+	Sent         bool      `bson:"sent"`
+	QueuedAt     time.Time `bson:"queued_at"`
+}
+
+// GetNotificationPreferences returns the user's preferences, defaulting to
+// immediate delivery.
+func (s *NotificationServiceImpl) GetNotificationPreferences(userID string) (*NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var prefs NotificationPreferences
+	err := database.Collection(s.cfg, "notification_preferences").FindOne(ctx,
+		bson.M{"user_id": userID}).Decode(&prefs)
+	if err != nil {
+		return &NotificationPreferences{UserID: userID, Mode: NotificationModeImmediate}, nil
+	}
+	return &prefs, nil
+}
+
+// SetNotificationPreferences updates the user's delivery mode.
+func (s *NotificationServiceImpl) SetNotificationPreferences(userID, mode string) error {
+	if mode != NotificationModeImmediate && mode != NotificationModeDigest {
+		return fmt.Errorf("invalid notification mode: %s", mode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "notification_preferences").UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$set": NotificationPreferences{
+			UserID:    userID,
+			Mode:      mode,
+			UpdatedAt: time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// NotifyOrQueue delivers a new-workshop match respecting the user's mode:
+// immediate users get a push now, digest users get the match buffered for
+// the evening digest.
+func (s *NotificationServiceImpl) NotifyOrQueue(userID, workshopUUID, artistName string) error {
+	prefs, err := s.GetNotificationPreferences(userID)
+	if err != nil {
+		return err
+	}
+	if prefs.Mode == NotificationModeDigest {
+		return s.queueDigestEntry(userID, workshopUUID, artistName)
+	}
+	data := map[string]string{"deep_link": "nachna://workshop/" + workshopUUID}
+	s.applyWorkshopBranding(workshopUUID, data)
+	return s.sendToUser(userID,
+		fmt.Sprintf("%s just added a workshop", artistName),
+		"Tap to see the schedule and book your spot.",
+		data)
+}
+
+// applyWorkshopBranding attaches the workshop's studio branding to a push
+// payload so the client renders the studio's sender name, accent color, and
+// logo instead of the Nachna defaults.
+func (s *NotificationServiceImpl) applyWorkshopBranding(workshopUUID string, data map[string]string) {
+	workshop, err := database.GetWorkshopByUUID(s.cfg, workshopUUID)
+	if err != nil {
+		return
+	}
+	branding := database.BrandingForStudio(s.cfg, workshop.StudioID)
+	data["sender_name"] = branding.SenderName
+	data["accent_color"] = branding.AccentColor
+	if branding.LogoURL != "" {
+		data["logo_url"] = branding.LogoURL
+	}
+}
+
+// queueDigestEntry buffers one match for the user's next digest.
+func (s *NotificationServiceImpl) queueDigestEntry(userID, workshopUUID, artistName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "notification_digest_queue").UpdateOne(ctx,
+		bson.M{"user_id": userID, "workshop_uuid": workshopUUID, "sent": false},
+		bson.M{"$setOnInsert": digestEntry{
+			UserID:       userID,
+			WorkshopUUID: workshopUUID,
+			ArtistName:   artistName,
+			QueuedAt:     time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// SendDailyDigests sends one batched push per user with pending entries and
+// marks them sent. Returns the number of digests delivered.
+func (s *NotificationServiceImpl) SendDailyDigests() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "notification_digest_queue").Find(ctx,
+		bson.M{"sent": false})
+	if err != nil {
+		return 0, err
+	}
+	var entries []digestEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return 0, err
+	}
+	byUser := make(map[string][]digestEntry)
+	for _, e := range entries {
+		byUser[e.UserID] = append(byUser[e.UserID], e)
+	}
+
+	sent := 0
+	for userID, userEntries := range byUser {
+		title := fmt.Sprintf("%d new workshops from artists you follow", len(userEntries))
+		if len(userEntries) == 1 {
+			title = fmt.Sprintf("%s just added a workshop", userEntries[0].ArtistName)
+		}
+		err := s.sendToUser(userID, title,
+			"Tap to see today's digest.",
+			map[string]string{"deep_link": "nachna://digest"})
+		if err != nil {
+			log.Printf("digest send failed for user %s: %v", userID, err)
+			continue
+		}
+		sent++
+		_, err = database.Collection(s.cfg, "notification_digest_queue").UpdateMany(ctx,
+			bson.M{"user_id": userID, "sent": false},
+			bson.M{"$set": bson.M{"sent": true}})
+		if err != nil {
+			log.Printf("digest mark-sent failed for user %s: %v", userID, err)
+		}
+	}
+	return sent, nil
+}
+
+// NextDigestTime returns the next evening send time after now in IST.
+func NextDigestTime(now time.Time) time.Time {
+	nowIST := now.In(utils.IST)
+	next := time.Date(nowIST.Year(), nowIST.Month(), nowIST.Day(), digestSendHourIST, 0, 0, 0, utils.IST)
+	if !next.After(nowIST) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// sendToUser pushes to every active device registered to the user, iOS and
+// Android alike.
+func (s *NotificationServiceImpl) sendToUser(userID, title, body string, data map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "device_tokens").Find(ctx,
+		bson.M{"user_id": userID, "is_active": true})
+	if err != nil {
+		return err
+	}
+	var tokens []DeviceToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := s.sendToDevice(token, title, body, data); err != nil {
+			log.Printf("%s send failed for token %s...: %v", token.Platform, truncateToken(token.DeviceToken), err)
+		}
+	}
+	return nil
+}
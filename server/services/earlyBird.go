@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Early-bird closing alerts: when a workshop's active price tier has a
+// deadline inside the lead window, users who liked the workshop get one
+// push nudging them to book before the price goes up. Seat-capped tiers
+// have no predictable closing moment, so only dated deadlines alert.
+// Delivery is deduped per user and workshop in the early_bird_alerts
+// collection, mirroring how reminders dedupe.
+
+// EarlyBirdAlertLeadHours is how far before a tier's deadline the closing
+// alert goes out.
+const EarlyBirdAlertLeadHours = 24
+
+// SendEarlyBirdClosingAlerts scans tiered upcoming workshops for active
+// tiers whose deadline falls within the lead window and alerts every
+// un-alerted liker. Returns the number of alerts delivered.
+func (s *NotificationServiceImpl) SendEarlyBirdClosingAlerts() (int, error) {
+	workshops, err := database.GetTieredUpcomingWorkshops(s.cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	deadline := now + EarlyBirdAlertLeadHours*3600
+	sent := 0
+	for _, w := range workshops {
+		tier, err := database.CurrentWorkshopTier(s.cfg, w.UUID)
+		if err != nil {
+			log.Printf("early bird %s: tier resolution failed: %v", w.UUID, err)
+			continue
+		}
+		if tier == nil || tier.UntilEpoch == 0 || tier.UntilEpoch <= now || tier.UntilEpoch > deadline {
+			continue
+		}
+		for _, userID := range s.workshopLikers(w.UUID) {
+			if !s.markEarlyBirdAlerted(userID, w.UUID) {
+				continue
+			}
+			if err := s.sendEarlyBirdAlert(userID, w, tier); err != nil {
+				log.Printf("early bird %s: alert failed for user %s: %v", w.UUID, userID, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// workshopLikers returns the users with a live like on the workshop.
+func (s *NotificationServiceImpl) workshopLikers(workshopUUID string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "reactions").Find(ctx,
+		bson.M{"entity_id": workshopUUID, "entity_type": "WORKSHOP", "reaction": "LIKE", "is_deleted": false})
+	if err != nil {
+		log.Printf("early bird: liker lookup failed for %s: %v", workshopUUID, err)
+		return nil
+	}
+	var reactions []Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		log.Printf("early bird: liker lookup failed for %s: %v", workshopUUID, err)
+		return nil
+	}
+	userIDs := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		userIDs = append(userIDs, r.UserID)
+	}
+	return userIDs
+}
+
+// sendEarlyBirdAlert pushes one closing alert with the tier's price and
+// deadline.
+func (s *NotificationServiceImpl) sendEarlyBirdAlert(userID string, w response.Workshop, tier *response.PriceTier) error {
+	title := "Early-bird price ending soon"
+	if w.Song != nil && *w.Song != "" {
+		title = fmt.Sprintf("%s: early-bird price ending soon", *w.Song)
+	}
+	closes := time.Unix(tier.UntilEpoch, 0).In(utils.IST).Format("Mon 3:04 PM")
+	body := fmt.Sprintf("The %s price closes %s. Book now before it goes up!", tier.Name, closes)
+	data := map[string]string{"deep_link": "nachna://workshop/" + w.UUID}
+	s.applyWorkshopBranding(w.UUID, data)
+	return s.sendToUser(userID, title, body, data)
+}
+
+// markEarlyBirdAlerted records the alert in early_bird_alerts and reports
+// whether this call was the first for the user-workshop pair. A Mongo
+// error counts as first — a duplicate alert beats a missed one.
+func (s *NotificationServiceImpl) markEarlyBirdAlerted(userID, workshopUUID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(s.cfg, "early_bird_alerts").UpdateOne(ctx,
+		bson.M{"user_id": userID, "workshop_uuid": workshopUUID},
+		bson.M{"$setOnInsert": bson.M{
+			"user_id":       userID,
+			"workshop_uuid": workshopUUID,
+			"sent_at":       time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("early_bird_alerts: record failed for user %s: %v", userID, err)
+		return true
+	}
+	return result.MatchedCount == 0
+}
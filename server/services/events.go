@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Domain events: canonical records of the things that happened on the
+// platform, appended to the domain_events collection for offline analytics.
+// The schema registry below is the contract — every event type declares its
+// required payload fields, and EmitEvent refuses payloads that do not
+// satisfy them so the export never ships half-formed rows.
+
+// Canonical event types.
+const (
+	EventWorkshopPublished = "WorkshopPublished"
+	EventOrderPaid         = "OrderPaid"
+	EventOrderCancelled    = "OrderCancelled"
+	EventReactionCreated   = "ReactionCreated"
+	EventUserRegistered    = "UserRegistered"
+	EventScraperDisabled   = "ScraperDisabled"
+)
+
+// eventSchemaVersion is bumped when any payload contract changes.
+const eventSchemaVersion = 1
+
+// eventSchemas is the registry of required payload fields per event type.
+// UserRegistered is emitted by the Python registration flow; it is declared
+// here so both emitters share one contract.
+var eventSchemas = map[string][]string{
+	EventWorkshopPublished: {"workshop_uuid", "studio_id"},
+	EventOrderPaid:         {"order_id", "user_id", "workshop_uuid", "amount"},
+	EventOrderCancelled:    {"order_id", "user_id", "workshop_uuid", "refund_method"},
+	EventReactionCreated:   {"user_id", "entity_id", "entity_type", "reaction"},
+	EventUserRegistered:    {"user_id"},
+	EventScraperDisabled:   {"studio_id", "reason"},
+}
+
+// EmitEvent appends one domain event. Emission is best-effort: a failure is
+// logged and swallowed so analytics can never break the write path that
+// triggered it.
+func EmitEvent(cfg *config.Config, eventType string, payload bson.M) {
+	if err := validateEvent(eventType, payload); err != nil {
+		log.Printf("domain events: dropping invalid %s event: %v", eventType, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "domain_events").InsertOne(ctx, bson.M{
+		"event_id":       uuid.NewString(),
+		"type":           eventType,
+		"schema_version": eventSchemaVersion,
+		"payload":        payload,
+		"emitted_at":     time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("domain events: insert %s failed: %v", eventType, err)
+	}
+}
+
+// validateEvent checks an event against the schema registry.
+func validateEvent(eventType string, payload bson.M) error {
+	required, ok := eventSchemas[eventType]
+	if !ok {
+		return fmt.Errorf("unknown event type")
+	}
+	for _, field := range required {
+		if _, present := payload[field]; !present {
+			return fmt.Errorf("missing required field %q", field)
+		}
+	}
+	return nil
+}
+
+// exportBatchSize bounds one export push.
+const exportBatchSize = 500
+
+// ExportDomainEvents pushes unexported events to the analytics warehouse as
+// newline-delimited JSON (the insert format both ClickHouse's HTTP
+// interface and BigQuery loaders accept) and stamps exported_at on success.
+// It returns the number of events shipped; with no export URL configured it
+// is a no-op.
+func ExportDomainEvents(cfg *config.Config) (int, error) {
+	if cfg.AnalyticsExportURL == "" {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	coll := database.Collection(cfg, "domain_events")
+	cursor, err := coll.Find(ctx,
+		bson.M{"exported_at": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.M{"emitted_at": 1}).SetLimit(exportBatchSize))
+	if err != nil {
+		return 0, err
+	}
+	var events []bson.M
+	if err := cursor.All(ctx, &events); err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	var body bytes.Buffer
+	ids := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		delete(event, "_id")
+		line, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+		ids = append(ids, event["event_id"])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.AnalyticsExportURL, &body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("warehouse returned %d", resp.StatusCode)
+	}
+
+	_, err = coll.UpdateMany(ctx,
+		bson.M{"event_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"exported_at": time.Now().UTC()}})
+	if err != nil {
+		return len(ids), err
+	}
+	return len(ids), nil
+}
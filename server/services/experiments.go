@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Experiments: users are bucketed into variants deterministically — the
+// same user always sees the same variant, with no assignment state to
+// store — and every time a variant actually shapes what a user saw, an
+// exposure row is recorded so downstream analysis can join bookings
+// against variants. Anonymous traffic stays on the first (control)
+// variant and is not logged; without a stable identity there is nothing
+// to join on.
+
+// ExperimentWorkshopRanking selects the workshop list ranking variant.
+const ExperimentWorkshopRanking = "workshop_ranking"
+
+// AssignVariant buckets a user into one of the variants by hashing the
+// experiment name and user ID. Empty userID gets the first variant.
+func AssignVariant(experiment, userID string, variants []string) string {
+	if len(variants) == 0 {
+		return ""
+	}
+	if userID == "" {
+		return variants[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(experiment + ":" + userID))
+	return variants[int(h.Sum32())%len(variants)]
+}
+
+// RecordExposure logs that a user saw their variant of an experiment, one
+// row per user per experiment with first/last timestamps and a counter.
+// Best effort — exposure bookkeeping must never fail the request.
+func RecordExposure(cfg *config.Config, experiment, variant, userID string) {
+	if userID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	_, err := database.Collection(cfg, "experiment_exposures").UpdateOne(ctx,
+		bson.M{"experiment": experiment, "user_id": userID},
+		bson.M{
+			"$set":         bson.M{"variant": variant, "last_exposed_at": now},
+			"$setOnInsert": bson.M{"first_exposed_at": now},
+			"$inc":         bson.M{"exposures": 1},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("experiments: exposure write failed for %s/%s: %v", experiment, userID, err)
+	}
+}
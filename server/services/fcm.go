@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"dance_api_go/config"
+)
+
+// FCM delivery for Android devices, mirroring the APNs path: a cached
+// OAuth access token minted from the Firebase service account, and one
+// HTTP v1 send per device token. Credentials come from the service
+// account JSON at FCM_CREDENTIALS_PATH; without it Android sends fail
+// with a configuration error, same as APNs without its key.
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+var (
+	fcmClient     = &http.Client{Timeout: 10 * time.Second}
+	fcmToken      string
+	fcmTokenAt    time.Time
+	fcmProjectID  string
+	fcmTokenMutex sync.Mutex
+)
+
+// fcmServiceAccount is the subset of the Firebase service account JSON the
+// sender needs.
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	PrivateKey  string `json:"private_key"`
+	ClientEmail string `json:"client_email"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmAccessToken returns a cached OAuth access token for the messaging
+// scope, exchanging a fresh service-account JWT when the previous token is
+// near Google's one hour expiry.
+func fcmAccessToken(cfg *config.Config) (string, error) {
+	fcmTokenMutex.Lock()
+	defer fcmTokenMutex.Unlock()
+
+	if fcmToken != "" && time.Since(fcmTokenAt) < 50*time.Minute {
+		return fcmToken, nil
+	}
+
+	raw, err := os.ReadFile(cfg.FCMCredentialsPath)
+	if err != nil {
+		return "", fmt.Errorf("read FCM credentials: %w", err)
+	}
+	var account fcmServiceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return "", fmt.Errorf("parse FCM credentials: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("parse FCM private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	resp, err := fcmClient.Post(account.TokenURI,
+		"application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FCM token exchange responded %d", resp.StatusCode)
+	}
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+
+	fcmToken = tokenResp.AccessToken
+	fcmTokenAt = now
+	fcmProjectID = account.ProjectID
+	return fcmToken, nil
+}
+
+// fcmPush delivers one notification payload to an Android device token
+// over the FCM HTTP v1 API.
+func fcmPush(cfg *config.Config, deviceToken string, payload map[string]interface{}) error {
+	accessToken, err := fcmAccessToken(cfg)
+	if err != nil {
+		return err
+	}
+	if message, ok := payload["message"].(map[string]interface{}); ok {
+		message["token"] = deviceToken
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", fcmProjectID)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := fcmClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("FCM responded %d", resp.StatusCode)
+	}
+	return nil
+}
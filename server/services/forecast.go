@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Capacity forecasting for studios: predicts expected bookings for an
+// upcoming workshop from historical fill per artist, per studio, and per
+// time slot, so studios can pick room sizes before opening sales. Built on
+// the orders we already store — a workshop's fill is its count of paid
+// orders.
+
+// Forecast signal weights. The artist is the strongest demand predictor,
+// the studio's own baseline next, and the time-slot average across the
+// platform the weakest; weights renormalize when a signal has no samples.
+const (
+	forecastArtistWeight = 0.5
+	forecastStudioWeight = 0.3
+	forecastSlotWeight   = 0.2
+)
+
+// ForecastSignal is one historical average contributing to a forecast.
+type ForecastSignal struct {
+	Source      string  `json:"source"`
+	AvgBookings float64 `json:"avg_bookings"`
+	Samples     int     `json:"samples"`
+	Weight      float64 `json:"weight"`
+}
+
+// WorkshopForecast is the predicted fill for one upcoming workshop.
+type WorkshopForecast struct {
+	WorkshopUUID     string           `json:"workshop_uuid"`
+	StudioID         string           `json:"studio_id"`
+	TimeSlot         string           `json:"time_slot"`
+	ExpectedBookings float64          `json:"expected_bookings"`
+	Confidence       string           `json:"confidence"`
+	Signals          []ForecastSignal `json:"signals"`
+	GeneratedAt      time.Time        `json:"generated_at"`
+}
+
+// ForecastServiceImpl computes workshop forecasts against MongoDB.
+type ForecastServiceImpl struct {
+	cfg *config.Config
+}
+
+// NewForecastService builds the forecast service for the given config.
+func NewForecastService(cfg *config.Config) *ForecastServiceImpl {
+	return &ForecastServiceImpl{cfg: cfg}
+}
+
+// historicalWorkshop is the per-workshop history a forecast averages over.
+type historicalWorkshop struct {
+	artists  map[string]bool
+	studioID string
+	slot     string
+	bookings int
+}
+
+// ForecastWorkshop predicts expected bookings for one upcoming workshop.
+func (s *ForecastServiceImpl) ForecastWorkshop(workshopUUID string) (*WorkshopForecast, error) {
+	workshop, err := database.GetWorkshopByUUID(s.cfg, workshopUUID)
+	if err != nil {
+		return nil, fmt.Errorf("workshop not found: %s", workshopUUID)
+	}
+	slot := workshopTimeSlot(*workshop)
+
+	history, err := s.loadHistory(workshopUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	artistAvg, artistSamples := averageWhere(history, func(h historicalWorkshop) bool {
+		for _, id := range workshop.ArtistIDList {
+			if h.artists[id] {
+				return true
+			}
+		}
+		return false
+	})
+	studioAvg, studioSamples := averageWhere(history, func(h historicalWorkshop) bool {
+		return h.studioID == workshop.StudioID
+	})
+	slotAvg, slotSamples := averageWhere(history, func(h historicalWorkshop) bool {
+		return h.slot == slot
+	})
+
+	signals := []ForecastSignal{
+		{Source: "artist", AvgBookings: artistAvg, Samples: artistSamples, Weight: forecastArtistWeight},
+		{Source: "studio", AvgBookings: studioAvg, Samples: studioSamples, Weight: forecastStudioWeight},
+		{Source: "time_slot", AvgBookings: slotAvg, Samples: slotSamples, Weight: forecastSlotWeight},
+	}
+	expected, totalWeight := 0.0, 0.0
+	for _, signal := range signals {
+		if signal.Samples > 0 {
+			expected += signal.AvgBookings * signal.Weight
+			totalWeight += signal.Weight
+		}
+	}
+	if totalWeight > 0 {
+		expected /= totalWeight
+	}
+
+	return &WorkshopForecast{
+		WorkshopUUID:     workshopUUID,
+		StudioID:         workshop.StudioID,
+		TimeSlot:         slot,
+		ExpectedBookings: expected,
+		Confidence:       forecastConfidence(artistSamples, studioSamples+slotSamples),
+		Signals:          signals,
+		GeneratedAt:      time.Now().UTC(),
+	}, nil
+}
+
+// loadHistory builds the per-workshop booking history: every workshop whose
+// first session has already started, with its count of paid orders.
+func (s *ForecastServiceImpl) loadHistory(excludeUUID string) ([]historicalWorkshop, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := database.Collection(s.cfg, "workshops_v2").Find(ctx, bson.M{"uuid": bson.M{"$ne": excludeUUID}})
+	if err != nil {
+		return nil, err
+	}
+	var workshops []response.Workshop
+	if err := cursor.All(ctx, &workshops); err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.paidOrderCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	var history []historicalWorkshop
+	for _, w := range workshops {
+		epoch := firstSessionEpoch(w)
+		if epoch == 0 || epoch >= now {
+			continue
+		}
+		artists := make(map[string]bool, len(w.ArtistIDList))
+		for _, id := range w.ArtistIDList {
+			artists[id] = true
+		}
+		history = append(history, historicalWorkshop{
+			artists:  artists,
+			studioID: w.StudioID,
+			slot:     workshopTimeSlot(w),
+			bookings: bookings[w.UUID],
+		})
+	}
+	return history, nil
+}
+
+// paidOrderCounts aggregates paid orders per workshop uuid.
+func (s *ForecastServiceImpl) paidOrderCounts(ctx context.Context) (map[string]int, error) {
+	cursor, err := database.Collection(s.cfg, "orders").Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"status": OrderStatusPaid}},
+		{"$group": bson.M{"_id": "$workshop_uuid", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		UUID  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.UUID] = row.Count
+	}
+	return counts, nil
+}
+
+// averageWhere averages bookings over the history entries matching the
+// predicate.
+func averageWhere(history []historicalWorkshop, match func(historicalWorkshop) bool) (float64, int) {
+	total, samples := 0, 0
+	for _, h := range history {
+		if match(h) {
+			total += h.bookings
+			samples++
+		}
+	}
+	if samples == 0 {
+		return 0, 0
+	}
+	return float64(total) / float64(samples), samples
+}
+
+// workshopTimeSlot buckets a workshop's first session into weekday/weekend
+// plus morning/afternoon/evening, e.g. "weekend_evening".
+func workshopTimeSlot(w response.Workshop) string {
+	epoch := firstSessionEpoch(w)
+	if epoch == 0 {
+		return "unknown"
+	}
+	at := time.Unix(epoch, 0).In(utils.IST)
+	dayPart := "weekday"
+	if at.Weekday() == time.Saturday || at.Weekday() == time.Sunday {
+		dayPart = "weekend"
+	}
+	hourPart := "evening"
+	switch {
+	case at.Hour() < 12:
+		hourPart = "morning"
+	case at.Hour() < 17:
+		hourPart = "afternoon"
+	}
+	return dayPart + "_" + hourPart
+}
+
+// firstSessionEpoch returns the epoch of the workshop's first dated session.
+func firstSessionEpoch(w response.Workshop) int64 {
+	for _, td := range w.TimeDetails {
+		if td.Day == nil || td.Month == nil || td.Year == nil {
+			continue
+		}
+		return utils.GetTimestampEpoch(td)
+	}
+	return 0
+}
+
+// forecastConfidence grades a forecast by how much history backs it: the
+// artist signal dominates the prediction, so it drives the grade.
+func forecastConfidence(artistSamples, otherSamples int) string {
+	switch {
+	case artistSamples >= 10:
+		return "high"
+	case artistSamples >= 3 || otherSamples >= 10:
+		return "medium"
+	default:
+		return "low"
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// "Going with friends": the social layer on top of contact sync. Friend
+// edges recorded during sync power two things — listing which friends are
+// going to a workshop, and sending a nudge invite. Attendance is shared
+// only for orders the owner explicitly marked visible_to_friends, and
+// always subject to the owner's privacy.share_attendance flag; invites
+// additionally respect privacy.allow_friend_invites.
+
+// FriendGoing is one friend attending a workshop.
+type FriendGoing struct {
+	UserID string  `json:"user_id"`
+	Name   *string `json:"name"`
+}
+
+// FriendsGoingResult lists the caller's friends going to one workshop.
+type FriendsGoingResult struct {
+	WorkshopUUID string        `json:"workshop_uuid"`
+	Friends      []FriendGoing `json:"friends"`
+}
+
+// recordFriendEdges persists the caller's discovered friends so later
+// social queries don't need the contact hashes again. Best effort — a
+// failed write never fails the sync.
+func recordFriendEdges(ctx context.Context, cfg *config.Config, userID string, friends []FriendMatch) {
+	for _, friend := range friends {
+		_, err := database.Collection(cfg, "friends").UpdateOne(ctx,
+			bson.M{"user_id": userID, "friend_user_id": friend.UserID},
+			bson.M{"$setOnInsert": bson.M{
+				"user_id":        userID,
+				"friend_user_id": friend.UserID,
+				"matched_at":     time.Now().UTC(),
+			}},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			log.Printf("friend edge upsert failed for %s: %v", userID, err)
+			return
+		}
+	}
+}
+
+// friendIDs returns the user IDs connected to userID by a sync edge in
+// either direction.
+func friendIDs(ctx context.Context, cfg *config.Config, userID string) (map[string]bool, error) {
+	cursor, err := database.Collection(cfg, "friends").Find(ctx, bson.M{
+		"$or": []bson.M{{"user_id": userID}, {"friend_user_id": userID}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var edges []struct {
+		UserID       string `bson:"user_id"`
+		FriendUserID string `bson:"friend_user_id"`
+	}
+	if err := cursor.All(ctx, &edges); err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, edge := range edges {
+		if edge.UserID == userID {
+			ids[edge.FriendUserID] = true
+		} else {
+			ids[edge.UserID] = true
+		}
+	}
+	return ids, nil
+}
+
+// SetOrderVisibility marks or unmarks the caller's paid order as visible
+// to their friends.
+func SetOrderVisibility(cfg *config.Config, orderID, userID string, visible bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order Order
+	if err := database.Collection(cfg, "orders").FindOne(ctx,
+		bson.M{"order_id": orderID, "user_id": userID}).Decode(&order); err != nil {
+		return fmt.Errorf("order not found: %s", orderID)
+	}
+	if order.Status != OrderStatusPaid {
+		return fmt.Errorf("only paid orders can be shared with friends")
+	}
+	_, err := database.Collection(cfg, "orders").UpdateOne(ctx,
+		bson.M{"order_id": orderID},
+		bson.M{"$set": bson.M{"visible_to_friends": visible}})
+	return err
+}
+
+// GetFriendsGoing lists the caller's friends holding a paid,
+// friend-visible order for the workshop, excluding friends who opted out
+// of sharing attendance.
+func GetFriendsGoing(cfg *config.Config, userID, workshopUUID string) (*FriendsGoingResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	friends, err := friendIDs(ctx, cfg, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := &FriendsGoingResult{WorkshopUUID: workshopUUID, Friends: []FriendGoing{}}
+	if len(friends) == 0 {
+		return result, nil
+	}
+	ids := make([]string, 0, len(friends))
+	for id := range friends {
+		ids = append(ids, id)
+	}
+
+	cursor, err := database.Collection(cfg, "orders").Find(ctx, bson.M{
+		"workshop_uuid":      workshopUUID,
+		"status":             OrderStatusPaid,
+		"user_id":            bson.M{"$in": ids},
+		"visible_to_friends": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var orders []Order
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		friend, err := loadFriendUser(ctx, cfg, order.UserID)
+		if err != nil || friend == nil || !allows(friend.Privacy.ShareAttendance) {
+			continue
+		}
+		result.Friends = append(result.Friends, FriendGoing{UserID: order.UserID, Name: friend.Name})
+	}
+	return result, nil
+}
+
+// InviteFriend sends a one-time nudge push to a friend for a workshop.
+// The pair must be connected by a sync edge, the friend must accept
+// invites, and repeat nudges for the same workshop are deduped.
+func InviteFriend(cfg *config.Config, notif *NotificationServiceImpl, userID, friendUserID, workshopUUID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	friends, err := friendIDs(ctx, cfg, userID)
+	if err != nil {
+		return err
+	}
+	if !friends[friendUserID] {
+		return fmt.Errorf("you can only invite synced friends")
+	}
+	friend, err := loadFriendUser(ctx, cfg, friendUserID)
+	if err != nil || friend == nil {
+		return fmt.Errorf("friend not found")
+	}
+	if !allows(friend.Privacy.AllowFriendInvites) {
+		return fmt.Errorf("this friend is not accepting invites")
+	}
+	workshop, err := database.GetWorkshopByUUID(cfg, workshopUUID)
+	if err != nil {
+		return fmt.Errorf("workshop not found: %s", workshopUUID)
+	}
+
+	// One nudge per sender, recipient, and workshop; the unique index
+	// makes the dedupe safe across replicas.
+	_, err = database.Collection(cfg, "friend_invites").InsertOne(ctx, bson.M{
+		"from_user_id":  userID,
+		"to_user_id":    friendUserID,
+		"workshop_uuid": workshopUUID,
+		"invited_at":    time.Now().UTC(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("you already invited this friend to this workshop")
+	}
+	if err != nil {
+		return err
+	}
+
+	senderName := "A friend"
+	if sender, err := loadFriendUser(ctx, cfg, userID); err == nil && sender != nil && sender.Name != nil && *sender.Name != "" {
+		senderName = *sender.Name
+	}
+	title := senderName + " invited you to a workshop"
+	body := "Join them on Nachna!"
+	if workshop.Song != nil && *workshop.Song != "" {
+		body = "Come dance to " + *workshop.Song + " together."
+	}
+	return notif.sendToUser(friendUserID, title, body, map[string]string{
+		"deep_link":     "nachna://workshop/" + workshopUUID,
+		"workshop_uuid": workshopUUID,
+	})
+}
+
+// loadFriendUser reads the slice of a user document the social layer
+// needs; (nil, nil) for unknown or deleted users.
+func loadFriendUser(ctx context.Context, cfg *config.Config, userID string) (*contactUser, error) {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	var user contactUser
+	err = database.Collection(cfg, "users").FindOne(ctx, bson.M{"_id": oid}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeleted {
+		return nil, nil
+	}
+	return &user, nil
+}
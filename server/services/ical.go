@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// iCalendar feeds: workshops rendered as RFC 5545 VEVENTs so users can pull
+// a single workshop into their calendar or subscribe to a per-artist or
+// per-studio feed from Google/Apple Calendar. All sessions are anchored in
+// IST via an embedded VTIMEZONE (fixed +0530, no DST), and every session
+// gets a stable UID — workshop UUID plus session date — so calendar clients
+// update events in place across refreshes instead of duplicating them.
+
+const (
+	icsProdID = "-//Nachna//Workshop Calendar//EN"
+	// icsDefaultDuration is assumed when a session has no parseable end time.
+	icsDefaultDuration = 90 * time.Minute
+)
+
+// icsTimezone is the VTIMEZONE block for Asia/Kolkata.
+const icsTimezone = "BEGIN:VTIMEZONE\r\n" +
+	"TZID:Asia/Kolkata\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"TZOFFSETFROM:+0530\r\n" +
+	"TZOFFSETTO:+0530\r\n" +
+	"TZNAME:IST\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n"
+
+// RenderWorkshopICS renders one workshop's sessions as an iCalendar file.
+func RenderWorkshopICS(cfg *config.Config, workshopUUID string) (string, error) {
+	workshop, err := database.GetWorkshopByUUID(cfg, workshopUUID)
+	if err != nil {
+		return "", err
+	}
+	return renderCalendar(cfg, []response.Workshop{*workshop})
+}
+
+// RenderArtistICS renders an artist's upcoming workshops as a subscribable
+// calendar feed.
+func RenderArtistICS(cfg *config.Config, artistID string) (string, error) {
+	workshops, err := database.GetUpcomingWorkshopsRaw(cfg, "", artistID)
+	if err != nil {
+		return "", err
+	}
+	return renderCalendar(cfg, workshops)
+}
+
+// RenderStudioICS renders a studio's upcoming workshops as a subscribable
+// calendar feed.
+func RenderStudioICS(cfg *config.Config, studioID string) (string, error) {
+	workshops, err := database.GetUpcomingWorkshopsRaw(cfg, studioID, "")
+	if err != nil {
+		return "", err
+	}
+	return renderCalendar(cfg, workshops)
+}
+
+// icsEvent is one session flattened for rendering.
+type icsEvent struct {
+	uid      string
+	start    time.Time
+	end      time.Time
+	summary  string
+	location string
+	url      string
+}
+
+// renderCalendar assembles the VCALENDAR document from every dated session
+// of the given workshops, sorted by start time.
+func renderCalendar(cfg *config.Config, workshops []response.Workshop) (string, error) {
+	studios, err := database.GetStudioMap(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	var events []icsEvent
+	for _, w := range workshops {
+		summary := icsSummary(w)
+		location := ""
+		if studio, ok := studios[w.StudioID]; ok {
+			location = studio.Name
+		}
+		for _, td := range w.TimeDetails {
+			if td.Day == nil || td.Month == nil || td.Year == nil {
+				continue
+			}
+			start := time.Unix(utils.GetTimestampEpoch(td), 0).In(utils.IST)
+			events = append(events, icsEvent{
+				uid:      fmt.Sprintf("%s-%04d%02d%02d@nachna.com", w.UUID, *td.Year, *td.Month, *td.Day),
+				start:    start,
+				end:      icsSessionEnd(start, td.EndTime),
+				summary:  summary,
+				location: location,
+				url:      w.PaymentLink,
+			})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].start.Before(events[j].start) })
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:" + icsProdID + "\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString("METHOD:PUBLISH\r\n")
+	b.WriteString(icsTimezone)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		b.WriteString("BEGIN:VEVENT\r\n")
+		b.WriteString("UID:" + e.uid + "\r\n")
+		b.WriteString("DTSTAMP:" + stamp + "\r\n")
+		b.WriteString("DTSTART;TZID=Asia/Kolkata:" + e.start.Format("20060102T150405") + "\r\n")
+		b.WriteString("DTEND;TZID=Asia/Kolkata:" + e.end.Format("20060102T150405") + "\r\n")
+		b.WriteString("SUMMARY:" + escapeICSText(e.summary) + "\r\n")
+		if e.location != "" {
+			b.WriteString("LOCATION:" + escapeICSText(e.location) + "\r\n")
+		}
+		if e.url != "" {
+			b.WriteString("URL:" + escapeICSText(e.url) + "\r\n")
+		}
+		b.WriteString("END:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String(), nil
+}
+
+// icsSummary builds the event title from the workshop's song and artist.
+func icsSummary(w response.Workshop) string {
+	song := ""
+	if w.Song != nil {
+		song = *w.Song
+	}
+	by := ""
+	if w.By != nil {
+		by = *w.By
+	}
+	switch {
+	case song != "" && by != "":
+		return fmt.Sprintf("%s by %s", song, by)
+	case song != "":
+		return song
+	case by != "":
+		return fmt.Sprintf("Workshop by %s", by)
+	default:
+		return "Dance workshop"
+	}
+}
+
+// icsSessionEnd resolves a session's end from its end_time clock, falling
+// back to a default duration when missing or unparseable.
+func icsSessionEnd(start time.Time, endTime *string) time.Time {
+	if endTime != nil && strings.Contains(*endTime, " ") {
+		parsed, err := time.Parse("3:04 PM", strings.ToUpper(strings.TrimSpace(*endTime)))
+		if err == nil {
+			end := time.Date(start.Year(), start.Month(), start.Day(),
+				parsed.Hour(), parsed.Minute(), 0, 0, utils.IST)
+			if end.After(start) {
+				return end
+			}
+		}
+	}
+	return start.Add(icsDefaultDuration)
+}
+
+// escapeICSText escapes text per RFC 5545 (backslash, semicolon, comma,
+// newline).
+func escapeICSText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/utils"
+)
+
+// Check-in kiosks: a studio can put a tablet at the door without giving it
+// a personal login. An admin issues a kiosk token scoped to one workshop
+// and its time window; the tablet presents the token on the check-in
+// endpoint only — it opens nothing else — and the token expires on its
+// own once the event is over (a TTL index then reaps the document).
+
+// kioskGraceAfterEvent is how long past a workshop's last session start a
+// kiosk token stays usable: doors stay open while the session runs and
+// stragglers trickle in.
+const kioskGraceAfterEvent = 6 * time.Hour
+
+// KioskToken is one issued door-tablet credential.
+type KioskToken struct {
+	Token        string    `bson:"token" json:"token"`
+	StudioID     string    `bson:"studio_id" json:"studio_id"`
+	WorkshopUUID string    `bson:"workshop_uuid" json:"workshop_uuid"`
+	ValidFrom    time.Time `bson:"valid_from" json:"valid_from"`
+	ExpiresAt    time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+}
+
+// IssueKioskToken mints a kiosk token for one of the studio's workshops,
+// valid from now until the grace window after its last dated session.
+func IssueKioskToken(cfg *config.Config, studioID, workshopUUID string) (*KioskToken, error) {
+	workshop, err := database.GetWorkshopByUUID(cfg, workshopUUID)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("workshop %s not found", workshopUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if workshop.StudioID != studioID {
+		return nil, fmt.Errorf("workshop %s does not belong to studio %s", workshopUUID, studioID)
+	}
+	var lastSession int64
+	for _, td := range workshop.TimeDetails {
+		if td.Day == nil || td.Month == nil || td.Year == nil {
+			continue
+		}
+		if epoch := utils.GetTimestampEpoch(td); epoch > lastSession {
+			lastSession = epoch
+		}
+	}
+	if lastSession == 0 {
+		return nil, fmt.Errorf("workshop %s has no dated sessions to scope a kiosk window to", workshopUUID)
+	}
+
+	token := KioskToken{
+		Token:        uuid.NewString(),
+		StudioID:     studioID,
+		WorkshopUUID: workshopUUID,
+		ValidFrom:    time.Now().UTC(),
+		ExpiresAt:    time.Unix(lastSession, 0).UTC().Add(kioskGraceAfterEvent),
+		CreatedAt:    time.Now().UTC(),
+	}
+	if !token.ExpiresAt.After(token.ValidFrom) {
+		return nil, fmt.Errorf("workshop %s is already over", workshopUUID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := database.Collection(cfg, "kiosk_tokens").InsertOne(ctx, token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// KioskCheckInResult is the outcome of verifying one ticket at the door.
+// It deliberately omits the owning user: the door tablet only needs to
+// know whether the ticket is good, not whose account bought it.
+type KioskCheckInResult struct {
+	OrderID          string    `json:"order_id"`
+	WorkshopUUID     string    `json:"workshop_uuid"`
+	CheckedInAt      time.Time `json:"checked_in_at"`
+	AlreadyCheckedIn bool      `json:"already_checked_in"`
+}
+
+// KioskCheckIn verifies a scanned ticket against a kiosk token: the token
+// must be inside its window, the order paid, and for the token's workshop.
+// The first successful scan stamps checked_in_at; a repeat scan reports
+// the earlier check-in instead of silently passing (the door staff should
+// see a ticket being reused).
+func KioskCheckIn(cfg *config.Config, token, orderID string) (*KioskCheckInResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var kiosk KioskToken
+	err := database.Collection(cfg, "kiosk_tokens").FindOne(ctx, bson.M{"token": token}).Decode(&kiosk)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("invalid kiosk token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if now.Before(kiosk.ValidFrom) || now.After(kiosk.ExpiresAt) {
+		return nil, fmt.Errorf("kiosk token is outside its validity window")
+	}
+
+	var order Order
+	err = database.Collection(cfg, "orders").FindOne(ctx, bson.M{"order_id": orderID}).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != OrderStatusPaid {
+		return nil, fmt.Errorf("order %s is not paid", orderID)
+	}
+	if order.WorkshopUUID != kiosk.WorkshopUUID {
+		return nil, fmt.Errorf("order %s is for a different workshop", orderID)
+	}
+
+	result, err := database.Collection(cfg, "orders").UpdateOne(ctx,
+		bson.M{"order_id": orderID, "checked_in_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"checked_in_at": now}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		checkedInAt := now
+		if order.CheckedInAt != nil {
+			checkedInAt = *order.CheckedInAt
+		}
+		return &KioskCheckInResult{
+			OrderID:          order.OrderID,
+			WorkshopUUID:     order.WorkshopUUID,
+			CheckedInAt:      checkedInAt,
+			AlreadyCheckedIn: true,
+		}, nil
+	}
+	return &KioskCheckInResult{
+		OrderID:      order.OrderID,
+		WorkshopUUID: order.WorkshopUUID,
+		CheckedInAt:  now,
+	}, nil
+}
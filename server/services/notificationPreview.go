@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/database"
+)
+
+// Admin notification preview: renders the new-workshop push for a real
+// workshop and user and returns the exact per-platform payloads that would
+// go out, so admins can check a template against real data instead of
+// test-spamming every user. The send-to-self path delivers the rendered
+// push to the admin's own devices only.
+
+// PreviewDevice is the payload one of the user's device platforms would
+// receive. Deliverable is false for platforms the user has no active
+// token on.
+type PreviewDevice struct {
+	Platform    string      `json:"platform"`
+	Deliverable bool        `json:"deliverable"`
+	Payload     interface{} `json:"payload"`
+}
+
+// NotificationPreview is the rendered notification plus its wire payloads.
+type NotificationPreview struct {
+	WorkshopUUID string            `json:"workshop_uuid"`
+	UserID       string            `json:"user_id"`
+	Title        string            `json:"title"`
+	Body         string            `json:"body"`
+	Data         map[string]string `json:"data"`
+	Devices      []PreviewDevice   `json:"devices"`
+}
+
+// PreviewWorkshopNotification renders the new-workshop push for the given
+// workshop as the given user would receive it. Users with no registered
+// devices still get both platform payloads rendered, marked undeliverable.
+func (s *NotificationServiceImpl) PreviewWorkshopNotification(workshopUUID, userID string) (*NotificationPreview, error) {
+	workshop, err := database.GetWorkshopByUUID(s.cfg, workshopUUID)
+	if err != nil {
+		return nil, fmt.Errorf("workshop not found: %s", workshopUUID)
+	}
+	artistName := "An artist you follow"
+	if workshop.By != nil && *workshop.By != "" {
+		artistName = *workshop.By
+	}
+
+	// Exactly the rendering NotifyOrQueue's immediate path performs.
+	title := fmt.Sprintf("%s just added a workshop", artistName)
+	body := "Tap to see the schedule and book your spot."
+	data := map[string]string{"deep_link": "nachna://workshop/" + workshopUUID}
+	s.applyWorkshopBranding(workshopUUID, data)
+
+	registered, err := s.userPlatforms(userID)
+	if err != nil {
+		return nil, err
+	}
+	devices := []PreviewDevice{
+		{
+			Platform:    "ios",
+			Deliverable: registered["ios"],
+			Payload:     apnsPayload(title, body, data),
+		},
+		{
+			Platform:    "android",
+			Deliverable: registered["android"],
+			Payload:     fcmPayload(title, body, data),
+		},
+	}
+
+	return &NotificationPreview{
+		WorkshopUUID: workshopUUID,
+		UserID:       userID,
+		Title:        title,
+		Body:         body,
+		Data:         data,
+		Devices:      devices,
+	}, nil
+}
+
+// SendPreviewToSelf delivers the rendered push to the admin's own devices
+// only, returning the preview that was sent.
+func (s *NotificationServiceImpl) SendPreviewToSelf(workshopUUID, adminUserID string) (*NotificationPreview, error) {
+	preview, err := s.PreviewWorkshopNotification(workshopUUID, adminUserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sendToUser(adminUserID, preview.Title, preview.Body, preview.Data); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// userPlatforms returns which platforms the user has active tokens on.
+func (s *NotificationServiceImpl) userPlatforms(userID string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	platforms, err := database.Collection(s.cfg, "device_tokens").Distinct(ctx,
+		"platform", bson.M{"user_id": userID, "is_active": true})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]bool, len(platforms))
+	for _, p := range platforms {
+		if name, ok := p.(string); ok {
+			out[name] = true
+		}
+	}
+	return out, nil
+}
+
+// fcmPayload builds the FCM HTTP v1 message shape Android devices receive;
+// the sender injects the device token before dispatch.
+func fcmPayload(title, body string, data map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"message": map[string]interface{}{
+			"notification": map[string]string{"title": title, "body": body},
+			"data":         data,
+		},
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/utils"
+)
+
+// OrderStatus is the lifecycle state of an order.
+type OrderStatus string
+
+const (
+	OrderStatusCreated   OrderStatus = "created"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusFailed    OrderStatus = "failed"
+	OrderStatusRefunded  OrderStatus = "refunded"
+	OrderStatusExpired   OrderStatus = "expired"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// Order is the order document shape shared with the Python server.
+type Order struct {
+	OrderID      string      `bson:"order_id" json:"order_id"`
+	UserID       string      `bson:"user_id" json:"user_id"`
+	WorkshopUUID string      `bson:"workshop_uuid" json:"workshop_uuid"`
+	Amount       int64       `bson:"amount" json:"amount"`
+	Currency     string      `bson:"currency" json:"currency"`
+	Status       OrderStatus `bson:"status" json:"status"`
+	CreatedAt    time.Time   `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time   `bson:"updated_at" json:"updated_at"`
+	// ShareCode is the order's public ticket-sharing code, set on first
+	// share; empty until then.
+	ShareCode string `bson:"share_code,omitempty" json:"share_code,omitempty"`
+	// VisibleToFriends opts this order into the "going with friends"
+	// listing; off by default.
+	VisibleToFriends bool `bson:"visible_to_friends,omitempty" json:"visible_to_friends,omitempty"`
+	// PassProductID marks a pass purchase order; such orders have no
+	// workshop_uuid and activate the pass when paid.
+	PassProductID string `bson:"pass_product_id,omitempty" json:"pass_product_id,omitempty"`
+	// PassID marks a booking paid by redeeming a pass.
+	PassID string `bson:"pass_id,omitempty" json:"pass_id,omitempty"`
+	// CheckedInAt is when the ticket was scanned at the door; nil until
+	// then.
+	CheckedInAt *time.Time `bson:"checked_in_at,omitempty" json:"checked_in_at,omitempty"`
+}
+
+// WebhookEvent is a payment gateway event mapped onto an order transition.
+// EventID doubles as the replay nonce and Timestamp (unix seconds) as the
+// freshness stamp; events outside the replay window or with a reused
+// EventID are rejected.
+type WebhookEvent struct {
+	EventID   string `json:"event_id"`
+	Event     string `json:"event"`
+	OrderID   string `json:"order_id"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// OrderService manages order creation and payment webhook processing.
+type OrderService interface {
+	CreateOrder(userID, workshopUUID string, amount int64) (*Order, error)
+	ProcessPaymentWebhook(event WebhookEvent) error
+	QuoteRefund(orderID, userID string) (*RefundQuote, error)
+	GetStreamAccess(orderID, userID string) (*StreamAccess, error)
+	CheckCancelRateLimit(userID string) error
+	CancelOrder(orderID, userID string) (*CancelResult, error)
+	PurchasePass(userID, productID string) (*Order, error)
+}
+
+// OrderServiceImpl implements OrderService against MongoDB.
+type OrderServiceImpl struct {
+	cfg    *config.Config
+	auth   *AuthServiceImpl
+	replay *ReplayGuard
+
+	cancelMu      sync.Mutex
+	recentCancels map[string][]time.Time
+}
+
+// NewOrderService builds the order service for the given config.
+func NewOrderService(cfg *config.Config) *OrderServiceImpl {
+	return &OrderServiceImpl{
+		cfg:           cfg,
+		auth:          NewAuthService(cfg),
+		replay:        NewReplayGuard(cfg),
+		recentCancels: make(map[string][]time.Time),
+	}
+}
+
+// CreateOrder inserts a new order in the created state. Suspended and
+// banned accounts cannot create orders; the returned AccountStateError
+// carries the user-facing explanation.
+func (s *OrderServiceImpl) CreateOrder(userID, workshopUUID string, amount int64) (*Order, error) {
+	if _, err := s.auth.GetUser(userID); err != nil {
+		return nil, err
+	}
+	// Workshops with automatic price tiers are charged at the tier active
+	// right now, never the client-supplied amount — a stale app showing a
+	// closed early-bird price cannot book at it.
+	tier, err := database.CurrentWorkshopTier(s.cfg, workshopUUID)
+	if err != nil {
+		return nil, err
+	}
+	if tier != nil {
+		amount = tier.Price
+	}
+	order := &Order{
+		OrderID:      uuid.NewString(),
+		UserID:       userID,
+		WorkshopUUID: workshopUUID,
+		Amount:       amount,
+		Currency:     s.workshopCurrency(workshopUUID),
+		Status:       OrderStatusCreated,
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := database.Collection(s.cfg, "orders").InsertOne(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// workshopCurrency resolves the currency an order should be charged in from
+// the workshop's studio configuration, defaulting to INR.
+func (s *OrderServiceImpl) workshopCurrency(workshopUUID string) string {
+	workshop, err := database.GetWorkshopByUUID(s.cfg, workshopUUID)
+	if err != nil {
+		return utils.DefaultCurrency
+	}
+	studios, err := database.GetStudioMap(s.cfg)
+	if err != nil {
+		return utils.DefaultCurrency
+	}
+	if studio, ok := studios[workshop.StudioID]; ok {
+		return utils.NormalizeCurrency(studio.Currency)
+	}
+	return utils.DefaultCurrency
+}
+
+// ProcessPaymentWebhook maps a gateway event to an order status transition.
+// The event must pass replay validation first: gateways retry deliveries,
+// and a captured request must not be able to flip an order twice.
+func (s *OrderServiceImpl) ProcessPaymentWebhook(event WebhookEvent) error {
+	if err := s.replay.CheckAndRecord(event.EventID, event.Timestamp); err != nil {
+		return err
+	}
+	var status OrderStatus
+	switch event.Event {
+	case "payment.captured", "order.paid":
+		status = OrderStatusPaid
+	case "payment.failed":
+		status = OrderStatusFailed
+	case "refund.processed":
+		status = OrderStatusRefunded
+	default:
+		return fmt.Errorf("unhandled webhook event: %s", event.Event)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(s.cfg, "orders").UpdateOne(ctx,
+		bson.M{"order_id": event.OrderID},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now().UTC()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("order not found: %s", event.OrderID)
+	}
+	if status == OrderStatusPaid {
+		if order := s.getOrder(event.OrderID); order != nil {
+			EmitEvent(s.cfg, EventOrderPaid, bson.M{
+				"order_id":      order.OrderID,
+				"user_id":       order.UserID,
+				"workshop_uuid": order.WorkshopUUID,
+				"amount":        order.Amount,
+				"currency":      order.Currency,
+			})
+			if order.PassProductID != "" {
+				if err := ActivatePassForOrder(s.cfg, order); err != nil {
+					// The order is already paid; log loudly rather than
+					// failing the webhook, and let support re-mint.
+					log.Printf("pass activation failed for order %s: %v", order.OrderID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getOrder fetches one order, or nil when it cannot be read.
+func (s *OrderServiceImpl) getOrder(orderID string) *Order {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var order Order
+	if err := database.Collection(s.cfg, "orders").FindOne(ctx, bson.M{"order_id": orderID}).Decode(&order); err != nil {
+		return nil
+	}
+	return &order
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/utils"
+)
+
+// Subscription passes: artists running recurring classes sell class packs
+// (N credits redeemable on that artist's workshops) and studios sell
+// monthly unlimited passes. A purchase reuses the existing order
+// pipeline — a pass order carries pass_product_id instead of
+// workshop_uuid — so the payment webhook flow is unchanged; the paid
+// transition activates the pass. Redemption books a workshop against a
+// pass, decrementing class-pack credits atomically so concurrent
+// bookings cannot overspend a pack.
+
+// Pass product types.
+const (
+	PassTypeClassPack        = "class_pack"
+	PassTypeMonthlyUnlimited = "monthly_unlimited"
+)
+
+// Pass lifecycle states.
+const (
+	PassStatusActive    = "active"
+	PassStatusExpired   = "expired"
+	PassStatusExhausted = "exhausted"
+)
+
+// PassProduct is an admin-defined pass for sale: a class pack scoped to
+// one artist, or a monthly unlimited pass scoped to one studio.
+type PassProduct struct {
+	ProductID string `bson:"product_id" json:"product_id"`
+	Type      string `bson:"type" json:"type"`
+	Name      string `bson:"name" json:"name"`
+	// ArtistID scopes a class pack; empty for studio passes.
+	ArtistID string `bson:"artist_id,omitempty" json:"artist_id,omitempty"`
+	// StudioID scopes a monthly unlimited pass; empty for class packs.
+	StudioID string `bson:"studio_id,omitempty" json:"studio_id,omitempty"`
+	// Credits is the number of classes a pack covers; 0 for unlimited.
+	Credits int `bson:"credits,omitempty" json:"credits,omitempty"`
+	// ValidityDays is how long a purchased pass stays redeemable.
+	ValidityDays int    `bson:"validity_days" json:"validity_days"`
+	Price        int64  `bson:"price" json:"price"`
+	Currency     string `bson:"currency" json:"currency"`
+	Active       bool   `bson:"active" json:"active"`
+}
+
+// Pass is one user's purchased pass instance.
+type Pass struct {
+	PassID    string `bson:"pass_id" json:"pass_id"`
+	UserID    string `bson:"user_id" json:"user_id"`
+	ProductID string `bson:"product_id" json:"product_id"`
+	OrderID   string `bson:"order_id" json:"order_id"`
+	Type      string `bson:"type" json:"type"`
+	ArtistID  string `bson:"artist_id,omitempty" json:"artist_id,omitempty"`
+	StudioID  string `bson:"studio_id,omitempty" json:"studio_id,omitempty"`
+	// RemainingCredits counts down on class packs; always 0 for
+	// unlimited passes, whose validity is purely time-based.
+	RemainingCredits int       `bson:"remaining_credits" json:"remaining_credits"`
+	Status           string    `bson:"status" json:"status"`
+	PurchasedAt      time.Time `bson:"purchased_at" json:"purchased_at"`
+	ExpiresAt        time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// PassRedemptionResult is the response to redeeming a pass at booking.
+type PassRedemptionResult struct {
+	OrderID          string `json:"order_id"`
+	PassID           string `json:"pass_id"`
+	RemainingCredits int    `json:"remaining_credits"`
+}
+
+// validatePassProduct checks an admin-submitted product definition.
+func validatePassProduct(product PassProduct) error {
+	switch product.Type {
+	case PassTypeClassPack:
+		if product.ArtistID == "" {
+			return fmt.Errorf("class packs require artist_id")
+		}
+		if product.Credits < 1 {
+			return fmt.Errorf("class packs require credits >= 1")
+		}
+	case PassTypeMonthlyUnlimited:
+		if product.StudioID == "" {
+			return fmt.Errorf("monthly unlimited passes require studio_id")
+		}
+	default:
+		return fmt.Errorf("type must be %s or %s", PassTypeClassPack, PassTypeMonthlyUnlimited)
+	}
+	if product.ProductID == "" {
+		return fmt.Errorf("product_id is required")
+	}
+	if product.ValidityDays < 1 {
+		return fmt.Errorf("validity_days must be >= 1")
+	}
+	if product.Price < 1 {
+		return fmt.Errorf("price must be >= 1")
+	}
+	return nil
+}
+
+// UpsertPassProduct creates or updates a pass product by product_id.
+func UpsertPassProduct(cfg *config.Config, product PassProduct) error {
+	if err := validatePassProduct(product); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "pass_products").UpdateOne(ctx,
+		bson.M{"product_id": product.ProductID},
+		bson.M{"$set": product},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// GetPassProducts lists pass products; activeOnly narrows to products
+// currently for sale.
+func GetPassProducts(cfg *config.Config, activeOnly bool) ([]PassProduct, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+	cursor, err := database.Collection(cfg, "pass_products").Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "product_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	products := []PassProduct{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// PurchasePass creates a payment order for a pass product. The client
+// completes payment through the usual gateway flow; the paid webhook
+// activates the pass.
+func (s *OrderServiceImpl) PurchasePass(userID, productID string) (*Order, error) {
+	if _, err := s.auth.GetUser(userID); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var product PassProduct
+	err := database.Collection(s.cfg, "pass_products").FindOne(ctx,
+		bson.M{"product_id": productID, "active": true}).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("pass product not found: %s", productID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order := &Order{
+		OrderID:       uuid.NewString(),
+		UserID:        userID,
+		PassProductID: productID,
+		Amount:        product.Price,
+		Currency:      product.Currency,
+		Status:        OrderStatusCreated,
+		CreatedAt:     time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+	}
+	if _, err := database.Collection(s.cfg, "orders").InsertOne(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ActivatePassForOrder mints the pass once its purchase order is paid.
+// Idempotent across webhook retries via the unique order_id index.
+func ActivatePassForOrder(cfg *config.Config, order *Order) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var product PassProduct
+	if err := database.Collection(cfg, "pass_products").FindOne(ctx,
+		bson.M{"product_id": order.PassProductID}).Decode(&product); err != nil {
+		return fmt.Errorf("pass product not found for order %s: %w", order.OrderID, err)
+	}
+	now := time.Now().UTC()
+	pass := Pass{
+		PassID:           uuid.NewString(),
+		UserID:           order.UserID,
+		ProductID:        product.ProductID,
+		OrderID:          order.OrderID,
+		Type:             product.Type,
+		ArtistID:         product.ArtistID,
+		StudioID:         product.StudioID,
+		RemainingCredits: product.Credits,
+		Status:           PassStatusActive,
+		PurchasedAt:      now,
+		ExpiresAt:        now.AddDate(0, 0, product.ValidityDays),
+	}
+	_, err := database.Collection(cfg, "passes").InsertOne(ctx, pass)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// RedeemPass books a workshop against one of the caller's passes. The
+// workshop must fall inside the pass's scope (the pack's artist on the
+// bill, or the studio for unlimited passes); class packs decrement one
+// credit atomically, guarded so the last credit cannot be spent twice.
+// The booking lands as a paid zero-amount order referencing the pass.
+func RedeemPass(cfg *config.Config, userID, passID, workshopUUID string) (*PassRedemptionResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var pass Pass
+	err := database.Collection(cfg, "passes").FindOne(ctx,
+		bson.M{"pass_id": passID, "user_id": userID}).Decode(&pass)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("pass not found: %s", passID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if pass.Status != PassStatusActive || now.After(pass.ExpiresAt) {
+		return nil, fmt.Errorf("pass is no longer active")
+	}
+
+	workshop, err := database.GetWorkshopByUUID(cfg, workshopUUID)
+	if err != nil {
+		return nil, fmt.Errorf("workshop not found: %s", workshopUUID)
+	}
+	switch pass.Type {
+	case PassTypeClassPack:
+		if !containsString(workshop.ArtistIDList, pass.ArtistID) {
+			return nil, fmt.Errorf("this pass only covers workshops by its artist")
+		}
+	case PassTypeMonthlyUnlimited:
+		if workshop.StudioID != pass.StudioID {
+			return nil, fmt.Errorf("this pass only covers workshops at its studio")
+		}
+	}
+
+	remaining := pass.RemainingCredits
+	if pass.Type == PassTypeClassPack {
+		// The remaining_credits > 0 guard plus $inc makes the decrement
+		// atomic: two concurrent redemptions of a one-credit pack cannot
+		// both match.
+		var updated Pass
+		err := database.Collection(cfg, "passes").FindOneAndUpdate(ctx,
+			bson.M{"pass_id": passID, "status": PassStatusActive, "remaining_credits": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"remaining_credits": -1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("pass has no credits left")
+		}
+		if err != nil {
+			return nil, err
+		}
+		remaining = updated.RemainingCredits
+		if remaining == 0 {
+			_, _ = database.Collection(cfg, "passes").UpdateOne(ctx,
+				bson.M{"pass_id": passID},
+				bson.M{"$set": bson.M{"status": PassStatusExhausted}})
+		}
+	}
+
+	order := &Order{
+		OrderID:      uuid.NewString(),
+		UserID:       userID,
+		WorkshopUUID: workshopUUID,
+		Amount:       0,
+		Currency:     utils.DefaultCurrency,
+		Status:       OrderStatusPaid,
+		PassID:       passID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := database.Collection(cfg, "orders").InsertOne(ctx, order); err != nil {
+		return nil, err
+	}
+	return &PassRedemptionResult{
+		OrderID:          order.OrderID,
+		PassID:           passID,
+		RemainingCredits: remaining,
+	}, nil
+}
+
+// GetUserPasses lists the caller's passes for the account screen, lazily
+// flagging passes that expired since the last read.
+func GetUserPasses(cfg *config.Config, userID string) ([]Pass, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := database.Collection(cfg, "passes").Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "purchased_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	passes := []Pass{}
+	if err := cursor.All(ctx, &passes); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for i, pass := range passes {
+		if pass.Status == PassStatusActive && now.After(pass.ExpiresAt) {
+			passes[i].Status = PassStatusExpired
+			if _, err := database.Collection(cfg, "passes").UpdateOne(ctx,
+				bson.M{"pass_id": pass.PassID},
+				bson.M{"$set": bson.M{"status": PassStatusExpired}}); err != nil {
+				log.Printf("pass expiry flag failed for %s: %v", pass.PassID, err)
+			}
+		}
+	}
+	return passes, nil
+}
+
+// containsString reports whether list contains value.
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
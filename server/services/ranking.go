@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+)
+
+// Workshop ranking experiment: the default feed order has always been
+// chronological, but nobody has measured whether popularity or
+// personalization drives more bookings. Ranking is a pluggable scorer
+// picked per user by the workshop_ranking experiment; an explicit ?sort=
+// from the user always wins over the experiment. Scorers reorder within
+// the week's day buckets and the post-this-week tail, same as the manual
+// sort keys, so the week grouping stays intact.
+
+// Ranking variant names; chronological is the control.
+const (
+	RankingChronological = "chronological"
+	RankingPopularity    = "popularity"
+	RankingPersonalized  = "personalized"
+)
+
+// rankingVariants are the workshop_ranking experiment's arms, control
+// first.
+var rankingVariants = []string{RankingChronological, RankingPopularity, RankingPersonalized}
+
+// RankingScorer scores one workshop for ordering; higher scores list
+// first, and ties keep the feed's chronological order. Scorers are built
+// per request so they can load whatever data they need once.
+type RankingScorer interface {
+	Name() string
+	Score(item response.WorkshopListItem) float64
+}
+
+// chronologicalScorer reproduces the default first-session order.
+type chronologicalScorer struct{}
+
+func (chronologicalScorer) Name() string { return RankingChronological }
+func (chronologicalScorer) Score(item response.WorkshopListItem) float64 {
+	return -float64(item.TimestampEpoch)
+}
+
+// popularityScorer ranks by live like counts.
+type popularityScorer struct {
+	likes map[string]int64
+}
+
+func (s popularityScorer) Name() string { return RankingPopularity }
+func (s popularityScorer) Score(item response.WorkshopListItem) float64 {
+	return float64(s.likes[item.UUID])
+}
+
+// personalizedScorer boosts workshops by artists the user follows or
+// likes; everything else keeps chronological order among itself.
+type personalizedScorer struct {
+	artists map[string]bool
+}
+
+func (s personalizedScorer) Name() string { return RankingPersonalized }
+func (s personalizedScorer) Score(item response.WorkshopListItem) float64 {
+	score := 0.0
+	for _, artistID := range item.ArtistIDList {
+		if s.artists[artistID] {
+			score++
+		}
+	}
+	return score
+}
+
+// newRankingScorer builds the scorer for a variant, falling back to
+// chronological for unknown names so a stale variant can never break the
+// feed.
+func newRankingScorer(cfg *config.Config, userID, variant string) RankingScorer {
+	switch variant {
+	case RankingPopularity:
+		return popularityScorer{likes: workshopLikeCounts(cfg)}
+	case RankingPersonalized:
+		return personalizedScorer{artists: userArtistReactions(cfg, userID)}
+	default:
+		return chronologicalScorer{}
+	}
+}
+
+// ApplyRankingExperiment reorders the categorized feed by the user's
+// workshop_ranking variant and logs the exposure. The control variant
+// leaves the chronological feed untouched but is still logged, so both
+// arms of a comparison have exposure rows.
+func ApplyRankingExperiment(cfg *config.Config, userID string, resp *response.CategorizedWorkshopResponse) {
+	variant := AssignVariant(ExperimentWorkshopRanking, userID, rankingVariants)
+	scorer := newRankingScorer(cfg, userID, variant)
+	if scorer.Name() != RankingChronological {
+		for i := range resp.ThisWeek {
+			rankItems(scorer, resp.ThisWeek[i].Workshops)
+		}
+		rankItems(scorer, resp.PostThisWeek)
+	}
+	RecordExposure(cfg, ExperimentWorkshopRanking, scorer.Name(), userID)
+}
+
+// rankItems sorts one bucket by descending score; the stable sort keeps
+// chronological order within equal scores.
+func rankItems(scorer RankingScorer, items []response.WorkshopListItem) {
+	sort.SliceStable(items, func(a, b int) bool {
+		return scorer.Score(items[a]) > scorer.Score(items[b])
+	})
+}
+
+// workshopLikeCounts aggregates live like counts per workshop UUID.
+// Errors read as an empty map: a ranking experiment must never fail the
+// feed.
+func workshopLikeCounts(cfg *config.Config) map[string]int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	counts := make(map[string]int64)
+	cursor, err := database.Collection(cfg, "reactions").Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"entity_type": "WORKSHOP", "reaction": "LIKE", "is_deleted": false}},
+		{"$group": bson.M{"_id": "$entity_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return counts
+	}
+	var rows []struct {
+		ID    string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return counts
+	}
+	for _, row := range rows {
+		counts[row.ID] = row.Count
+	}
+	return counts
+}
+
+// userArtistReactions returns the artist IDs the user follows or likes.
+func userArtistReactions(cfg *config.Config, userID string) map[string]bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	artists := make(map[string]bool)
+	cursor, err := database.Collection(cfg, "reactions").Find(ctx,
+		bson.M{"user_id": userID, "entity_type": "ARTIST", "is_deleted": false})
+	if err != nil {
+		return artists
+	}
+	var reactions []Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return artists
+	}
+	for _, r := range reactions {
+		artists[r.EntityID] = true
+	}
+	return artists
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Razorpay webhook plumbing: signature verification over the raw delivery
+// body, translation of Razorpay's native payload shape into the WebhookEvent
+// that ProcessPaymentWebhook consumes, and an audit trail of every delivery.
+
+// razorpayWebhook is the subset of Razorpay's webhook payload we read. The
+// order ID lives on the payment entity for payment.* events and on the
+// order entity for order.* events.
+type razorpayWebhook struct {
+	Event     string `json:"event"`
+	CreatedAt int64  `json:"created_at"`
+	Payload   struct {
+		Payment struct {
+			Entity struct {
+				OrderID string `json:"order_id"`
+			} `json:"entity"`
+		} `json:"payment"`
+		Order struct {
+			Entity struct {
+				ID string `json:"id"`
+			} `json:"entity"`
+		} `json:"order"`
+		Refund struct {
+			Entity struct {
+				OrderID string `json:"order_id"`
+			} `json:"entity"`
+		} `json:"refund"`
+	} `json:"payload"`
+}
+
+// VerifyRazorpaySignature checks the X-Razorpay-Signature header against the
+// HMAC-SHA256 of the raw body under the webhook secret. Comparison is
+// constant-time.
+func VerifyRazorpaySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// ParseRazorpayEvent maps a verified Razorpay delivery onto the WebhookEvent
+// shape. The event ID comes from the X-Razorpay-Event-Id header and doubles
+// as the idempotency nonce; a delivery without a created_at stamp is treated
+// as sent now.
+func ParseRazorpayEvent(eventID string, body []byte) (WebhookEvent, error) {
+	var payload razorpayWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return WebhookEvent{}, fmt.Errorf("malformed webhook payload: %w", err)
+	}
+	if payload.Event == "" {
+		return WebhookEvent{}, fmt.Errorf("webhook payload missing event name")
+	}
+	orderID := payload.Payload.Payment.Entity.OrderID
+	if orderID == "" {
+		orderID = payload.Payload.Order.Entity.ID
+	}
+	if orderID == "" {
+		orderID = payload.Payload.Refund.Entity.OrderID
+	}
+	if orderID == "" {
+		return WebhookEvent{}, fmt.Errorf("webhook payload missing order id")
+	}
+	timestamp := payload.CreatedAt
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+	return WebhookEvent{
+		EventID:   eventID,
+		Event:     payload.Event,
+		OrderID:   orderID,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// RazorpayEventHandled reports whether a gateway event name maps to an order
+// transition. Unhandled events are acknowledged without processing so
+// Razorpay does not retry deliveries we will never act on.
+func RazorpayEventHandled(event string) bool {
+	switch event {
+	case "payment.captured", "order.paid", "payment.failed", "refund.processed":
+		return true
+	}
+	return false
+}
+
+// RecordWebhookDelivery writes one delivery to the webhook_events audit
+// trail with its raw payload and outcome. Best-effort: auditing must never
+// fail the webhook response the gateway sees.
+func RecordWebhookDelivery(cfg *config.Config, eventID, event, orderID string, signatureValid bool, body []byte, outcome string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "webhook_events").InsertOne(ctx, bson.M{
+		"event_id":        eventID,
+		"event":           event,
+		"order_id":        orderID,
+		"signature_valid": signatureValid,
+		"raw_payload":     string(body),
+		"outcome":         outcome,
+		"received_at":     time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("webhook audit: record failed for event %s: %v", eventID, err)
+	}
+}
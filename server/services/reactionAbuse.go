@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+)
+
+// Reaction abuse detection: like/follow farms create bursts well under the
+// hard rate limit and spread accounts across a single device. Sustained
+// burst velocity or a device token shared across accounts flags the account
+// into the moderation queue and shadow-limits it — its reactions still
+// return success but are written soft-deleted, so spam never surfaces while
+// moderation reviews the flag.
+
+const (
+	// abuseBurstThreshold reactions inside abuseBurstWindow flags the
+	// account; it sits below the hard limit so bots pacing themselves
+	// under it are still caught across the longer window.
+	abuseBurstWindow    = 5 * time.Minute
+	abuseBurstThreshold = 100
+	// A device token seen on more than this many accounts is a farm.
+	abuseSharedTokenAccounts = 3
+)
+
+// AbuseFlag is a moderation-queue entry for a flagged account.
+type AbuseFlag struct {
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Reason    string    `bson:"reason" json:"reason"`
+	Detail    string    `bson:"detail" json:"detail"`
+	FlaggedAt time.Time `bson:"flagged_at" json:"flagged_at"`
+	Resolved  bool      `bson:"resolved" json:"resolved"`
+}
+
+var (
+	shadowLimited   = make(map[string]bool)
+	shadowLimitedMu sync.RWMutex
+)
+
+// IsShadowLimited reports whether the user's reactions are being silently
+// dropped.
+func (s *ReactionServiceImpl) IsShadowLimited(userID string) bool {
+	shadowLimitedMu.RLock()
+	limited := shadowLimited[userID]
+	shadowLimitedMu.RUnlock()
+	if limited {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	count, err := database.Collection(s.cfg, "abuse_flags").CountDocuments(ctx,
+		bson.M{"user_id": userID, "resolved": false})
+	if err != nil || count == 0 {
+		return false
+	}
+	shadowLimitedMu.Lock()
+	shadowLimited[userID] = true
+	shadowLimitedMu.Unlock()
+	return true
+}
+
+// CreateShadowedReaction writes a shadow-limited account's reaction as
+// already soft-deleted: the caller sees success, followers and counts
+// never do.
+func (s *ReactionServiceImpl) CreateShadowedReaction(r Reaction) error {
+	r.IsDeleted = true
+	r.UpdatedAt = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "reactions").UpdateOne(ctx,
+		bson.M{"user_id": r.UserID, "entity_id": r.EntityID, "entity_type": r.EntityType, "reaction": r.Reaction},
+		bson.M{"$set": r},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// RecordReactionForAbuse runs the abuse heuristics after a reaction write.
+// Detection is advisory: errors are logged, never returned to the caller.
+func (s *ReactionServiceImpl) RecordReactionForAbuse(userID string) {
+	if s.checkBurstVelocity(userID) {
+		s.flagAccount(userID, "burst_velocity",
+			"sustained reaction burst above threshold")
+	}
+}
+
+// checkBurstVelocity counts the user's reaction writes in the burst window.
+func (s *ReactionServiceImpl) checkBurstVelocity(userID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	count, err := database.Collection(s.cfg, "reactions").CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"updated_at": bson.M{"$gte": time.Now().UTC().Add(-abuseBurstWindow)},
+	})
+	if err != nil {
+		log.Printf("abuse check: burst count failed for %s: %v", userID, err)
+		return false
+	}
+	return count >= abuseBurstThreshold
+}
+
+// CheckSharedDeviceToken flags every account registered with the given
+// device token when the token is shared across too many accounts. Called
+// from device token registration.
+func (s *ReactionServiceImpl) CheckSharedDeviceToken(token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	userIDs, err := database.Collection(s.cfg, "device_tokens").Distinct(ctx,
+		"user_id", bson.M{"token": token})
+	if err != nil {
+		log.Printf("abuse check: shared token lookup failed: %v", err)
+		return
+	}
+	if len(userIDs) <= abuseSharedTokenAccounts {
+		return
+	}
+	for _, id := range userIDs {
+		userID, ok := id.(string)
+		if !ok {
+			continue
+		}
+		s.flagAccount(userID, "shared_device_token",
+			"device token registered across multiple accounts")
+	}
+}
+
+// flagAccount shadow-limits the account and upserts its moderation-queue
+// entry.
+func (s *ReactionServiceImpl) flagAccount(userID, reason, detail string) {
+	shadowLimitedMu.Lock()
+	alreadyLimited := shadowLimited[userID]
+	shadowLimited[userID] = true
+	shadowLimitedMu.Unlock()
+	if alreadyLimited {
+		return
+	}
+	log.Printf("abuse: shadow-limited user %s (%s)", userID, reason)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "abuse_flags").UpdateOne(ctx,
+		bson.M{"user_id": userID, "reason": reason, "resolved": false},
+		bson.M{"$setOnInsert": AbuseFlag{
+			UserID:    userID,
+			Reason:    reason,
+			Detail:    detail,
+			FlaggedAt: time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("abuse: persist flag for %s failed: %v", userID, err)
+	}
+}
+
+// GetFlaggedAccounts returns the unresolved moderation queue, newest first.
+func (s *ReactionServiceImpl) GetFlaggedAccounts() ([]AbuseFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "abuse_flags").Find(ctx,
+		bson.M{"resolved": false},
+		options.Find().SetSort(bson.M{"flagged_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	flags := []AbuseFlag{}
+	if err := cursor.All(ctx, &flags); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// ResolveAbuseFlag clears a user's flags and lifts the shadow limit.
+func (s *ReactionServiceImpl) ResolveAbuseFlag(userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "abuse_flags").UpdateMany(ctx,
+		bson.M{"user_id": userID, "resolved": false},
+		bson.M{"$set": bson.M{"resolved": true}})
+	if err != nil {
+		return err
+	}
+	shadowLimitedMu.Lock()
+	delete(shadowLimited, userID)
+	shadowLimitedMu.Unlock()
+	return nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Reaction is a user's like/follow/notify relationship with an entity.
+type Reaction struct {
+	UserID     string    `bson:"user_id" json:"user_id"`
+	EntityID   string    `bson:"entity_id" json:"entity_id"`
+	EntityType string    `bson:"entity_type" json:"entity_type"`
+	Reaction   string    `bson:"reaction" json:"reaction"`
+	IsDeleted  bool      `bson:"is_deleted" json:"is_deleted"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ReactionSyncResult is the delta-sync envelope: every reaction changed
+// since the client's cursor — deletions included as is_deleted tombstones
+// — plus the cursor to pass as ?since= on the next sync.
+type ReactionSyncResult struct {
+	Reactions []Reaction `json:"reactions"`
+	Cursor    int64      `json:"cursor"`
+}
+
+// ReactionService manages likes, follows, and notification follows.
+type ReactionService interface {
+	CheckRateLimit(userID string) error
+	CreateReaction(r Reaction) error
+	DeleteReaction(userID, entityID, entityType, reaction string) error
+	GetUserReactions(userID string) ([]Reaction, error)
+	GetUserReactionsSince(userID string, since int64) (*ReactionSyncResult, error)
+}
+
+// ReactionServiceImpl implements ReactionService with a simple in-memory
+// per-user rate limit.
+type ReactionServiceImpl struct {
+	cfg    *config.Config
+	mu     sync.Mutex
+	recent map[string][]time.Time
+	limit  int
+	window time.Duration
+}
+
+// NewReactionService builds the reaction service.
+func NewReactionService(cfg *config.Config) *ReactionServiceImpl {
+	return &ReactionServiceImpl{
+		cfg:    cfg,
+		recent: make(map[string][]time.Time),
+		limit:  30,
+		window: time.Minute,
+	}
+}
+
+// CheckRateLimit rejects users creating reactions faster than the per-minute
+// limit.
+func (s *ReactionServiceImpl) CheckRateLimit(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.window)
+	kept := s.recent[userID][:0]
+	for _, t := range s.recent[userID] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= s.limit {
+		s.recent[userID] = kept
+		return fmt.Errorf("rate limit exceeded: max %d reactions per minute", s.limit)
+	}
+	s.recent[userID] = append(kept, time.Now())
+	return nil
+}
+
+// CreateReaction upserts a reaction, reviving a previously soft-deleted one.
+func (s *ReactionServiceImpl) CreateReaction(r Reaction) error {
+	r.IsDeleted = false
+	r.UpdatedAt = time.Now().UTC()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(s.cfg, "reactions").UpdateOne(ctx,
+		bson.M{"user_id": r.UserID, "entity_id": r.EntityID, "entity_type": r.EntityType, "reaction": r.Reaction},
+		bson.M{"$set": r},
+		options.Update().SetUpsert(true))
+	if err == nil && result.UpsertedCount > 0 {
+		EmitEvent(s.cfg, EventReactionCreated, bson.M{
+			"user_id":     r.UserID,
+			"entity_id":   r.EntityID,
+			"entity_type": r.EntityType,
+			"reaction":    r.Reaction,
+		})
+	}
+	return err
+}
+
+// DeleteReaction soft-deletes a reaction.
+func (s *ReactionServiceImpl) DeleteReaction(userID, entityID, entityType, reaction string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(s.cfg, "reactions").UpdateOne(ctx,
+		bson.M{"user_id": userID, "entity_id": entityID, "entity_type": entityType, "reaction": reaction},
+		bson.M{"$set": bson.M{"is_deleted": true, "updated_at": time.Now().UTC()}})
+	return err
+}
+
+// GetUserReactions returns every non-deleted reaction for a user.
+func (s *ReactionServiceImpl) GetUserReactions(userID string) ([]Reaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "reactions").Find(ctx,
+		bson.M{"user_id": userID, "is_deleted": false})
+	if err != nil {
+		return nil, err
+	}
+	reactions := []Reaction{}
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// GetUserReactionsSince returns only the user's reactions touched after
+// the since cursor (epoch seconds), soft-deleted tombstones included so
+// the client can drop them locally. The returned cursor is captured
+// before the query runs — a write landing mid-query is re-sent on the
+// next sync rather than lost.
+func (s *ReactionServiceImpl) GetUserReactionsSince(userID string, since int64) (*ReactionSyncResult, error) {
+	cursor := time.Now().Unix()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dbCursor, err := database.Collection(s.cfg, "reactions").Find(ctx,
+		bson.M{"user_id": userID, "updated_at": bson.M{"$gt": time.Unix(since, 0).UTC()}},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	reactions := []Reaction{}
+	if err := dbCursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	return &ReactionSyncResult{Reactions: reactions, Cursor: cursor}, nil
+}
+
+// GetFollowersOfArtist returns user IDs with a notify follow on the artist.
+func (s *ReactionServiceImpl) GetFollowersOfArtist(artistID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(s.cfg, "reactions").Find(ctx,
+		bson.M{"entity_id": artistID, "entity_type": "ARTIST", "reaction": "NOTIFY", "is_deleted": false})
+	if err != nil {
+		return nil, err
+	}
+	var reactions []Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	userIDs := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		userIDs = append(userIDs, r.UserID)
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+)
+
+// Post-event recap videos: studios attach a highlight reel to a finished
+// workshop, and the recap surfaces on the artist and studio profiles to
+// drive future bookings. Recaps carry denormalized artist and studio IDs
+// captured at save time, because workshop documents are archived or
+// replaced after the event — profile queries go straight to the recaps
+// collection. Video bytes follow the page-snapshot precedent and live in
+// MongoDB; external hosting (Instagram, YouTube) is just a link.
+
+// recapMaxUploadBytes caps direct video uploads; anything bigger belongs
+// on an external host with a link recap instead.
+const recapMaxUploadBytes = 25 << 20
+
+// WorkshopRecap is one recap video attached to a workshop. Exactly one of
+// VideoURL (external host) or the stored blob is set; the blob itself
+// never serializes, only its size.
+type WorkshopRecap struct {
+	RecapID      string    `bson:"recap_id" json:"recap_id"`
+	WorkshopUUID string    `bson:"workshop_uuid" json:"workshop_uuid"`
+	StudioID     string    `bson:"studio_id" json:"studio_id"`
+	ArtistIDList []string  `bson:"artist_id_list" json:"artist_id_list"`
+	Song         *string   `bson:"song" json:"song"`
+	VideoURL     string    `bson:"video_url" json:"video_url"`
+	Video        []byte    `bson:"video,omitempty" json:"-"`
+	VideoBytes   int64     `bson:"video_bytes" json:"video_bytes"`
+	ContentType  string    `bson:"content_type" json:"content_type"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+}
+
+// SaveRecapLink attaches an externally hosted recap video to a workshop.
+func SaveRecapLink(cfg *config.Config, workshopUUID, videoURL string) (*WorkshopRecap, error) {
+	return saveRecap(cfg, workshopUUID, videoURL, "", nil)
+}
+
+// SaveRecapUpload stores an uploaded recap video in MongoDB and attaches
+// it to a workshop.
+func SaveRecapUpload(cfg *config.Config, workshopUUID, contentType string, video []byte) (*WorkshopRecap, error) {
+	if len(video) == 0 {
+		return nil, fmt.Errorf("video body is empty")
+	}
+	if len(video) > recapMaxUploadBytes {
+		return nil, fmt.Errorf("video exceeds %d MB; host it externally and attach the link", recapMaxUploadBytes>>20)
+	}
+	return saveRecap(cfg, workshopUUID, "", contentType, video)
+}
+
+// saveRecap resolves the workshop (archived documents included — recaps
+// are inherently post-event) and inserts the recap with the workshop's
+// identity denormalized onto it.
+func saveRecap(cfg *config.Config, workshopUUID, videoURL, contentType string, video []byte) (*WorkshopRecap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var workshop response.Workshop
+	err := database.Collection(cfg, "workshops_v2").FindOne(ctx,
+		bson.M{"uuid": workshopUUID}).Decode(&workshop)
+	if err != nil {
+		return nil, fmt.Errorf("workshop not found: %s", workshopUUID)
+	}
+
+	recap := &WorkshopRecap{
+		RecapID:      uuid.NewString(),
+		WorkshopUUID: workshopUUID,
+		StudioID:     workshop.StudioID,
+		ArtistIDList: workshop.ArtistIDList,
+		Song:         workshop.Song,
+		VideoURL:     videoURL,
+		Video:        video,
+		VideoBytes:   int64(len(video)),
+		ContentType:  contentType,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if _, err := database.Collection(cfg, "workshop_recaps").InsertOne(ctx, recap); err != nil {
+		return nil, err
+	}
+	recap.Video = nil
+	return recap, nil
+}
+
+// DeleteRecap removes one recap.
+func DeleteRecap(cfg *config.Config, recapID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(cfg, "workshop_recaps").DeleteOne(ctx,
+		bson.M{"recap_id": recapID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("recap not found: %s", recapID)
+	}
+	return nil
+}
+
+// GetRecapsByArtist lists an artist's recaps newest first, blobs excluded.
+func GetRecapsByArtist(cfg *config.Config, artistID string) ([]WorkshopRecap, error) {
+	return findRecaps(cfg, bson.M{"artist_id_list": artistID})
+}
+
+// GetRecapsByStudio lists a studio's recaps newest first, blobs excluded.
+func GetRecapsByStudio(cfg *config.Config, studioID string) ([]WorkshopRecap, error) {
+	return findRecaps(cfg, bson.M{"studio_id": studioID})
+}
+
+func findRecaps(cfg *config.Config, filter bson.M) ([]WorkshopRecap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "workshop_recaps").Find(ctx, filter,
+		options.Find().
+			SetProjection(bson.M{"video": 0}).
+			SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	recaps := []WorkshopRecap{}
+	if err := cursor.All(ctx, &recaps); err != nil {
+		return nil, err
+	}
+	return recaps, nil
+}
+
+// GetRecapVideo fetches one stored recap's bytes and content type;
+// (nil, "", nil) when the recap does not exist or is link-only.
+func GetRecapVideo(cfg *config.Config, recapID string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var recap WorkshopRecap
+	err := database.Collection(cfg, "workshop_recaps").FindOne(ctx,
+		bson.M{"recap_id": recapID}).Decode(&recap)
+	if err == mongo.ErrNoDocuments {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if len(recap.Video) == 0 {
+		return nil, "", nil
+	}
+	contentType := recap.ContentType
+	if contentType == "" {
+		contentType = "video/mp4"
+	}
+	return recap.Video, contentType, nil
+}
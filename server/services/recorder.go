@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dev-mode request recorder: in non-prod environments every API request
+// and response is appended, sanitized, to a JSONL file on disk, and the
+// replay command (cmd/replay) re-runs a recorded session against another
+// build. That turns a few minutes of clicking through the admin panel or
+// the app into a reusable regression check after a refactor. Secrets
+// never reach disk — credential headers are dropped and sensitive JSON
+// fields are masked — and bodies are capped so a recording stays
+// readable.
+
+// maxRecordedBody caps how much of a request or response body is kept.
+const maxRecordedBody = 16 * 1024
+
+// RecordedRequest is one request/response pair in a recording file.
+type RecordedRequest struct {
+	// Server says which listener served the request: "api" (fasthttp) or
+	// "mux", so the replayer knows which base URL to hit.
+	Server       string    `json:"server"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Query        string    `json:"query,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	Status       int       `json:"status"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// RequestRecorder appends recorded requests to one JSONL file per process.
+type RequestRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRequestRecorder opens a fresh recording file in dir.
+func NewRequestRecorder(dir string) (*RequestRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("requests-%s.jsonl", time.Now().UTC().Format("20060102-150405")))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("request recorder: writing %s", name)
+	return &RequestRecorder{file: file}, nil
+}
+
+// Record sanitizes and appends one request. Best effort — recording must
+// never fail the request it observed.
+func (r *RequestRecorder) Record(entry RecordedRequest) {
+	entry.RequestBody = sanitizeRecordedBody(entry.RequestBody)
+	entry.ResponseBody = sanitizeRecordedBody(entry.ResponseBody)
+	entry.RecordedAt = time.Now().UTC()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("request recorder: marshal failed: %v", err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		log.Printf("request recorder: write failed: %v", err)
+	}
+}
+
+// WrapHTTP records every JSON request/response served by the mux server.
+// The event stream is passed through untouched: it never ends, so there
+// is nothing meaningful to record or replay.
+func (r *RequestRecorder) WrapHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/api/stream" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		var requestBody []byte
+		if req.Body != nil {
+			requestBody, _ = io.ReadAll(io.LimitReader(req.Body, maxRecordedBody))
+			req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(requestBody), req.Body))
+		}
+		capture := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(capture, req)
+		r.Record(RecordedRequest{
+			Server:       "mux",
+			Method:       req.Method,
+			Path:         req.URL.Path,
+			Query:        req.URL.RawQuery,
+			ContentType:  req.Header.Get("Content-Type"),
+			RequestBody:  string(requestBody),
+			Status:       capture.status,
+			ResponseBody: capture.body.String(),
+		})
+	})
+}
+
+// responseCapture tees the response status and up to maxRecordedBody of
+// the body while writing through to the client.
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(p []byte) (int, error) {
+	if c.body.Len() < maxRecordedBody {
+		remaining := maxRecordedBody - c.body.Len()
+		if remaining > len(p) {
+			remaining = len(p)
+		}
+		c.body.Write(p[:remaining])
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// Flush keeps streaming handlers working behind the capture.
+func (c *responseCapture) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// sensitiveRecordedFields are JSON keys whose values are masked before a
+// body reaches disk.
+var sensitiveRecordedFields = map[string]bool{
+	"password":      true,
+	"otp":           true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"device_token":  true,
+	"csrf_token":    true,
+	"authorization": true,
+	"api_key":       true,
+	"secret":        true,
+}
+
+// sanitizeRecordedBody masks sensitive fields in a JSON body and caps its
+// size; non-JSON bodies are kept as-is (already capped at capture time).
+func sanitizeRecordedBody(body string) string {
+	if len(body) > maxRecordedBody {
+		body = body[:maxRecordedBody]
+	}
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+		return body
+	}
+	masked, err := json.Marshal(maskSensitiveValues(parsed))
+	if err != nil {
+		return body
+	}
+	return string(masked)
+}
+
+// maskSensitiveValues walks a decoded JSON value, replacing sensitive
+// field values with a placeholder.
+func maskSensitiveValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if sensitiveRecordedFields[strings.ToLower(key)] {
+				v[key] = "[redacted]"
+				continue
+			}
+			v[key] = maskSensitiveValues(inner)
+		}
+		return v
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = maskSensitiveValues(inner)
+		}
+		return v
+	default:
+		return value
+	}
+}
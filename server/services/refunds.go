@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Refund policy engine: each studio can declare cancellation tiers on its
+// document (no refund inside 48 hours, half inside a week, and so on), and
+// the engine evaluates them against the hours remaining until the booked
+// session starts. Quotes are computed the same way before confirmation and
+// during the actual cancellation, so what the user saw is what is applied.
+
+// DefaultRefundPolicy applies to studios without their own tiers: full
+// refund a week or more out, half inside a week, nothing inside 48 hours.
+var DefaultRefundPolicy = []response.RefundTier{
+	{MinHoursBefore: 168, RefundPercent: 100},
+	{MinHoursBefore: 48, RefundPercent: 50},
+	{MinHoursBefore: 0, RefundPercent: 0},
+}
+
+// RefundQuote is the evaluated outcome of cancelling an order now.
+type RefundQuote struct {
+	OrderID       string `json:"order_id"`
+	WorkshopUUID  string `json:"workshop_uuid"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	HoursBefore   int64  `json:"hours_before"`
+	RefundPercent int64  `json:"refund_percent"`
+	RefundAmount  int64  `json:"refund_amount"`
+	// PolicySource is "studio" when the studio's own tiers applied, or
+	// "default" for the platform policy.
+	PolicySource string    `json:"policy_source"`
+	EvaluatedAt  time.Time `json:"evaluated_at"`
+}
+
+// EvaluateRefundPolicy returns the refund percentage for a cancellation
+// hoursBefore the session start. Tiers are matched highest threshold first;
+// sessions already started refund nothing.
+func EvaluateRefundPolicy(policy []response.RefundTier, hoursBefore int64) int64 {
+	if hoursBefore < 0 {
+		return 0
+	}
+	tiers := append([]response.RefundTier(nil), policy...)
+	sort.Slice(tiers, func(a, b int) bool { return tiers[a].MinHoursBefore > tiers[b].MinHoursBefore })
+	for _, tier := range tiers {
+		if hoursBefore >= tier.MinHoursBefore {
+			return tier.RefundPercent
+		}
+	}
+	return 0
+}
+
+// QuoteRefund evaluates the refund policy for cancelling a paid order now.
+// The quote is what the client shows before asking the user to confirm;
+// the cancellation flow re-evaluates with the same engine on execution.
+func (s *OrderServiceImpl) QuoteRefund(orderID, userID string) (*RefundQuote, error) {
+	order := s.getOrder(orderID)
+	if order == nil || order.UserID != userID {
+		return nil, fmt.Errorf("order not found: %s", orderID)
+	}
+	if order.Status != OrderStatusPaid {
+		return nil, fmt.Errorf("only paid orders can be cancelled")
+	}
+
+	workshop, err := database.GetWorkshopByUUID(s.cfg, order.WorkshopUUID)
+	if err != nil {
+		return nil, fmt.Errorf("workshop not found for order: %s", orderID)
+	}
+	start, err := firstSessionStart(workshop)
+	if err != nil {
+		return nil, err
+	}
+	hoursBefore := int64(time.Until(start) / time.Hour)
+
+	policy, source := studioRefundPolicy(s.cfg, workshop.StudioID)
+	percent := EvaluateRefundPolicy(policy, hoursBefore)
+	return &RefundQuote{
+		OrderID:       order.OrderID,
+		WorkshopUUID:  order.WorkshopUUID,
+		Amount:        order.Amount,
+		Currency:      order.Currency,
+		HoursBefore:   hoursBefore,
+		RefundPercent: percent,
+		RefundAmount:  order.Amount * percent / 100,
+		PolicySource:  source,
+		EvaluatedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// studioRefundPolicy returns the studio's own tiers when configured, or the
+// platform default.
+func studioRefundPolicy(cfg *config.Config, studioID string) ([]response.RefundTier, string) {
+	studios, err := database.GetStudioMap(cfg)
+	if err != nil {
+		return DefaultRefundPolicy, "default"
+	}
+	if studio, ok := studios[studioID]; ok && len(studio.RefundPolicy) > 0 {
+		return studio.RefundPolicy, "studio"
+	}
+	return DefaultRefundPolicy, "default"
+}
+
+// firstSessionStart returns the start of the workshop's earliest scheduled
+// session.
+func firstSessionStart(workshop *response.Workshop) (time.Time, error) {
+	var earliest int64
+	for _, td := range workshop.TimeDetails {
+		if td.Day == nil || td.Month == nil || td.Year == nil {
+			continue
+		}
+		epoch := utils.GetTimestampEpoch(td)
+		if earliest == 0 || epoch < earliest {
+			earliest = epoch
+		}
+	}
+	if earliest == 0 {
+		return time.Time{}, fmt.Errorf("workshop has no scheduled session")
+	}
+	return time.Unix(earliest, 0), nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+)
+
+// Workshop reminders: users who booked a workshop (paid order) or liked
+// it get one push in the hours before the first session starts. Sent
+// reminders are tracked per user and workshop in the reminders collection,
+// so the frequent scheduler passes in application/main.go never double-send
+// no matter how often they scan the same window.
+
+// ReminderLeadHours is how far before a workshop's first session the
+// reminder goes out. The scheduler runs much more often than this, so a
+// reminder lands within one scheduler interval of the lead mark.
+const ReminderLeadHours = 24
+
+// SendWorkshopReminders scans upcoming sessions starting within the lead
+// window and reminds every booked or liking user who has not been reminded
+// for that workshop yet. Returns the number of reminders delivered.
+func (s *NotificationServiceImpl) SendWorkshopReminders() (int, error) {
+	items, err := database.GetUpcomingWorkshopItems(s.cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	deadline := now + ReminderLeadHours*3600
+	sent := 0
+	remindedWorkshops := map[string]bool{}
+	for _, item := range items {
+		if item.TimestampEpoch <= now || item.TimestampEpoch > deadline || remindedWorkshops[item.UUID] {
+			continue
+		}
+		// Multi-session workshops appear once per session; one reminder
+		// per workshop is enough.
+		remindedWorkshops[item.UUID] = true
+		for _, userID := range s.reminderRecipients(item.UUID) {
+			if !s.markReminderSent(userID, item.UUID) {
+				continue
+			}
+			if err := s.sendReminder(userID, item); err != nil {
+				log.Printf("reminder failed for user %s workshop %s: %v", userID, item.UUID, err)
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// reminderRecipients returns the deduped users who should be reminded
+// about a workshop: paid-order holders plus users who liked it.
+func (s *NotificationServiceImpl) reminderRecipients(workshopUUID string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	seen := map[string]bool{}
+	var userIDs []string
+
+	cursor, err := database.Collection(s.cfg, "orders").Find(ctx,
+		bson.M{"workshop_uuid": workshopUUID, "status": OrderStatusPaid})
+	if err != nil {
+		log.Printf("reminder: order lookup failed for %s: %v", workshopUUID, err)
+	} else {
+		var orders []Order
+		if err := cursor.All(ctx, &orders); err != nil {
+			log.Printf("reminder: order lookup failed for %s: %v", workshopUUID, err)
+		}
+		for _, order := range orders {
+			if !seen[order.UserID] {
+				seen[order.UserID] = true
+				userIDs = append(userIDs, order.UserID)
+			}
+		}
+	}
+
+	cursor, err = database.Collection(s.cfg, "reactions").Find(ctx,
+		bson.M{"entity_id": workshopUUID, "entity_type": "WORKSHOP", "reaction": "LIKE", "is_deleted": false})
+	if err != nil {
+		log.Printf("reminder: reaction lookup failed for %s: %v", workshopUUID, err)
+		return userIDs
+	}
+	var reactions []Reaction
+	if err := cursor.All(ctx, &reactions); err != nil {
+		log.Printf("reminder: reaction lookup failed for %s: %v", workshopUUID, err)
+		return userIDs
+	}
+	for _, r := range reactions {
+		if !seen[r.UserID] {
+			seen[r.UserID] = true
+			userIDs = append(userIDs, r.UserID)
+		}
+	}
+	return userIDs
+}
+
+// sendReminder pushes one reminder with the workshop's branding attached.
+func (s *NotificationServiceImpl) sendReminder(userID string, item response.WorkshopListItem) error {
+	title := "Workshop starting soon"
+	if item.Song != nil && *item.Song != "" {
+		title = fmt.Sprintf("%s is coming up", *item.Song)
+	}
+	body := "Your workshop starts within the next day."
+	if item.Date != nil && item.Time != nil {
+		body = fmt.Sprintf("Starts %s at %s. See you on the floor!", *item.Date, *item.Time)
+	}
+	data := map[string]string{"deep_link": "nachna://workshop/" + item.UUID}
+	// Streaming workshops get a heads-up in the reminder, but never the
+	// raw link — that stays behind the paid-order reveal window.
+	if HasStreamingLink(s.cfg, item.UUID) {
+		body += fmt.Sprintf(" You can join online; the stream link unlocks %d minutes before start.", StreamRevealMinutes)
+		data["online_stream"] = "true"
+	}
+	s.applyWorkshopBranding(item.UUID, data)
+	return s.sendToUser(userID, title, body, data)
+}
+
+// markReminderSent records a reminder in the reminders collection and
+// reports whether this call was the first for the user-workshop pair. A
+// Mongo error counts as first — a duplicate reminder beats a missed one.
+func (s *NotificationServiceImpl) markReminderSent(userID, workshopUUID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(s.cfg, "reminders").UpdateOne(ctx,
+		bson.M{"user_id": userID, "workshop_uuid": workshopUUID},
+		bson.M{"$setOnInsert": bson.M{
+			"user_id":       userID,
+			"workshop_uuid": workshopUUID,
+			"sent_at":       time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("reminders: record failed for user %s: %v", userID, err)
+		return true
+	}
+	return result.MatchedCount == 0
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Replay protection for endpoints that mutate data on behalf of an external
+// caller — payment webhooks today, MCP tool calls once mutating tools exist.
+// Callers send a unique nonce and a unix timestamp; the guard rejects
+// requests outside the freshness window and nonces it has already seen.
+// Nonces are recorded in the replay_nonces collection so a replay against a
+// different replica is still caught.
+
+// defaultReplayWindow bounds how old (or how far in the future, for clock
+// skew) a request timestamp may be.
+const defaultReplayWindow = 5 * time.Minute
+
+// replayPruneInterval is how often stale nonces are swept.
+const replayPruneInterval = time.Minute
+
+// ErrReplayedRequest marks a nonce that has already been accepted. Webhook
+// handlers treat it as "already processed" and acknowledge the delivery so
+// the gateway stops retrying, rather than surfacing an error.
+var ErrReplayedRequest = errors.New("replayed request: nonce already used")
+
+// ReplayGuard validates nonce/timestamp pairs against a replay cache.
+type ReplayGuard struct {
+	cfg    *config.Config
+	window time.Duration
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewReplayGuard builds a guard with the configured freshness window.
+func NewReplayGuard(cfg *config.Config) *ReplayGuard {
+	window := defaultReplayWindow
+	if cfg.ReplayWindowSeconds > 0 {
+		window = time.Duration(cfg.ReplayWindowSeconds) * time.Second
+	}
+	return &ReplayGuard{cfg: cfg, window: window, seen: map[string]time.Time{}}
+}
+
+// CheckAndRecord accepts a request exactly once. It returns an error when
+// the nonce is missing, the timestamp falls outside the freshness window, or
+// the nonce has been seen before (in memory or by another replica).
+func (g *ReplayGuard) CheckAndRecord(nonce string, timestamp int64) error {
+	if nonce == "" {
+		return fmt.Errorf("missing request nonce")
+	}
+	now := time.Now()
+	sent := time.Unix(timestamp, 0)
+	if sent.Before(now.Add(-g.window)) || sent.After(now.Add(g.window)) {
+		return fmt.Errorf("request timestamp outside the %v freshness window", g.window)
+	}
+
+	g.mu.Lock()
+	if _, dup := g.seen[nonce]; dup {
+		g.mu.Unlock()
+		return ErrReplayedRequest
+	}
+	g.seen[nonce] = now
+	prune := now.Sub(g.lastPrune) > replayPruneInterval
+	if prune {
+		g.lastPrune = now
+		for n, at := range g.seen {
+			if now.Sub(at) > g.window {
+				delete(g.seen, n)
+			}
+		}
+	}
+	g.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(g.cfg, "replay_nonces").InsertOne(ctx,
+		bson.M{"_id": nonce, "seen_at": now.UTC()})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrReplayedRequest
+	}
+	if err != nil {
+		// Mongo being unreachable should not take payment processing down;
+		// the in-memory check above still protects this replica.
+		return nil
+	}
+	if prune {
+		go g.pruneStored()
+	}
+	return nil
+}
+
+// pruneStored deletes nonces older than the window from the shared cache.
+func (g *ReplayGuard) pruneStored() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cutoff := time.Now().Add(-g.window).UTC()
+	database.Collection(g.cfg, "replay_nonces").DeleteMany(ctx,
+		bson.M{"seen_at": bson.M{"$lt": cutoff}})
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Retention policy engine: the compliance rules that used to live in
+// ad-hoc scripts run as declarative policies inside the server. Each
+// policy names a collection, a cutoff derived from config, and an action
+// (anonymize or delete). A nightly job executes them all; the admin
+// endpoint runs the same engine in dry-run mode to report what would be
+// touched, and every executed policy writes an audit entry to the
+// retention_audit collection.
+
+// RetentionAction is what a policy does to matching documents.
+type RetentionAction string
+
+const (
+	RetentionAnonymize RetentionAction = "anonymize"
+	RetentionDelete    RetentionAction = "delete"
+)
+
+// retentionPolicy is one declarative retention rule.
+type retentionPolicy struct {
+	Name        string
+	Collection  string
+	Description string
+	Action      RetentionAction
+	Filter      bson.M
+	// Update applies only to anonymize policies.
+	Update bson.M
+}
+
+// RetentionResult reports one policy's pass.
+type RetentionResult struct {
+	Policy      string          `json:"policy"`
+	Collection  string          `json:"collection"`
+	Description string          `json:"description"`
+	Action      RetentionAction `json:"action"`
+	Matched     int64           `json:"matched"`
+	Affected    int64           `json:"affected"`
+	DryRun      bool            `json:"dry_run"`
+	RanAt       time.Time       `json:"ran_at"`
+	// Error is set when the policy's pass failed; the counts may then be
+	// partial or zero.
+	Error string `json:"error,omitempty"`
+}
+
+// retentionPolicies builds the active policy set from config-driven
+// cutoffs. Soft-deleted user cleanup matches nothing until user deletion
+// gains a soft-delete flag, which keeps the policy declared where
+// compliance reviews can see it.
+func retentionPolicies(cfg *config.Config) []retentionPolicy {
+	now := time.Now().UTC()
+	orderCutoff := now.AddDate(-cfg.RetentionOrdersYears, 0, 0)
+	analyticsCutoff := now.AddDate(0, -cfg.RetentionAnalyticsMonths, 0)
+	userCutoff := now.AddDate(0, 0, -cfg.RetentionDeletedUserGraceDays)
+
+	return []retentionPolicy{
+		{
+			Name:        "orders-pii",
+			Collection:  "orders",
+			Description: "Strip the user link from orders past the retention horizon; amounts stay for revenue reporting.",
+			Action:      RetentionAnonymize,
+			Filter:      bson.M{"created_at": bson.M{"$lt": orderCutoff}, "anonymized": bson.M{"$ne": true}},
+			Update: bson.M{"$set": bson.M{
+				"user_id":       "",
+				"anonymized":    true,
+				"anonymized_at": now,
+			}},
+		},
+		{
+			Name:        "analytics-events",
+			Collection:  "domain_events",
+			Description: "Drop exported domain events past the analytics retention window.",
+			Action:      RetentionDelete,
+			Filter: bson.M{
+				"emitted_at":  bson.M{"$lt": analyticsCutoff},
+				"exported_at": bson.M{"$exists": true},
+			},
+		},
+		{
+			Name:        "deleted-users",
+			Collection:  "users",
+			Description: "Remove soft-deleted accounts once the restore grace period has passed.",
+			Action:      RetentionDelete,
+			Filter:      bson.M{"is_deleted": true, "deleted_at": bson.M{"$lt": userCutoff}},
+		},
+	}
+}
+
+// RunRetentionPolicies executes every policy, or only counts matches when
+// dryRun is set. One failing policy is reported in its result and does not
+// stop the rest.
+func RunRetentionPolicies(cfg *config.Config, dryRun bool) ([]RetentionResult, error) {
+	results := make([]RetentionResult, 0, 3)
+	for _, policy := range retentionPolicies(cfg) {
+		result := RetentionResult{
+			Policy:      policy.Name,
+			Collection:  policy.Collection,
+			Description: policy.Description,
+			Action:      policy.Action,
+			DryRun:      dryRun,
+			RanAt:       time.Now().UTC(),
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		coll := database.Collection(cfg, policy.Collection)
+
+		matched, err := coll.CountDocuments(ctx, policy.Filter)
+		if err != nil {
+			cancel()
+			log.Printf("retention %s: count failed: %v", policy.Name, err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Matched = matched
+
+		if !dryRun && matched > 0 {
+			switch policy.Action {
+			case RetentionAnonymize:
+				updateResult, err := coll.UpdateMany(ctx, policy.Filter, policy.Update)
+				if err != nil {
+					log.Printf("retention %s: update failed: %v", policy.Name, err)
+					result.Error = err.Error()
+				} else {
+					result.Affected = updateResult.ModifiedCount
+				}
+			case RetentionDelete:
+				deleteResult, err := coll.DeleteMany(ctx, policy.Filter)
+				if err != nil {
+					log.Printf("retention %s: delete failed: %v", policy.Name, err)
+					result.Error = err.Error()
+				} else {
+					result.Affected = deleteResult.DeletedCount
+				}
+			}
+			recordRetentionAudit(cfg, result)
+		}
+		cancel()
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// recordRetentionAudit appends one executed policy pass to the
+// retention_audit collection. Best-effort: the data change has already
+// happened, so an audit write failure is logged, not returned.
+func recordRetentionAudit(cfg *config.Config, result RetentionResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "retention_audit").InsertOne(ctx, bson.M{
+		"policy":     result.Policy,
+		"collection": result.Collection,
+		"action":     result.Action,
+		"matched":    result.Matched,
+		"affected":   result.Affected,
+		"ran_at":     result.RanAt,
+	})
+	if err != nil {
+		log.Printf("retention %s: audit write failed: %v", result.Policy, err)
+	}
+}
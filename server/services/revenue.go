@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Revenue reporting for admins: a scheduled aggregation folds the orders
+// collection into revenue_reports, one document per month per studio, so
+// the admin endpoint reads precomputed rows instead of scanning orders on
+// every request. Cohorts split buyers into first-time (this is their
+// earliest paid order on the platform) and returning.
+
+// revenueTakeRate is the flat platform commission assumed for take
+// reporting until per-studio contracts carry their own rates.
+const revenueTakeRate = 0.10
+
+// RevenueReport is one month-by-studio row of the reporting collection.
+type RevenueReport struct {
+	Month           string    `bson:"month" json:"month"`
+	StudioID        string    `bson:"studio_id" json:"studio_id"`
+	GMV             int64     `bson:"gmv" json:"gmv"`
+	Take            int64     `bson:"take" json:"take"`
+	Refunds         int64     `bson:"refunds" json:"refunds"`
+	NetRevenue      int64     `bson:"net_revenue" json:"net_revenue"`
+	Orders          int       `bson:"orders" json:"orders"`
+	FirstTimeBuyers int       `bson:"first_time_buyers" json:"first_time_buyers"`
+	ReturningBuyers int       `bson:"returning_buyers" json:"returning_buyers"`
+	ComputedAt      time.Time `bson:"computed_at" json:"computed_at"`
+}
+
+// ComputeRevenueReports rebuilds the reporting collection from the full
+// order history and returns how many rows were written.
+func ComputeRevenueReports(cfg *config.Config) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := database.Collection(cfg, "orders").Find(ctx, bson.M{
+		"status": bson.M{"$in": []OrderStatus{OrderStatusPaid, OrderStatusRefunded, OrderStatusCancelled}},
+	}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var orders []struct {
+		Order        `bson:",inline"`
+		RefundAmount int64 `bson:"refund_amount"`
+	}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return 0, err
+	}
+
+	studioByWorkshop := workshopStudioMap(cfg)
+
+	type cohortKey struct{ month, studio string }
+	rows := map[cohortKey]*RevenueReport{}
+	firstKey := map[string]cohortKey{}
+	buyersSeen := map[cohortKey]map[string]bool{}
+
+	for _, order := range orders {
+		month := order.CreatedAt.UTC().Format("2006-01")
+		studio := studioByWorkshop[order.WorkshopUUID]
+		if studio == "" {
+			studio = "unknown"
+		}
+		key := cohortKey{month: month, studio: studio}
+		row := rows[key]
+		if row == nil {
+			row = &RevenueReport{Month: month, StudioID: studio}
+			rows[key] = row
+			buyersSeen[key] = map[string]bool{}
+		}
+
+		row.Orders++
+		row.GMV += order.Amount
+		switch order.Status {
+		case OrderStatusRefunded:
+			row.Refunds += order.Amount
+		case OrderStatusCancelled:
+			row.Refunds += order.RefundAmount
+		}
+
+		// Cohort: a buyer is first-time only in the row holding their
+		// earliest order on the platform; orders are sorted by created_at,
+		// so the first sighting pins that row.
+		if _, ok := firstKey[order.UserID]; !ok {
+			firstKey[order.UserID] = key
+		}
+		if !buyersSeen[key][order.UserID] {
+			buyersSeen[key][order.UserID] = true
+			if firstKey[order.UserID] == key {
+				row.FirstTimeBuyers++
+			} else {
+				row.ReturningBuyers++
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	written := 0
+	for key, row := range rows {
+		row.Take = int64(float64(row.GMV-row.Refunds) * revenueTakeRate)
+		row.NetRevenue = row.GMV - row.Refunds
+		row.ComputedAt = now
+		_, err := database.Collection(cfg, "revenue_reports").UpdateOne(ctx,
+			bson.M{"month": key.month, "studio_id": key.studio},
+			bson.M{"$set": row},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// GetRevenueReports reads the precomputed rows, optionally narrowed to one
+// month (YYYY-MM) or one studio, sorted newest month first.
+func GetRevenueReports(cfg *config.Config, month, studioID string) ([]RevenueReport, error) {
+	filter := bson.M{}
+	if month != "" {
+		filter["month"] = month
+	}
+	if studioID != "" {
+		filter["studio_id"] = studioID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "revenue_reports").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	reports := []RevenueReport{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Month != reports[j].Month {
+			return reports[i].Month > reports[j].Month
+		}
+		return reports[i].StudioID < reports[j].StudioID
+	})
+	return reports, nil
+}
+
+// workshopStudioMap maps workshop uuids to studio ids for order attribution;
+// best-effort, since refreshed studios replace their workshop documents.
+func workshopStudioMap(cfg *config.Config) map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "workshops_v2").Find(ctx, bson.M{},
+		options.Find().SetProjection(bson.M{"uuid": 1, "studio_id": 1}))
+	if err != nil {
+		return map[string]string{}
+	}
+	var docs []struct {
+		UUID     string `bson:"uuid"`
+		StudioID string `bson:"studio_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return map[string]string{}
+	}
+	m := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		m[doc.UUID] = doc.StudioID
+	}
+	return m
+}
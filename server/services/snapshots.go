@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Scrape snapshot archive: every event page the scraper analyzes is saved
+// — raw HTML plus the screenshot the AI actually saw — keyed by workshop
+// UUID and run ID. When a user disputes a listed time or price, admins can
+// pull up the source of truth instead of re-scraping a page that may have
+// changed since. Snapshots live in the page_snapshots collection, the same
+// place this codebase keeps other binary payloads (profile pictures).
+
+// PageSnapshot is one archived scrape of an event page. The blobs are
+// excluded from JSON so the metadata view stays small; they are served raw
+// through the admin snapshot endpoint.
+type PageSnapshot struct {
+	WorkshopUUID   string    `bson:"workshop_uuid" json:"workshop_uuid"`
+	RunID          string    `bson:"run_id" json:"run_id"`
+	StudioID       string    `bson:"studio_id" json:"studio_id"`
+	PageURL        string    `bson:"page_url" json:"page_url"`
+	HTML           []byte    `bson:"html" json:"-"`
+	Screenshot     []byte    `bson:"screenshot" json:"-"`
+	HTMLBytes      int       `bson:"html_bytes" json:"html_bytes"`
+	ScreenshotSize int       `bson:"screenshot_bytes" json:"screenshot_bytes"`
+	CapturedAt     time.Time `bson:"captured_at" json:"captured_at"`
+}
+
+// SaveScrapeSnapshot archives one scraped page. Best-effort: archive
+// failures are logged and never fail the scrape that produced the page.
+func SaveScrapeSnapshot(cfg *config.Config, studioID, workshopUUID, runID, pageURL string, html, screenshot []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "page_snapshots").InsertOne(ctx, PageSnapshot{
+		WorkshopUUID:   workshopUUID,
+		RunID:          runID,
+		StudioID:       studioID,
+		PageURL:        pageURL,
+		HTML:           html,
+		Screenshot:     screenshot,
+		HTMLBytes:      len(html),
+		ScreenshotSize: len(screenshot),
+		CapturedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("page snapshot: archive failed for %s: %v", pageURL, err)
+	}
+}
+
+// GetLatestSnapshot returns the most recent archived scrape of a workshop's
+// event page, or the one from a specific run when runID is non-empty.
+func GetLatestSnapshot(cfg *config.Config, workshopUUID, runID string) (*PageSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	filter := bson.M{"workshop_uuid": workshopUUID}
+	if runID != "" {
+		filter["run_id"] = runID
+	}
+	var snapshot PageSnapshot
+	err := database.Collection(cfg, "page_snapshots").FindOne(ctx, filter,
+		options.FindOne().SetSort(bson.M{"captured_at": -1})).Decode(&snapshot)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
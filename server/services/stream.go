@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Live event stream: /api/stream pushes platform events to connected
+// clients over Server-Sent Events the moment they happen. The emit sites
+// already record everything the stream needs in domain_events — new
+// workshops, paid and cancelled orders — so this subsystem is a reader over
+// that collection: a MongoDB change stream feeds an in-process broker that
+// fans events out to subscribers by topic. Clients subscribe to
+// "studio:{id}" and "artist:{id}" for workshop publications, or "order"
+// for their own order status changes; the order topic is private and only
+// ever delivers the authenticated user's events.
+
+// StreamEvent is one event as delivered on the wire: the stored domain
+// event's ID, type, and payload. The ID doubles as the SSE event ID so
+// reconnecting clients can resume from Last-Event-ID.
+type StreamEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Payload bson.M `json:"payload"`
+
+	topics []string
+}
+
+// subscriberBuffer bounds how far a slow client may fall behind before the
+// broker drops it; replay-on-reconnect makes the drop lossless.
+const subscriberBuffer = 16
+
+// StreamSubscriber is one connected client's subscription; events matching
+// its topics arrive on Events until Close (or the broker dropping a stalled
+// subscriber) closes the channel.
+type StreamSubscriber struct {
+	topics map[string]bool
+	Events chan StreamEvent
+}
+
+// streamBroker holds the connected subscribers. One broker per process is
+// enough — every instance tails domain_events itself, so no cross-instance
+// fan-out is needed.
+var streamBroker = struct {
+	mu   sync.Mutex
+	subs map[*StreamSubscriber]bool
+}{subs: map[*StreamSubscriber]bool{}}
+
+// ParseStreamTopics validates a client's comma-separated topic list. The
+// "order" topic requires an authenticated user and is rewritten to the
+// private per-user form, so order events can never reach another user's
+// subscription.
+func ParseStreamTopics(raw, userID string) (map[string]bool, error) {
+	topics := map[string]bool{}
+	for _, topic := range strings.Split(raw, ",") {
+		topic = strings.TrimSpace(topic)
+		switch {
+		case topic == "order":
+			if userID == "" {
+				return nil, fmt.Errorf("the order topic requires authentication")
+			}
+			topics["order:"+userID] = true
+		case strings.HasPrefix(topic, "studio:") && topic != "studio:":
+			topics[topic] = true
+		case strings.HasPrefix(topic, "artist:") && topic != "artist:":
+			topics[topic] = true
+		case topic == "":
+		default:
+			return nil, fmt.Errorf("unknown topic %q", topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("at least one topic is required (studio:{id}, artist:{id}, order)")
+	}
+	return topics, nil
+}
+
+// SubscribeStream registers a subscriber for the given topics.
+func SubscribeStream(topics map[string]bool) *StreamSubscriber {
+	sub := &StreamSubscriber{
+		topics: topics,
+		Events: make(chan StreamEvent, subscriberBuffer),
+	}
+	streamBroker.mu.Lock()
+	streamBroker.subs[sub] = true
+	streamBroker.mu.Unlock()
+	return sub
+}
+
+// Close unregisters the subscriber. Safe to call after the broker has
+// already dropped it.
+func (s *StreamSubscriber) Close() {
+	streamBroker.mu.Lock()
+	defer streamBroker.mu.Unlock()
+	if streamBroker.subs[s] {
+		delete(streamBroker.subs, s)
+		close(s.Events)
+	}
+}
+
+// publishStreamEvent fans one event out to every subscriber whose topics
+// match. A subscriber whose buffer is full is dropped rather than buffered
+// without bound — its connection closes and the SSE retry reconnects with
+// Last-Event-ID, replaying what it missed.
+func publishStreamEvent(event StreamEvent) {
+	streamBroker.mu.Lock()
+	defer streamBroker.mu.Unlock()
+	for sub := range streamBroker.subs {
+		if !matchesTopics(event.topics, sub.topics) {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			delete(streamBroker.subs, sub)
+			close(sub.Events)
+		}
+	}
+}
+
+// matchesTopics reports whether any of the event's topics is subscribed.
+func matchesTopics(eventTopics []string, subscribed map[string]bool) bool {
+	for _, topic := range eventTopics {
+		if subscribed[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+// streamTopics derives which subscription topics an event reaches. Only
+// events with a client-facing audience map to topics; the rest
+// (registrations, reactions, scraper health) stay analytics-only.
+func streamTopics(eventType string, payload bson.M) []string {
+	switch eventType {
+	case EventWorkshopPublished:
+		var topics []string
+		if studioID, _ := payload["studio_id"].(string); studioID != "" {
+			topics = append(topics, "studio:"+studioID)
+		}
+		for _, artistID := range payloadStringList(payload["artist_id_list"]) {
+			topics = append(topics, "artist:"+artistID)
+		}
+		return topics
+	case EventOrderPaid, EventOrderCancelled:
+		if userID, _ := payload["user_id"].(string); userID != "" {
+			return []string{"order:" + userID}
+		}
+	}
+	return nil
+}
+
+// payloadStringList extracts a string slice from a payload field, which
+// decodes as primitive.A when read back from MongoDB.
+func payloadStringList(raw interface{}) []string {
+	var values []string
+	switch list := raw.(type) {
+	case []string:
+		return list
+	case primitive.A:
+		for _, item := range list {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+	case []interface{}:
+		for _, item := range list {
+			if value, ok := item.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// StartEventStream launches the goroutine that feeds the broker from the
+// domain_events change stream once MongoDB is ready.
+func StartEventStream(cfg *config.Config) {
+	go func() {
+		for !database.IsReady() {
+			time.Sleep(5 * time.Second)
+		}
+		watchDomainEvents(cfg)
+	}()
+}
+
+// watchDomainEvents follows domain_events inserts forever, resuming from
+// the last seen token and reconnecting with backoff — the same shape as the
+// cache invalidation watchers. Change streams require a replica set; on
+// standalone deployments the watch logs and retries, and clients simply see
+// no live events.
+func watchDomainEvents(cfg *config.Config) {
+	var resumeToken bson.Raw
+	backoff := time.Second
+	for {
+		opts := options.ChangeStream().SetMaxAwaitTime(10 * time.Second)
+		if resumeToken != nil {
+			opts.SetResumeAfter(resumeToken)
+		}
+		stream, err := database.Collection(cfg, "domain_events").Watch(context.Background(),
+			mongo.Pipeline{bson.D{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}, opts)
+		if err != nil {
+			log.Printf("event stream: watch failed, retrying in %v: %v", backoff, err)
+			time.Sleep(backoff)
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+			// A stale resume token (e.g. fell off the oplog) makes every
+			// retry fail; drop it and start fresh after the first minute.
+			if backoff >= time.Minute {
+				resumeToken = nil
+			}
+			continue
+		}
+		backoff = time.Second
+		log.Printf("event stream: watching domain_events")
+
+		for stream.Next(context.Background()) {
+			resumeToken = stream.ResumeToken()
+			var change struct {
+				FullDocument bson.M `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err != nil {
+				continue
+			}
+			publishDomainEventDoc(change.FullDocument)
+		}
+		if err := stream.Err(); err != nil {
+			log.Printf("event stream: cursor error: %v", err)
+		}
+		stream.Close(context.Background())
+	}
+}
+
+// publishDomainEventDoc turns one stored domain event into a stream event
+// and fans it out; events without a live-stream audience are skipped.
+func publishDomainEventDoc(doc bson.M) {
+	eventType, _ := doc["type"].(string)
+	payload, _ := doc["payload"].(bson.M)
+	topics := streamTopics(eventType, payload)
+	if len(topics) == 0 {
+		return
+	}
+	eventID, _ := doc["event_id"].(string)
+	publishStreamEvent(StreamEvent{ID: eventID, Type: eventType, Payload: payload, topics: topics})
+}
+
+// ReplayStreamEvents returns the events a reconnecting client missed after
+// its Last-Event-ID, oldest first, filtered to its topics. An unknown ID
+// (the marker event may predate what the collection retains) replays
+// nothing and the client resumes from the live feed.
+func ReplayStreamEvents(cfg *config.Config, lastEventID string, topics map[string]bool) []StreamEvent {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	coll := database.Collection(cfg, "domain_events")
+
+	var marker struct {
+		EmittedAt time.Time `bson:"emitted_at"`
+	}
+	if err := coll.FindOne(ctx, bson.M{"event_id": lastEventID}).Decode(&marker); err != nil {
+		return nil
+	}
+	cursor, err := coll.Find(ctx,
+		bson.M{"emitted_at": bson.M{"$gt": marker.EmittedAt}},
+		options.Find().SetSort(bson.M{"emitted_at": 1}).SetLimit(exportBatchSize))
+	if err != nil {
+		log.Printf("event stream: replay failed: %v", err)
+		return nil
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		log.Printf("event stream: replay failed: %v", err)
+		return nil
+	}
+
+	var events []StreamEvent
+	for _, doc := range docs {
+		eventType, _ := doc["type"].(string)
+		payload, _ := doc["payload"].(bson.M)
+		eventTopics := streamTopics(eventType, payload)
+		if !matchesTopics(eventTopics, topics) {
+			continue
+		}
+		eventID, _ := doc["event_id"].(string)
+		events = append(events, StreamEvent{ID: eventID, Type: eventType, Payload: payload, topics: eventTopics})
+	}
+	return events
+}
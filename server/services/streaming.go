@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Online attendance for workshops that stream their sessions. The link
+// lives in its own streaming_links collection keyed by workshop UUID rather
+// than on the workshop document — the scraper deletes and reinserts
+// workshops on every refresh, and an admin-entered link must survive that.
+// The raw link is only revealed to paid order holders inside the reveal
+// window; reminders mention the stream but never carry the URL.
+
+// StreamRevealMinutes is how long before the first session a paid order
+// can fetch the join link.
+const StreamRevealMinutes = 30
+
+// StreamingLink is one workshop's admin-configured online stream link.
+type StreamingLink struct {
+	WorkshopUUID string    `bson:"workshop_uuid" json:"workshop_uuid"`
+	OnlineLink   string    `bson:"online_link" json:"online_link"`
+	UpdatedAt    time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// StreamAccess is the join-link response for an order. Before the reveal
+// window opens, Available is false and AvailableAt says when to retry; the
+// link itself is only present once available.
+type StreamAccess struct {
+	WorkshopUUID string     `json:"workshop_uuid"`
+	Available    bool       `json:"available"`
+	OnlineLink   string     `json:"online_link,omitempty"`
+	AvailableAt  *time.Time `json:"available_at,omitempty"`
+}
+
+// StreamJoin is one order's join record for the studio's report.
+type StreamJoin struct {
+	WorkshopUUID  string    `bson:"workshop_uuid" json:"workshop_uuid"`
+	OrderID       string    `bson:"order_id" json:"order_id"`
+	UserID        string    `bson:"user_id" json:"user_id"`
+	JoinCount     int64     `bson:"join_count" json:"join_count"`
+	FirstJoinedAt time.Time `bson:"first_joined_at" json:"first_joined_at"`
+	LastJoinedAt  time.Time `bson:"last_joined_at" json:"last_joined_at"`
+}
+
+// SetStreamingLink stores or replaces a workshop's online stream link.
+func SetStreamingLink(cfg *config.Config, workshopUUID, link string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "streaming_links").UpdateOne(ctx,
+		bson.M{"workshop_uuid": workshopUUID},
+		bson.M{"$set": bson.M{
+			"workshop_uuid": workshopUUID,
+			"online_link":   link,
+			"updated_at":    time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// RemoveStreamingLink deletes a workshop's stream link so the workshop is
+// in-person only again.
+func RemoveStreamingLink(cfg *config.Config, workshopUUID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "streaming_links").DeleteOne(ctx,
+		bson.M{"workshop_uuid": workshopUUID})
+	return err
+}
+
+// getStreamingLink fetches a workshop's stream link; (nil, nil) when the
+// workshop has no online stream.
+func getStreamingLink(cfg *config.Config, workshopUUID string) (*StreamingLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var link StreamingLink
+	err := database.Collection(cfg, "streaming_links").FindOne(ctx,
+		bson.M{"workshop_uuid": workshopUUID}).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// HasStreamingLink reports whether a workshop has an online stream
+// configured; lookup errors read as no, so callers stay best-effort.
+func HasStreamingLink(cfg *config.Config, workshopUUID string) bool {
+	link, err := getStreamingLink(cfg, workshopUUID)
+	if err != nil {
+		log.Printf("streaming: link lookup failed for %s: %v", workshopUUID, err)
+		return false
+	}
+	return link != nil
+}
+
+// GetStreamAccess resolves the join link for an order. The order must
+// belong to the caller and be paid, the workshop must have a stream link,
+// and the reveal window must have opened; inside the window the join is
+// recorded for the studio's report.
+func (s *OrderServiceImpl) GetStreamAccess(orderID, userID string) (*StreamAccess, error) {
+	order := s.getOrder(orderID)
+	if order == nil || order.UserID != userID {
+		return nil, fmt.Errorf("order not found: %s", orderID)
+	}
+	if order.Status != OrderStatusPaid {
+		return nil, fmt.Errorf("order is not paid")
+	}
+	link, err := getStreamingLink(s.cfg, order.WorkshopUUID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, fmt.Errorf("this workshop has no online stream")
+	}
+
+	access := &StreamAccess{WorkshopUUID: order.WorkshopUUID}
+	workshop, err := database.GetWorkshopByUUID(s.cfg, order.WorkshopUUID)
+	if err != nil {
+		return nil, fmt.Errorf("workshop not found for order: %s", orderID)
+	}
+	start, err := firstSessionStart(workshop)
+	if err != nil {
+		return nil, err
+	}
+	revealAt := start.Add(-StreamRevealMinutes * time.Minute)
+	if time.Now().Before(revealAt) {
+		access.AvailableAt = &revealAt
+		return access, nil
+	}
+
+	access.Available = true
+	access.OnlineLink = link.OnlineLink
+	recordStreamJoin(s.cfg, order)
+	return access, nil
+}
+
+// recordStreamJoin tracks a join per order: first/last join time plus a
+// count. Best effort — a failed write never blocks the link reveal.
+func recordStreamJoin(cfg *config.Config, order *Order) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	_, err := database.Collection(cfg, "stream_joins").UpdateOne(ctx,
+		bson.M{"order_id": order.OrderID},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"workshop_uuid":   order.WorkshopUUID,
+				"order_id":        order.OrderID,
+				"user_id":         order.UserID,
+				"first_joined_at": now,
+			},
+			"$set": bson.M{"last_joined_at": now},
+			"$inc": bson.M{"join_count": 1},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("streaming: join record failed for order %s: %v", order.OrderID, err)
+	}
+}
+
+// GetStreamJoinReport lists per-order join records for one workshop.
+func GetStreamJoinReport(cfg *config.Config, workshopUUID string) ([]StreamJoin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "stream_joins").Find(ctx,
+		bson.M{"workshop_uuid": workshopUUID})
+	if err != nil {
+		return nil, err
+	}
+	joins := []StreamJoin{}
+	if err := cursor.All(ctx, &joins); err != nil {
+		return nil, err
+	}
+	return joins, nil
+}
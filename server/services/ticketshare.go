@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+	"dance_api_go/utils"
+)
+
+// Ticket sharing: a paid order gets a short share code that resolves to a
+// public page showing the workshop (never the entry QR) with a deep link
+// to book the same workshop. Every click on a share page is recorded with
+// its share code, so bookings can later be attributed to the sharer — the
+// raw attribution feed a referral program needs.
+
+// TicketShareView is the public ticket page's data: workshop identity
+// only, nothing from the order beyond the workshop it bought.
+type TicketShareView struct {
+	ShareCode    string  `json:"share_code"`
+	WorkshopUUID string  `json:"workshop_uuid"`
+	StudioID     string  `json:"studio_id"`
+	StudioName   string  `json:"studio_name"`
+	Song         *string `json:"song"`
+	Artist       *string `json:"artist"`
+	Date         string  `json:"date"`
+	Time         string  `json:"time"`
+}
+
+// EnsureShareCode returns the order's share code, generating and storing
+// one on first request. The order must belong to the caller and be paid.
+func EnsureShareCode(cfg *config.Config, orderID, userID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order Order
+	if err := database.Collection(cfg, "orders").FindOne(ctx,
+		bson.M{"order_id": orderID, "user_id": userID}).Decode(&order); err != nil {
+		return "", fmt.Errorf("order not found: %s", orderID)
+	}
+	if order.Status != OrderStatusPaid {
+		return "", fmt.Errorf("only paid orders can be shared")
+	}
+	if order.ShareCode != "" {
+		return order.ShareCode, nil
+	}
+
+	code, err := newShareCode()
+	if err != nil {
+		return "", err
+	}
+	if _, err := database.Collection(cfg, "orders").UpdateOne(ctx,
+		bson.M{"order_id": orderID},
+		bson.M{"$set": bson.M{"share_code": code}}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// newShareCode generates a short random code; long enough that codes are
+// not guessable, short enough to read off a screen.
+func newShareCode() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ResolveShareCode maps a share code to its public ticket view; (nil, nil)
+// when the code is unknown.
+func ResolveShareCode(cfg *config.Config, shareCode string) (*TicketShareView, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var order Order
+	err := database.Collection(cfg, "orders").FindOne(ctx,
+		bson.M{"share_code": shareCode}).Decode(&order)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var workshop response.Workshop
+	if err := database.Collection(cfg, "workshops_v2").FindOne(ctx,
+		bson.M{"uuid": order.WorkshopUUID}).Decode(&workshop); err != nil {
+		return nil, fmt.Errorf("workshop not found for share code")
+	}
+
+	view := &TicketShareView{
+		ShareCode:    shareCode,
+		WorkshopUUID: workshop.UUID,
+		StudioID:     workshop.StudioID,
+		Song:         workshop.Song,
+		Artist:       workshop.By,
+	}
+	if studios, err := database.GetStudioMap(cfg); err == nil {
+		if studio, ok := studios[workshop.StudioID]; ok {
+			view.StudioName = studio.Name
+		}
+	}
+	for _, td := range workshop.TimeDetails {
+		if td.Day == nil || td.Month == nil || td.Year == nil {
+			continue
+		}
+		view.Date = utils.GetFormattedDate(td)
+		view.Time = utils.GetFormattedTime(td)
+		break
+	}
+	return view, nil
+}
+
+// RecordShareClick logs one visit to a ticket share page for booking
+// attribution. Best effort — a failed write never blocks the page.
+func RecordShareClick(cfg *config.Config, view *TicketShareView, referrer, userAgent string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "ticket_share_clicks").InsertOne(ctx, bson.M{
+		"share_code":    view.ShareCode,
+		"workshop_uuid": view.WorkshopUUID,
+		"studio_id":     view.StudioID,
+		"referrer":      referrer,
+		"user_agent":    userAgent,
+		"clicked_at":    time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("ticket share: click record failed for %s: %v", view.ShareCode, err)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"dance_api_go/config"
+)
+
+// Dev-only gateway webhook simulator: builds realistic Razorpay webhook
+// payloads (paid, failed, refunded) for an order, signs them with the
+// configured webhook secret, and delivers them to the local webhook
+// endpoint over HTTP. The delivery goes through the real handler — body
+// limit, signature verification, idempotency, audit trail, order state
+// machine, QR pipeline — so the whole path can be exercised without a
+// Razorpay account.
+
+// simulatedWebhookEvents maps the simulator's short names onto the gateway
+// event names the webhook handler acts on.
+var simulatedWebhookEvents = map[string]string{
+	"paid":     "payment.captured",
+	"failed":   "payment.failed",
+	"refunded": "refund.processed",
+}
+
+// WebhookSimulationResult reports what the simulator sent and how the local
+// webhook handler answered.
+type WebhookSimulationResult struct {
+	EventID    string `json:"event_id"`
+	Event      string `json:"event"`
+	OrderID    string `json:"order_id"`
+	Payload    string `json:"payload"`
+	StatusCode int    `json:"status_code"`
+	Response   string `json:"response"`
+}
+
+// BuildSimulatedWebhook renders a Razorpay-shaped payload for the given
+// short event name ("paid", "failed", "refunded") and order, mirroring
+// where the real gateway puts the order ID per event family.
+func BuildSimulatedWebhook(shortName, orderID string) ([]byte, string, error) {
+	event, ok := simulatedWebhookEvents[shortName]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown event %q (want paid, failed, or refunded)", shortName)
+	}
+	payload := map[string]interface{}{
+		"event":      event,
+		"created_at": time.Now().Unix(),
+		"payload":    map[string]interface{}{},
+	}
+	entities := payload["payload"].(map[string]interface{})
+	switch event {
+	case "refund.processed":
+		entities["refund"] = map[string]interface{}{
+			"entity": map[string]interface{}{
+				"id":       "rfnd_" + uuid.NewString()[:14],
+				"order_id": orderID,
+				"status":   "processed",
+			},
+		}
+	default:
+		entities["payment"] = map[string]interface{}{
+			"entity": map[string]interface{}{
+				"id":       "pay_" + uuid.NewString()[:14],
+				"order_id": orderID,
+				"status":   map[string]string{"payment.captured": "captured", "payment.failed": "failed"}[event],
+				"method":   "upi",
+			},
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, event, nil
+}
+
+// SignWebhookBody computes the hex HMAC-SHA256 signature Razorpay would put
+// in X-Razorpay-Signature.
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SimulateWebhook builds, signs, and delivers one webhook to the local
+// server's webhook endpoint, returning the handler's verdict.
+func SimulateWebhook(cfg *config.Config, shortName, orderID string) (*WebhookSimulationResult, error) {
+	if cfg.RazorpayWebhookSecret == "" {
+		return nil, fmt.Errorf("RAZORPAY_WEBHOOK_SECRET is not configured")
+	}
+	body, event, err := BuildSimulatedWebhook(shortName, orderID)
+	if err != nil {
+		return nil, err
+	}
+	eventID := "evt_sim_" + uuid.NewString()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/payments/webhook", cfg.MuxPort)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Razorpay-Event-Id", eventID)
+	req.Header.Set("X-Razorpay-Signature", SignWebhookBody(cfg.RazorpayWebhookSecret, body))
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("delivering simulated webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	answer, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+
+	return &WebhookSimulationResult{
+		EventID:    eventID,
+		Event:      event,
+		OrderID:    orderID,
+		Payload:    string(body),
+		StatusCode: resp.StatusCode,
+		Response:   string(answer),
+	}, nil
+}
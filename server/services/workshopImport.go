@@ -0,0 +1,386 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/models/response"
+)
+
+// Bulk workshop import/export for admins managing data by hand. Both
+// directions speak the same two formats: a JSON array of workshop documents,
+// or a CSV with one row per session (rows sharing a uuid merge into one
+// workshop, which is exactly what the exporter emits). Imports validate
+// every row, upsert by uuid, and report per-row outcomes; dry-run mode runs
+// the full validation and reports what would happen without writing.
+
+// csvImportHeader is the column order the CSV import expects and the export
+// produces.
+var csvImportHeader = []string{
+	"uuid", "studio_id", "payment_link", "payment_link_type", "by", "song",
+	"pricing_info", "min_price", "max_price", "artist_id_list", "event_type",
+	"day", "month", "year", "start_time", "end_time", "choreo_insta_link",
+}
+
+// ImportRowResult is the outcome for one imported workshop.
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	UUID   string `json:"uuid,omitempty"`
+	Action string `json:"action"` // created | updated | error
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportSummary is the import endpoint's response.
+type ImportSummary struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Errors  int               `json:"errors"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// importedWorkshop pairs a parsed workshop with its source row number for
+// error reporting.
+type importedWorkshop struct {
+	row      int
+	workshop response.Workshop
+}
+
+// ImportWorkshops parses, validates, and upserts workshops from a CSV or
+// JSON payload. With dryRun set, validation and existence checks run in
+// full but nothing is written.
+func ImportWorkshops(cfg *config.Config, data []byte, format string, dryRun bool) (*ImportSummary, error) {
+	var parsed []importedWorkshop
+	var err error
+	switch format {
+	case "csv":
+		parsed, err = parseWorkshopCSV(data)
+	case "json":
+		parsed, err = parseWorkshopJSON(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q (want csv or json)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	studios, err := database.GetStudioMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ImportSummary{DryRun: dryRun, Total: len(parsed)}
+	changed := false
+	for _, item := range parsed {
+		result := ImportRowResult{Row: item.row, UUID: item.workshop.UUID}
+		if problem := validateImportedWorkshop(item.workshop, studios); problem != "" {
+			result.Action = "error"
+			result.Error = problem
+			summary.Errors++
+			summary.Rows = append(summary.Rows, result)
+			continue
+		}
+		action, err := upsertImportedWorkshop(cfg, &item.workshop, dryRun)
+		if err != nil {
+			result.Action = "error"
+			result.Error = err.Error()
+			summary.Errors++
+		} else {
+			result.Action = action
+			result.UUID = item.workshop.UUID
+			if action == "created" {
+				summary.Created++
+			} else {
+				summary.Updated++
+			}
+			changed = true
+		}
+		summary.Rows = append(summary.Rows, result)
+	}
+	if changed && !dryRun {
+		database.InvalidateForWrite(cfg, database.EntityWrite{Entity: "workshop"})
+	}
+	return summary, nil
+}
+
+// validateImportedWorkshop returns a human-readable problem, or "" when the
+// row is importable.
+func validateImportedWorkshop(w response.Workshop, studios map[string]response.Studio) string {
+	if w.StudioID == "" {
+		return "studio_id is required"
+	}
+	if _, ok := studios[w.StudioID]; !ok {
+		return fmt.Sprintf("unknown studio_id %q", w.StudioID)
+	}
+	if w.PaymentLink == "" {
+		return "payment_link is required"
+	}
+	if len(w.TimeDetails) == 0 {
+		return "at least one session (day/month/year) is required"
+	}
+	for i, td := range w.TimeDetails {
+		if td.Day == nil || td.Month == nil || td.Year == nil {
+			return fmt.Sprintf("session %d is missing day/month/year", i+1)
+		}
+	}
+	switch w.EventType {
+	case "", "workshop", "intensive", "regulars":
+	default:
+		return fmt.Sprintf("unknown event_type %q", w.EventType)
+	}
+	return ""
+}
+
+// upsertImportedWorkshop writes one workshop keyed by uuid and reports
+// whether it was created or updated. Rows without a uuid always create. In
+// dry-run mode only the existence check runs.
+func upsertImportedWorkshop(cfg *config.Config, w *response.Workshop, dryRun bool) (string, error) {
+	if w.EventType == "" {
+		w.EventType = "workshop"
+	}
+	if w.PaymentLinkType == "" {
+		w.PaymentLinkType = "url"
+	}
+	if w.UUID == "" {
+		w.UUID = fmt.Sprintf("%s_%s", w.StudioID, uuid.NewString())
+		if dryRun {
+			return "created", nil
+		}
+	} else if dryRun {
+		if _, err := database.GetWorkshopByUUID(cfg, w.UUID); err != nil {
+			return "created", nil
+		}
+		return "updated", nil
+	}
+
+	doc := bson.M{
+		"uuid":              w.UUID,
+		"payment_link":      w.PaymentLink,
+		"payment_link_type": w.PaymentLinkType,
+		"studio_id":         w.StudioID,
+		"by":                w.By,
+		"song":              w.Song,
+		"pricing_info":      w.PricingInfo,
+		"min_price":         w.MinPrice,
+		"max_price":         w.MaxPrice,
+		"artist_id_list":    normalizedArtistIDs(w.ArtistIDList),
+		"event_type":        w.EventType,
+		"time_details":      timeDetailDocs(w.TimeDetails),
+		"updated_at":        float64(time.Now().Unix()),
+	}
+	if w.ChoreoInstaLink != nil {
+		doc["choreo_insta_link"] = w.ChoreoInstaLink
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := database.Collection(cfg, "workshops_v2").UpdateOne(ctx,
+		bson.M{"uuid": w.UUID}, bson.M{"$set": doc}, options.Update().SetUpsert(true))
+	if err != nil {
+		return "", err
+	}
+	if res.MatchedCount == 0 {
+		return "created", nil
+	}
+	return "updated", nil
+}
+
+// normalizedArtistIDs drops empty entries so CSV rows with blank columns
+// don't store empty-string artist IDs.
+func normalizedArtistIDs(ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if trimmed := strings.TrimSpace(id); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+// timeDetailDocs converts sessions to their stored shape.
+func timeDetailDocs(details []response.TimeDetail) []bson.M {
+	docs := make([]bson.M, 0, len(details))
+	for _, td := range details {
+		docs = append(docs, bson.M{
+			"day":        td.Day,
+			"month":      td.Month,
+			"year":       td.Year,
+			"start_time": td.StartTime,
+			"end_time":   td.EndTime,
+		})
+	}
+	return docs
+}
+
+// parseWorkshopJSON decodes a JSON array of workshop documents.
+func parseWorkshopJSON(data []byte) ([]importedWorkshop, error) {
+	var workshops []response.Workshop
+	if err := json.Unmarshal(data, &workshops); err != nil {
+		return nil, fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	parsed := make([]importedWorkshop, 0, len(workshops))
+	for i, w := range workshops {
+		parsed = append(parsed, importedWorkshop{row: i + 1, workshop: w})
+	}
+	return parsed, nil
+}
+
+// parseWorkshopCSV decodes session rows and merges rows sharing a uuid into
+// one workshop, preserving first-seen order.
+func parseWorkshopCSV(data []byte) ([]importedWorkshop, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV payload: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV payload")
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"studio_id", "payment_link"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %q column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var parsed []importedWorkshop
+	byUUID := map[string]int{}
+	for rowNum, record := range records[1:] {
+		w := response.Workshop{
+			UUID:            field(record, "uuid"),
+			StudioID:        field(record, "studio_id"),
+			PaymentLink:     field(record, "payment_link"),
+			PaymentLinkType: field(record, "payment_link_type"),
+			By:              optionalField(field(record, "by")),
+			Song:            optionalField(field(record, "song")),
+			PricingInfo:     optionalField(field(record, "pricing_info")),
+			EventType:       field(record, "event_type"),
+			ChoreoInstaLink: optionalField(field(record, "choreo_insta_link")),
+		}
+		w.MinPrice, _ = strconv.ParseInt(field(record, "min_price"), 10, 64)
+		w.MaxPrice, _ = strconv.ParseInt(field(record, "max_price"), 10, 64)
+		if list := field(record, "artist_id_list"); list != "" {
+			w.ArtistIDList = strings.Split(list, "|")
+		}
+		td := response.TimeDetail{
+			Day:       optionalInt(field(record, "day")),
+			Month:     optionalInt(field(record, "month")),
+			Year:      optionalInt(field(record, "year")),
+			StartTime: optionalField(field(record, "start_time")),
+			EndTime:   optionalField(field(record, "end_time")),
+		}
+		w.TimeDetails = []response.TimeDetail{td}
+
+		if w.UUID != "" {
+			if i, seen := byUUID[w.UUID]; seen {
+				parsed[i].workshop.TimeDetails = append(parsed[i].workshop.TimeDetails, td)
+				continue
+			}
+			byUUID[w.UUID] = len(parsed)
+		}
+		parsed = append(parsed, importedWorkshop{row: rowNum + 2, workshop: w})
+	}
+	return parsed, nil
+}
+
+func optionalField(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalInt(s string) *int {
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// ExportWorkshops renders every non-archived workshop in the import format:
+// a JSON array, or a CSV with one row per session.
+func ExportWorkshops(cfg *config.Config, format string) ([]byte, string, error) {
+	workshops, err := database.GetUpcomingWorkshopsRaw(cfg, "", "")
+	if err != nil {
+		return nil, "", err
+	}
+	switch format {
+	case "json":
+		body, err := json.Marshal(workshops)
+		if err != nil {
+			return nil, "", err
+		}
+		return body, "application/json", nil
+	case "csv":
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		if err := writer.Write(csvImportHeader); err != nil {
+			return nil, "", err
+		}
+		for _, w := range workshops {
+			for _, td := range w.TimeDetails {
+				record := []string{
+					w.UUID, w.StudioID, w.PaymentLink, w.PaymentLinkType,
+					strOrEmpty(w.By), strOrEmpty(w.Song),
+					strOrEmpty(w.PricingInfo),
+					strconv.FormatInt(w.MinPrice, 10), strconv.FormatInt(w.MaxPrice, 10),
+					strings.Join(w.ArtistIDList, "|"), w.EventType,
+					intOrEmpty(td.Day), intOrEmpty(td.Month), intOrEmpty(td.Year),
+					strOrEmpty(td.StartTime), strOrEmpty(td.EndTime),
+					strOrEmpty(w.ChoreoInstaLink),
+				}
+				if err := writer.Write(record); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "text/csv; charset=utf-8", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported format %q (want csv or json)", format)
+	}
+}
+
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intOrEmpty(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/database"
+)
+
+// Follower notification pipeline: when a scraper refresh publishes a
+// workshop we have not listed before, every user with a notify follow on
+// one of its artists gets the new-workshop push (or a digest entry,
+// depending on their mode). Scrapers re-run constantly and payment links
+// are the only stable workshop identity, so delivery is deduped per
+// user-and-workshop in the notified_workshops collection — a user is never
+// pushed twice for the same workshop no matter how often the studio is
+// refreshed.
+
+// NotifyFollowersOfNewWorkshop fans a newly published workshop out to the
+// notify-followers of its artists, returning how many users were reached.
+// Best-effort throughout: a failed push for one follower never blocks the
+// rest, and errors surface in logs only.
+func (s *NotificationServiceImpl) NotifyFollowersOfNewWorkshop(workshopUUID string, artistIDs []string, artistName string) int {
+	if artistName == "" {
+		artistName = "An artist you follow"
+	}
+	reactions := NewReactionService(s.cfg)
+	notified := 0
+	seen := map[string]bool{}
+	for _, artistID := range artistIDs {
+		followers, err := reactions.GetFollowersOfArtist(artistID)
+		if err != nil {
+			log.Printf("new workshop %s: follower lookup failed for artist %s: %v", workshopUUID, artistID, err)
+			continue
+		}
+		for _, userID := range followers {
+			if seen[userID] {
+				continue
+			}
+			seen[userID] = true
+			if !s.markNotified(userID, workshopUUID) {
+				continue
+			}
+			if err := s.NotifyOrQueue(userID, workshopUUID, artistName); err != nil {
+				log.Printf("new workshop %s: notify failed for user %s: %v", workshopUUID, userID, err)
+				continue
+			}
+			notified++
+		}
+	}
+	return notified
+}
+
+// markNotified records that a user has been notified for a workshop and
+// reports whether this call was the first. A Mongo error counts as first —
+// a rare duplicate push beats silently dropping the notification.
+func (s *NotificationServiceImpl) markNotified(userID, workshopUUID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(s.cfg, "notified_workshops").UpdateOne(ctx,
+		bson.M{"user_id": userID, "workshop_uuid": workshopUUID},
+		bson.M{"$setOnInsert": bson.M{
+			"user_id":       userID,
+			"workshop_uuid": workshopUUID,
+			"notified_at":   time.Now().UTC(),
+		}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("notified_workshops: record failed for user %s: %v", userID, err)
+		return true
+	}
+	return result.MatchedCount == 0
+}
@@ -0,0 +1,113 @@
+package studios
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/ai"
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Analysis cache: most refresh cycles see the same event pages with the
+// same content as the last cycle, and re-sending an unchanged screenshot
+// to OpenAI is pure waste. Results are cached in the analysis_cache
+// collection keyed by the screenshot's content hash, so only new or
+// changed pages cost an API call. Entries are keyed to the prompt revision
+// that produced them — bumping the prompt naturally invalidates the whole
+// cache — and a TTL index reaps old entries so a stale extraction can
+// never outlive analysisCacheTTL.
+
+// analysisCacheTTL is how long a cached extraction stays reusable; the
+// analysis_cache TTL index enforces it.
+const analysisCacheTTL = 7 * 24 * time.Hour
+
+// analysisCacheEntry is one cached extraction. The summary is stored as
+// the marshaled JSON alongside its provenance, mirroring what the decision
+// log keeps.
+type analysisCacheEntry struct {
+	ContentHash   string    `bson:"content_hash"`
+	StudioID      string    `bson:"studio_id"`
+	PaymentLink   string    `bson:"payment_link"`
+	PromptVersion int       `bson:"prompt_version"`
+	Model         string    `bson:"model"`
+	RawOutput     string    `bson:"raw_output"`
+	SummaryJSON   string    `bson:"summary_json"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// ScreenshotHash fingerprints a page screenshot for cache lookups.
+func ScreenshotHash(screenshot []byte) string {
+	sum := sha256.Sum256(screenshot)
+	return hex.EncodeToString(sum[:])
+}
+
+// LookupCachedAnalysis returns the cached summary for a content hash, or
+// nil when there is no entry from the current prompt revision. Errors also
+// read as a miss: a Mongo hiccup should cost an OpenAI call, not fail the
+// scrape.
+func LookupCachedAnalysis(cfg *config.Config, contentHash string) *ai.EventSummary {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var entry analysisCacheEntry
+	err := database.Collection(cfg, "analysis_cache").FindOne(ctx,
+		bson.M{"content_hash": contentHash, "prompt_version": ai.PromptVersion()}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		log.Printf("analysis cache: lookup failed for %s: %v", contentHash, err)
+		return nil
+	}
+	var summary ai.EventSummary
+	if err := json.Unmarshal([]byte(entry.SummaryJSON), &summary); err != nil {
+		log.Printf("analysis cache: unparseable entry for %s: %v", contentHash, err)
+		return nil
+	}
+	summary.Decision = &ai.AnalysisDecision{
+		RawOutput:     entry.RawOutput,
+		Model:         entry.Model,
+		PromptVersion: entry.PromptVersion,
+	}
+	return &summary
+}
+
+// CacheAnalysis stores a fresh extraction under its content hash. Best
+// effort: a failure just means the next run pays for the call again.
+func CacheAnalysis(cfg *config.Config, studioID, paymentLink, contentHash string, summary *ai.EventSummary) {
+	if summary == nil || summary.Decision == nil {
+		return
+	}
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		log.Printf("analysis cache: marshal failed for %s: %v", contentHash, err)
+		return
+	}
+	entry := analysisCacheEntry{
+		ContentHash:   contentHash,
+		StudioID:      studioID,
+		PaymentLink:   paymentLink,
+		PromptVersion: summary.Decision.PromptVersion,
+		Model:         summary.Decision.Model,
+		RawOutput:     summary.Decision.RawOutput,
+		SummaryJSON:   string(summaryJSON),
+		CreatedAt:     time.Now().UTC(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = database.Collection(cfg, "analysis_cache").UpdateOne(ctx,
+		bson.M{"content_hash": contentHash},
+		bson.M{"$set": entry},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("analysis cache: store failed for %s: %v", contentHash, err)
+	}
+}
@@ -0,0 +1,82 @@
+package studios
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+// httpClient is shared by all scrapers with a conservative timeout.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// discoverEventLinks fetches the listing page and extracts event links with
+// the studio's link pattern, de-duplicating while preserving order.
+func discoverEventLinks(ctx context.Context, listingURL string, pattern *regexp.Regexp) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listingURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; NachnaBot/1.0)")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing page returned %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var links []string
+	for _, match := range pattern.FindAllString(string(body), -1) {
+		if !seen[match] {
+			seen[match] = true
+			links = append(links, match)
+		}
+	}
+	return links, nil
+}
+
+// fetchPageHTML downloads the raw HTML of an event page for the snapshot
+// archive. Unlike the screenshot this is the un-rendered source, which is
+// what matters when auditing what a page claimed at scrape time.
+func fetchPageHTML(ctx context.Context, pageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; NachnaBot/1.0)")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("event page returned %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// capturePageScreenshot renders the page in headless Chromium and returns a
+// PNG screenshot for the AI analyzer.
+func capturePageScreenshot(ctx context.Context, pageURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "chromium-browser",
+		"--headless", "--disable-gpu", "--no-sandbox",
+		"--window-size=1280,2000", "--screenshot=/dev/stdout",
+		"--virtual-time-budget=10000", pageURL)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("headless screenshot of %s: %w", pageURL, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,280 @@
+package studios
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Scraper dry runs: a refresh deletes and reinserts a studio's whole
+// workshop set, so admins want to see what it would change before it
+// lands. A dry run executes the full scrape and analysis but, instead of
+// persisting, stores a pending diff — new workshops, removed ones, and
+// per-field changes — alongside the scraped documents themselves. The
+// approve endpoint then applies a stored diff without re-scraping,
+// refusing if the studio's listings moved underneath it in the meantime.
+
+// Scrape diff lifecycle states.
+const (
+	ScrapeDiffPending = "pending"
+	ScrapeDiffApplied = "applied"
+)
+
+// scrapeDiffFields are the workshop fields compared when the same payment
+// link appears in both the scraped and the listed set.
+var scrapeDiffFields = []string{"by", "song", "pricing_info", "event_type", "min_price", "max_price", "time_details"}
+
+// ScrapeDiffEntry names one workshop a refresh would add or drop.
+type ScrapeDiffEntry struct {
+	PaymentLink string `bson:"payment_link" json:"payment_link"`
+	By          string `bson:"by,omitempty" json:"by,omitempty"`
+	Song        string `bson:"song,omitempty" json:"song,omitempty"`
+}
+
+// ScrapeFieldChange is one field whose scraped value differs from the
+// currently listed one, both sides rendered for display.
+type ScrapeFieldChange struct {
+	PaymentLink string `bson:"payment_link" json:"payment_link"`
+	Field       string `bson:"field" json:"field"`
+	Before      string `bson:"before" json:"before"`
+	After       string `bson:"after" json:"after"`
+}
+
+// ScrapeDiff is one dry run's pending (or applied) changeset. The scraped
+// documents and the payment-link set they were diffed against ride along
+// in MongoDB so approval needs no re-scrape, but neither is served to the
+// admin panel.
+type ScrapeDiff struct {
+	DiffID    string              `bson:"diff_id" json:"diff_id"`
+	StudioID  string              `bson:"studio_id" json:"studio_id"`
+	Status    string              `bson:"status" json:"status"`
+	New       []ScrapeDiffEntry   `bson:"new" json:"new"`
+	Removed   []ScrapeDiffEntry   `bson:"removed" json:"removed"`
+	Changed   []ScrapeFieldChange `bson:"changed" json:"changed"`
+	Workshops []bson.M            `bson:"workshops" json:"-"`
+	// PreviousLinks is the listed payment-link set at dry-run time; approval
+	// checks it against the live set to catch diffs computed against a state
+	// that no longer exists.
+	PreviousLinks []string   `bson:"previous_links" json:"-"`
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	AppliedAt     *time.Time `bson:"applied_at,omitempty" json:"applied_at,omitempty"`
+}
+
+// DryRunWorkshops scrapes and analyzes a studio without persisting,
+// storing and returning the pending diff against the currently listed set.
+// Locked fields are applied before diffing so the preview matches what an
+// approval would insert. Dry runs skip the disabled-scraper guard and
+// source health recording: a preview is how an admin checks a degraded
+// source before re-enabling it, and it should not count as a run.
+func DryRunWorkshops(ctx context.Context, cfg *config.Config, studio Studio) (*ScrapeDiff, error) {
+	workshops, err := studio.FetchExistingWorkshops(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locks, err := database.GetLockedWorkshopFields(cfg, studio.StudioID())
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range workshops {
+		link, _ := w["payment_link"].(string)
+		if override, ok := locks[link]; ok {
+			applyLockedFields(w, override)
+		}
+	}
+	current, err := currentWorkshopDocs(cfg, studio.StudioID())
+	if err != nil {
+		return nil, err
+	}
+
+	diff := ScrapeDiff{
+		DiffID:    uuid.NewString(),
+		StudioID:  studio.StudioID(),
+		Status:    ScrapeDiffPending,
+		New:       []ScrapeDiffEntry{},
+		Removed:   []ScrapeDiffEntry{},
+		Changed:   []ScrapeFieldChange{},
+		Workshops: workshops,
+		CreatedAt: time.Now().UTC(),
+	}
+	for link := range current {
+		diff.PreviousLinks = append(diff.PreviousLinks, link)
+	}
+	scraped := map[string]bool{}
+	for _, w := range workshops {
+		link, _ := w["payment_link"].(string)
+		if link == "" {
+			continue
+		}
+		scraped[link] = true
+		existing, listed := current[link]
+		if !listed {
+			diff.New = append(diff.New, diffEntry(link, w))
+			continue
+		}
+		for _, field := range scrapeDiffFields {
+			before := renderDiffValue(existing[field])
+			after := renderDiffValue(w[field])
+			if before != after {
+				diff.Changed = append(diff.Changed, ScrapeFieldChange{
+					PaymentLink: link,
+					Field:       field,
+					Before:      before,
+					After:       after,
+				})
+			}
+		}
+	}
+	for link, existing := range current {
+		if !scraped[link] {
+			diff.Removed = append(diff.Removed, diffEntry(link, existing))
+		}
+	}
+
+	insertCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if _, err := database.Collection(cfg, "scrape_diffs").InsertOne(insertCtx, diff); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// ApplyScrapeDiff persists a pending diff's scraped workshop set, running
+// the same replacement path as a live refresh. Locked fields are re-read
+// so admin corrections made after the dry run still win, and the apply is
+// refused when the studio's listings changed since the diff was computed
+// — stale previews need a fresh dry run, not a blind write.
+func ApplyScrapeDiff(cfg *config.Config, diffID string) (*ScrapeDiff, error) {
+	diff, err := GetScrapeDiff(cfg, diffID)
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil {
+		return nil, fmt.Errorf("no such scrape diff: %s", diffID)
+	}
+	if diff.Status != ScrapeDiffPending {
+		return nil, fmt.Errorf("scrape diff %s is already %s", diffID, diff.Status)
+	}
+	previous, err := database.GetWorkshopPaymentLinks(cfg, diff.StudioID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameLinkSet(previous, diff.PreviousLinks) {
+		return nil, fmt.Errorf("listings for %s changed since this diff was computed; run a new dry run", diff.StudioID)
+	}
+	locks, err := database.GetLockedWorkshopFields(cfg, diff.StudioID)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range diff.Workshops {
+		link, _ := w["payment_link"].(string)
+		if override, ok := locks[link]; ok {
+			applyLockedFields(w, override)
+		}
+	}
+	if _, err := persistWorkshops(cfg, diff.StudioID, diff.Workshops, previous); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	diff.Status = ScrapeDiffApplied
+	diff.AppliedAt = &now
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = database.Collection(cfg, "scrape_diffs").UpdateOne(ctx,
+		bson.M{"diff_id": diffID},
+		bson.M{"$set": bson.M{"status": ScrapeDiffApplied, "applied_at": now}})
+	if err != nil {
+		// The workshops are already live; a stuck "pending" diff just
+		// refuses a double apply next time around.
+		return diff, nil
+	}
+	return diff, nil
+}
+
+// GetScrapeDiff returns one diff by ID, or (nil, nil) when unknown.
+func GetScrapeDiff(cfg *config.Config, diffID string) (*ScrapeDiff, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var diff ScrapeDiff
+	err := database.Collection(cfg, "scrape_diffs").FindOne(ctx, bson.M{"diff_id": diffID}).Decode(&diff)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// currentWorkshopDocs returns the studio's listed workshops keyed by
+// payment link.
+func currentWorkshopDocs(cfg *config.Config, studioID string) (map[string]bson.M, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "workshops_v2").Find(ctx, bson.M{"studio_id": studioID})
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	current := map[string]bson.M{}
+	for _, doc := range docs {
+		if link, _ := doc["payment_link"].(string); link != "" {
+			current[link] = doc
+		}
+	}
+	return current, nil
+}
+
+// diffEntry summarizes one workshop document for the diff listing.
+func diffEntry(link string, doc bson.M) ScrapeDiffEntry {
+	return ScrapeDiffEntry{PaymentLink: link, By: renderDiffValue(doc["by"]), Song: renderDiffValue(doc["song"])}
+}
+
+// renderDiffValue renders a workshop field for comparison and display.
+// Rendering both sides through JSON flattens the type differences between
+// freshly built documents and ones decoded from MongoDB — pointer versus
+// plain values, int64 versus int32, []bson.M versus primitive.A — so only
+// real content changes show up as changed.
+func renderDiffValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+	rendered, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(rendered)
+}
+
+// sameLinkSet reports whether the live payment-link set matches the one a
+// diff was computed against.
+func sameLinkSet(live map[string]bool, recorded []string) bool {
+	if len(live) != len(recorded) {
+		return false
+	}
+	for _, link := range recorded {
+		if !live[link] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,199 @@
+package studios
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/services"
+)
+
+// Scraper health scoring: every refresh records its outcome, and a source
+// whose runs keep degrading — hard failures, zero discovered links, or AI
+// analysis failing on most pages (the usual signature of a site redesign
+// breaking the link regex or page layout) — is automatically disabled so it
+// stops wiping that studio's workshop set on every cycle. Disabled sources
+// stay down until an admin re-enables them via the admin API.
+
+const (
+	// scrapeDisableStreak is how many consecutive degraded runs disable a
+	// source.
+	scrapeDisableStreak = 3
+	// scrapeDegradedFailureRate marks a run degraded when this fraction of
+	// discovered links failed screenshotting, analysis, or validation.
+	scrapeDegradedFailureRate = 0.5
+)
+
+// ScrapeStats summarizes one scrape run's outcome.
+type ScrapeStats struct {
+	LinksFound         int `bson:"links_found" json:"links_found"`
+	ScreenshotFailures int `bson:"screenshot_failures" json:"screenshot_failures"`
+	AnalysisFailures   int `bson:"analysis_failures" json:"analysis_failures"`
+	InvalidSummaries   int `bson:"invalid_summaries" json:"invalid_summaries"`
+	Workshops          int `bson:"workshops" json:"workshops"`
+	// CacheHits counts links whose analysis was served from the analysis
+	// cache instead of a fresh OpenAI call.
+	CacheHits int       `bson:"cache_hits" json:"cache_hits"`
+	StartedAt time.Time `bson:"started_at" json:"started_at"`
+}
+
+// SourceHealth is one studio's rolling scrape health, as served to admins.
+type SourceHealth struct {
+	StudioID         string     `bson:"studio_id" json:"studio_id"`
+	Disabled         bool       `bson:"disabled" json:"disabled"`
+	DisabledReason   string     `bson:"disabled_reason,omitempty" json:"disabled_reason,omitempty"`
+	DisabledAt       *time.Time `bson:"disabled_at,omitempty" json:"disabled_at,omitempty"`
+	DegradedStreak   int        `bson:"degraded_streak" json:"degraded_streak"`
+	ZeroResultStreak int        `bson:"zero_result_streak" json:"zero_result_streak"`
+	TotalRuns        int64      `bson:"total_runs" json:"total_runs"`
+	TotalDegraded    int64      `bson:"total_degraded" json:"total_degraded"`
+	LastRunAt        time.Time  `bson:"last_run_at" json:"last_run_at"`
+	LastError        string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+}
+
+// IsScraperDisabled reports whether a source has been auto-disabled. Errors
+// read as enabled so a Mongo hiccup never blocks scraping.
+func IsScraperDisabled(cfg *config.Config, studioID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var health SourceHealth
+	err := database.Collection(cfg, "scraper_health").
+		FindOne(ctx, bson.M{"studio_id": studioID}).Decode(&health)
+	if err != nil {
+		return false
+	}
+	return health.Disabled
+}
+
+// RecordScrapeRun logs one run's outcome and updates the source's rolling
+// health, disabling it when the degraded streak crosses the threshold.
+// Recording is best-effort: failures are logged, never returned, so health
+// bookkeeping can't break a refresh that otherwise succeeded.
+func RecordScrapeRun(cfg *config.Config, studioID string, stats ScrapeStats, runErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	degraded := isDegradedRun(stats, runErr)
+	run := bson.M{
+		"studio_id":           studioID,
+		"links_found":         stats.LinksFound,
+		"screenshot_failures": stats.ScreenshotFailures,
+		"analysis_failures":   stats.AnalysisFailures,
+		"invalid_summaries":   stats.InvalidSummaries,
+		"workshops":           stats.Workshops,
+		"started_at":          stats.StartedAt,
+		"finished_at":         time.Now().UTC(),
+		"degraded":            degraded,
+	}
+	if runErr != nil {
+		run["error"] = runErr.Error()
+	}
+	if _, err := database.Collection(cfg, "scrape_runs").InsertOne(ctx, run); err != nil {
+		log.Printf("scraper health: recording run for %s failed: %v", studioID, err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{"last_run_at": time.Now().UTC()},
+		"$inc": bson.M{"total_runs": 1},
+	}
+	if runErr != nil {
+		update["$set"].(bson.M)["last_error"] = runErr.Error()
+	}
+	if degraded {
+		update["$inc"].(bson.M)["degraded_streak"] = 1
+		update["$inc"].(bson.M)["total_degraded"] = 1
+	} else {
+		update["$set"].(bson.M)["degraded_streak"] = 0
+	}
+	if stats.LinksFound == 0 {
+		update["$inc"].(bson.M)["zero_result_streak"] = 1
+	} else {
+		update["$set"].(bson.M)["zero_result_streak"] = 0
+	}
+
+	coll := database.Collection(cfg, "scraper_health")
+	var health SourceHealth
+	err := coll.FindOneAndUpdate(ctx, bson.M{"studio_id": studioID}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&health)
+	if err != nil {
+		log.Printf("scraper health: updating health for %s failed: %v", studioID, err)
+		return
+	}
+	if !health.Disabled && health.DegradedStreak >= scrapeDisableStreak {
+		disableScraperSource(ctx, cfg, studioID, &health)
+	}
+}
+
+// isDegradedRun decides whether a run counts against the source: a hard
+// error, no links discovered, or most discovered links failing to yield a
+// workshop.
+func isDegradedRun(stats ScrapeStats, runErr error) bool {
+	if runErr != nil {
+		return true
+	}
+	if stats.LinksFound == 0 {
+		return true
+	}
+	failed := stats.ScreenshotFailures + stats.AnalysisFailures + stats.InvalidSummaries
+	return float64(failed) >= scrapeDegradedFailureRate*float64(stats.LinksFound)
+}
+
+// disableScraperSource flips a source off and alerts admins through the
+// domain event stream and the server log.
+func disableScraperSource(ctx context.Context, cfg *config.Config, studioID string, health *SourceHealth) {
+	reason := "degraded runs"
+	if health.ZeroResultStreak >= scrapeDisableStreak {
+		reason = "no event links discovered; the listing page layout likely changed"
+	} else if health.LastError != "" {
+		reason = health.LastError
+	}
+	now := time.Now().UTC()
+	_, err := database.Collection(cfg, "scraper_health").UpdateOne(ctx,
+		bson.M{"studio_id": studioID},
+		bson.M{"$set": bson.M{"disabled": true, "disabled_reason": reason, "disabled_at": now}})
+	if err != nil {
+		log.Printf("scraper health: disabling %s failed: %v", studioID, err)
+		return
+	}
+	log.Printf("scraper health: auto-disabled source %s after %d degraded runs: %s",
+		studioID, health.DegradedStreak, reason)
+	services.EmitEvent(cfg, services.EventScraperDisabled, bson.M{
+		"studio_id": studioID,
+		"reason":    reason,
+	})
+}
+
+// EnableScraperSource re-enables a disabled source and resets its streaks.
+func EnableScraperSource(cfg *config.Config, studioID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "scraper_health").UpdateOne(ctx,
+		bson.M{"studio_id": studioID},
+		bson.M{
+			"$set":   bson.M{"disabled": false, "degraded_streak": 0, "zero_result_streak": 0},
+			"$unset": bson.M{"disabled_reason": "", "disabled_at": ""},
+		})
+	return err
+}
+
+// GetScraperHealth returns every tracked source's health for the admin
+// dashboard.
+func GetScraperHealth(cfg *config.Config) ([]SourceHealth, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "scraper_health").Find(ctx, bson.M{},
+		options.Find().SetSort(bson.M{"studio_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	health := []SourceHealth{}
+	if err := cursor.All(ctx, &health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
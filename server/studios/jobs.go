@@ -0,0 +1,175 @@
+package studios
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/ai"
+	"dance_api_go/config"
+	"dance_api_go/database"
+)
+
+// Scrape job queue: a studio refresh takes minutes even with the worker
+// pool, far too long to hold an admin HTTP request open. Kicking off a
+// refresh now enqueues a job and returns its ID immediately; a goroutine
+// runs the scrape and the admin polls the job for status and the run
+// counters. Jobs persist in the scrape_jobs collection so the history
+// survives restarts — a job that was mid-flight when the process died is
+// marked abandoned at the next boot rather than staying "running" forever.
+
+// Scrape job lifecycle states.
+const (
+	ScrapeJobQueued    = "queued"
+	ScrapeJobRunning   = "running"
+	ScrapeJobCompleted = "completed"
+	ScrapeJobFailed    = "failed"
+	ScrapeJobAbandoned = "abandoned"
+)
+
+// ScrapeJob is one queued or executed studio refresh.
+type ScrapeJob struct {
+	JobID    string `bson:"job_id" json:"job_id"`
+	StudioID string `bson:"studio_id" json:"studio_id"`
+	Status   string `bson:"status" json:"status"`
+	// DryRun marks a preview job: the scrape runs in full but its outcome
+	// is a pending diff (DiffID) rather than a persisted workshop set.
+	DryRun bool   `bson:"dry_run,omitempty" json:"dry_run,omitempty"`
+	DiffID string `bson:"diff_id,omitempty" json:"diff_id,omitempty"`
+	// Stats carries the run counters (links found, analysis failures,
+	// accepted workshops, ...) once the job has run.
+	Stats     ScrapeStats `bson:"stats" json:"stats"`
+	Workshops int         `bson:"workshops" json:"workshops"`
+	Error     string      `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt time.Time   `bson:"created_at" json:"created_at"`
+	StartedAt *time.Time  `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	EndedAt   *time.Time  `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+}
+
+// EnqueueScrapeJob records a refresh job for the studio and starts it in
+// the background, returning the job for the caller to poll. A dry-run job
+// computes and stores a pending diff instead of persisting (see
+// DryRunWorkshops).
+func EnqueueScrapeJob(cfg *config.Config, studioID string, dryRun bool, analyzer ai.AIAnalyzer) (*ScrapeJob, error) {
+	scraper, err := GetStudioScraper(cfg, studioID, analyzer)
+	if err != nil {
+		return nil, err
+	}
+	job := ScrapeJob{
+		JobID:     uuid.NewString(),
+		StudioID:  studioID,
+		Status:    ScrapeJobQueued,
+		DryRun:    dryRun,
+		CreatedAt: time.Now().UTC(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := database.Collection(cfg, "scrape_jobs").InsertOne(ctx, job); err != nil {
+		return nil, err
+	}
+	go runScrapeJob(cfg, job.JobID, dryRun, scraper)
+	return &job, nil
+}
+
+// runScrapeJob executes one queued refresh (or dry run) and records its
+// outcome.
+func runScrapeJob(cfg *config.Config, jobID string, dryRun bool, scraper Studio) {
+	now := time.Now().UTC()
+	updateScrapeJob(cfg, jobID, bson.M{"status": ScrapeJobRunning, "started_at": now})
+
+	update := bson.M{"status": ScrapeJobCompleted}
+	var err error
+	if dryRun {
+		var diff *ScrapeDiff
+		diff, err = DryRunWorkshops(context.Background(), cfg, scraper)
+		if diff != nil {
+			update["diff_id"] = diff.DiffID
+			update["workshops"] = len(diff.Workshops)
+		}
+	} else {
+		var count int
+		count, err = RefreshWorkshops(context.Background(), cfg, scraper)
+		update["workshops"] = count
+	}
+	update["ended_at"] = time.Now().UTC()
+	if reporter, ok := scraper.(interface{ LastRunStats() ScrapeStats }); ok {
+		update["stats"] = reporter.LastRunStats()
+	}
+	if err != nil {
+		update["status"] = ScrapeJobFailed
+		update["error"] = err.Error()
+		log.Printf("scrape job %s: refresh failed for %s: %v", jobID, scraper.StudioID(), err)
+	}
+	updateScrapeJob(cfg, jobID, update)
+}
+
+// updateScrapeJob applies one status transition; failures are logged since
+// the scrape itself already ran (or is running) regardless.
+func updateScrapeJob(cfg *config.Config, jobID string, fields bson.M) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "scrape_jobs").UpdateOne(ctx,
+		bson.M{"job_id": jobID}, bson.M{"$set": fields})
+	if err != nil {
+		log.Printf("scrape job %s: status update failed: %v", jobID, err)
+	}
+}
+
+// GetScrapeJob returns one job by ID, or (nil, nil) when unknown.
+func GetScrapeJob(cfg *config.Config, jobID string) (*ScrapeJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var job ScrapeJob
+	err := database.Collection(cfg, "scrape_jobs").FindOne(ctx, bson.M{"job_id": jobID}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// scrapeJobHistoryLimit bounds the job listing.
+const scrapeJobHistoryLimit = 50
+
+// ListScrapeJobs returns recent jobs, newest first, optionally narrowed to
+// one studio.
+func ListScrapeJobs(cfg *config.Config, studioID string) ([]ScrapeJob, error) {
+	filter := bson.M{}
+	if studioID != "" {
+		filter["studio_id"] = studioID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "scrape_jobs").Find(ctx, filter,
+		options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(scrapeJobHistoryLimit))
+	if err != nil {
+		return nil, err
+	}
+	jobs := []ScrapeJob{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// AbandonStaleScrapeJobs marks jobs left queued or running by a dead
+// process as abandoned. Jobs run as in-process goroutines, so anything
+// non-terminal at boot cannot still be executing.
+func AbandonStaleScrapeJobs(cfg *config.Config) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := database.Collection(cfg, "scrape_jobs").UpdateMany(ctx,
+		bson.M{"status": bson.M{"$in": []string{ScrapeJobQueued, ScrapeJobRunning}}},
+		bson.M{"$set": bson.M{"status": ScrapeJobAbandoned, "ended_at": time.Now().UTC()}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,189 @@
+package studios
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"dance_api_go/ai"
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/services"
+)
+
+// Retry queue for failed AI analyses. A refresh that loses an OpenAI call
+// used to lose the workshop until the next full scrape; instead the failed
+// task is persisted with its screenshot, retried independently with
+// backoff, and the result merged into the studio's live workshop set when
+// a retry succeeds. Tasks are keyed by payment link, the scraper's stable
+// workshop identity, so repeat refreshes never queue duplicates.
+
+// analysisRetryBaseDelay is the first retry's delay; each subsequent
+// attempt doubles it.
+const analysisRetryBaseDelay = 10 * time.Minute
+
+// analysisRetryMaxAttempts caps retries before a task is marked exhausted;
+// a page that fails analysis five times needs a human, not a sixth call.
+const analysisRetryMaxAttempts = 5
+
+// Task lifecycle states.
+const (
+	analysisTaskPending   = "pending"
+	analysisTaskExhausted = "exhausted"
+)
+
+// analysisTask is one queued analysis retry. The screenshot rides along in
+// the document following the page-snapshot precedent for blobs.
+type analysisTask struct {
+	StudioID      string    `bson:"studio_id"`
+	PaymentLink   string    `bson:"payment_link"`
+	Screenshot    []byte    `bson:"screenshot"`
+	Status        string    `bson:"status"`
+	Attempts      int       `bson:"attempts"`
+	LastError     string    `bson:"last_error"`
+	NextAttemptAt time.Time `bson:"next_attempt_at"`
+	CreatedAt     time.Time `bson:"created_at"`
+}
+
+// EnqueueFailedAnalysis persists a failed analysis for independent retry.
+// Best effort — queueing trouble must not break the scrape that is still
+// running.
+func EnqueueFailedAnalysis(cfg *config.Config, studioID, paymentLink string, screenshot []byte, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "analysis_retries").UpdateOne(ctx,
+		bson.M{"studio_id": studioID, "payment_link": paymentLink, "status": analysisTaskPending},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"studio_id":       studioID,
+				"payment_link":    paymentLink,
+				"screenshot":      screenshot,
+				"status":          analysisTaskPending,
+				"attempts":        0,
+				"next_attempt_at": time.Now().UTC().Add(analysisRetryBaseDelay),
+				"created_at":      time.Now().UTC(),
+			},
+			"$set": bson.M{"last_error": cause.Error()},
+		},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("analysis retry: enqueue failed for %s: %v", paymentLink, err)
+	}
+}
+
+// RetryFailedAnalyses runs every due pending task through the analyzer and
+// merges successful results into the live workshop set. Returns how many
+// workshops were recovered.
+func RetryFailedAnalyses(ctx context.Context, cfg *config.Config, analyzer ai.AIAnalyzer) (int, error) {
+	findCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	cursor, err := database.Collection(cfg, "analysis_retries").Find(findCtx, bson.M{
+		"status":          analysisTaskPending,
+		"next_attempt_at": bson.M{"$lte": time.Now().UTC()},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var tasks []analysisTask
+	if err := cursor.All(findCtx, &tasks); err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, task := range tasks {
+		summary, err := analyzer.AnalyzeScreenshot(ctx, task.Screenshot)
+		if err != nil {
+			recordAnalysisFailure(cfg, task, err)
+			continue
+		}
+		doc := buildWorkshopDoc(task.StudioID, task.PaymentLink, summary)
+		if doc == nil {
+			// The model answered but the page lacks a usable date; more
+			// retries will see the same screenshot.
+			finishAnalysisTask(cfg, task, analysisTaskExhausted)
+			continue
+		}
+		if merged, err := mergeRecoveredWorkshop(cfg, task.StudioID, task.PaymentLink, doc); err != nil {
+			recordAnalysisFailure(cfg, task, err)
+			continue
+		} else if merged {
+			recovered++
+			workshopUUID, _ := doc["uuid"].(string)
+			services.RecordAIDecision(cfg, task.StudioID, workshopUUID, task.PaymentLink, summary)
+		}
+		finishAnalysisTask(cfg, task, "")
+	}
+	if recovered > 0 {
+		database.InvalidateForWrite(cfg, database.EntityWrite{Entity: "workshop"})
+	}
+	return recovered, nil
+}
+
+// mergeRecoveredWorkshop inserts the recovered workshop unless a refresh
+// already re-listed its payment link in the meantime; reports whether an
+// insert happened.
+func mergeRecoveredWorkshop(cfg *config.Config, studioID, paymentLink string, doc bson.M) (bool, error) {
+	existing, err := database.GetWorkshopPaymentLinks(cfg, studioID)
+	if err != nil {
+		return false, err
+	}
+	if existing[paymentLink] {
+		return false, nil
+	}
+	if err := database.InsertWorkshops(cfg, []interface{}{doc}); err != nil {
+		return false, err
+	}
+	services.EmitEvent(cfg, services.EventWorkshopPublished, bson.M{
+		"workshop_uuid":  doc["uuid"],
+		"studio_id":      studioID,
+		"song":           doc["song"],
+		"by":             doc["by"],
+		"artist_id_list": doc["artist_id_list"],
+	})
+	return true, nil
+}
+
+// recordAnalysisFailure bumps the task's attempt count with doubled
+// backoff, exhausting it once the attempt cap is reached.
+func recordAnalysisFailure(cfg *config.Config, task analysisTask, cause error) {
+	attempts := task.Attempts + 1
+	if attempts >= analysisRetryMaxAttempts {
+		log.Printf("analysis retry: giving up on %s after %d attempts: %v", task.PaymentLink, attempts, cause)
+		finishAnalysisTask(cfg, task, analysisTaskExhausted)
+		return
+	}
+	delay := analysisRetryBaseDelay << uint(attempts)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := database.Collection(cfg, "analysis_retries").UpdateOne(ctx,
+		bson.M{"studio_id": task.StudioID, "payment_link": task.PaymentLink, "status": analysisTaskPending},
+		bson.M{"$set": bson.M{
+			"attempts":        attempts,
+			"last_error":      cause.Error(),
+			"next_attempt_at": time.Now().UTC().Add(delay),
+		}})
+	if err != nil {
+		log.Printf("analysis retry: failure record for %s failed: %v", task.PaymentLink, err)
+	}
+}
+
+// finishAnalysisTask removes a completed task, or flips it to the given
+// terminal status (keeping the screenshot for inspection) when non-empty.
+func finishAnalysisTask(cfg *config.Config, task analysisTask, status string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	filter := bson.M{"studio_id": task.StudioID, "payment_link": task.PaymentLink, "status": analysisTaskPending}
+	var err error
+	if status == "" {
+		_, err = database.Collection(cfg, "analysis_retries").DeleteOne(ctx, filter)
+	} else {
+		_, err = database.Collection(cfg, "analysis_retries").UpdateOne(ctx, filter,
+			bson.M{"$set": bson.M{"status": status}})
+	}
+	if err != nil {
+		log.Printf("analysis retry: task cleanup for %s failed: %v", task.PaymentLink, err)
+	}
+}
@@ -0,0 +1,418 @@
+// Package studios implements per-studio workshop scraping: discovering event
+// links on a studio's booking site, analyzing each page with the AI pipeline,
+// and replacing that studio's workshop set in MongoDB.
+package studios
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"dance_api_go/ai"
+	"dance_api_go/config"
+	"dance_api_go/database"
+	"dance_api_go/services"
+	"dance_api_go/utils"
+)
+
+// Studio is a scrapable studio source.
+type Studio interface {
+	StudioID() string
+	FetchExistingWorkshops(ctx context.Context) ([]bson.M, error)
+}
+
+// WebBasedStudioImpl scrapes a studio whose bookings are plain web pages:
+// event links are discovered with a regex over the listing page, then each
+// link is screenshotted and analyzed by a bounded worker pool.
+type WebBasedStudioImpl struct {
+	cfg         *config.Config
+	studioID    string
+	listingURL  string
+	linkPattern *regexp.Regexp
+	analyzer    ai.AIAnalyzer
+	maxWorkers  int
+	lastRun     ScrapeStats
+}
+
+// NewWebBasedStudio builds a scraper for a web-booking studio. Concurrency
+// is capped by SCRAPER_WORKERS.
+func NewWebBasedStudio(cfg *config.Config, studioID, listingURL, linkPattern string, analyzer ai.AIAnalyzer) *WebBasedStudioImpl {
+	return &WebBasedStudioImpl{
+		cfg:         cfg,
+		studioID:    studioID,
+		listingURL:  listingURL,
+		linkPattern: regexp.MustCompile(linkPattern),
+		analyzer:    analyzer,
+		maxWorkers:  cfg.ScraperWorkers,
+	}
+}
+
+// StudioID returns the studio identifier this scraper covers.
+func (s *WebBasedStudioImpl) StudioID() string { return s.studioID }
+
+// FetchExistingWorkshops discovers event links and analyzes the pages with
+// a bounded worker pool, returning the workshop documents to insert. Every
+// page that yields a workshop is archived (HTML plus the analyzed
+// screenshot) under this run's ID so disputed listings can be checked
+// against what the scraper actually saw.
+func (s *WebBasedStudioImpl) FetchExistingWorkshops(ctx context.Context) ([]bson.M, error) {
+	s.lastRun = ScrapeStats{StartedAt: time.Now().UTC()}
+	links, err := discoverEventLinks(ctx, s.listingURL, s.linkPattern)
+	if err != nil {
+		return nil, fmt.Errorf("discover links for %s: %w", s.studioID, err)
+	}
+	s.lastRun.LinksFound = len(links)
+
+	runID := uuid.NewString()
+	var workshops []bson.M
+	for _, result := range s.scrapeLinks(ctx, links) {
+		if result.cachedAnalysis {
+			s.lastRun.CacheHits++
+		}
+		switch {
+		case result.screenshotErr != nil:
+			log.Printf("screenshot failed for %s: %v", result.link, result.screenshotErr)
+			s.lastRun.ScreenshotFailures++
+		case result.analysisErr != nil:
+			log.Printf("analysis failed for %s: %v", result.link, result.analysisErr)
+			s.lastRun.AnalysisFailures++
+			EnqueueFailedAnalysis(s.cfg, s.studioID, result.link, result.screenshot, result.analysisErr)
+		case result.doc == nil:
+			s.lastRun.InvalidSummaries++
+		default:
+			workshops = append(workshops, result.doc)
+			if result.htmlErr != nil {
+				log.Printf("snapshot HTML fetch failed for %s: %v", result.link, result.htmlErr)
+			}
+			workshopUUID, _ := result.doc["uuid"].(string)
+			services.SaveScrapeSnapshot(s.cfg, s.studioID, workshopUUID, runID, result.link, result.html, result.screenshot)
+			services.RecordAIDecision(s.cfg, s.studioID, workshopUUID, result.link, result.summary)
+		}
+	}
+	s.lastRun.Workshops = len(workshops)
+	return workshops, nil
+}
+
+// linkResult is the outcome of scraping one event link. At most one of the
+// error fields is set (htmlErr aside, which only degrades the archive).
+type linkResult struct {
+	link string
+	doc  bson.M
+	// cachedAnalysis marks a summary served from the analysis cache rather
+	// than a fresh OpenAI call.
+	cachedAnalysis bool
+	summary        *ai.EventSummary
+	screenshot     []byte
+	html           []byte
+	htmlErr        error
+	screenshotErr  error
+	analysisErr    error
+}
+
+// openaiRateLimitBackoff is how long every worker holds off after OpenAI
+// answers 429; one throttled call means the next would be throttled too.
+const openaiRateLimitBackoff = 20 * time.Second
+
+// analysisThrottle coordinates the shared backoff across workers.
+type analysisThrottle struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait blocks while a backoff window is open.
+func (t *analysisThrottle) wait() {
+	t.mu.Lock()
+	until := t.until
+	t.mu.Unlock()
+	if d := time.Until(until); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// backOff opens (or extends) the shared backoff window.
+func (t *analysisThrottle) backOff(d time.Duration) {
+	t.mu.Lock()
+	if until := time.Now().Add(d); until.After(t.until) {
+		t.until = until
+	}
+	t.mu.Unlock()
+}
+
+// scrapeLinks fans the links out to at most maxWorkers goroutines. Each
+// link is screenshotted, analyzed, and built independently, so one bad
+// page never stops the rest, and results come back in listing order so
+// aggregation stays deterministic. Writes to MongoDB (snapshots, decision
+// log, retry queue) happen in the caller's aggregation pass, not in the
+// workers.
+func (s *WebBasedStudioImpl) scrapeLinks(ctx context.Context, links []string) []linkResult {
+	workers := s.maxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(links) {
+		workers = len(links)
+	}
+
+	results := make([]linkResult, len(links))
+	jobs := make(chan int)
+	throttle := &analysisThrottle{}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.scrapeLink(ctx, links[i], throttle)
+			}
+		}()
+	}
+	for i := range links {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// scrapeLink processes one event link: screenshot, AI analysis, document
+// build, and the HTML capture for the archive. A rate-limited analysis
+// opens the shared backoff so the other workers stop hammering OpenAI.
+func (s *WebBasedStudioImpl) scrapeLink(ctx context.Context, link string, throttle *analysisThrottle) linkResult {
+	result := linkResult{link: link}
+	screenshot, err := capturePageScreenshot(ctx, link)
+	if err != nil {
+		result.screenshotErr = err
+		return result
+	}
+	result.screenshot = screenshot
+
+	contentHash := ScreenshotHash(screenshot)
+	summary := LookupCachedAnalysis(s.cfg, contentHash)
+	if summary != nil {
+		result.cachedAnalysis = true
+	} else {
+		throttle.wait()
+		var err error
+		summary, err = s.analyzer.AnalyzeScreenshot(ctx, screenshot)
+		if err != nil {
+			if ai.IsRateLimited(err) {
+				throttle.backOff(openaiRateLimitBackoff)
+			}
+			result.analysisErr = err
+			return result
+		}
+		CacheAnalysis(s.cfg, s.studioID, link, contentHash, summary)
+	}
+	result.summary = summary
+	result.doc = buildWorkshopDoc(s.studioID, link, summary)
+	if result.doc == nil {
+		return result
+	}
+	result.html, result.htmlErr = fetchPageHTML(ctx, link)
+	return result
+}
+
+// LastRunStats returns the outcome counters from the most recent
+// FetchExistingWorkshops call, for health scoring.
+func (s *WebBasedStudioImpl) LastRunStats() ScrapeStats { return s.lastRun }
+
+// DanceInnStudioImpl scrapes the Dance Inn studio, whose booking pages need
+// an extra click-through before the event details render.
+type DanceInnStudioImpl struct {
+	WebBasedStudioImpl
+}
+
+// NewDanceInnStudio builds the Dance Inn scraper.
+func NewDanceInnStudio(cfg *config.Config, analyzer ai.AIAnalyzer) *DanceInnStudioImpl {
+	return &DanceInnStudioImpl{
+		WebBasedStudioImpl: *NewWebBasedStudio(cfg, "dance_inn.bangalore",
+			"https://bookings.danceinn.studio", `https://rzp\.io/l/[A-Za-z0-9]+`, analyzer),
+	}
+}
+
+// buildWorkshopDoc converts an AI event summary into a workshop document, or
+// nil when the summary lacks the minimum required fields.
+func buildWorkshopDoc(studioID, paymentLink string, summary *ai.EventSummary) bson.M {
+	if summary.Day == nil || summary.Month == nil || summary.Year == nil {
+		return nil
+	}
+	eventType := summary.EventType
+	if eventType == "" {
+		eventType = "workshop"
+	}
+	var minPrice, maxPrice int64
+	if summary.PricingInfo != nil {
+		minPrice = utils.ParseMinPrice(*summary.PricingInfo)
+		maxPrice = utils.ParseMaxPrice(*summary.PricingInfo)
+	}
+	return bson.M{
+		"uuid":              fmt.Sprintf("%s_%s", studioID, uuid.NewString()),
+		"payment_link":      paymentLink,
+		"payment_link_type": "url",
+		"studio_id":         studioID,
+		"by":                utils.JoinArtistNames(summary.ArtistNames),
+		"song":              summary.Song,
+		"pricing_info":      summary.PricingInfo,
+		"min_price":         minPrice,
+		"max_price":         maxPrice,
+		"artist_id_list":    []string{},
+		"event_type":        eventType,
+		"source_language":   summary.Language,
+		"time_details": []bson.M{{
+			"day":        summary.Day,
+			"month":      summary.Month,
+			"year":       summary.Year,
+			"start_time": summary.StartTime,
+			"end_time":   summary.EndTime,
+		}},
+		"updated_at": float64(time.Now().Unix()),
+	}
+}
+
+// RefreshWorkshops re-scrapes one studio and replaces its workshop set. A
+// WorkshopPublished event is emitted for each workshop whose payment link
+// was not listed before the refresh, and followers of its artists get a
+// new-workshop push (deduped per user and workshop, so repeat refreshes
+// never re-notify).
+func RefreshWorkshops(ctx context.Context, cfg *config.Config, studio Studio) (int, error) {
+	if IsScraperDisabled(cfg, studio.StudioID()) {
+		return 0, fmt.Errorf("scraper for %s is disabled after repeated degraded runs; re-enable it via the admin API", studio.StudioID())
+	}
+
+	workshops, err := studio.FetchExistingWorkshops(ctx)
+	stats := ScrapeStats{StartedAt: time.Now().UTC()}
+	if reporter, ok := studio.(interface{ LastRunStats() ScrapeStats }); ok {
+		stats = reporter.LastRunStats()
+	}
+	if err != nil {
+		RecordScrapeRun(cfg, studio.StudioID(), stats, err)
+		return 0, err
+	}
+	previous, err := database.GetWorkshopPaymentLinks(cfg, studio.StudioID())
+	if err != nil {
+		log.Printf("RefreshWorkshops: reading previous links for %s failed: %v", studio.StudioID(), err)
+		previous = map[string]bool{}
+	}
+	// A run that found nothing while the studio previously had listings is
+	// far more likely a broken source than a genuinely empty schedule:
+	// record it against the source's health and keep the existing set
+	// instead of wiping it.
+	if len(workshops) == 0 && len(previous) > 0 {
+		err := fmt.Errorf("scrape for %s yielded no workshops while %d were previously listed; keeping existing set", studio.StudioID(), len(previous))
+		RecordScrapeRun(cfg, studio.StudioID(), stats, err)
+		return 0, err
+	}
+	locks, err := database.GetLockedWorkshopFields(cfg, studio.StudioID())
+	if err != nil {
+		log.Printf("RefreshWorkshops: reading locked fields for %s failed: %v", studio.StudioID(), err)
+		locks = map[string]database.LockedFieldOverride{}
+	}
+	for _, w := range workshops {
+		link, _ := w["payment_link"].(string)
+		if override, ok := locks[link]; ok {
+			applyLockedFields(w, override)
+		}
+	}
+	count, err := persistWorkshops(cfg, studio.StudioID(), workshops, previous)
+	if err != nil {
+		return 0, err
+	}
+	RecordScrapeRun(cfg, studio.StudioID(), stats, nil)
+	return count, nil
+}
+
+// persistWorkshops replaces a studio's workshop set with the given
+// documents and runs the post-replacement bookkeeping: review task sync,
+// publish events and follower pushes for links not in the previous set,
+// and cache invalidation. Shared by live refreshes and approved dry-run
+// diffs.
+func persistWorkshops(cfg *config.Config, studioID string, workshops []bson.M, previous map[string]bool) (int, error) {
+	if err := database.RemoveWorkshopsGivenStudioId(cfg, studioID); err != nil {
+		return 0, err
+	}
+	docs := make([]interface{}, len(workshops))
+	for i, w := range workshops {
+		docs[i] = w
+	}
+	if err := database.InsertWorkshops(cfg, docs); err != nil {
+		return 0, err
+	}
+	// After locks are applied, so admin-corrected fields count as complete.
+	database.SyncReviewTasks(cfg, studioID, workshops)
+	notif := services.NewNotificationService(cfg)
+	for _, w := range workshops {
+		link, _ := w["payment_link"].(string)
+		if link == "" || previous[link] {
+			continue
+		}
+		services.EmitEvent(cfg, services.EventWorkshopPublished, bson.M{
+			"workshop_uuid":  w["uuid"],
+			"studio_id":      studioID,
+			"song":           w["song"],
+			"by":             w["by"],
+			"artist_id_list": w["artist_id_list"],
+		})
+		workshopUUID, _ := w["uuid"].(string)
+		artistIDs := artistIDList(w["artist_id_list"])
+		if workshopUUID == "" || len(artistIDs) == 0 {
+			continue
+		}
+		artistName, _ := w["by"].(string)
+		go func(uuid string, ids []string, name string) {
+			if sent := notif.NotifyFollowersOfNewWorkshop(uuid, ids, name); sent > 0 {
+				log.Printf("persistWorkshops: notified %d followers about workshop %s", sent, uuid)
+			}
+		}(workshopUUID, artistIDs, artistName)
+	}
+	database.InvalidateForWrite(cfg, database.EntityWrite{Entity: "workshop", StudioID: studioID})
+	return len(workshops), nil
+}
+
+// applyLockedFields copies admin-locked values from the previous refresh's
+// document onto a freshly scraped one, so manual corrections survive the
+// delete-and-reinsert cycle instead of being reverted to the AI extraction.
+func applyLockedFields(doc bson.M, override database.LockedFieldOverride) {
+	for field, value := range override.Values {
+		doc[field] = value
+	}
+	doc["locked_fields"] = override.LockedFields
+}
+
+// artistIDList normalizes the artist_id_list field from a workshop doc,
+// which is []string on freshly built docs but []interface{} after a round
+// trip through Mongo.
+func artistIDList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		ids := make([]string, 0, len(list))
+		for _, v := range list {
+			if id, ok := v.(string); ok && id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	default:
+		return nil
+	}
+}
+
+// GetStudioScraper returns the scraper registered for a studio ID.
+func GetStudioScraper(cfg *config.Config, studioID string, analyzer ai.AIAnalyzer) (Studio, error) {
+	switch studioID {
+	case "dance_inn.bangalore":
+		return NewDanceInnStudio(cfg, analyzer), nil
+	case "vins.bangalore":
+		return NewWebBasedStudio(cfg, "vins.bangalore",
+			"https://www.vinsdancestudio.com/events", `https://www\.vinsdancestudio\.com/event-details/[a-z0-9-]+`, analyzer), nil
+	default:
+		return nil, fmt.Errorf("no scraper registered for studio: %s", studioID)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"dance_api_go/services"
+)
+
+// Public ticket share pages: /ticket/{share_code} shows the workshop a
+// friend booked — song, artist, studio, date — with Open Graph meta so the
+// link unfurls nicely in chat apps, and a deep link to book the same
+// workshop. The entry QR never appears here; the page is an invitation,
+// not a ticket.
+
+// ticketPageTemplate is the share page shell; %s slots are, in order:
+// escaped title (og meta, page title, heading), escaped description,
+// escaped detail line, deep link href, web link href.
+const ticketPageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta property="og:title" content="%s">
+  <meta property="og:description" content="%s">
+  <meta property="og:type" content="website">
+  <title>%s</title>
+  <style>
+    body { margin: 0; font-family: -apple-system, sans-serif; color: #fff; min-height: 100vh;
+           display: flex; align-items: center; justify-content: center;
+           background: linear-gradient(135deg, #0A0A0F, #1A1A2E, #16213E, #0F3460); }
+    .card { max-width: 360px; margin: 16px; padding: 32px 24px; border-radius: 20px; text-align: center;
+            background: linear-gradient(rgba(255,255,255,0.15), rgba(255,255,255,0.05));
+            border: 1.5px solid rgba(255,255,255,0.2); }
+    h1 { font-size: 22px; letter-spacing: 1.2px; margin: 0 0 8px; }
+    p { color: rgba(255,255,255,0.7); margin: 0 0 24px; }
+    a.book { display: block; padding: 16px; border-radius: 16px; text-decoration: none; color: #fff;
+             font-weight: bold; background: linear-gradient(90deg, #00D4FF, #9C27B0); }
+    a.web { display: block; margin-top: 12px; color: rgba(255,255,255,0.5); font-size: 14px; }
+  </style>
+</head>
+<body>
+  <div class="card">
+    <h1>%s</h1>
+    <p>%s</p>
+    <a class="book" href="%s">Book this workshop</a>
+    <a class="web" href="%s">View on nachna.com</a>
+  </div>
+</body>
+</html>
+`
+
+// handleTicketPage serves a share code's public ticket page and records
+// the click for booking attribution.
+func handleTicketPage(ctx *fasthttp.RequestCtx) {
+	shareCode := strings.TrimPrefix(string(ctx.Path()), "/ticket/")
+	if shareCode == "" || strings.Contains(shareCode, "/") {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Not found")
+		return
+	}
+	view, err := services.ResolveShareCode(cfg, shareCode)
+	if err != nil {
+		writeJSONError(ctx, fasthttp.StatusInternalServerError, "Failed to resolve ticket")
+		return
+	}
+	if view == nil {
+		writeJSONError(ctx, fasthttp.StatusNotFound, "Unknown share code")
+		return
+	}
+	services.RecordShareClick(cfg, view,
+		string(ctx.Request.Header.Peek("Referer")),
+		string(ctx.Request.Header.UserAgent()))
+
+	title := "Dance workshop on Nachna"
+	if view.Song != nil && *view.Song != "" {
+		title = *view.Song
+	}
+	if view.Artist != nil && *view.Artist != "" {
+		title = fmt.Sprintf("%s by %s", title, *view.Artist)
+	}
+	detail := view.StudioName
+	if view.Date != "" {
+		detail = strings.TrimSpace(fmt.Sprintf("%s · %s %s", view.StudioName, view.Date, view.Time))
+	}
+	description := "A friend is going to this workshop — join them on Nachna."
+
+	page := fmt.Sprintf(ticketPageTemplate,
+		html.EscapeString(title), html.EscapeString(description),
+		html.EscapeString(title),
+		html.EscapeString(title), html.EscapeString(detail),
+		"nachna://workshop/"+html.EscapeString(view.WorkshopUUID),
+		"https://nachna.com/web/"+html.EscapeString(view.StudioID))
+	ctx.SetStatusCode(fasthttp.StatusOK)
+	ctx.SetContentType("text/html; charset=utf-8")
+	ctx.SetBodyString(page)
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a single cached API response.
+type cacheEntry struct {
+	Body        []byte
+	ContentType string
+	ETag        string
+	CreatedAt   time.Time
+	TTL         time.Duration
+}
+
+var (
+	responseCache   = make(map[string]cacheEntry)
+	responseCacheMu sync.RWMutex
+)
+
+// GetCachedResponse returns the cached body, content type, and ETag for a
+// key, or found=false when the entry is missing or expired.
+func GetCachedResponse(key string) (body []byte, contentType, etag string, found bool) {
+	responseCacheMu.RLock()
+	entry, ok := responseCache[key]
+	responseCacheMu.RUnlock()
+	if !ok || time.Since(entry.CreatedAt) > entry.TTL {
+		recordCacheMiss(key, ok)
+		return nil, "", "", false
+	}
+	recordCacheHit(key)
+	return entry.Body, entry.ContentType, entry.ETag, true
+}
+
+// SetCachedResponse stores an API response body under a key with a TTL and
+// returns the ETag computed for the body.
+func SetCachedResponse(key string, body []byte, contentType string, ttl time.Duration) string {
+	etag := ETagFor(body)
+	responseCacheMu.Lock()
+	responseCache[key] = cacheEntry{Body: body, ContentType: contentType, ETag: etag, CreatedAt: time.Now(), TTL: ttl}
+	responseCacheMu.Unlock()
+	recordCacheStore(key)
+	return etag
+}
+
+// ETagFor computes the strong ETag for a response body: a quoted hex SHA-256
+// of the marshaled JSON.
+func ETagFor(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// ClearCache removes every cached entry whose key starts with prefix. An
+// empty prefix clears the whole cache.
+func ClearCache(prefix string) {
+	responseCacheMu.Lock()
+	var evicted []string
+	for key := range responseCache {
+		if prefix == "" || strings.HasPrefix(key, prefix) {
+			delete(responseCache, key)
+			evicted = append(evicted, key)
+		}
+	}
+	responseCacheMu.Unlock()
+	for _, key := range evicted {
+		recordCacheEviction(key)
+	}
+}
+
+// StartCacheInvalidationWatcher is kept for callers that predate the change
+// stream watchers in the database package; real invalidation now lives in
+// database.StartChangeStreamWatchers.
+func StartCacheInvalidationWatcher() {
+	log.Println("cache invalidation is handled by the database change stream watchers")
+}
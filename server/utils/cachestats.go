@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache instrumentation: every lookup, store, and eviction is counted per
+// key prefix (the first two path segments, e.g. "/api/workshops"), and the
+// stats endpoint combines those counters with a live scan of the cache for
+// sizes and ages. The goal is making TTL tuning data-driven — a prefix with
+// a poor hit rate and young entries wants a longer TTL; one full of old
+// bytes that never get hit wants a shorter one.
+
+// cachePrefixCounters accumulates lookup outcomes for one key prefix.
+type cachePrefixCounters struct {
+	Hits      uint64
+	Misses    uint64
+	Expired   uint64
+	Stores    uint64
+	Evictions uint64
+}
+
+var (
+	cacheCounters   = make(map[string]*cachePrefixCounters)
+	cacheCountersMu sync.Mutex
+)
+
+// CachePrefixStats is one prefix's counters plus a snapshot of its live
+// entries, as served by the admin stats endpoint.
+type CachePrefixStats struct {
+	Prefix        string  `json:"prefix"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Expired       uint64  `json:"expired"`
+	Stores        uint64  `json:"stores"`
+	Evictions     uint64  `json:"evictions"`
+	HitRate       float64 `json:"hit_rate"`
+	Entries       int     `json:"entries"`
+	TotalBytes    int64   `json:"total_bytes"`
+	AvgEntryBytes int64   `json:"avg_entry_bytes"`
+	OldestAgeSecs int64   `json:"oldest_age_seconds"`
+	AvgAgeSecs    int64   `json:"avg_age_seconds"`
+}
+
+// cacheKeyPrefix reduces a cache key to its stats bucket: the path without
+// query string, truncated to the first two segments.
+func cacheKeyPrefix(key string) string {
+	if i := strings.IndexByte(key, '?'); i >= 0 {
+		key = key[:i]
+	}
+	trimmed := strings.TrimPrefix(key, "/")
+	parts := strings.SplitN(trimmed, "/", 3)
+	if len(parts) >= 2 {
+		return "/" + parts[0] + "/" + parts[1]
+	}
+	return "/" + parts[0]
+}
+
+// countersFor returns the counter bucket for a key, creating it on first use.
+// Callers must hold cacheCountersMu.
+func countersFor(key string) *cachePrefixCounters {
+	prefix := cacheKeyPrefix(key)
+	counters, ok := cacheCounters[prefix]
+	if !ok {
+		counters = &cachePrefixCounters{}
+		cacheCounters[prefix] = counters
+	}
+	return counters
+}
+
+func recordCacheHit(key string) {
+	cacheCountersMu.Lock()
+	countersFor(key).Hits++
+	cacheCountersMu.Unlock()
+}
+
+func recordCacheMiss(key string, expired bool) {
+	cacheCountersMu.Lock()
+	counters := countersFor(key)
+	if expired {
+		counters.Expired++
+	} else {
+		counters.Misses++
+	}
+	cacheCountersMu.Unlock()
+}
+
+func recordCacheStore(key string) {
+	cacheCountersMu.Lock()
+	countersFor(key).Stores++
+	cacheCountersMu.Unlock()
+}
+
+func recordCacheEviction(key string) {
+	cacheCountersMu.Lock()
+	countersFor(key).Evictions++
+	cacheCountersMu.Unlock()
+}
+
+// CacheStats snapshots the per-prefix counters merged with the live cache's
+// entry counts, sizes, and ages, sorted by prefix.
+func CacheStats() []CachePrefixStats {
+	type liveStats struct {
+		entries    int
+		totalBytes int64
+		totalAge   time.Duration
+		oldestAge  time.Duration
+	}
+	live := make(map[string]*liveStats)
+	now := time.Now()
+	responseCacheMu.RLock()
+	for key, entry := range responseCache {
+		prefix := cacheKeyPrefix(key)
+		stats, ok := live[prefix]
+		if !ok {
+			stats = &liveStats{}
+			live[prefix] = stats
+		}
+		age := now.Sub(entry.CreatedAt)
+		stats.entries++
+		stats.totalBytes += int64(len(entry.Body))
+		stats.totalAge += age
+		if age > stats.oldestAge {
+			stats.oldestAge = age
+		}
+	}
+	responseCacheMu.RUnlock()
+
+	cacheCountersMu.Lock()
+	prefixes := make(map[string]bool, len(cacheCounters)+len(live))
+	for prefix := range cacheCounters {
+		prefixes[prefix] = true
+	}
+	for prefix := range live {
+		prefixes[prefix] = true
+	}
+	report := make([]CachePrefixStats, 0, len(prefixes))
+	for prefix := range prefixes {
+		row := CachePrefixStats{Prefix: prefix}
+		if counters, ok := cacheCounters[prefix]; ok {
+			row.Hits = counters.Hits
+			row.Misses = counters.Misses
+			row.Expired = counters.Expired
+			row.Stores = counters.Stores
+			row.Evictions = counters.Evictions
+			if lookups := counters.Hits + counters.Misses + counters.Expired; lookups > 0 {
+				row.HitRate = float64(counters.Hits) / float64(lookups)
+			}
+		}
+		if stats, ok := live[prefix]; ok && stats.entries > 0 {
+			row.Entries = stats.entries
+			row.TotalBytes = stats.totalBytes
+			row.AvgEntryBytes = stats.totalBytes / int64(stats.entries)
+			row.OldestAgeSecs = int64(stats.oldestAge / time.Second)
+			row.AvgAgeSecs = int64(stats.totalAge/time.Duration(stats.entries)) / int64(time.Second)
+		}
+		report = append(report, row)
+	}
+	cacheCountersMu.Unlock()
+
+	sort.Slice(report, func(i, j int) bool { return report[i].Prefix < report[j].Prefix })
+	return report
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Currency support for international pop-up workshops. Amounts everywhere
+// in the system are integers in the currency's major unit; DefaultCurrency
+// applies when a studio has not configured one.
+
+// DefaultCurrency is the ISO 4217 code assumed when none is configured.
+const DefaultCurrency = "INR"
+
+// currencySymbols maps supported ISO 4217 codes to display symbols. Codes
+// outside this map are rendered with the code itself as a prefix.
+var currencySymbols = map[string]string{
+	"INR": "₹",
+	"AED": "AED ",
+	"SGD": "S$",
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+}
+
+// NormalizeCurrency returns the code itself, or DefaultCurrency for empty
+// input.
+func NormalizeCurrency(code string) string {
+	if code == "" {
+		return DefaultCurrency
+	}
+	return code
+}
+
+// FormatAmount renders an amount with its currency symbol and thousands
+// grouping, e.g. FormatAmount(1500, "INR") == "₹1,500".
+func FormatAmount(amount int64, currency string) string {
+	currency = NormalizeCurrency(currency)
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency + " "
+	}
+	return symbol + groupThousands(amount)
+}
+
+// groupThousands formats an integer with comma grouping.
+func groupThousands(n int64) string {
+	if n < 0 {
+		return "-" + groupThousands(-n)
+	}
+	s := strconv.FormatInt(n, 10)
+	if len(s) <= 3 {
+		return s
+	}
+	var out []byte
+	lead := len(s) % 3
+	if lead > 0 {
+		out = append(out, s[:lead]...)
+	}
+	for i := lead; i < len(s); i += 3 {
+		if len(out) > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, s[i:i+3]...)
+	}
+	return string(out)
+}
+
+// FormatPriceRange renders a workshop's parsed price range, e.g. "₹799" or
+// "₹799 - ₹1,299".
+func FormatPriceRange(minPrice, maxPrice int64, currency string) string {
+	if minPrice <= 0 {
+		return ""
+	}
+	if maxPrice > minPrice {
+		return fmt.Sprintf("%s - %s", FormatAmount(minPrice, currency), FormatAmount(maxPrice, currency))
+	}
+	return FormatAmount(minPrice, currency)
+}
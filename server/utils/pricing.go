@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pricing_info is scraped free text like "₹799/- early bird, ₹999 OTS" or
+// "Rs. 1,500 per head". priceNumberPattern pulls every plausible amount out
+// of such blobs.
+var priceNumberPattern = regexp.MustCompile(`[0-9][0-9,]*`)
+
+// ParseMinPrice returns the lowest amount found in a free-text pricing blob,
+// or 0 when nothing parseable is present. Amounts under 50 are ignored as
+// noise (session counts, percentages).
+func ParseMinPrice(pricingInfo string) int64 {
+	return pickPrice(pricingInfo, false)
+}
+
+// ParseMaxPrice returns the highest amount found in a free-text pricing
+// blob, or 0 when nothing parseable is present.
+func ParseMaxPrice(pricingInfo string) int64 {
+	return pickPrice(pricingInfo, true)
+}
+
+func pickPrice(pricingInfo string, max bool) int64 {
+	var best int64
+	for _, match := range priceNumberPattern.FindAllString(pricingInfo, -1) {
+		n, err := strconv.ParseInt(strings.ReplaceAll(match, ",", ""), 10, 64)
+		if err != nil || n < 50 {
+			continue
+		}
+		if best == 0 || (max && n > best) || (!max && n < best) {
+			best = n
+		}
+	}
+	return best
+}
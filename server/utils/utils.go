@@ -0,0 +1,56 @@
+// Package utils contains shared helpers for the Golang API server: query
+// instrumentation, the response cache, and thin forwarding wrappers around
+// the formatting package kept so existing call sites and import paths keep
+// working.
+package utils
+
+import (
+	"strings"
+	"time"
+
+	"dance_api_go/formatting"
+	"dance_api_go/models/response"
+)
+
+// IST is the timezone all workshop schedules are interpreted in.
+var IST = formatting.IST
+
+// GetFormattedDate formats time details as e.g. "07th Feb (Thu)". The logic
+// lives in the formatting package; this wrapper remains for existing callers.
+func GetFormattedDate(td response.TimeDetail) string {
+	return formatting.GetFormattedDate(td)
+}
+
+// GetFormattedDateWithDay returns the formatted date plus the full weekday
+// name (e.g. "Thursday") used for this-week day grouping.
+func GetFormattedDateWithDay(td response.TimeDetail) (string, string) {
+	return formatting.GetFormattedDateWithDay(td)
+}
+
+// GetFormattedTime formats a session time range like "6-7:30 PM", matching
+// the Python get_formatted_time behaviour including the "TBA" fallbacks.
+func GetFormattedTime(td response.TimeDetail) string {
+	return formatting.GetFormattedTime(td)
+}
+
+// JoinArtistNames joins multiple artist names the way listings display them.
+func JoinArtistNames(names []string) string {
+	return formatting.JoinArtistNames(names)
+}
+
+// GetTimestampEpoch converts time details to a Unix timestamp, defaulting the
+// start time to midnight when missing or unparseable.
+func GetTimestampEpoch(td response.TimeDetail) int64 {
+	if td.Day == nil || td.Month == nil || td.Year == nil {
+		return 0
+	}
+	hour, minute := 0, 0
+	if td.StartTime != nil && strings.Contains(*td.StartTime, " ") {
+		parts := strings.SplitN(*td.StartTime, " ", 2)
+		clock, meridiem := parts[0], strings.ToUpper(parts[1])
+		if t, err := time.Parse("3:04 PM", clock+" "+meridiem); err == nil {
+			hour, minute = t.Hour(), t.Minute()
+		}
+	}
+	return time.Date(*td.Year, time.Month(*td.Month), *td.Day, hour, minute, 0, 0, IST).Unix()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Build identification for the operational runbook: /api/version tells
+// support exactly which build a misbehaving instance is running without
+// shelling into the box. The stamped values are injected at build time:
+//
+//	go build -ldflags "-X main.gitSHA=$(git rev-parse HEAD) \
+//	  -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unstamped binaries (go run, local builds) report "unknown".
+var (
+	gitSHA    = "unknown"
+	buildTime = "unknown"
+)
+
+// handleVersion serves build and runtime identification. Everything here is
+// non-sensitive: feature flags are booleans only, never the underlying
+// secrets or URLs.
+func handleVersion(ctx *fasthttp.RequestCtx) {
+	writeJSON(ctx, fasthttp.StatusOK, map[string]interface{}{
+		"git_sha":    gitSHA,
+		"build_time": buildTime,
+		"go_version": runtime.Version(),
+		"profile":    cfg.Env,
+		"features": map[string]bool{
+			"embedded_assets":  cfg.UseEmbeddedAssets,
+			"analytics_export": cfg.AnalyticsExportURL != "",
+			"partner_api_keys": len(cfg.PartnerAPIKeys) > 0,
+			"apns":             cfg.APNSKeyID != "",
+			"openai":           cfg.OpenAIAPIKey != "",
+		},
+	})
+}